@@ -26,7 +26,7 @@ var _ = Describe("Ginkgo/Server", func() {
 
 	Context("basic endpoint tests", func() {
 		It("Root should respond correctly", func() {
-			apiTest(nil, nil, nil, nil).
+			apiTest(nil, nil, nil, nil, nil).
 				Get("/").
 				Expect(t).
 				Status(http.StatusOK).
@@ -34,7 +34,7 @@ var _ = Describe("Ginkgo/Server", func() {
 				End()
 		})
 		It("health", func() {
-			apiTest(nil, nil, nil, nil).
+			apiTest(nil, nil, nil, nil, nil).
 				Get("/health").
 				Expect(t).
 				Status(http.StatusOK).
@@ -75,10 +75,10 @@ var _ = Describe("Ginkgo/Server", func() {
 	})
 })
 
-func apiTest(timeWaster app.TimeWaster, memoryGobbler app.MemoryGobbler, cpuWaster app.CPUWaster, customMetricClient app.CustomMetricClient) *apitest.APITest {
+func apiTest(timeWaster app.TimeWaster, memoryGobbler app.MemoryGobbler, cpuWaster app.CPUWaster, diskOccupier app.DiskOccupier, customMetricClient app.CustomMetricClient) *apitest.APITest {
 	GinkgoHelper()
 	logger := zaptest.LoggerWriter(GinkgoWriter)
 
 	return apitest.New().
-		Handler(app.Router(logger, timeWaster, memoryGobbler, cpuWaster, customMetricClient))
+		Handler(app.Router(logger, timeWaster, memoryGobbler, cpuWaster, diskOccupier, customMetricClient))
 }
@@ -16,7 +16,7 @@ var _ = Describe("CPU tests", func() {
 		fakeCPUWaster := &appfakes.FakeCPUWaster{}
 
 		It("should err if utilization not an int64", func() {
-			apiTest(nil, nil, fakeCPUWaster, nil).
+			apiTest(nil, nil, fakeCPUWaster, nil, nil).
 				Get("/cpu/invalid/4").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -24,7 +24,7 @@ var _ = Describe("CPU tests", func() {
 				End()
 		})
 		It("should err if cpu out of bounds", func() {
-			apiTest(nil, nil, fakeCPUWaster, nil).
+			apiTest(nil, nil, fakeCPUWaster, nil, nil).
 				Get("/cpu/100001010101010249032897287298719874687936483275648273632429479827398798271/4").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -32,7 +32,7 @@ var _ = Describe("CPU tests", func() {
 				End()
 		})
 		It("should err if cpu not an int", func() {
-			apiTest(nil, nil, fakeCPUWaster, nil).
+			apiTest(nil, nil, fakeCPUWaster, nil, nil).
 				Get("/cpu/5/invalid").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -40,7 +40,7 @@ var _ = Describe("CPU tests", func() {
 				End()
 		})
 		It("should return ok and sleep correctDuration", func() {
-			apiTest(nil, nil, fakeCPUWaster, nil).
+			apiTest(nil, nil, fakeCPUWaster, nil, nil).
 				Get("/cpu/5/4").
 				Expect(GinkgoT()).
 				Status(http.StatusOK).
@@ -19,7 +19,7 @@ var _ = Describe("custom metrics tests", func() {
 		fakeCustomMetricClient := &appfakes.FakeCustomMetricClient{}
 
 		It("should err if value out of bounds", func() {
-			apiTest(nil, nil, nil, nil).
+			apiTest(nil, nil, nil, nil, nil).
 				Get("/custom-metrics/test/100001010101010249032897287298719874687936483275648273632429479827398798271").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -27,7 +27,7 @@ var _ = Describe("custom metrics tests", func() {
 				End()
 		})
 		It("should err if value not a number", func() {
-			apiTest(nil, nil, nil, nil).
+			apiTest(nil, nil, nil, nil, nil).
 				Get("/custom-metrics/test/invalid").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -35,7 +35,7 @@ var _ = Describe("custom metrics tests", func() {
 				End()
 		})
 		It("should post the custom metric", func() {
-			apiTest(nil, nil, nil, fakeCustomMetricClient).
+			apiTest(nil, nil, nil, nil, fakeCustomMetricClient).
 				Get("/custom-metrics/test/4").
 				Expect(GinkgoT()).
 				Status(http.StatusOK).
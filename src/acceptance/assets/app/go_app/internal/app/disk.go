@@ -0,0 +1,112 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-logr/logr"
+)
+
+//counterfeiter:generate . DiskOccupier
+type DiskOccupier interface {
+	Occupy(numBytes uint64, duration time.Duration) error
+	IsRunning() bool
+	StopTest()
+}
+
+type FileBasedDiskOccupier struct {
+	mu        sync.Mutex
+	isRunning bool
+	filePath  string
+}
+
+var _ DiskOccupier = &FileBasedDiskOccupier{}
+
+func DiskTests(logger logr.Logger, r *gin.RouterGroup, diskOccupier DiskOccupier) *gin.RouterGroup {
+	r.GET("/:diskMiB/:minutes", func(c *gin.Context) {
+		if diskOccupier.IsRunning() {
+			Error(c, http.StatusConflict, "disk test is already running")
+			return
+		}
+		var diskMiB uint64
+		var minutes uint64
+		var err error
+		diskMiB, err = strconv.ParseUint(c.Param("diskMiB"), 10, 64)
+		if err != nil {
+			Error(c, http.StatusBadRequest, "invalid diskMiB: %s", err.Error())
+			return
+		}
+		if minutes, err = strconv.ParseUint(c.Param("minutes"), 10, 64); err != nil {
+			Error(c, http.StatusBadRequest, "invalid minutes: %s", err.Error())
+			return
+		}
+		duration := time.Duration(minutes) * time.Minute
+		if err = diskOccupier.Occupy(diskMiB*Mebi, duration); err != nil {
+			logger.Error(err, "could not occupy disk", "diskMiB", diskMiB)
+			Error(c, http.StatusInternalServerError, "could not occupy disk: %s", err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"diskMiB": diskMiB, "minutes": minutes})
+	})
+
+	r.GET("/close", func(c *gin.Context) {
+		if diskOccupier.IsRunning() {
+			logger.Info("stop disk test")
+			diskOccupier.StopTest()
+			c.JSON(http.StatusOK, gin.H{"status": "close disk test"})
+		} else {
+			Error(c, http.StatusBadRequest, "disk test not running")
+		}
+	})
+	return r
+}
+
+// Occupy writes a file of the given size and removes it after the duration or when the test is stopped.
+func (d *FileBasedDiskOccupier) Occupy(numBytes uint64, duration time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	file, err := os.CreateTemp("", "disk-occupier-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	// The bytes need to be non-zero, otherwise the file system might not allocate them
+	chunk := bytes.Repeat([]byte("X"), chunkSize)
+	for written := uint64(0); written < numBytes; written += chunkSize {
+		if _, err = file.Write(chunk); err != nil {
+			_ = os.Remove(file.Name())
+			return err
+		}
+	}
+
+	d.isRunning = true
+	d.filePath = file.Name()
+	go func() {
+		time.Sleep(duration)
+		d.StopTest()
+	}()
+	return nil
+}
+
+func (d *FileBasedDiskOccupier) IsRunning() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.isRunning
+}
+
+func (d *FileBasedDiskOccupier) StopTest() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.filePath != "" {
+		_ = os.Remove(d.filePath)
+		d.filePath = ""
+	}
+	d.isRunning = false
+}
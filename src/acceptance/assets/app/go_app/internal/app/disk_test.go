@@ -0,0 +1,80 @@
+package app_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler-release/src/acceptance/assets/app/go_app/internal/app"
+	"code.cloudfoundry.org/app-autoscaler-release/src/acceptance/assets/app/go_app/internal/app/appfakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Disk tests", func() {
+
+	Context("Disk handler", func() {
+		fakeDiskOccupier := &appfakes.FakeDiskOccupier{}
+
+		It("should err if diskMiB not an int", func() {
+			apiTest(nil, nil, nil, fakeDiskOccupier, nil).
+				Get("/disk/invalid/4").
+				Expect(GinkgoT()).
+				Status(http.StatusBadRequest).
+				Body(`{"error":{"description":"invalid diskMiB: strconv.ParseUint: parsing \"invalid\": invalid syntax"}}`).
+				End()
+		})
+		It("should err if minutes not an int", func() {
+			apiTest(nil, nil, nil, fakeDiskOccupier, nil).
+				Get("/disk/5/invalid").
+				Expect(GinkgoT()).
+				Status(http.StatusBadRequest).
+				Body(`{"error":{"description":"invalid minutes: strconv.ParseUint: parsing \"invalid\": invalid syntax"}}`).
+				End()
+		})
+		It("should return ok and occupy disk for the duration", func() {
+			apiTest(nil, nil, nil, fakeDiskOccupier, nil).
+				Get("/disk/5/4").
+				Expect(GinkgoT()).
+				Status(http.StatusOK).
+				Body(`{"diskMiB":5, "minutes":4 }`).
+				End()
+
+			Expect(fakeDiskOccupier.OccupyCallCount()).To(Equal(1))
+			numBytes, duration := fakeDiskOccupier.OccupyArgsForCall(0)
+			Expect(numBytes).Should(Equal(uint64(5 * app.Mebi)))
+			Expect(duration).Should(Equal(4 * time.Minute))
+		})
+		It("should err if a disk test is already running", func() {
+			fakeDiskOccupier.IsRunningReturns(true)
+			defer fakeDiskOccupier.IsRunningReturns(false)
+
+			apiTest(nil, nil, nil, fakeDiskOccupier, nil).
+				Get("/disk/5/4").
+				Expect(GinkgoT()).
+				Status(http.StatusConflict).
+				Body(`{"error":{"description":"disk test is already running"}}`).
+				End()
+		})
+	})
+	Context("Occupy", func() {
+		It("should write a file and remove it when the duration is over", func() {
+			diskOccupier := &app.FileBasedDiskOccupier{}
+			before, err := filepath.Glob(filepath.Join(os.TempDir(), "disk-occupier-*"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = diskOccupier.Occupy(app.Mebi, time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diskOccupier.IsRunning()).To(Equal(true))
+			during, err := filepath.Glob(filepath.Join(os.TempDir(), "disk-occupier-*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(during).To(HaveLen(len(before) + 1))
+
+			Eventually(diskOccupier.IsRunning, "2s").Should(Equal(false))
+			after, err := filepath.Glob(filepath.Join(os.TempDir(), "disk-occupier-*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(after).To(HaveLen(len(before)))
+		})
+	})
+})
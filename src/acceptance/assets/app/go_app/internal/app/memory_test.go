@@ -18,7 +18,7 @@ var _ = Describe("Memory tests", func() {
 		fakeMemoryTest := &appfakes.FakeMemoryGobbler{}
 
 		It("should err if memory not an int64", func() {
-			apiTest(nil, fakeMemoryTest, nil, nil).
+			apiTest(nil, fakeMemoryTest, nil, nil, nil).
 				Get("/memory/invalid/4").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -26,7 +26,7 @@ var _ = Describe("Memory tests", func() {
 				End()
 		})
 		It("should err if memory out of bounds", func() {
-			apiTest(nil, fakeMemoryTest, nil, nil).
+			apiTest(nil, fakeMemoryTest, nil, nil, nil).
 				Get("/memory/100001010101010249032897287298719874687936483275648273632429479827398798271/4").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -34,7 +34,7 @@ var _ = Describe("Memory tests", func() {
 				End()
 		})
 		It("should err if memory not an int", func() {
-			apiTest(nil, fakeMemoryTest, nil, nil).
+			apiTest(nil, fakeMemoryTest, nil, nil, nil).
 				Get("/memory/5/invalid").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -42,7 +42,7 @@ var _ = Describe("Memory tests", func() {
 				End()
 		})
 		It("should return ok and sleep correctDuration", func() {
-			apiTest(nil, fakeMemoryTest, nil, nil).
+			apiTest(nil, fakeMemoryTest, nil, nil, nil).
 				Get("/memory/5/4").
 				Expect(GinkgoT()).
 				Status(http.StatusOK).
@@ -15,7 +15,7 @@ var _ = Describe("Responsetime tests", func() {
 	Context("Responsetime tests", func() {
 		fakeTimeWaster := &appfakes.FakeTimeWaster{}
 		It("should err if delayInMS not an int64", func() {
-			apiTest(fakeTimeWaster, nil, nil, nil).
+			apiTest(fakeTimeWaster, nil, nil, nil, nil).
 				Get("/responsetime/slow/yes").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -23,7 +23,7 @@ var _ = Describe("Responsetime tests", func() {
 				End()
 		})
 		It("should err if memory out of bounds", func() {
-			apiTest(fakeTimeWaster, nil, nil, nil).
+			apiTest(fakeTimeWaster, nil, nil, nil, nil).
 				Get("/responsetime/slow/100001010101010249032897287298719874687936483275648273632429479827398798271").
 				Expect(GinkgoT()).
 				Status(http.StatusBadRequest).
@@ -32,7 +32,7 @@ var _ = Describe("Responsetime tests", func() {
 		})
 
 		It("should return ok and sleep correctDuration", func() {
-			apiTest(fakeTimeWaster, nil, nil, nil).
+			apiTest(fakeTimeWaster, nil, nil, nil, nil).
 				Get("/responsetime/slow/4000").
 				Expect(GinkgoT()).
 				Status(http.StatusOK).
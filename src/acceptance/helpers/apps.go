@@ -229,3 +229,12 @@ func AppSetCpuUsage(cfg *config.Config, appName string, percent int, minutes int
 func AppEndCpuTest(cfg *config.Config, appName string, instance int) {
 	Expect(CurlAppInstance(cfg, appName, instance, "/cpu/close")).Should(ContainSubstring(`close cpu test`))
 }
+
+func AppSetDiskUsage(cfg *config.Config, appName string, diskMiB int, minutes int) {
+	GinkgoHelper()
+	Expect(cfh.CurlAppWithTimeout(cfg, appName, fmt.Sprintf("/disk/%d/%d", diskMiB, minutes), 60*time.Second)).Should(MatchJSON(fmt.Sprintf("{\"diskMiB\":%d,\"minutes\":%d}", diskMiB, minutes)))
+}
+
+func AppEndDiskTest(cfg *config.Config, appName string, instance int) {
+	Expect(CurlAppInstance(cfg, appName, instance, "/disk/close")).Should(ContainSubstring(`close disk test`))
+}
@@ -3,6 +3,7 @@ package broker
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,6 +22,7 @@ import (
 	uuid "github.com/nu7hatch/gouuid"
 	"github.com/pivotal-cf/brokerapi/v10/domain"
 	"github.com/pivotal-cf/brokerapi/v10/domain/apiresponses"
+	"github.com/pivotal-cf/brokerapi/v10/middlewares"
 	"golang.org/x/exp/slices"
 )
 
@@ -209,6 +211,12 @@ func (b *Broker) Deprovision(ctx context.Context, instanceID string, details dom
 		}
 	}
 
+	err = b.policydb.DeleteServiceInstanceWebhookSubscriptions(ctx, instanceID)
+	if err != nil {
+		logger.Error("delete-service-instance-webhook-subscriptions", err)
+		return result, apiresponses.NewFailureResponse(serviceInstanceDeletionError, http.StatusInternalServerError, "delete-service-instance-webhook-subscriptions")
+	}
+
 	err = b.bindingdb.DeleteServiceInstance(ctx, instanceID)
 	if err != nil {
 		if errors.Is(err, db.ErrDoesNotExist) {
@@ -574,7 +582,7 @@ func (b *Broker) attachPolicyToApp(ctx context.Context, appGUID string, policy *
 	} else {
 		logger.Info("saving policy")
 
-		if err := b.policydb.SaveAppPolicy(ctx, appGUID, policy, policyGuidStr); err != nil {
+		if err := b.policydb.SaveAppPolicy(ctx, appGUID, policy, policyGuidStr, authorFromOriginatingIdentity(ctx)); err != nil {
 			logger.Error("save-appGUID-policy", err)
 			//failed to save policy, so revert creating binding and custom metrics credential
 			err = b.credentials.Delete(ctx, appGUID)
@@ -596,6 +604,27 @@ func (b *Broker) attachPolicyToApp(ctx context.Context, appGUID string, policy *
 	return nil
 }
 
+// authorFromOriginatingIdentity returns the user id of the X-Broker-API-Originating-Identity header, which the platform
+// sends as "cloudfoundry <base64 encoded JSON>". It returns an empty string if the header is absent or can not be read.
+func authorFromOriginatingIdentity(ctx context.Context) string {
+	originatingIdentity, _ := ctx.Value(middlewares.OriginatingIdentityKey).(string)
+	identityParts := strings.Fields(originatingIdentity)
+	if len(identityParts) != 2 {
+		return ""
+	}
+	identityJson, err := base64.StdEncoding.DecodeString(identityParts[1])
+	if err != nil {
+		return ""
+	}
+	identity := struct {
+		UserId string `json:"user_id"`
+	}{}
+	if err = json.Unmarshal(identityJson, &identity); err != nil {
+		return ""
+	}
+	return identity.UserId
+}
+
 func (b *Broker) handleExistingBindingsResiliently(ctx context.Context, instanceID string, appGUID string, logger lager.Logger) error {
 	// fetch and all service bindings for the service instance
 	logger = logger.Session("handleExistingBindingsResiliently", lager.Data{"app_id": appGUID, "instance_id": instanceID})
@@ -792,6 +821,20 @@ func (b *Broker) deleteBinding(ctx context.Context, bindingId string, serviceIns
 		return ErrDeletePolicyForUnbinding
 	}
 
+	logger.Info("deleting policy revisions")
+	err = b.policydb.DeletePolicyRevisions(ctx, appId)
+	if err != nil {
+		logger.Error("failed to delete policy revisions for unbinding", err)
+		return ErrDeletePolicyForUnbinding
+	}
+
+	logger.Info("deleting webhook subscriptions")
+	err = b.policydb.DeleteWebhookSubscriptions(ctx, appId)
+	if err != nil {
+		logger.Error("failed to delete webhook subscriptions for unbinding", err)
+		return ErrDeletePolicyForUnbinding
+	}
+
 	logger.Info("deleting schedules")
 	err = b.schedulerUtil.DeleteSchedule(ctx, appId)
 	if err != nil {
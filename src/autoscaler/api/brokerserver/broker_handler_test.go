@@ -2,7 +2,9 @@ package brokerserver_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,6 +17,7 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/testhelpers"
 
 	"github.com/pivotal-cf/brokerapi/v10/handlers"
+	"github.com/pivotal-cf/brokerapi/v10/middlewares"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
@@ -725,6 +728,22 @@ var _ = Describe("BrokerHandler", func() {
 			It("succeeds with 200", func() {
 				Expect(resp.Code).To(Equal(http.StatusOK))
 			})
+			It("deletes the webhook subscriptions of the service instance", func() {
+				Expect(policydb.DeleteServiceInstanceWebhookSubscriptionsCallCount()).To(Equal(1))
+				_, instanceId := policydb.DeleteServiceInstanceWebhookSubscriptionsArgsForCall(0)
+				Expect(instanceId).To(Equal(testInstanceId))
+			})
+		})
+
+		Context("When deleting the webhook subscriptions of the service instance fails", func() {
+			BeforeEach(func() {
+				policydb.DeleteServiceInstanceWebhookSubscriptionsReturns(fmt.Errorf("error"))
+			})
+			It("fails with 500 and keeps the service instance", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(MatchJSON(`{"description":"error deleting service instance"}`))
+				Expect(bindingdb.DeleteServiceInstanceCallCount()).To(BeZero())
+			})
 		})
 
 		Context("When service bindings are present", func() {
@@ -779,12 +798,14 @@ var _ = Describe("BrokerHandler", func() {
 
 	Describe("BindServiceInstance", func() {
 		var (
-			err                error
-			bindingRequestBody *models.BindingRequestBody
-			bindingPolicy      string
-			body               []byte
+			err                 error
+			bindingRequestBody  *models.BindingRequestBody
+			bindingPolicy       string
+			body                []byte
+			originatingIdentity string
 		)
 		BeforeEach(func() {
+			originatingIdentity = ""
 			bindingPolicy = `{
 					"instance_max_count":4,
 					"instance_min_count":1,
@@ -822,6 +843,7 @@ var _ = Describe("BrokerHandler", func() {
 		})
 		JustBeforeEach(func() {
 			req, err = http.NewRequest(http.MethodPut, "", bytes.NewReader(body))
+			req = req.WithContext(context.WithValue(req.Context(), middlewares.OriginatingIdentityKey, originatingIdentity))
 			handler.Bind(resp, req)
 		})
 		Context("When request body is not a valid json", func() {
@@ -924,6 +946,17 @@ var _ = Describe("BrokerHandler", func() {
 				Expect(creds.Credentials.CustomMetrics.URL).To(Equal("someURL"))
 				Expect(creds.Credentials.CustomMetrics.MtlsUrl).To(Equal("Mtls-someURL"))
 			})
+			Context("and the platform passes the originating identity", func() {
+				BeforeEach(func() {
+					originatingIdentity = "cloudfoundry " + base64.StdEncoding.EncodeToString([]byte(`{"user_id":"a-user-id"}`))
+				})
+				It("saves the policy with the user as author", func() {
+					Expect(resp.Code).To(Equal(http.StatusCreated))
+					Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
+					_, _, _, _, author := policydb.SaveAppPolicyArgsForCall(0)
+					Expect(author).To(Equal("a-user-id"))
+				})
+			})
 		})
 
 		Context("When a default policy was provided when creating the service instance", func() {
@@ -975,7 +1008,7 @@ var _ = Describe("BrokerHandler", func() {
 				It("succeeds with 201 and saves the binding's policy", func() {
 					Expect(resp.Code).To(Equal(http.StatusCreated))
 					Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
-					ctx, appID, policy, _ := policydb.SaveAppPolicyArgsForCall(0)
+					ctx, appID, policy, _, _ := policydb.SaveAppPolicyArgsForCall(0)
 					Expect(ctx).To(Not(BeNil()))
 					Expect(appID).To(Equal(testAppId))
 					Expect(policy).NotTo(MatchJSON(testDefaultPolicy))
@@ -1002,7 +1035,7 @@ var _ = Describe("BrokerHandler", func() {
 				It("succeeds with 201 and saves the default policy", func() {
 					Expect(resp.Code).To(Equal(http.StatusCreated))
 					Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
-					ctx, appID, policy, _ := policydb.SaveAppPolicyArgsForCall(0)
+					ctx, appID, policy, _, _ := policydb.SaveAppPolicyArgsForCall(0)
 					Expect(ctx).To(Not(BeNil()))
 					Expect(appID).To(Equal(testAppId))
 					Expect(policy).To(MatchJSON(testDefaultPolicy))
@@ -1119,6 +1152,21 @@ var _ = Describe("BrokerHandler", func() {
 				Expect(resp.Code).To(Equal(http.StatusOK))
 				Expect(schedulerServer.ReceivedRequests()).To(HaveLen(1))
 			})
+			It("deletes the webhook subscriptions of the app", func() {
+				Expect(policydb.DeleteWebhookSubscriptionsCallCount()).To(Equal(1))
+				_, appId := policydb.DeleteWebhookSubscriptionsArgsForCall(0)
+				Expect(appId).To(Equal(testAppId))
+			})
+		})
+		Context("When deleting the webhook subscriptions fails", func() {
+			BeforeEach(func() {
+				policydb.DeleteWebhookSubscriptionsReturns(errors.New("some sql error"))
+			})
+			It("fails with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(MatchJSON(`{"description":"unbind failed: error deleting service binding"}`))
+				Expect(bindingdb.DeleteServiceBindingCallCount()).To(BeZero())
+			})
 		})
 		Context("When there is no app with the bindingId", func() {
 			BeforeEach(func() {
@@ -16,6 +16,7 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
 	"github.com/pivotal-cf/brokerapi/v10"
 	"github.com/pivotal-cf/brokerapi/v10/domain"
+	"github.com/pivotal-cf/brokerapi/v10/middlewares"
 
 	"code.cloudfoundry.org/lager/v3"
 	"github.com/go-chi/chi/v5"
@@ -116,6 +117,7 @@ func NewBrokerServer(logger lager.Logger, conf *config.Config, bindingdb db.Bind
 
 	r.Use(basicAuthentication.Middleware)
 	r.Use(httpStatusCollectMiddleware.Collect)
+	r.Use(middlewares.AddOriginatingIdentityToContext)
 	brokerapi.AttachRoutes(r, autoscalerBroker, logger.Session("broker_handler"))
 
 	r.HandleFunc(routes.BrokerHealthPath, GetHealth)
@@ -79,6 +79,11 @@ type CPUConfig struct {
 	UpperThreshold int `yaml:"upper_threshold"`
 }
 
+// WebhookConfig configures the URLs apps may subscribe to their scaling events with.
+type WebhookConfig struct {
+	AllowedNetworks []string `yaml:"allowed_networks"`
+}
+
 type Config struct {
 	Logging               helpers.LoggingConfig         `yaml:"logging"`
 	BrokerServer          helpers.ServerConfig          `yaml:"broker_server"`
@@ -103,6 +108,7 @@ type Config struct {
 	CredHelperImpl        string                        `yaml:"cred_helper_impl"`
 	StoredProcedureConfig *models.StoredProcedureConfig `yaml:"stored_procedure_binding_credential_config"`
 	ScalingRules          ScalingRulesConfig            `yaml:"scaling_rules"`
+	Webhook               WebhookConfig                 `yaml:"webhook"`
 }
 
 type PlanCheckConfig struct {
@@ -189,6 +195,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("Configuration error: ScalingRules.CPU.UpperThreshold is less than zero")
 	}
 
+	if _, err := helpers.NewAddressGuard(c.Webhook.AllowedNetworks); err != nil {
+		return fmt.Errorf("Configuration error: webhook.allowed_networks: %w", err)
+	}
+
 	if !c.UseBuildInMode {
 		if c.DB[db.BindingDb].URL == "" {
 			return fmt.Errorf("Configuration error: BindingDB URL is empty")
@@ -113,6 +113,7 @@ var _ = Describe("Config", func() {
 					},
 				))
 				Expect(conf.CredHelperImpl).To(Equal("default"))
+				Expect(conf.Webhook.AllowedNetworks).To(Equal([]string{"10.0.0.0/8"}))
 			})
 		})
 
@@ -505,6 +506,15 @@ rate_limit:
 			})
 		})
 
+		Context("when webhook.allowed_networks contains an invalid network", func() {
+			BeforeEach(func() {
+				conf.Webhook.AllowedNetworks = []string{"10.0.0.1"}
+			})
+			It("should err", func() {
+				Expect(err).To(MatchError(ContainSubstring("Configuration error: webhook.allowed_networks: invalid allowed network")))
+			})
+		})
+
 		Describe("Using BuildIn Mode", func() {
 			BeforeEach(func() {
 				conf.UseBuildInMode = true
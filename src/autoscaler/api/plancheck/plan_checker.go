@@ -50,7 +50,7 @@ func (pc planChecker) CheckPlan(policy *models.ScalingPolicy, planID string) (bo
 		}
 	}
 
-	numScalingRules := len(policy.ScalingRules)
+	numScalingRules := len(policy.ScalingRules) + len(policy.CompoundScalingRules) + len(policy.TargetTrackingRules)
 	if numScalingRules > definition.ScalingRulesCount {
 		validationResult += fmt.Sprintf("Too many scaling rules: Found %d scaling rules, but a maximum of %d scaling rules are allowed for this service plan. ", numScalingRules, definition.SchedulesCount)
 	}
@@ -149,6 +149,16 @@ func (pv *PolicyValidator) validateAttributes(policy *models.ScalingPolicy, resu
 	scalingRulesContext := gojsonschema.NewJsonContext("scaling_rules", rootContext)
 	pv.validateScalingRuleThreshold(policy, scalingRulesContext, result)
 
+	compoundScalingRulesContext := gojsonschema.NewJsonContext("compound_scaling_rules", rootContext)
+	pv.validateCompoundScalingRuleThreshold(policy, compoundScalingRulesContext, result)
+
+	targetTrackingRulesContext := gojsonschema.NewJsonContext("target_tracking_rules", rootContext)
+	pv.validateTargetTrackingRuleTargetValue(policy, targetTrackingRulesContext, result)
+
+	derivedMetricsContext := gojsonschema.NewJsonContext("derived_metrics", rootContext)
+	pv.validateDerivedMetrics(policy, derivedMetricsContext, result)
+	pv.validateDerivedMetricAggregations(policy, rootContext, result)
+
 	if policy.Schedules == nil {
 		return
 	}
@@ -164,43 +174,172 @@ func (pv *PolicyValidator) validateScalingRuleThreshold(policy *models.ScalingPo
 		errDetails := gojsonschema.ErrorDetails{
 			"scalingRuleIndex": srIndex,
 		}
+		pv.validateThreshold(scalingRule.MetricType, scalingRule.Threshold, "scaling_rules[{{.scalingRuleIndex}}].threshold", currentContext, errDetails, result)
+	}
+}
 
-		switch scalingRule.MetricType {
-		case "memoryused":
-			if scalingRule.Threshold <= 0 {
-				formatString := "scaling_rules[{{.scalingRuleIndex}}].threshold for metric_type memoryused should be greater than 0"
-				err := newPolicyValidationError(currentContext, formatString, errDetails)
-				result.AddError(err, errDetails)
-			}
-		case "memoryutil":
-			if scalingRule.Threshold <= 0 || scalingRule.Threshold > 100 {
-				formatString := "scaling_rules[{{.scalingRuleIndex}}].threshold for metric_type memoryutil should be greater than 0 and less than equal to 100"
-				err := newPolicyValidationError(currentContext, formatString, errDetails)
-				result.AddError(err, errDetails)
-			}
-		case "responsetime":
-			if scalingRule.Threshold <= 0 {
-				formatString := "scaling_rules[{{.scalingRuleIndex}}].threshold for metric_type responsetime should be greater than 0"
-				err := newPolicyValidationError(currentContext, formatString, errDetails)
-				result.AddError(err, errDetails)
-			}
-		case "throughput":
-			if scalingRule.Threshold <= 0 {
-				formatString := "scaling_rules[{{.scalingRuleIndex}}].threshold for metric_type throughput should be greater than 0"
-				err := newPolicyValidationError(currentContext, formatString, errDetails)
-				result.AddError(err, errDetails)
+func (pv *PolicyValidator) validateCompoundScalingRuleThreshold(policy *models.ScalingPolicy, compoundScalingRulesContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	for srIndex, compoundScalingRule := range policy.CompoundScalingRules {
+		for conditionIndex, condition := range compoundScalingRule.Conditions {
+			currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d.conditions.%d", srIndex, conditionIndex), compoundScalingRulesContext)
+			errDetails := gojsonschema.ErrorDetails{
+				"scalingRuleIndex": srIndex,
+				"conditionIndex":   conditionIndex,
 			}
-		case "cpu":
-			if scalingRule.Threshold < int64(pv.scalingRules.CPU.LowerThreshold) || scalingRule.Threshold >= int64(pv.scalingRules.CPU.UpperThreshold) {
-				formatString := fmt.Sprintf("scaling_rules[{{.scalingRuleIndex}}].threshold for metric_type cpu should be greater than %d and less than or equal to %d", pv.scalingRules.CPU.LowerThreshold, pv.scalingRules.CPU.UpperThreshold)
+			pv.validateThreshold(condition.MetricType, condition.Threshold, "compound_scaling_rules[{{.scalingRuleIndex}}].conditions[{{.conditionIndex}}].threshold", currentContext, errDetails, result)
+		}
+	}
+}
+
+func (pv *PolicyValidator) validateTargetTrackingRuleTargetValue(policy *models.ScalingPolicy, targetTrackingRulesContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	for ttrIndex, targetTrackingRule := range policy.TargetTrackingRules {
+		currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d", ttrIndex), targetTrackingRulesContext)
+		errDetails := gojsonschema.ErrorDetails{
+			"targetTrackingRuleIndex": ttrIndex,
+		}
+		pv.validateThreshold(targetTrackingRule.MetricType, targetTrackingRule.TargetValue, "target_tracking_rules[{{.targetTrackingRuleIndex}}].target_value", currentContext, errDetails, result)
+	}
+}
+
+func (pv *PolicyValidator) validateDerivedMetrics(policy *models.ScalingPolicy, derivedMetricsContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	names := map[string]bool{}
+	for dmIndex, derivedMetric := range policy.DerivedMetrics {
+		currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d", dmIndex), derivedMetricsContext)
+		errDetails := gojsonschema.ErrorDetails{
+			"derivedMetricIndex": dmIndex,
+			"name":               derivedMetric.Name,
+		}
+
+		if models.IsStandardMetric(derivedMetric.Name) || derivedMetric.Name == models.MetricNameInstances {
+			formatString := "derived_metrics[{{.derivedMetricIndex}}].name {{.name}} is reserved for a standard metric"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		} else if names[derivedMetric.Name] {
+			formatString := "derived_metrics[{{.derivedMetricIndex}}].name {{.name}} is defined more than once"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+		names[derivedMetric.Name] = true
+
+		expression, err := models.ParseExpression(derivedMetric.Expression)
+		if err != nil {
+			errDetails["error"] = err.Error()
+			formatString := "derived_metrics[{{.derivedMetricIndex}}].expression is invalid: {{.error}}"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+			continue
+		}
+		for _, variable := range expression.Variables() {
+			if policy.GetDerivedMetric(variable) != nil {
+				errDetails["variable"] = variable
+				formatString := "derived_metrics[{{.derivedMetricIndex}}].expression must not refer to the derived metric {{.variable}}"
 				err := newPolicyValidationError(currentContext, formatString, errDetails)
 				result.AddError(err, errDetails)
 			}
-		default:
 		}
 	}
 }
 
+// validateDerivedMetricAggregations rejects aggregations of derived metrics, as they are computed from the aggregated
+// metrics of their variables.
+func (pv *PolicyValidator) validateDerivedMetricAggregations(policy *models.ScalingPolicy, rootContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
+	validate := func(metricType string, aggregation string, path string, currentContext *gojsonschema.JsonContext) {
+		if policy.GetDerivedMetric(metricType) == nil || aggregation == "" || aggregation == models.AggregationAvg {
+			return
+		}
+		errDetails := gojsonschema.ErrorDetails{
+			"metric_type": metricType,
+		}
+		formatString := path + ".aggregation for derived metric {{.metric_type}} must be avg"
+		err := newPolicyValidationError(currentContext, formatString, errDetails)
+		result.AddError(err, errDetails)
+	}
+
+	scalingRulesContext := gojsonschema.NewJsonContext("scaling_rules", rootContext)
+	for srIndex, scalingRule := range policy.ScalingRules {
+		currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d", srIndex), scalingRulesContext)
+		validate(scalingRule.MetricType, scalingRule.Aggregation, fmt.Sprintf("scaling_rules[%d]", srIndex), currentContext)
+	}
+	compoundScalingRulesContext := gojsonschema.NewJsonContext("compound_scaling_rules", rootContext)
+	for srIndex, compoundScalingRule := range policy.CompoundScalingRules {
+		for conditionIndex, condition := range compoundScalingRule.Conditions {
+			currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d.conditions.%d", srIndex, conditionIndex), compoundScalingRulesContext)
+			validate(condition.MetricType, condition.Aggregation, fmt.Sprintf("compound_scaling_rules[%d].conditions[%d]", srIndex, conditionIndex), currentContext)
+		}
+	}
+	targetTrackingRulesContext := gojsonschema.NewJsonContext("target_tracking_rules", rootContext)
+	for ttrIndex, targetTrackingRule := range policy.TargetTrackingRules {
+		currentContext := gojsonschema.NewJsonContext(fmt.Sprintf("%d", ttrIndex), targetTrackingRulesContext)
+		validate(targetTrackingRule.MetricType, targetTrackingRule.Aggregation, fmt.Sprintf("target_tracking_rules[%d]", ttrIndex), currentContext)
+	}
+}
+
+func (pv *PolicyValidator) validateThreshold(metricType string, threshold float64, thresholdPath string, currentContext *gojsonschema.JsonContext, errDetails gojsonschema.ErrorDetails, result *gojsonschema.Result) {
+	switch metricType {
+	case "memoryused":
+		if threshold <= 0 {
+			formatString := thresholdPath + " for metric_type memoryused should be greater than 0"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "memoryutil":
+		if threshold <= 0 || threshold > 100 {
+			formatString := thresholdPath + " for metric_type memoryutil should be greater than 0 and less than equal to 100"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "responsetime":
+		if threshold <= 0 {
+			formatString := thresholdPath + " for metric_type responsetime should be greater than 0"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "throughput":
+		if threshold <= 0 {
+			formatString := thresholdPath + " for metric_type throughput should be greater than 0"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "responsetime_p90", "responsetime_p95", "responsetime_p99":
+		if threshold <= 0 {
+			formatString := thresholdPath + " for metric_type " + metricType + " should be greater than 0"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "errorrate":
+		if threshold < 0 || threshold > 100 {
+			formatString := thresholdPath + " for metric_type errorrate should be greater than or equal to 0 and less than or equal to 100"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "diskused":
+		if threshold <= 0 {
+			formatString := thresholdPath + " for metric_type diskused should be greater than 0"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "diskutil":
+		if threshold <= 0 || threshold > 100 {
+			formatString := thresholdPath + " for metric_type diskutil should be greater than 0 and less than or equal to 100"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "cpu_entitlement":
+		if threshold <= 0 {
+			formatString := thresholdPath + " for metric_type cpu_entitlement should be greater than 0"
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	case "cpu":
+		if threshold < float64(pv.scalingRules.CPU.LowerThreshold) || threshold >= float64(pv.scalingRules.CPU.UpperThreshold) {
+			formatString := fmt.Sprintf(thresholdPath+" for metric_type cpu should be greater than %d and less than or equal to %d", pv.scalingRules.CPU.LowerThreshold, pv.scalingRules.CPU.UpperThreshold)
+			err := newPolicyValidationError(currentContext, formatString, errDetails)
+			result.AddError(err, errDetails)
+		}
+	default:
+	}
+}
+
 func (pv *PolicyValidator) validateRecurringSchedules(policy *models.ScalingPolicy, schedulesContext *gojsonschema.JsonContext, result *gojsonschema.Result) {
 	recurringScheduleContext := gojsonschema.NewJsonContext("recurring_schedule", schedulesContext)
 	for scheduleIndex, recSched := range policy.Schedules.RecurringSchedules {
@@ -3,6 +3,7 @@ package policyvalidator_test
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
@@ -297,7 +298,7 @@ var _ = Describe("PolicyValidator", func() {
 					Expect(errResult).To(Equal([]PolicyValidationErrors{
 						{
 							Context:     "(root).scaling_rules.0.metric_type",
-							Description: "String length must be less than or equal to 100",
+							Description: "String length must be less than or equal to 93",
 						},
 					}))
 				})
@@ -327,7 +328,7 @@ var _ = Describe("PolicyValidator", func() {
 					}))
 				})
 			})
-			Context("when threshold is not integer", func() {
+			Context("when threshold is a decimal", func() {
 				BeforeEach(func() {
 					policyString = `{
 					"instance_max_count":4,
@@ -343,13 +344,31 @@ var _ = Describe("PolicyValidator", func() {
 					}]
 				}`
 				})
-				It("should fail", func() {
-					Expect(errResult).To(Equal([]PolicyValidationErrors{
-						{
-							Context:     "(root)",
-							Description: "json: cannot unmarshal number 90.55 into Go struct field ScalingRule.scaling_rules.threshold of type int64",
-						},
-					}))
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when threshold for a custom metric is a decimal between 0 and 1", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"queue_ratio",
+						"breach_duration_secs":600,
+						"threshold": 0.75,
+						"operator":">",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
 				})
 			})
 
@@ -548,6 +567,224 @@ var _ = Describe("PolicyValidator", func() {
 				})
 			})
 
+			Context("when threshold for responsetime_p95 is 0", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"responsetime_p95",
+						"breach_duration_secs":600,
+						"threshold": 0,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0",
+							Description: "scaling_rules[0].threshold for metric_type responsetime_p95 should be greater than 0",
+						},
+					}))
+				})
+			})
+
+			Context("when threshold for responsetime_p99 is greater than 0", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"responsetime_p99",
+						"breach_duration_secs":600,
+						"threshold": 800,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when threshold for errorrate is greater than 100", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"errorrate",
+						"breach_duration_secs":600,
+						"threshold": 120,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0",
+							Description: "scaling_rules[0].threshold for metric_type errorrate should be greater than or equal to 0 and less than or equal to 100",
+						},
+					}))
+				})
+			})
+
+			Context("when threshold for errorrate is 0", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"errorrate",
+						"breach_duration_secs":600,
+						"threshold": 0,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when threshold for diskused is 0", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"diskused",
+						"breach_duration_secs":600,
+						"threshold": 0,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0",
+							Description: "scaling_rules[0].threshold for metric_type diskused should be greater than 0",
+						},
+					}))
+				})
+			})
+
+			Context("when threshold for diskutil is greater than 100", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"diskutil",
+						"breach_duration_secs":600,
+						"threshold": 120,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0",
+							Description: "scaling_rules[0].threshold for metric_type diskutil should be greater than 0 and less than or equal to 100",
+						},
+					}))
+				})
+			})
+
+			Context("when threshold for diskutil is between 0 and 100", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"diskutil",
+						"breach_duration_secs":600,
+						"threshold": 80,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when threshold for cpu_entitlement is 0", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu_entitlement",
+						"breach_duration_secs":600,
+						"threshold": 0,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0",
+							Description: "scaling_rules[0].threshold for metric_type cpu_entitlement should be greater than 0",
+						},
+					}))
+				})
+			})
+
+			Context("when threshold for cpu_entitlement is greater than 100", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu_entitlement",
+						"breach_duration_secs":600,
+						"threshold": 150,
+						"operator":">=",
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
 			Context(fmt.Sprintf("when threshold for cpu is less than %d", lowerCPUThreshold), func() {
 				BeforeEach(func() {
 					policyString = `{
@@ -931,6 +1168,757 @@ var _ = Describe("PolicyValidator", func() {
 				})
 			})
 		})
+		Context("Compound Scaling Rules", func() {
+			Context("when a valid compound scaling rule is present", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"compound_scaling_rules":[
+					{
+						"combinator":"and",
+						"conditions":[
+						{
+							"metric_type":"cpu",
+							"breach_duration_secs":120,
+							"threshold":70,
+							"operator":">"
+						},
+						{
+							"metric_type":"responsetime",
+							"threshold":300,
+							"operator":">"
+						}],
+						"cool_down_secs":300,
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when combinator is invalid", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"compound_scaling_rules":[
+					{
+						"combinator":"xor",
+						"conditions":[
+						{
+							"metric_type":"cpu",
+							"threshold":70,
+							"operator":">"
+						},
+						{
+							"metric_type":"responsetime",
+							"threshold":300,
+							"operator":">"
+						}],
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).compound_scaling_rules.0.combinator",
+							Description: `compound_scaling_rules.0.combinator must be one of the following: "and", "or"`,
+						},
+					}))
+				})
+			})
+
+			Context("when there is only one condition", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"compound_scaling_rules":[
+					{
+						"combinator":"or",
+						"conditions":[
+						{
+							"metric_type":"cpu",
+							"threshold":70,
+							"operator":">"
+						}],
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).compound_scaling_rules.0.conditions",
+							Description: "Array must have at least 2 items",
+						},
+					}))
+				})
+			})
+
+			Context("when the threshold of a condition is out of range", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"compound_scaling_rules":[
+					{
+						"combinator":"and",
+						"conditions":[
+						{
+							"metric_type":"cpu",
+							"threshold":70,
+							"operator":">"
+						},
+						{
+							"metric_type":"memoryutil",
+							"threshold":120,
+							"operator":">"
+						}],
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).compound_scaling_rules.0.conditions.1",
+							Description: "compound_scaling_rules[0].conditions[1].threshold for metric_type memoryutil should be greater than 0 and less than equal to 100",
+						},
+					}))
+				})
+			})
+		})
+
+		Context("Process Type", func() {
+			Context("when the policy and a rule name process types", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"process_type":"worker",
+					"scaling_rules":[
+					{
+						"metric_type":"queue_length",
+						"threshold":100,
+						"operator":">",
+						"adjustment":"+1"
+					},
+					{
+						"metric_type":"cpu",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1",
+						"process_type":"web"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when the process type is invalid", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1",
+						"process_type":"web worker"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0.process_type",
+							Description: "Does not match pattern '^[a-zA-Z0-9_-]+$'",
+						},
+					}))
+				})
+			})
+		})
+
+		Context("Mode", func() {
+			Context("when the policy is in shadow mode", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"mode":"shadow",
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when the mode is unknown", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"mode":"dry-run",
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).mode",
+							Description: `mode must be one of the following: "active", "shadow"`,
+						},
+					}))
+				})
+			})
+		})
+
+		Context("ScaleInStabilizationWindow", func() {
+			Context("when the scale-in stabilization window is valid", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scale_in_stabilization_window_secs":300,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"threshold":20,
+						"operator":"<",
+						"adjustment":"-1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when the scale-in stabilization window is longer than an hour", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scale_in_stabilization_window_secs":3601,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"threshold":20,
+						"operator":"<",
+						"adjustment":"-1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scale_in_stabilization_window_secs",
+							Description: "Must be less than or equal to 3600",
+						},
+					}))
+				})
+			})
+		})
+
+		Context("Scaling Limits", func() {
+			Context("when the scaling limits are valid", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":50,
+					"instance_min_count":1,
+					"max_scale_out_step":5,
+					"max_scale_in_step":2,
+					"max_scaling_actions_per_hour":6,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1000%"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when a scaling limit is below 1", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":50,
+					"instance_min_count":1,
+					"max_scaling_actions_per_hour":0,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).max_scaling_actions_per_hour",
+							Description: "Must be greater than or equal to 1",
+						},
+					}))
+				})
+			})
+		})
+
+		Context("Aggregation", func() {
+			Context("when the rules use aggregations", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"queue_depth",
+						"aggregation":"sum",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					},
+					{
+						"metric_type":"responsetime",
+						"aggregation":"p95",
+						"threshold":200,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when the aggregation is unknown", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"aggregation":"p99",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0.aggregation",
+							Description: `scaling_rules.0.aggregation must be one of the following: "avg", "max", "min", "sum", "median", "p95"`,
+						},
+					}))
+				})
+			})
+
+			Context("when a target tracking rule uses another aggregation than avg", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"target_tracking_rules":[
+					{
+						"metric_type":"responsetime",
+						"aggregation":"p95",
+						"target_value":200
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).target_tracking_rules.0.aggregation",
+							Description: `target_tracking_rules.0.aggregation must be one of the following: "avg"`,
+						},
+					}))
+				})
+			})
+
+			Context("when the metric type leaves no room for the aggregation suffix", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"` + strings.Repeat("a", 94) + `",
+						"aggregation":"median",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0.metric_type",
+							Description: "String length must be less than or equal to 93",
+						},
+					}))
+				})
+			})
+		})
+
+		Context("Missing Data", func() {
+			Context("when the rules treat missing data", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"queue_depth",
+						"missing_data":"breaching",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}],
+					"compound_scaling_rules":[
+					{
+						"combinator":"and",
+						"conditions":[
+							{"metric_type":"cpu","threshold":70,"operator":">","missing_data":"last_known"},
+							{"metric_type":"responsetime","threshold":300,"operator":">","missing_data":"ignore"}
+						],
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when the missing data treatment is unknown", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"scaling_rules":[
+					{
+						"metric_type":"cpu",
+						"missing_data":"zero",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0.missing_data",
+							Description: `scaling_rules.0.missing_data must be one of the following: "ignore", "breaching", "not_breaching", "last_known"`,
+						},
+					}))
+				})
+			})
+		})
+
+		Context("Derived Metrics", func() {
+			Context("when rules refer to valid derived metrics", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"derived_metrics":[
+						{"name":"throughput_per_instance","expression":"throughput / instances","unit":"rps"},
+						{"name":"queue_load","expression":"(custom_queue_len:sum - 10) / 50"}
+					],
+					"scaling_rules":[
+					{
+						"metric_type":"throughput_per_instance",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}],
+					"compound_scaling_rules":[
+					{
+						"combinator":"or",
+						"conditions":[
+							{"metric_type":"queue_load","aggregation":"avg","threshold":1,"operator":">"},
+							{"metric_type":"cpu","threshold":70,"operator":">"}
+						],
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when the expression is invalid", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"derived_metrics":[{"name":"throughput_per_instance","expression":"throughput / (instances"}],
+					"scaling_rules":[
+					{
+						"metric_type":"throughput_per_instance",
+						"threshold":80,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).derived_metrics.0",
+							Description: "derived_metrics[0].expression is invalid: missing ) at position 23",
+						},
+					}))
+				})
+			})
+
+			Context("when the names clash with standard metrics or each other", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"derived_metrics":[
+						{"name":"cpu","expression":"memoryused * 2"},
+						{"name":"queue_load","expression":"queue / 50"},
+						{"name":"queue_load","expression":"queue / 20"}
+					],
+					"scaling_rules":[
+					{
+						"metric_type":"queue_load",
+						"threshold":1,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).derived_metrics.0",
+							Description: "derived_metrics[0].name cpu is reserved for a standard metric",
+						},
+						{
+							Context:     "(root).derived_metrics.2",
+							Description: "derived_metrics[2].name queue_load is defined more than once",
+						},
+					}))
+				})
+			})
+
+			Context("when an expression refers to a derived metric", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"derived_metrics":[
+						{"name":"queue_load","expression":"queue / 50"},
+						{"name":"queue_load_per_instance","expression":"queue_load / instances"}
+					],
+					"scaling_rules":[
+					{
+						"metric_type":"queue_load_per_instance",
+						"threshold":1,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).derived_metrics.1",
+							Description: "derived_metrics[1].expression must not refer to the derived metric queue_load",
+						},
+					}))
+				})
+			})
+
+			Context("when a rule aggregates a derived metric", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"derived_metrics":[{"name":"queue_load","expression":"queue / 50"}],
+					"scaling_rules":[
+					{
+						"metric_type":"queue_load",
+						"aggregation":"max",
+						"threshold":1,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).scaling_rules.0",
+							Description: "scaling_rules[0].aggregation for derived metric queue_load must be avg",
+						},
+					}))
+				})
+			})
+
+			Context("when the expression is missing", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"derived_metrics":[{"name":"queue_load"}],
+					"scaling_rules":[
+					{
+						"metric_type":"queue_load",
+						"threshold":1,
+						"operator":">",
+						"adjustment":"+1"
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).derived_metrics.0",
+							Description: "expression is required",
+						},
+					}))
+				})
+			})
+		})
+
+		Context("Target Tracking Rules", func() {
+			Context("when a valid target tracking rule is present", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"target_tracking_rules":[
+					{
+						"metric_type":"cpu",
+						"target_value":60,
+						"breach_duration_secs":120,
+						"cool_down_secs":300,
+						"scale_out_dampening":1,
+						"scale_in_dampening":0.5
+					}]
+				}`
+				})
+				It("should succeed", func() {
+					Expect(errResult).To(BeNil())
+					Expect(policyJson).To(MatchJSON(policyString))
+				})
+			})
+
+			Context("when target_value is not greater than 0", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"target_tracking_rules":[
+					{
+						"metric_type":"throughput",
+						"target_value":0
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).target_tracking_rules.0.target_value",
+							Description: "Must be greater than 0",
+						},
+					}))
+				})
+			})
+
+			Context("when scale_in_dampening is greater than 1", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"target_tracking_rules":[
+					{
+						"metric_type":"throughput",
+						"target_value":100,
+						"scale_in_dampening":1.5
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).target_tracking_rules.0.scale_in_dampening",
+							Description: "Must be less than or equal to 1",
+						},
+					}))
+				})
+			})
+
+			Context("when target_value for memoryutil is out of range", func() {
+				BeforeEach(func() {
+					policyString = `{
+					"instance_max_count":4,
+					"instance_min_count":1,
+					"target_tracking_rules":[
+					{
+						"metric_type":"memoryutil",
+						"target_value":120
+					}]
+				}`
+				})
+				It("should fail", func() {
+					Expect(errResult).To(Equal([]PolicyValidationErrors{
+						{
+							Context:     "(root).target_tracking_rules.0",
+							Description: "target_tracking_rules[0].target_value for metric_type memoryutil should be greater than 0 and less than equal to 100",
+						},
+					}))
+				})
+			})
+		})
+
 		Context("Schedules", func() {
 
 			Context("when timezone is missing", func() {
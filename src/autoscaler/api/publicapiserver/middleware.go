@@ -1,6 +1,8 @@
 package publicapiserver
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
@@ -180,3 +182,31 @@ func (mw *Middleware) HasClientToken(next http.Handler) http.Handler {
 		writeErrorResponse(w, http.StatusUnauthorized, "client is not authorized to perform the requested action")
 	})
 }
+
+// userNameFromToken returns the user name in the claims of a bearer token which has been checked by the Oauth
+// middleware, or the client id for client credentials tokens. It returns an empty string if the token can not be read.
+func userNameFromToken(userToken string) string {
+	tokenParts := strings.Fields(userToken)
+	if len(tokenParts) != 2 {
+		return ""
+	}
+	jwtParts := strings.Split(tokenParts[1], ".")
+	if len(jwtParts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwtParts[1], "="))
+	if err != nil {
+		return ""
+	}
+	claims := struct {
+		UserName string `json:"user_name"`
+		ClientId string `json:"client_id"`
+	}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	if claims.UserName != "" {
+		return claims.UserName
+	}
+	return claims.ClientId
+}
@@ -1,14 +1,19 @@
 package publicapiserver
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"reflect"
+	"strconv"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/config"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/policyvalidator"
@@ -118,6 +123,12 @@ func (h *PublicApiHandler) AttachScalingPolicy(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	h.saveScalingPolicy(w, r, logger, appId, policyBytes)
+}
+
+// saveScalingPolicy validates the policy, saves it as a new revision of the policy of the app and updates the
+// schedules of the app accordingly. The saved policy is written to the response.
+func (h *PublicApiHandler) saveScalingPolicy(w http.ResponseWriter, r *http.Request, logger lager.Logger, appId string, policyBytes []byte) {
 	policy, errResults := h.policyValidator.ValidatePolicy(policyBytes)
 	if errResults != nil {
 		logger.Info("Failed to validate policy", lager.Data{"errResults": errResults})
@@ -132,7 +143,7 @@ func (h *PublicApiHandler) AttachScalingPolicy(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	err = h.policydb.SaveAppPolicy(r.Context(), appId, policy, policyGuid.String())
+	err = h.policydb.SaveAppPolicy(r.Context(), appId, policy, policyGuid.String(), userNameFromToken(r.Header.Get("Authorization")))
 	if err != nil {
 		logger.Error("Failed to save policy", err)
 		writeErrorResponse(w, http.StatusInternalServerError, "Error saving policy")
@@ -202,7 +213,7 @@ func (h *PublicApiHandler) DetachScalingPolicy(w http.ResponseWriter, r *http.Re
 				return
 			}
 
-			err = h.policydb.SaveAppPolicy(r.Context(), appId, policy, policyGuidStr)
+			err = h.policydb.SaveAppPolicy(r.Context(), appId, policy, policyGuidStr, userNameFromToken(r.Header.Get("Authorization")))
 			if err != nil {
 				logger.Error("failed to save policy", err, lager.Data{"policy": policyStr})
 				writeErrorResponse(w, http.StatusInternalServerError, "Error attaching the default policy")
@@ -229,6 +240,222 @@ func (h *PublicApiHandler) DetachScalingPolicy(w http.ResponseWriter, r *http.Re
 	}
 }
 
+func (h *PublicApiHandler) GetPolicyRevisions(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+	logger := h.logger.Session("GetPolicyRevisions", lager.Data{"appId": appId})
+	logger.Info("Get Policy Revisions")
+
+	revisions, err := h.policydb.RetrievePolicyRevisions(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve policy revisions from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revisions")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, revisions)
+}
+
+func (h *PublicApiHandler) GetPolicyRevision(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	logger := h.logger.Session("GetPolicyRevision", lager.Data{"appId": vars["appId"], "version": vars["version"]})
+	logger.Info("Get Policy Revision")
+
+	revision, ok := h.getPolicyRevision(w, r, logger, vars)
+	if !ok {
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, revision)
+}
+
+// DiffPolicyRevision compares a revision of the policy with the revision given by the "to" query parameter or, if
+// the parameter is absent, with the current policy of the app.
+func (h *PublicApiHandler) DiffPolicyRevision(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	logger := h.logger.Session("DiffPolicyRevision", lager.Data{"appId": vars["appId"], "version": vars["version"], "to": r.URL.Query().Get("to")})
+	logger.Info("Diff Policy Revision")
+
+	revision, ok := h.getPolicyRevision(w, r, logger, vars)
+	if !ok {
+		return
+	}
+
+	var toPolicy *models.ScalingPolicy
+	if to := r.URL.Query().Get("to"); to != "" {
+		toRevision, ok := h.getPolicyRevision(w, r, logger, map[string]string{"appId": vars["appId"], "version": to})
+		if !ok {
+			return
+		}
+		toPolicy = toRevision.Policy
+	} else {
+		var err error
+		toPolicy, err = h.policydb.GetAppPolicy(r.Context(), revision.AppId)
+		if err != nil {
+			logger.Error("Failed to retrieve scaling policy from database", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling policy")
+			return
+		}
+	}
+
+	changes, err := models.DiffPolicies(revision.Policy, toPolicy)
+	if err != nil {
+		logger.Error("Failed to compare policies", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error comparing policies")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, changes)
+}
+
+// RollbackPolicyRevision attaches the policy of a revision again, which keeps it as the newest revision.
+func (h *PublicApiHandler) RollbackPolicyRevision(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	logger := h.logger.Session("RollbackPolicyRevision", lager.Data{"appId": vars["appId"], "version": vars["version"]})
+	logger.Info("Rollback Policy Revision")
+
+	revision, ok := h.getPolicyRevision(w, r, logger, vars)
+	if !ok {
+		return
+	}
+
+	policyBytes, err := json.Marshal(revision.Policy)
+	if err != nil {
+		logger.Error("Failed to marshal policy", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error marshaling policy")
+		return
+	}
+
+	h.saveScalingPolicy(w, r, logger, revision.AppId, policyBytes)
+}
+
+// getPolicyRevision looks up the revision given by the "appId" and "version" vars. If the revision can not be
+// returned, an error response is written and false is returned.
+func (h *PublicApiHandler) getPolicyRevision(w http.ResponseWriter, r *http.Request, logger lager.Logger, vars map[string]string) (*models.PolicyRevision, bool) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return nil, false
+	}
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "version must be a positive integer")
+		return nil, false
+	}
+
+	revision, err := h.policydb.GetPolicyRevision(r.Context(), appId, version)
+	if err != nil {
+		logger.Error("Failed to retrieve policy revision from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving policy revision")
+		return nil, false
+	}
+	if revision == nil {
+		logger.Info("policy revision doesn't exist", lager.Data{"version": version})
+		writeErrorResponse(w, http.StatusNotFound, "Policy Revision Not Found")
+		return nil, false
+	}
+	return revision, true
+}
+
+func (h *PublicApiHandler) GetSuspension(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+	logger := h.logger.Session("GetSuspension", lager.Data{"appId": appId})
+	logger.Info("Get Suspension")
+
+	suspension, err := h.policydb.GetAppSuspension(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve suspension from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving suspension")
+		return
+	}
+
+	if !suspension.IsActive(time.Now()) {
+		logger.Info("app is not suspended")
+		writeErrorResponse(w, http.StatusNotFound, "Suspension Not Found")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, suspension)
+}
+
+func (h *PublicApiHandler) SuspendApp(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+	logger := h.logger.Session("SuspendApp", lager.Data{"appId": appId})
+	logger.Info("Suspend App")
+
+	var suspendRequest struct {
+		ExpireAt int64  `json:"expire_at"`
+		Reason   string `json:"reason"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&suspendRequest)
+	if err != nil && !errors.Is(err, io.EOF) {
+		logger.Info("Failed to decode request body", lager.Data{"error": err.Error()})
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body format")
+		return
+	}
+
+	now := time.Now()
+	if suspendRequest.ExpireAt != 0 && suspendRequest.ExpireAt <= now.UnixNano() {
+		writeErrorResponse(w, http.StatusBadRequest, "expire_at must be in the future")
+		return
+	}
+	if len(suspendRequest.Reason) > 255 {
+		writeErrorResponse(w, http.StatusBadRequest, "reason must not be longer than 255 characters")
+		return
+	}
+
+	suspension := &models.AppSuspension{
+		AppId:       appId,
+		SuspendedAt: now.UnixNano(),
+		ExpireAt:    suspendRequest.ExpireAt,
+		Reason:      suspendRequest.Reason,
+	}
+	err = h.policydb.SuspendApp(r.Context(), suspension)
+	if err != nil {
+		logger.Error("Failed to save suspension", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error suspending app")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, suspension)
+}
+
+func (h *PublicApiHandler) ResumeApp(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+	logger := h.logger.Session("ResumeApp", lager.Data{"appId": appId})
+	logger.Info("Resume App")
+
+	err := h.policydb.ResumeApp(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to delete suspension from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error resuming app")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write([]byte("{}"))
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
 func (h *PublicApiHandler) GetScalingHistories(w http.ResponseWriter, req *http.Request, vars map[string]string) {
 	appId := vars["appId"]
 	logger := h.logger.Session("GetScalingHistories", lager.Data{"appId": appId})
@@ -267,6 +494,153 @@ func (h *PublicApiHandler) GetScalingHistories(w http.ResponseWriter, req *http.
 	}
 }
 
+func (h *PublicApiHandler) GetScalingState(w http.ResponseWriter, _ *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	logger := h.logger.Session("GetScalingState", lager.Data{"appId": appId})
+	logger.Info("Get ScalingState")
+
+	path, _ := routes.ScalingEngineRoutes().Get(routes.GetScalingStateRouteName).URLPath("appid", appId)
+	targetURL := h.conf.ScalingEngine.ScalingEngineUrl + path.RequestURI()
+	resp, err := h.scalingEngineClient.Get(targetURL)
+	if err != nil {
+		logger.Error("error-getting-scaling-state", err, lager.Data{"url": targetURL})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling state from scaling engine")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	responseData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("error-reading-scaling-state", err, lager.Data{"url": targetURL})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving scaling state from scaling engine")
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("error-getting-scaling-state", nil, lager.Data{"statusCode": resp.StatusCode, "body": string(responseData), "url": targetURL})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	_, err = w.Write(responseData)
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
+// ExportScalingHistories streams the scaling history export of the scaling engine to the client without buffering it.
+func (h *PublicApiHandler) ExportScalingHistories(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	logger := h.logger.Session("ExportScalingHistories", lager.Data{"appId": appId})
+	logger.Info("Export ScalingHistories")
+
+	path, _ := routes.ScalingEngineRoutes().Get(routes.ExportScalingHistoriesRouteName).URLPath("appid", appId)
+	query := url.Values{}
+	for _, parameter := range []string{"format", "start-time", "end-time", "order-direction", "status", "scaling-type", "reason"} {
+		for _, value := range r.URL.Query()[parameter] {
+			query.Add(parameter, value)
+		}
+	}
+	path.RawQuery = query.Encode()
+	targetURL := h.conf.ScalingEngine.ScalingEngineUrl + path.RequestURI()
+	resp, err := h.scalingEngineClient.Get(targetURL)
+	if err != nil {
+		logger.Error("error-exporting-scaling-histories", err, lager.Data{"url": targetURL})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error exporting scaling histories from scaling engine")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("error-exporting-scaling-histories", nil, lager.Data{"statusCode": resp.StatusCode, "url": targetURL})
+	}
+
+	for _, header := range []string{"Content-Type", "Content-Disposition"} {
+		if value := resp.Header.Get(header); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
+func (h *PublicApiHandler) ManualScale(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	logger := h.logger.Session("ManualScale", lager.Data{"appId": appId})
+	logger.Info("Manual Scale")
+
+	manualScalingRequest := models.ManualScalingRequest{}
+	err := json.NewDecoder(r.Body).Decode(&manualScalingRequest)
+	if err != nil {
+		logger.Info("Failed to decode request body", lager.Data{"error": err.Error()})
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body format")
+		return
+	}
+	if manualScalingRequest.Instances < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "instances must be greater than or equal to 1")
+		return
+	}
+	if !models.IsValidProcessType(manualScalingRequest.ProcessType) {
+		writeErrorResponse(w, http.StatusBadRequest, "process_type must only contain letters, digits, '_' and '-'")
+		return
+	}
+	manualScalingRequest.Requester = userNameFromToken(r.Header.Get("Authorization"))
+
+	body, err := json.Marshal(manualScalingRequest)
+	if err != nil {
+		logger.Error("Failed to marshal manual scaling request", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error scaling app")
+		return
+	}
+
+	path, _ := routes.ScalingEngineRoutes().Get(routes.ManualScaleRouteName).URLPath("appid", appId)
+	targetURL := h.conf.ScalingEngine.ScalingEngineUrl + path.RequestURI()
+	resp, err := h.scalingEngineClient.Post(targetURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Error("error-scaling-app", err, lager.Data{"url": targetURL})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error scaling app")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	responseData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("error-reading-scaling-result", err, lager.Data{"url": targetURL})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error scaling app")
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("error-scaling-app", nil, lager.Data{"statusCode": resp.StatusCode, "body": string(responseData), "url": targetURL})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	_, err = w.Write(responseData)
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
 func proxyRequest(pathFn func() string, call func(url string) (*http.Response, error), w http.ResponseWriter, reqUrl *url.URL, parameters *url.Values, requestDescription string, logger lager.Logger) {
 	aUrl := pathFn()
 	resp, err := call(aUrl)
@@ -323,6 +697,77 @@ func (h *PublicApiHandler) GetAggregatedMetricsHistories(w http.ResponseWriter,
 	proxyRequest(pathFn, h.eventGeneratorClient.Get, w, req.URL, parameters, "metrics history from eventgenerator", logger)
 }
 
+func (h *PublicApiHandler) BacktestScalingPolicy(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	logger := h.logger.Session("BacktestScalingPolicy", lager.Data{"appId": appId})
+	logger.Info("Backtest Scaling Policy")
+
+	var backtestRequest struct {
+		Policy    json.RawMessage `json:"policy"`
+		Start     int64           `json:"start"`
+		End       int64           `json:"end"`
+		Instances int             `json:"instances"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&backtestRequest)
+	if err != nil {
+		logger.Info("Failed to decode request body", lager.Data{"error": err.Error()})
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body format")
+		return
+	}
+
+	policy, errResults := h.policyValidator.ValidatePolicy(backtestRequest.Policy)
+	if errResults != nil {
+		logger.Info("Failed to validate policy", lager.Data{"errResults": errResults})
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, errResults)
+		return
+	}
+
+	body, err := json.Marshal(models.BacktestRequest{
+		Policy:    policy,
+		Start:     backtestRequest.Start,
+		End:       backtestRequest.End,
+		Instances: backtestRequest.Instances,
+	})
+	if err != nil {
+		logger.Error("Failed to marshal backtest request", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error backtesting policy")
+		return
+	}
+
+	path, _ := routes.EventGeneratorRoutes().Get(routes.BacktestRouteName).URLPath("appid", appId)
+	aUrl := h.conf.EventGenerator.EventGeneratorUrl + path.RequestURI()
+	resp, err := h.eventGeneratorClient.Post(aUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Error("Failed to backtest policy in eventgenerator", err, lager.Data{"url": aUrl})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error backtesting policy")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	responseData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read backtest result", err, lager.Data{"url": aUrl})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error backtesting policy")
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("Failed to backtest policy in eventgenerator", nil, lager.Data{"statusCode": resp.StatusCode, "body": string(responseData), "url": aUrl})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	_, err = w.Write(responseData)
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
+
 func (h *PublicApiHandler) GetApiInfo(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
 	info, err := os.ReadFile(h.conf.InfoFilePath)
 	if err != nil {
@@ -409,3 +854,228 @@ func (h *PublicApiHandler) DeleteCredential(w http.ResponseWriter, r *http.Reque
 	}
 	handlers.WriteJSONResponse(w, http.StatusOK, nil)
 }
+
+func (h *PublicApiHandler) GetWebhooks(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+	logger := h.logger.Session("GetWebhooks", lager.Data{"appId": appId})
+	logger.Info("Get Webhooks")
+
+	subscriptions, err := h.policydb.RetrieveWebhookSubscriptions(r.Context(), appId)
+	if err != nil {
+		logger.Error("Failed to retrieve webhook subscriptions from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving webhooks")
+		return
+	}
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, subscriptions)
+}
+
+// CreateWebhook subscribes a URL to the scaling events of the app, or of every app bound to the same service instance.
+// The secret used to sign the deliveries is only returned here. URLs pointing to internal addresses are rejected, the
+// scaling engine checks the addresses again when it connects.
+func (h *PublicApiHandler) CreateWebhook(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+	logger := h.logger.Session("CreateWebhook", lager.Data{"appId": appId})
+	logger.Info("Create Webhook")
+
+	var webhookRequest struct {
+		Url    string `json:"url"`
+		Secret string `json:"secret"`
+		Scope  string `json:"scope"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&webhookRequest)
+	if err != nil {
+		logger.Info("Failed to decode request body", lager.Data{"error": err.Error()})
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body format")
+		return
+	}
+
+	webhookUrl, err := url.Parse(webhookRequest.Url)
+	if err != nil || webhookUrl.Scheme != "https" || webhookUrl.Host == "" || len(webhookRequest.Url) > 2048 {
+		writeErrorResponse(w, http.StatusBadRequest, "url must be an absolute https URL of at most 2048 characters")
+		return
+	}
+	rejection, err := h.checkWebhookHost(r.Context(), webhookUrl.Hostname())
+	if err != nil {
+		logger.Error("Failed to check the webhook host", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error creating webhook")
+		return
+	}
+	if rejection != "" {
+		writeErrorResponse(w, http.StatusBadRequest, rejection)
+		return
+	}
+	if len(webhookRequest.Secret) > 255 {
+		writeErrorResponse(w, http.StatusBadRequest, "secret must not be longer than 255 characters")
+		return
+	}
+	switch webhookRequest.Scope {
+	case "", models.WebhookScopeApp, models.WebhookScopeServiceInstance:
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "scope must be either app or service_instance")
+		return
+	}
+	if webhookRequest.Scope == models.WebhookScopeServiceInstance && h.conf.UseBuildInMode {
+		writeErrorResponse(w, http.StatusBadRequest, "scope service_instance is not available without the service broker")
+		return
+	}
+
+	guid, err := uuid.NewV4()
+	if err != nil {
+		logger.Error("Failed to generate webhook guid", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error creating webhook")
+		return
+	}
+	secret := webhookRequest.Secret
+	if secret == "" {
+		generatedSecret, err := uuid.NewV4()
+		if err != nil {
+			logger.Error("Failed to generate webhook secret", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error creating webhook")
+			return
+		}
+		secret = generatedSecret.String()
+	}
+
+	subscription := &models.WebhookSubscription{
+		Guid:      guid.String(),
+		AppId:     appId,
+		Url:       webhookRequest.Url,
+		Secret:    secret,
+		CreatedAt: time.Now().UnixNano(),
+	}
+	if webhookRequest.Scope == models.WebhookScopeServiceInstance {
+		// the apps of the service instance are resolved whenever a scaling event is queued
+		serviceInstance, err := h.bindingdb.GetServiceInstanceByAppId(appId)
+		if err != nil {
+			logger.Error("Failed to get the service instance of the app", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Error creating webhook")
+			return
+		}
+		subscription.AppId = ""
+		subscription.ServiceInstanceId = serviceInstance.ServiceInstanceId
+	}
+
+	err = h.policydb.SaveWebhookSubscriptions(r.Context(), []*models.WebhookSubscription{subscription})
+	if err != nil {
+		logger.Error("Failed to save webhook subscriptions", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error creating webhook")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusCreated, subscription)
+}
+
+// checkWebhookHost returns why the host of a webhook URL is rejected, or an empty string. IP addresses are rejected as
+// well as host names resolving to loopback, private or link-local addresses outside of the allowed networks. Host names
+// which cannot be resolved yet are accepted.
+func (h *PublicApiHandler) checkWebhookHost(ctx context.Context, host string) (string, error) {
+	if net.ParseIP(host) != nil {
+		return "url must use a host name instead of an IP address", nil
+	}
+	guard, err := helpers.NewAddressGuard(h.conf.Webhook.AllowedNetworks)
+	if err != nil {
+		return "", err
+	}
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", nil
+	}
+	for _, address := range addresses {
+		if !guard.Allows(address.IP) {
+			return "url must not point to a loopback, private or link-local address", nil
+		}
+	}
+	return "", nil
+}
+
+func (h *PublicApiHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+	webhookId := vars["webhookId"]
+	logger := h.logger.Session("DeleteWebhook", lager.Data{"appId": appId, "webhookId": webhookId})
+	logger.Info("Delete Webhook")
+
+	subscription, err := h.policydb.GetWebhookSubscription(r.Context(), appId, webhookId)
+	if err != nil {
+		logger.Error("Failed to retrieve webhook subscription from database", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error deleting webhook")
+		return
+	}
+	if subscription == nil {
+		writeErrorResponse(w, http.StatusNotFound, "Webhook Not Found")
+		return
+	}
+
+	err = h.policydb.DeleteWebhookSubscription(r.Context(), webhookId)
+	if err != nil {
+		logger.Error("Failed to delete webhook subscription", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Error deleting webhook")
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, nil)
+}
+
+func (h *PublicApiHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appId"]
+	if appId == "" {
+		h.logger.Error(ActionCheckAppId, errors.New(ErrorMessageAppidIsRequired), nil)
+		writeErrorResponse(w, http.StatusBadRequest, ErrorMessageAppidIsRequired)
+		return
+	}
+
+	logger := h.logger.Session("GetWebhookDeliveries", lager.Data{"appId": appId})
+	logger.Info("Get WebhookDeliveries")
+
+	path, _ := routes.ScalingEngineRoutes().Get(routes.GetWebhookDeliveriesRouteName).URLPath("appid", appId)
+	query := url.Values{}
+	for _, parameter := range []string{"status", "limit"} {
+		if value := r.URL.Query().Get(parameter); value != "" {
+			query.Set(parameter, value)
+		}
+	}
+	path.RawQuery = query.Encode()
+	targetURL := h.conf.ScalingEngine.ScalingEngineUrl + path.RequestURI()
+	resp, err := h.scalingEngineClient.Get(targetURL)
+	if err != nil {
+		logger.Error("error-getting-webhook-deliveries", err, lager.Data{"url": targetURL})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving webhook deliveries from scaling engine")
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	responseData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("error-reading-webhook-deliveries", err, lager.Data{"url": targetURL})
+		writeErrorResponse(w, http.StatusInternalServerError, "Error retrieving webhook deliveries from scaling engine")
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("error-getting-webhook-deliveries", nil, lager.Data{"statusCode": resp.StatusCode, "body": string(responseData), "url": targetURL})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.StatusCode)
+	_, err = w.Write(responseData)
+	if err != nil {
+		logger.Error(ActionWriteBody, err)
+	}
+}
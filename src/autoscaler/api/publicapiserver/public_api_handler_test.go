@@ -2,12 +2,15 @@ package publicapiserver_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"time"
 
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/api/publicapiserver"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
@@ -278,6 +281,221 @@ var _ = Describe("PublicApiHandler", func() {
 				Expect(resp.Code).To(Equal(http.StatusOK))
 			})
 		})
+
+		Context("When the request has a user token", func() {
+			BeforeEach(func() {
+				pathVariables["appId"] = TEST_APP_ID
+				req, _ = http.NewRequest(http.MethodPut, "", bytes.NewBufferString(VALID_POLICY_STR))
+				req.Header.Set("Authorization", "bearer header."+base64.RawURLEncoding.EncodeToString([]byte(`{"user_name":"a-user"}`))+".signature")
+				schedulerStatus = 200
+			})
+			It("should save the policy with the user as author", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
+				_, appId, _, _, author := policydb.SaveAppPolicyArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(author).To(Equal("a-user"))
+			})
+		})
+	})
+
+	Describe("GetPolicyRevisions", func() {
+		JustBeforeEach(func() {
+			handler.GetPolicyRevisions(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When database gives error", func() {
+			BeforeEach(func() {
+				pathVariables["appId"] = TEST_APP_ID
+				policydb.RetrievePolicyRevisionsReturns(nil, fmt.Errorf("Failed to retrieve policy revisions"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving policy revisions"}`))
+			})
+		})
+
+		Context("When revisions exist", func() {
+			BeforeEach(func() {
+				pathVariables["appId"] = TEST_APP_ID
+				policydb.RetrievePolicyRevisionsReturns([]*models.PolicyRevision{
+					{AppId: TEST_APP_ID, Version: 2, PolicyGuid: "guid-2", Author: "a-user", CreatedAt: 222},
+					{AppId: TEST_APP_ID, Version: 1, PolicyGuid: "guid-1", Author: "", CreatedAt: 111},
+				}, nil)
+			})
+			It("should return the revisions", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`[
+					{"app_id":"` + TEST_APP_ID + `","version":2,"policy_guid":"guid-2","author":"a-user","created_at":222},
+					{"app_id":"` + TEST_APP_ID + `","version":1,"policy_guid":"guid-1","author":"","created_at":111}
+				]`))
+			})
+		})
+	})
+
+	Describe("GetPolicyRevision", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["version"] = "1"
+		})
+		JustBeforeEach(func() {
+			handler.GetPolicyRevision(resp, req, pathVariables)
+		})
+
+		Context("When the version is not valid", func() {
+			BeforeEach(func() {
+				pathVariables["version"] = "0"
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"version must be a positive integer"}`))
+			})
+		})
+
+		Context("When database gives error", func() {
+			BeforeEach(func() {
+				policydb.GetPolicyRevisionReturns(nil, fmt.Errorf("Failed to retrieve policy revision"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving policy revision"}`))
+			})
+		})
+
+		Context("When the revision does not exist", func() {
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Policy Revision Not Found"}`))
+			})
+		})
+
+		Context("When the revision exists", func() {
+			BeforeEach(func() {
+				policydb.GetPolicyRevisionReturns(&models.PolicyRevision{
+					AppId: TEST_APP_ID, Version: 1, PolicyGuid: "guid-1", Author: "a-user", CreatedAt: 111,
+					Policy: &models.ScalingPolicy{InstanceMin: 1, InstanceMax: 3},
+				}, nil)
+			})
+			It("should return the revision with its policy", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"app_id":"` + TEST_APP_ID + `","version":1,"policy_guid":"guid-1","author":"a-user","created_at":111,
+					"policy":{"instance_min_count":1,"instance_max_count":3}}`))
+				_, appId, version := policydb.GetPolicyRevisionArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(version).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("DiffPolicyRevision", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["version"] = "1"
+			policydb.GetPolicyRevisionStub = func(_ context.Context, appId string, version int) (*models.PolicyRevision, error) {
+				return &models.PolicyRevision{AppId: appId, Version: version, Policy: &models.ScalingPolicy{InstanceMin: version, InstanceMax: 5}}, nil
+			}
+			policydb.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 10}, nil)
+		})
+		JustBeforeEach(func() {
+			handler.DiffPolicyRevision(resp, req, pathVariables)
+		})
+
+		Context("When no revision to compare with is given", func() {
+			It("should compare the revision with the current policy", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`[{"path":"instance_max_count","from":5,"to":10}]`))
+			})
+		})
+
+		Context("When a revision to compare with is given", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions/1/diff?to=2", nil)
+			})
+			It("should compare the revisions", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`[{"path":"instance_min_count","from":1,"to":2}]`))
+				Expect(policydb.GetAppPolicyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When the revision to compare with is not valid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/policy/revisions/1/diff?to=latest", nil)
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"version must be a positive integer"}`))
+			})
+		})
+
+		Context("When the app has no policy", func() {
+			BeforeEach(func() {
+				policydb.GetAppPolicyReturns(nil, nil)
+			})
+			It("should report all values of the revision as removed", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`[
+					{"path":"instance_max_count","from":5,"to":null},
+					{"path":"instance_min_count","from":1,"to":null}
+				]`))
+			})
+		})
+	})
+
+	Describe("RollbackPolicyRevision", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["version"] = "1"
+			req, _ = http.NewRequest(http.MethodPost, "", nil)
+			schedulerStatus = 200
+		})
+		JustBeforeEach(func() {
+			handler.RollbackPolicyRevision(resp, req, pathVariables)
+		})
+
+		Context("When the revision does not exist", func() {
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(policydb.SaveAppPolicyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When the policy of the revision is no longer valid", func() {
+			BeforeEach(func() {
+				policydb.GetPolicyRevisionReturns(&models.PolicyRevision{AppId: TEST_APP_ID, Version: 1,
+					Policy: &models.ScalingPolicy{InstanceMin: 1}}, nil)
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(policydb.SaveAppPolicyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When the policy of the revision is valid", func() {
+			var policy *models.ScalingPolicy
+			BeforeEach(func() {
+				Expect(json.Unmarshal([]byte(VALID_POLICY_STR), &policy)).To(Succeed())
+				policydb.GetPolicyRevisionReturns(&models.PolicyRevision{AppId: TEST_APP_ID, Version: 1, PolicyGuid: "old-guid", Policy: policy}, nil)
+				req.Header.Set("Authorization", "bearer header."+base64.RawURLEncoding.EncodeToString([]byte(`{"client_id":"a-client"}`))+".signature")
+			})
+			It("should save the policy as a new revision", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body).To(MatchJSON(VALID_POLICY_STR))
+				Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
+				_, appId, savedPolicy, policyGuid, author := policydb.SaveAppPolicyArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(savedPolicy).To(Equal(policy))
+				Expect(policyGuid).NotTo(Equal("old-guid"))
+				Expect(author).To(Equal("a-client"))
+			})
+		})
 	})
 
 	Describe("DetachScalingPolicy", func() {
@@ -368,7 +586,7 @@ var _ = Describe("PublicApiHandler", func() {
 						It("should succeed and set the default policy", func() {
 							Expect(resp.Code).To(Equal(http.StatusOK))
 							Expect(policydb.SaveAppPolicyCallCount()).To(Equal(1))
-							c, a, p, g := policydb.SaveAppPolicyArgsForCall(0)
+							c, a, p, g, _ := policydb.SaveAppPolicyArgsForCall(0)
 							Expect(c).NotTo(BeNil())
 							Expect(a).To(Equal(TEST_APP_ID))
 							Expect(p).To(MatchJSON(VALID_POLICY_STR))
@@ -831,84 +1049,91 @@ var _ = Describe("PublicApiHandler", func() {
 		})
 
 	})
-	Describe("CreateCredential", func() {
-		var requestBody string
+	Describe("BacktestScalingPolicy", func() {
+		JustBeforeEach(func() {
+			handler.BacktestScalingPolicy(resp, req, pathVariables)
+		})
 		BeforeEach(func() {
 			pathVariables["appId"] = TEST_APP_ID
+			backtestStatus = http.StatusOK
+			backtestResponse = models.BacktestResult{
+				Timeline: []*models.BacktestInstances{{Timestamp: 100, ProcessType: "web", Instances: 1}},
+				Decisions: []*models.AppScalingHistory{{
+					AppId: TEST_APP_ID, ProcessType: "web", Timestamp: 100, ScalingType: models.ScalingTypeDynamic,
+					Status: models.ScalingStatusIgnored, OldInstances: 1, NewInstances: 1, Simulated: true,
+				}},
+			}
 		})
-		JustBeforeEach(func() {
-			req, _ = http.NewRequest(http.MethodPut, "/v1/apps/"+TEST_APP_ID+"/credential", strings.NewReader(requestBody))
-			req.Header.Set("Content-type", "application/json")
-			handler.CreateCredential(resp, req, pathVariables)
 
-		})
-		AfterEach(func() {
-			requestBody = ""
-		})
 		Context("When appId is not present", func() {
 			BeforeEach(func() {
 				delete(pathVariables, "appId")
+				req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/backtest", strings.NewReader(`{"policy":`+VALID_POLICY_STR+`,"start":100,"end":200}`))
 			})
 			It("should fail with 400", func() {
 				Expect(resp.Code).To(Equal(http.StatusBadRequest))
 				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
 			})
 		})
-		Context("When user provide credential", func() {
-			Context("When request body is invalid json", func() {
-				BeforeEach(func() {
-					requestBody = "not-json"
-				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid credential format"}`))
-				})
+
+		Context("When the request body is invalid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/backtest", strings.NewReader(`not-json`))
 			})
-			Context("When credential.username is not provided", func() {
-				BeforeEach(func() {
-					requestBody = `{"password":"password"}`
-				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Username and password are both required"}`))
-				})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid request body format"}`))
 			})
-			Context("When credential.password is not provided", func() {
-				BeforeEach(func() {
-					requestBody = `{"username":"username"}`
-				})
-				It("should fail with 400", func() {
-					Expect(resp.Code).To(Equal(http.StatusBadRequest))
-					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Username and password are both required"}`))
-				})
+		})
+
+		Context("When the policy is invalid", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/backtest", strings.NewReader(`{"policy":`+INVALID_POLICY_STR+`,"start":100,"end":200}`))
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`[{"context":"(root)","description":"instance_min_count is required"}]`))
 			})
 		})
-		Context("When failed to save credential to a credential store", func() {
+
+		Context("When the eventgenerator rejects the request", func() {
 			BeforeEach(func() {
-				credentials.CreateReturns(nil, fmt.Errorf("sql db error"))
+				backtestStatus = http.StatusBadRequest
+				req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/backtest", strings.NewReader(`{"policy":`+VALID_POLICY_STR+`,"start":200,"end":100}`))
 			})
-			It("should fails with 500", func() {
-				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
-				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error creating credential"}`))
+			It("should forward the status code", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
 			})
 		})
-		Context("When successfully save data to a credential store", func() {
+
+		Context("When the policy is backtested successfully", func() {
 			BeforeEach(func() {
-				credentials.CreateReturns(nil, nil)
+				req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/backtest", strings.NewReader(`{"policy":`+VALID_POLICY_STR+`,"start":100,"end":200,"instances":2}`))
 			})
-			It("should succeed with 200", func() {
+			It("should return the backtest result", func() {
 				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+				result := models.BacktestResult{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), &result)).To(Succeed())
+				Expect(result).To(Equal(backtestResponse))
 			})
 		})
 	})
-	Describe("DeleteCredential", func() {
+
+	Describe("GetScalingState", func() {
 		JustBeforeEach(func() {
-			handler.DeleteCredential(resp, req, pathVariables)
+			handler.GetScalingState(resp, req, pathVariables)
 		})
 		BeforeEach(func() {
 			pathVariables["appId"] = TEST_APP_ID
-			req, _ = http.NewRequest(http.MethodPut, "", nil)
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/scaling_state", nil)
+			scalingStateStatus = http.StatusOK
+			scalingStateResponse = models.AppScalingState{
+				Cooldowns:       []*models.ScalingCooldown{{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: 300}},
+				Recommendations: []*models.ScalingRecommendation{{AppId: TEST_APP_ID, ProcessType: "web", Timestamp: 100, Instances: 3}},
+			}
 		})
+
 		Context("When appId is not present", func() {
 			BeforeEach(func() {
 				delete(pathVariables, "appId")
@@ -918,23 +1143,752 @@ var _ = Describe("PublicApiHandler", func() {
 				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
 			})
 		})
-		Context("When failed to delete credential from a credential store", func() {
+
+		Context("When the scaling engine fails", func() {
 			BeforeEach(func() {
-				credentials.DeleteReturns(fmt.Errorf("sql db error"))
+				scalingStateStatus = http.StatusInternalServerError
 			})
-			It("should fails with 500", func() {
+			It("should forward the status code", func() {
 				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
-				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting credential"}`))
 			})
 		})
-		Context("When successfully delete data from a credential store", func() {
+
+		Context("When the scaling state is retrieved successfully", func() {
+			It("should return the scaling state", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+				state := models.AppScalingState{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), &state)).To(Succeed())
+				Expect(state).To(Equal(scalingStateResponse))
+			})
+		})
+	})
+
+	Describe("ExportScalingHistories", func() {
+		JustBeforeEach(func() {
+			handler.ExportScalingHistories(resp, req, pathVariables)
+		})
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/scaling_histories/export?format=csv&status=failed&status=ignored&reason=memory&page=2", nil)
+			historyExportStatus = http.StatusOK
+			historyExportResponse = "app_id,process_type\n" + TEST_APP_ID + ",web\n"
+		})
+
+		Context("When appId is not present", func() {
 			BeforeEach(func() {
-				credentials.DeleteReturns(nil)
+				delete(pathVariables, "appId")
 			})
-			It("should succeed with 200", func() {
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When the scaling engine fails", func() {
+			BeforeEach(func() {
+				historyExportStatus = http.StatusBadRequest
+			})
+			It("should forward the status code", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("When the export succeeds", func() {
+			It("should stream the export with the known query parameters", func() {
 				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get("Content-Type")).To(Equal("text/csv"))
+				Expect(resp.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="scaling-histories.csv"`))
+				Expect(resp.Body.String()).To(Equal(historyExportResponse))
+
+				received := scalingEngineServer.ReceivedRequests()
+				Expect(received[len(received)-1].URL.Path).To(Equal("/v1/apps/" + TEST_APP_ID + "/scaling_histories/export"))
+				Expect(received[len(received)-1].URL.RawQuery).To(Equal("format=csv&reason=memory&status=failed&status=ignored"))
+			})
+		})
+	})
+
+	Describe("ManualScale", func() {
+		var requestBody string
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			requestBody = `{"instances":3}`
+			manualScaleStatus = http.StatusOK
+			manualScaleResponse = models.AppScalingResult{AppId: TEST_APP_ID, Status: models.ScalingStatusSucceeded, Adjustment: 1, CooldownExpiredAt: 300, Direction: models.ScalingDirectionOut}
+		})
+		JustBeforeEach(func() {
+			req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/scale", strings.NewReader(requestBody))
+			handler.ManualScale(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When the request body is invalid", func() {
+			BeforeEach(func() {
+				requestBody = `{"instances":`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid request body format"}`))
 			})
 		})
 
+		Context("When the instances are less than 1", func() {
+			BeforeEach(func() {
+				requestBody = `{"instances":0}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"instances must be greater than or equal to 1"}`))
+			})
+		})
+
+		Context("When the process type is invalid", func() {
+			BeforeEach(func() {
+				requestBody = `{"instances":3,"process_type":"web worker"}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"process_type must only contain letters, digits, '_' and '-'"}`))
+			})
+		})
+
+		Context("When the scaling engine fails", func() {
+			BeforeEach(func() {
+				manualScaleStatus = http.StatusInternalServerError
+			})
+			It("should forward the status code", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		Context("When the app is scaled successfully", func() {
+			BeforeEach(func() {
+				requestBody = `{"instances":3,"process_type":"worker","override_limits":true}`
+			})
+			It("should return the scaling result", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+				result := models.AppScalingResult{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), &result)).To(Succeed())
+				Expect(result).To(Equal(manualScaleResponse))
+			})
+		})
+	})
+
+	Describe("GetSuspension", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/suspension", nil)
+		})
+		JustBeforeEach(func() {
+			handler.GetSuspension(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When database gives error", func() {
+			BeforeEach(func() {
+				policydb.GetAppSuspensionReturns(nil, fmt.Errorf("Failed to retrieve suspension"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving suspension"}`))
+			})
+		})
+
+		Context("When the app is not suspended", func() {
+			BeforeEach(func() {
+				policydb.GetAppSuspensionReturns(nil, nil)
+			})
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Suspension Not Found"}`))
+			})
+		})
+
+		Context("When the suspension has expired", func() {
+			BeforeEach(func() {
+				policydb.GetAppSuspensionReturns(&models.AppSuspension{AppId: TEST_APP_ID, SuspendedAt: 100, ExpireAt: 200}, nil)
+			})
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("When the app is suspended", func() {
+			BeforeEach(func() {
+				policydb.GetAppSuspensionReturns(&models.AppSuspension{AppId: TEST_APP_ID, SuspendedAt: 100, Reason: "maintenance"}, nil)
+			})
+			It("should return the suspension", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"app_id":"` + TEST_APP_ID + `","suspended_at":100,"reason":"maintenance"}`))
+			})
+		})
+	})
+
+	Describe("SuspendApp", func() {
+		var requestBody string
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			requestBody = ""
+		})
+		JustBeforeEach(func() {
+			req = httptest.NewRequest(http.MethodPut, "/v1/apps/"+TEST_APP_ID+"/suspension", strings.NewReader(requestBody))
+			handler.SuspendApp(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When the request body is invalid", func() {
+			BeforeEach(func() {
+				requestBody = `{"expire_at":`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid request body format"}`))
+				Expect(policydb.SuspendAppCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When expire_at is in the past", func() {
+			BeforeEach(func() {
+				requestBody = fmt.Sprintf(`{"expire_at":%d}`, time.Now().Add(-time.Minute).UnixNano())
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"expire_at must be in the future"}`))
+				Expect(policydb.SuspendAppCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("When the reason is too long", func() {
+			BeforeEach(func() {
+				requestBody = `{"reason":"` + strings.Repeat("a", 256) + `"}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"reason must not be longer than 255 characters"}`))
+			})
+		})
+
+		Context("When saving the suspension fails", func() {
+			BeforeEach(func() {
+				policydb.SuspendAppReturns(fmt.Errorf("Failed to save suspension"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error suspending app"}`))
+			})
+		})
+
+		Context("When no request body is provided", func() {
+			It("should suspend the app without expiry", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(policydb.SuspendAppCallCount()).To(Equal(1))
+				_, suspension := policydb.SuspendAppArgsForCall(0)
+				Expect(suspension.AppId).To(Equal(TEST_APP_ID))
+				Expect(suspension.SuspendedAt).To(BeNumerically(">", 0))
+				Expect(suspension.ExpireAt).To(BeZero())
+			})
+		})
+
+		Context("When an expiry and a reason are provided", func() {
+			var expireAt int64
+			BeforeEach(func() {
+				expireAt = time.Now().Add(time.Hour).UnixNano()
+				requestBody = fmt.Sprintf(`{"expire_at":%d,"reason":"maintenance"}`, expireAt)
+			})
+			It("should suspend the app until the expiry", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, suspension := policydb.SuspendAppArgsForCall(0)
+				Expect(suspension.ExpireAt).To(Equal(expireAt))
+				Expect(suspension.Reason).To(Equal("maintenance"))
+
+				returned := &models.AppSuspension{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), returned)).To(Succeed())
+				Expect(returned).To(Equal(suspension))
+			})
+		})
+	})
+
+	Describe("ResumeApp", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodDelete, "/v1/apps/"+TEST_APP_ID+"/suspension", nil)
+		})
+		JustBeforeEach(func() {
+			handler.ResumeApp(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When deleting the suspension fails", func() {
+			BeforeEach(func() {
+				policydb.ResumeAppReturns(fmt.Errorf("Failed to delete suspension"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error resuming app"}`))
+			})
+		})
+
+		Context("When the app is resumed", func() {
+			It("should succeed", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(Equal("{}"))
+				_, appId := policydb.ResumeAppArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+			})
+		})
+	})
+
+	Describe("CreateCredential", func() {
+		var requestBody string
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+		})
+		JustBeforeEach(func() {
+			req, _ = http.NewRequest(http.MethodPut, "/v1/apps/"+TEST_APP_ID+"/credential", strings.NewReader(requestBody))
+			req.Header.Set("Content-type", "application/json")
+			handler.CreateCredential(resp, req, pathVariables)
+
+		})
+		AfterEach(func() {
+			requestBody = ""
+		})
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+		Context("When user provide credential", func() {
+			Context("When request body is invalid json", func() {
+				BeforeEach(func() {
+					requestBody = "not-json"
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid credential format"}`))
+				})
+			})
+			Context("When credential.username is not provided", func() {
+				BeforeEach(func() {
+					requestBody = `{"password":"password"}`
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Username and password are both required"}`))
+				})
+			})
+			Context("When credential.password is not provided", func() {
+				BeforeEach(func() {
+					requestBody = `{"username":"username"}`
+				})
+				It("should fail with 400", func() {
+					Expect(resp.Code).To(Equal(http.StatusBadRequest))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Username and password are both required"}`))
+				})
+			})
+		})
+		Context("When failed to save credential to a credential store", func() {
+			BeforeEach(func() {
+				credentials.CreateReturns(nil, fmt.Errorf("sql db error"))
+			})
+			It("should fails with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error creating credential"}`))
+			})
+		})
+		Context("When successfully save data to a credential store", func() {
+			BeforeEach(func() {
+				credentials.CreateReturns(nil, nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+			})
+		})
+	})
+	Describe("DeleteCredential", func() {
+		JustBeforeEach(func() {
+			handler.DeleteCredential(resp, req, pathVariables)
+		})
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req, _ = http.NewRequest(http.MethodPut, "", nil)
+		})
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+		Context("When failed to delete credential from a credential store", func() {
+			BeforeEach(func() {
+				credentials.DeleteReturns(fmt.Errorf("sql db error"))
+			})
+			It("should fails with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting credential"}`))
+			})
+		})
+		Context("When successfully delete data from a credential store", func() {
+			BeforeEach(func() {
+				credentials.DeleteReturns(nil)
+			})
+			It("should succeed with 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+			})
+		})
+
+	})
+
+	Describe("GetWebhooks", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/webhooks", nil)
+		})
+		JustBeforeEach(func() {
+			handler.GetWebhooks(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When database gives error", func() {
+			BeforeEach(func() {
+				policydb.RetrieveWebhookSubscriptionsReturns(nil, fmt.Errorf("Failed to retrieve webhook subscriptions"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error retrieving webhooks"}`))
+			})
+		})
+
+		Context("When the app has webhooks", func() {
+			BeforeEach(func() {
+				policydb.RetrieveWebhookSubscriptionsReturns([]*models.WebhookSubscription{
+					{Guid: "webhook-guid", AppId: TEST_APP_ID, Url: "https://example.com/hook", Secret: "a-secret", CreatedAt: 100},
+				}, nil)
+			})
+			It("should return the webhooks without their secrets", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`[{"guid":"webhook-guid","app_id":"` + TEST_APP_ID + `","url":"https://example.com/hook","created_at":100}]`))
+			})
+		})
+	})
+
+	Describe("CreateWebhook", func() {
+		var requestBody string
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			requestBody = `{"url":"https://example.com/hook","secret":"a-secret"}`
+		})
+		JustBeforeEach(func() {
+			req = httptest.NewRequest(http.MethodPost, "/v1/apps/"+TEST_APP_ID+"/webhooks", strings.NewReader(requestBody))
+			handler.CreateWebhook(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When the request body is invalid", func() {
+			BeforeEach(func() {
+				requestBody = `{"url":`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"Invalid request body format"}`))
+			})
+		})
+
+		Context("When the url is not https", func() {
+			BeforeEach(func() {
+				requestBody = `{"url":"http://example.com/hook"}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"url must be an absolute https URL of at most 2048 characters"}`))
+				Expect(policydb.SaveWebhookSubscriptionsCallCount()).To(BeZero())
+			})
+		})
+
+		Context("When the url host is an IP address", func() {
+			BeforeEach(func() {
+				requestBody = `{"url":"https://169.254.169.254/latest/meta-data"}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"url must use a host name instead of an IP address"}`))
+				Expect(policydb.SaveWebhookSubscriptionsCallCount()).To(BeZero())
+			})
+		})
+
+		Context("When the url host resolves to a loopback address", func() {
+			BeforeEach(func() {
+				requestBody = `{"url":"https://localhost:8443/hook"}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"url must not point to a loopback, private or link-local address"}`))
+				Expect(policydb.SaveWebhookSubscriptionsCallCount()).To(BeZero())
+			})
+
+			Context("in an allowed network", func() {
+				BeforeEach(func() {
+					conf.Webhook.AllowedNetworks = []string{"127.0.0.0/8", "::1/128"}
+				})
+				AfterEach(func() {
+					conf.Webhook.AllowedNetworks = nil
+				})
+				It("should save the subscription", func() {
+					Expect(resp.Code).To(Equal(http.StatusCreated))
+					Expect(policydb.SaveWebhookSubscriptionsCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("When the scope is unknown", func() {
+			BeforeEach(func() {
+				requestBody = `{"url":"https://example.com/hook","scope":"org"}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"scope must be either app or service_instance"}`))
+			})
+		})
+
+		Context("When the scope is service_instance in build-in mode", func() {
+			BeforeEach(func() {
+				requestBody = `{"url":"https://example.com/hook","scope":"service_instance"}`
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"scope service_instance is not available without the service broker"}`))
+			})
+		})
+
+		Context("When saving the webhook fails", func() {
+			BeforeEach(func() {
+				policydb.SaveWebhookSubscriptionsReturns(fmt.Errorf("Failed to save webhook subscriptions"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error creating webhook"}`))
+			})
+		})
+
+		Context("When the webhook is created for the app", func() {
+			It("should save the subscription and return it with its secret", func() {
+				Expect(resp.Code).To(Equal(http.StatusCreated))
+				_, subscriptions := policydb.SaveWebhookSubscriptionsArgsForCall(0)
+				Expect(subscriptions).To(HaveLen(1))
+				Expect(subscriptions[0].AppId).To(Equal(TEST_APP_ID))
+				Expect(subscriptions[0].Url).To(Equal("https://example.com/hook"))
+				Expect(subscriptions[0].Secret).To(Equal("a-secret"))
+				Expect(subscriptions[0].ServiceInstanceId).To(BeEmpty())
+
+				subscription := &models.WebhookSubscription{}
+				Expect(json.Unmarshal(resp.Body.Bytes(), subscription)).To(Succeed())
+				Expect(subscription).To(Equal(subscriptions[0]))
+			})
+
+			Context("without a secret", func() {
+				BeforeEach(func() {
+					requestBody = `{"url":"https://example.com/hook"}`
+				})
+				It("should generate one", func() {
+					Expect(resp.Code).To(Equal(http.StatusCreated))
+					_, subscriptions := policydb.SaveWebhookSubscriptionsArgsForCall(0)
+					Expect(subscriptions[0].Secret).NotTo(BeEmpty())
+				})
+			})
+		})
+
+		Context("When the webhook is created for the service instance", func() {
+			BeforeEach(func() {
+				conf.UseBuildInMode = false
+				requestBody = `{"url":"https://example.com/hook","scope":"service_instance"}`
+				bindingdb = &fakes.FakeBindingDB{}
+				bindingdb.GetServiceInstanceByAppIdReturns(&models.ServiceInstance{ServiceInstanceId: "a-service-instance-id"}, nil)
+			})
+			AfterEach(func() {
+				conf.UseBuildInMode = true
+			})
+			It("should save a single subscription of the service instance", func() {
+				Expect(resp.Code).To(Equal(http.StatusCreated))
+				Expect(bindingdb.GetServiceInstanceByAppIdArgsForCall(0)).To(Equal(TEST_APP_ID))
+
+				_, subscriptions := policydb.SaveWebhookSubscriptionsArgsForCall(0)
+				Expect(subscriptions).To(HaveLen(1))
+				Expect(subscriptions[0].AppId).To(BeEmpty())
+				Expect(subscriptions[0].ServiceInstanceId).To(Equal("a-service-instance-id"))
+				Expect(bindingdb.GetAppIdsByInstanceIdCallCount()).To(BeZero())
+			})
+
+			Context("but the service instance can not be found", func() {
+				BeforeEach(func() {
+					bindingdb.GetServiceInstanceByAppIdReturns(nil, db.ErrDoesNotExist)
+				})
+				It("should fail with 500", func() {
+					Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+					Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error creating webhook"}`))
+					Expect(policydb.SaveWebhookSubscriptionsCallCount()).To(BeZero())
+				})
+			})
+		})
+	})
+
+	Describe("DeleteWebhook", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			pathVariables["webhookId"] = "webhook-guid"
+			req = httptest.NewRequest(http.MethodDelete, "/v1/apps/"+TEST_APP_ID+"/webhooks/webhook-guid", nil)
+			policydb.GetWebhookSubscriptionReturns(&models.WebhookSubscription{Guid: "webhook-guid", AppId: TEST_APP_ID}, nil)
+		})
+		JustBeforeEach(func() {
+			handler.DeleteWebhook(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When the webhook does not belong to the app", func() {
+			BeforeEach(func() {
+				policydb.GetWebhookSubscriptionReturns(nil, nil)
+			})
+			It("should fail with 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Not Found","message":"Webhook Not Found"}`))
+				Expect(policydb.DeleteWebhookSubscriptionCallCount()).To(BeZero())
+			})
+		})
+
+		Context("When deleting the webhook fails", func() {
+			BeforeEach(func() {
+				policydb.DeleteWebhookSubscriptionReturns(fmt.Errorf("Failed to delete webhook subscription"))
+			})
+			It("should fail with 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Internal Server Error","message":"Error deleting webhook"}`))
+			})
+		})
+
+		Context("When the webhook is deleted", func() {
+			It("should succeed", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				_, appId, guid := policydb.GetWebhookSubscriptionArgsForCall(0)
+				Expect(appId).To(Equal(TEST_APP_ID))
+				Expect(guid).To(Equal("webhook-guid"))
+				_, deletedGuid := policydb.DeleteWebhookSubscriptionArgsForCall(0)
+				Expect(deletedGuid).To(Equal("webhook-guid"))
+			})
+		})
+	})
+
+	Describe("GetWebhookDeliveries", func() {
+		BeforeEach(func() {
+			pathVariables["appId"] = TEST_APP_ID
+			req = httptest.NewRequest(http.MethodGet, "/v1/apps/"+TEST_APP_ID+"/webhooks/deliveries?status=failed", nil)
+			webhookDeliveriesStatus = http.StatusOK
+			webhookDeliveriesResponse = []*models.WebhookDelivery{
+				{Guid: "delivery-guid", SubscriptionGuid: "webhook-guid", AppId: TEST_APP_ID, Status: models.WebhookDeliveryStatusFailed, Attempts: 8},
+			}
+		})
+		JustBeforeEach(func() {
+			handler.GetWebhookDeliveries(resp, req, pathVariables)
+		})
+
+		Context("When appId is not present", func() {
+			BeforeEach(func() {
+				delete(pathVariables, "appId")
+			})
+			It("should fail with 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(Equal(`{"code":"Bad Request","message":"AppId is required"}`))
+			})
+		})
+
+		Context("When the scaling engine fails", func() {
+			BeforeEach(func() {
+				webhookDeliveriesStatus = http.StatusBadRequest
+			})
+			It("should forward the status code", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("When the deliveries are retrieved successfully", func() {
+			It("should return the deliveries", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get("Content-Type")).To(Equal("application/json"))
+				var deliveries []*models.WebhookDelivery
+				Expect(json.Unmarshal(resp.Body.Bytes(), &deliveries)).To(Succeed())
+				Expect(deliveries).To(Equal(webhookDeliveriesResponse))
+
+				received := scalingEngineServer.ReceivedRequests()
+				Expect(received[len(received)-1].URL.RawQuery).To(Equal("status=failed"))
+			})
+		})
 	})
 })
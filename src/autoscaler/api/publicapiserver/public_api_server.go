@@ -55,7 +55,9 @@ func NewPublicApiServer(logger lager.Logger, conf *config.Config, policydb db.Po
 	rp.Use(httpStatusCollectMiddleware.Collect)
 
 	rp.Get(routes.PublicApiScalingHistoryRouteName).Handler(scalingHistoryHandler)
+	rp.Get(routes.PublicApiExportScalingHistoriesRouteName).Handler(VarsFunc(pah.ExportScalingHistories))
 	rp.Get(routes.PublicApiAggregatedMetricsHistoryRouteName).Handler(VarsFunc(pah.GetAggregatedMetricsHistories))
+	rp.Get(routes.PublicApiScalingStateRouteName).Handler(VarsFunc(pah.GetScalingState))
 
 	rpolicy := routes.ApiPolicyRoutes()
 	rpolicy.Use(rateLimiterMiddleware.CheckRateLimit)
@@ -69,6 +71,64 @@ func NewPublicApiServer(logger lager.Logger, conf *config.Config, policydb db.Po
 	rpolicy.Get(routes.PublicApiAttachPolicyRouteName).Handler(VarsFunc(pah.AttachScalingPolicy))
 	rpolicy.Get(routes.PublicApiDetachPolicyRouteName).Handler(VarsFunc(pah.DetachScalingPolicy))
 
+	rrevision := routes.ApiPolicyRevisionRoutes()
+	rrevision.Use(rateLimiterMiddleware.CheckRateLimit)
+	rrevision.Use(mw.HasClientToken)
+	rrevision.Use(mw.Oauth)
+	if !conf.UseBuildInMode {
+		rrevision.Use(mw.CheckServiceBinding)
+	}
+	rrevision.Use(httpStatusCollectMiddleware.Collect)
+	rrevision.Get(routes.PublicApiGetPolicyRevisionsRouteName).Handler(VarsFunc(pah.GetPolicyRevisions))
+	rrevision.Get(routes.PublicApiGetPolicyRevisionRouteName).Handler(VarsFunc(pah.GetPolicyRevision))
+	rrevision.Get(routes.PublicApiDiffPolicyRevisionRouteName).Handler(VarsFunc(pah.DiffPolicyRevision))
+	rrevision.Get(routes.PublicApiRollbackPolicyRevisionRouteName).Handler(VarsFunc(pah.RollbackPolicyRevision))
+
+	rsuspension := routes.ApiSuspensionRoutes()
+	rsuspension.Use(rateLimiterMiddleware.CheckRateLimit)
+	rsuspension.Use(mw.HasClientToken)
+	rsuspension.Use(mw.Oauth)
+	if !conf.UseBuildInMode {
+		rsuspension.Use(mw.CheckServiceBinding)
+	}
+	rsuspension.Use(httpStatusCollectMiddleware.Collect)
+	rsuspension.Get(routes.PublicApiGetSuspensionRouteName).Handler(VarsFunc(pah.GetSuspension))
+	rsuspension.Get(routes.PublicApiSuspendAppRouteName).Handler(VarsFunc(pah.SuspendApp))
+	rsuspension.Get(routes.PublicApiResumeAppRouteName).Handler(VarsFunc(pah.ResumeApp))
+
+	rbacktest := routes.ApiBacktestRoutes()
+	rbacktest.Use(rateLimiterMiddleware.CheckRateLimit)
+	rbacktest.Use(mw.HasClientToken)
+	rbacktest.Use(mw.Oauth)
+	if !conf.UseBuildInMode {
+		rbacktest.Use(mw.CheckServiceBinding)
+	}
+	rbacktest.Use(httpStatusCollectMiddleware.Collect)
+	rbacktest.Get(routes.PublicApiBacktestRouteName).Handler(VarsFunc(pah.BacktestScalingPolicy))
+
+	rmanualscale := routes.ApiManualScaleRoutes()
+	rmanualscale.Use(rateLimiterMiddleware.CheckRateLimit)
+	rmanualscale.Use(mw.HasClientToken)
+	rmanualscale.Use(mw.Oauth)
+	if !conf.UseBuildInMode {
+		rmanualscale.Use(mw.CheckServiceBinding)
+	}
+	rmanualscale.Use(httpStatusCollectMiddleware.Collect)
+	rmanualscale.Get(routes.PublicApiManualScaleRouteName).Handler(VarsFunc(pah.ManualScale))
+
+	rwebhook := routes.ApiWebhookRoutes()
+	rwebhook.Use(rateLimiterMiddleware.CheckRateLimit)
+	rwebhook.Use(mw.HasClientToken)
+	rwebhook.Use(mw.Oauth)
+	if !conf.UseBuildInMode {
+		rwebhook.Use(mw.CheckServiceBinding)
+	}
+	rwebhook.Use(httpStatusCollectMiddleware.Collect)
+	rwebhook.Get(routes.PublicApiGetWebhooksRouteName).Handler(VarsFunc(pah.GetWebhooks))
+	rwebhook.Get(routes.PublicApiCreateWebhookRouteName).Handler(VarsFunc(pah.CreateWebhook))
+	rwebhook.Get(routes.PublicApiGetWebhookDeliveriesRouteName).Handler(VarsFunc(pah.GetWebhookDeliveries))
+	rwebhook.Get(routes.PublicApiDeleteWebhookRouteName).Handler(VarsFunc(pah.DeleteWebhook))
+
 	rcredential := routes.ApiCredentialRoutes()
 	rcredential.Use(rateLimiterMiddleware.CheckRateLimit)
 	if !conf.UseBuildInMode {
@@ -56,15 +56,25 @@ var (
 	eventGeneratorServer   *ghttp.Server
 	schedulerServer        *ghttp.Server
 
-	scalingEngineStatus    int
-	metricsCollectorStatus int
-	eventGeneratorStatus   int
-	schedulerStatus        int
-	schedulerErrJson       string
-
-	scalingEngineResponse    scalinghistory.History
-	metricsCollectorResponse []models.AppInstanceMetric
-	eventGeneratorResponse   []models.AppMetric
+	scalingEngineStatus     int
+	metricsCollectorStatus  int
+	eventGeneratorStatus    int
+	backtestStatus          int
+	scalingStateStatus      int
+	manualScaleStatus       int
+	webhookDeliveriesStatus int
+	historyExportStatus     int
+	schedulerStatus         int
+	schedulerErrJson        string
+
+	scalingEngineResponse     scalinghistory.History
+	metricsCollectorResponse  []models.AppInstanceMetric
+	eventGeneratorResponse    []models.AppMetric
+	backtestResponse          models.BacktestResult
+	scalingStateResponse      models.AppScalingState
+	manualScaleResponse       models.AppScalingResult
+	webhookDeliveriesResponse []*models.WebhookDelivery
+	historyExportResponse     string
 
 	fakeCFClient     *fakes.FakeCFClient
 	fakePolicyDB     *fakes.FakePolicyDB
@@ -134,10 +144,29 @@ var _ = BeforeSuite(func() {
 	infoBytes, err = os.ReadFile("../exampleconfig/info-file.json")
 	Expect(err).NotTo(HaveOccurred())
 
+	historyExportPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_histories/export`)
+	Expect(err).NotTo(HaveOccurred())
+	scalingEngineServer.RouteToHandler(http.MethodGet, historyExportPathMatcher, ghttp.RespondWithPtr(&historyExportStatus, &historyExportResponse, http.Header{
+		"Content-Type":        []string{"text/csv"},
+		"Content-Disposition": []string{`attachment; filename="scaling-histories.csv"`},
+	}))
+
 	scalingHistoryPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_histories`)
 	Expect(err).NotTo(HaveOccurred())
 	scalingEngineServer.RouteToHandler(http.MethodGet, scalingHistoryPathMatcher, ghttp.RespondWithJSONEncodedPtr(&scalingEngineStatus, &scalingEngineResponse))
 
+	scalingStatePathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/scaling_state`)
+	Expect(err).NotTo(HaveOccurred())
+	scalingEngineServer.RouteToHandler(http.MethodGet, scalingStatePathMatcher, ghttp.RespondWithJSONEncodedPtr(&scalingStateStatus, &scalingStateResponse))
+
+	manualScalePathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/manual_scale`)
+	Expect(err).NotTo(HaveOccurred())
+	scalingEngineServer.RouteToHandler(http.MethodPost, manualScalePathMatcher, ghttp.RespondWithJSONEncodedPtr(&manualScaleStatus, &manualScaleResponse))
+
+	webhookDeliveriesPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/webhook_deliveries`)
+	Expect(err).NotTo(HaveOccurred())
+	scalingEngineServer.RouteToHandler(http.MethodGet, webhookDeliveriesPathMatcher, ghttp.RespondWithJSONEncodedPtr(&webhookDeliveriesStatus, &webhookDeliveriesResponse))
+
 	metricsCollectorPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/metric_histories/[a-zA-Z0-9_]+`)
 	Expect(err).NotTo(HaveOccurred())
 	metricsCollectorServer.RouteToHandler(http.MethodGet, metricsCollectorPathMatcher, ghttp.RespondWithJSONEncodedPtr(&metricsCollectorStatus, &metricsCollectorResponse))
@@ -146,6 +175,10 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	eventGeneratorServer.RouteToHandler(http.MethodGet, eventGeneratorPathMatcher, ghttp.RespondWithJSONEncodedPtr(&eventGeneratorStatus, &eventGeneratorResponse))
 
+	backtestPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/backtest`)
+	Expect(err).NotTo(HaveOccurred())
+	eventGeneratorServer.RouteToHandler(http.MethodPost, backtestPathMatcher, ghttp.RespondWithJSONEncodedPtr(&backtestStatus, &backtestResponse))
+
 	schedulerPathMatcher, err := regexp.Compile(`/v1/apps/[A-Za-z0-9\-]+/schedules`)
 	Expect(err).NotTo(HaveOccurred())
 	schedulerErrJson = "{}"
@@ -0,0 +1,92 @@
+package applog
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/go-loggregator/v9"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// SourceType marks the log lines written by the autoscaler into the log stream of an app, `cf logs` shows them as
+// [AUTOSCALER/<component>].
+const SourceType = "AUTOSCALER"
+
+const APP_LOG_ORIGIN = "autoscaler_app_log"
+
+const DefaultMetronAddress = "127.0.0.1:3458"
+
+type Config struct {
+	Enabled       bool            `yaml:"enabled"`
+	MetronAddress string          `yaml:"metron_address"`
+	TLS           models.TLSCerts `yaml:"tls"`
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.MetronAddress == "" {
+		return fmt.Errorf("Configuration error: app_log.metron_address is empty")
+	}
+	return nil
+}
+
+// Emitter writes messages into the log stream of an app. Info messages are written to stdout, error messages to
+// stderr.
+type Emitter interface {
+	Info(appId string, message string)
+	Error(appId string, message string)
+}
+
+type emitter struct {
+	client         *loggregator.IngressClient
+	sourceInstance string
+	logger         lager.Logger
+}
+
+type noopEmitter struct{}
+
+func (noopEmitter) Info(string, string)  {}
+func (noopEmitter) Error(string, string) {}
+
+// NewEmitter creates an Emitter which sends log envelopes through the loggregator agent. The source instance names
+// the autoscaler component which writes the messages. If the app log is disabled, the messages are dropped.
+func NewEmitter(logger lager.Logger, conf Config, sourceInstance string) (Emitter, error) {
+	if !conf.Enabled {
+		return noopEmitter{}, nil
+	}
+
+	tlsConfig, err := loggregator.NewIngressTLSConfig(
+		conf.TLS.CACertFile,
+		conf.TLS.CertFile,
+		conf.TLS.KeyFile,
+	)
+	if err != nil {
+		logger.Error("could-not-create-TLS-config", err, lager.Data{"config": conf})
+		return nil, err
+	}
+
+	client, err := loggregator.NewIngressClient(
+		tlsConfig,
+		loggregator.WithAddr(conf.MetronAddress),
+		loggregator.WithTag("origin", APP_LOG_ORIGIN),
+		loggregator.WithLogger(helpers.NewLoggregatorGRPCLogger(logger.Session("app_log"))),
+	)
+	if err != nil {
+		logger.Error("could-not-create-loggregator-client", err, lager.Data{"config": conf})
+		return nil, err
+	}
+
+	return &emitter{
+		client:         client,
+		sourceInstance: sourceInstance,
+		logger:         logger,
+	}, nil
+}
+
+func (e *emitter) Info(appId string, message string) {
+	e.client.EmitLog(message, loggregator.WithSourceInfo(appId, SourceType, e.sourceInstance), loggregator.WithStdout())
+}
+
+func (e *emitter) Error(appId string, message string) {
+	e.client.EmitLog(message, loggregator.WithSourceInfo(appId, SourceType, e.sourceInstance))
+}
@@ -0,0 +1,13 @@
+package applog_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestApplog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Applog Suite")
+}
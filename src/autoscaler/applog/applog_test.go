@@ -0,0 +1,102 @@
+package applog_test
+
+import (
+	"path/filepath"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/metricsforwarder/testhelpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	"code.cloudfoundry.org/go-loggregator/v9/rpc/loggregator_v2"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Emitter", func() {
+	var (
+		grpcIngressTestServer *testhelpers.TestIngressServer
+		conf                  applog.Config
+		emitter               applog.Emitter
+		err                   error
+	)
+
+	BeforeEach(func() {
+		testCertDir := "../../../test-certs"
+		grpcIngressTestServer, err = testhelpers.NewTestIngressServer(
+			filepath.Join(testCertDir, "metron.crt"),
+			filepath.Join(testCertDir, "metron.key"),
+			filepath.Join(testCertDir, "loggregator-ca.crt"),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		err = grpcIngressTestServer.Start()
+		Expect(err).NotTo(HaveOccurred())
+
+		conf = applog.Config{
+			Enabled:       true,
+			MetronAddress: grpcIngressTestServer.GetAddr(),
+			TLS: models.TLSCerts{
+				KeyFile:    filepath.Join(testCertDir, "metron.key"),
+				CertFile:   filepath.Join(testCertDir, "metron.crt"),
+				CACertFile: filepath.Join(testCertDir, "loggregator-ca.crt"),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		grpcIngressTestServer.Stop()
+	})
+
+	JustBeforeEach(func() {
+		emitter, err = applog.NewEmitter(lagertest.NewTestLogger("applog-test"), conf, "scalingengine")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("when an info message is emitted", func() {
+		JustBeforeEach(func() {
+			emitter.Info("an-app-id", "scaled process web from 2 to 3 instances")
+		})
+
+		It("writes it to stdout of the app log", func() {
+			env := getEnvelope(grpcIngressTestServer.Receivers)
+			Expect(env.SourceId).To(Equal("an-app-id"))
+			Expect(env.InstanceId).To(Equal("scalingengine"))
+			Expect(env.Tags["source_type"]).To(Equal(applog.SourceType))
+			Expect(env.Tags["origin"]).To(Equal(applog.APP_LOG_ORIGIN))
+			Expect(env.GetLog().Payload).To(Equal([]byte("scaled process web from 2 to 3 instances")))
+			Expect(env.GetLog().Type).To(Equal(loggregator_v2.Log_OUT))
+		})
+	})
+
+	Context("when an error message is emitted", func() {
+		JustBeforeEach(func() {
+			emitter.Error("an-app-id", "failed to scale")
+		})
+
+		It("writes it to stderr of the app log", func() {
+			env := getEnvelope(grpcIngressTestServer.Receivers)
+			Expect(env.SourceId).To(Equal("an-app-id"))
+			Expect(env.GetLog().Type).To(Equal(loggregator_v2.Log_ERR))
+		})
+	})
+
+	Context("when the app log is disabled", func() {
+		BeforeEach(func() {
+			conf.Enabled = false
+		})
+
+		It("drops the messages", func() {
+			emitter.Info("an-app-id", "scaled process web from 2 to 3 instances")
+			Consistently(grpcIngressTestServer.Receivers).ShouldNot(Receive())
+		})
+	})
+})
+
+func getEnvelope(receivers chan loggregator_v2.Ingress_BatchSenderServer) *loggregator_v2.Envelope {
+	var recv loggregator_v2.Ingress_BatchSenderServer
+	Eventually(receivers, 10).Should(Receive(&recv))
+	envBatch, err := recv.Recv()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(envBatch.Batch).NotTo(BeEmpty())
+	return envBatch.Batch[0]
+}
@@ -58,14 +58,27 @@ func (p Processes) GetInstances() int {
 	return instances
 }
 
+// GetMemoryInMb returns the memory of an instance of the processes, the highest one if they differ.
+func (p Processes) GetMemoryInMb() int {
+	memoryInMb := 0
+	for _, process := range p {
+		memoryInMb = max(memoryInMb, process.MemoryInMb)
+	}
+	return memoryInMb
+}
+
 /*GetAppAndProcesses
  * A utility function that gets the app and processes for the app in one call in parallel
+ * Only the web processes are returned if no process types are given
  */
-func (c *Client) GetAppAndProcesses(appID Guid) (*AppAndProcesses, error) {
-	return c.CtxClient.GetAppAndProcesses(context.Background(), appID)
+func (c *Client) GetAppAndProcesses(appID Guid, processTypes ...string) (*AppAndProcesses, error) {
+	return c.CtxClient.GetAppAndProcesses(context.Background(), appID, processTypes...)
 }
 
-func (c *CtxClient) GetAppAndProcesses(ctx context.Context, appID Guid) (*AppAndProcesses, error) {
+func (c *CtxClient) GetAppAndProcesses(ctx context.Context, appID Guid, processTypes ...string) (*AppAndProcesses, error) {
+	if len(processTypes) == 0 {
+		processTypes = []string{ProcessTypeWeb}
+	}
 	wg := sync.WaitGroup{}
 	wg.Add(2)
 	var app *App
@@ -77,7 +90,7 @@ func (c *CtxClient) GetAppAndProcesses(ctx context.Context, appID Guid) (*AppAnd
 	}()
 	go func() {
 		defer wg.Done()
-		processes, errProc = c.GetAppProcesses(ctx, appID, ProcessTypeWeb)
+		processes, errProc = c.GetAppProcesses(ctx, appID, processTypes...)
 	}()
 	wg.Wait()
 	if errApp != nil {
@@ -116,12 +129,27 @@ func (c *Client) ScaleAppWebProcess(appID Guid, num int) error {
 }
 
 func (c *CtxClient) ScaleAppWebProcess(ctx context.Context, appID Guid, num int) error {
-	url := fmt.Sprintf("/v3/apps/%s/processes/web/actions/scale", appID)
+	return c.ScaleAppProcess(ctx, appID, ProcessTypeWeb, num)
+}
+
+/*ScaleAppProcess
+ * Scale the given application processes of the given type to the given amount
+ * https://v3-apidocs.cloudfoundry.org/version/3.122.0/index.html#scale-a-process
+ */
+func (c *Client) ScaleAppProcess(appID Guid, processType string, num int) error {
+	return c.CtxClient.ScaleAppProcess(context.Background(), appID, processType, num)
+}
+
+func (c *CtxClient) ScaleAppProcess(ctx context.Context, appID Guid, processType string, num int) error {
+	url := fmt.Sprintf("/v3/apps/%s/processes/%s/actions/scale", appID, processType)
 	type scaleApp struct {
 		Instances int `json:"instances"`
 	}
 	_, err := ResourceRetriever[Process]{AuthenticatedClient{c}}.Post(ctx, url, scaleApp{Instances: num})
 	if err != nil {
+		if processType != ProcessTypeWeb {
+			return fmt.Errorf("failed scaling app '%s' process '%s' to %d: %w", appID, processType, num, err)
+		}
 		return fmt.Errorf("failed scaling app '%s' to %d: %w", appID, num, err)
 	}
 	return err
@@ -247,6 +247,43 @@ var _ = Describe("Cf client App", func() {
 		})
 	})
 
+	Describe("ScaleAppProcess", func() {
+		JustBeforeEach(func() {
+			err = cfc.ScaleAppProcess("test-app-id", cf.ProcessTypeWorker, 3)
+		})
+
+		scaleResponse := LoadFile("scale_response.yml")
+		When("scaling the worker processes succeeds", func() {
+			BeforeEach(func() {
+				fakeCC.AppendHandlers(
+					CombineHandlers(
+						VerifyRequest("POST", "/v3/apps/test-app-id/processes/worker/actions/scale"),
+						VerifyHeaderKV("Authorization", "Bearer test-access-token"),
+						VerifyJSON(`{"instances":3}`),
+						RespondWith(http.StatusAccepted, scaleResponse),
+					),
+				)
+			})
+
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("scaling endpoint return 500", func() {
+			BeforeEach(func() {
+				setCfcClient(3)
+				fakeCC.RouteToHandler("POST",
+					"/v3/apps/test-app-id/processes/worker/actions/scale",
+					RespondWithJSONEncoded(http.StatusInternalServerError, cf.CfInternalServerError))
+			})
+
+			It("should error with the process type", func() {
+				Expect(err).To(MatchError(MatchRegexp("failed scaling app 'test-app-id' process 'worker' to 3: failed POST-ing cf.Process: POST request failed:.*'UnknownError'.*")))
+			})
+		})
+	})
+
 	Describe("ScaleAppWebProcess", func() {
 		JustBeforeEach(func() {
 			err = cfc.ScaleAppWebProcess("test-app-id", 6)
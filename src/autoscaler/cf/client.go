@@ -81,20 +81,26 @@ type (
 		GetEndpoints() (Endpoints, error)
 		GetApp(appId Guid) (*App, error)
 		GetAppProcesses(appId Guid, processTypes ...string) (Processes, error)
-		GetAppAndProcesses(appId Guid) (*AppAndProcesses, error)
+		GetAppAndProcesses(appId Guid, processTypes ...string) (*AppAndProcesses, error)
 		ScaleAppWebProcess(appId Guid, numberOfProcesses int) error
+		ScaleAppProcess(appId Guid, processType string, numberOfProcesses int) error
 		GetServiceInstance(serviceInstanceGuid string) (*ServiceInstance, error)
 		GetServicePlan(servicePlanGuid string) (*ServicePlan, error)
+		GetSpaceQuotaUsage(spaceId SpaceId) (*SpaceQuotaUsage, error)
+		GetAppProcessStats(appId Guid, processType string) (ProcessStats, error)
 	}
 
 	ApiContextClient interface {
 		GetEndpoints(ctx context.Context) (Endpoints, error)
 		GetApp(ctx context.Context, appId Guid) (*App, error)
 		GetAppProcesses(ctx context.Context, appId Guid, processTypes ...string) (Processes, error)
-		GetAppAndProcesses(ctx context.Context, appId Guid) (*AppAndProcesses, error)
+		GetAppAndProcesses(ctx context.Context, appId Guid, processTypes ...string) (*AppAndProcesses, error)
 		ScaleAppWebProcess(ctx context.Context, appId Guid, numberOfProcesses int) error
+		ScaleAppProcess(ctx context.Context, appId Guid, processType string, numberOfProcesses int) error
 		GetServiceInstance(ctx context.Context, serviceInstanceGuid string) (*ServiceInstance, error)
 		GetServicePlan(ctx context.Context, servicePlanGuid string) (*ServicePlan, error)
+		GetSpaceQuotaUsage(ctx context.Context, spaceId SpaceId) (*SpaceQuotaUsage, error)
+		GetAppProcessStats(ctx context.Context, appId Guid, processType string) (ProcessStats, error)
 	}
 
 	Client struct {
@@ -110,6 +116,7 @@ type (
 		tokenInfo   TokensInfo
 
 		endpoints   *Lazy[Endpoints]
+		quotas      quotaCache
 		loginForm   url.Values
 		authHeader  string
 		Client      *http.Client
@@ -155,6 +162,10 @@ func NewCFClient(conf *Config, logger lager.Logger, clk clock.Clock) *Client {
 	if c.conf.PerPage == 0 {
 		c.conf.PerPage = defaultPerPage
 	}
+	if c.conf.QuotaCacheTTL == 0 {
+		c.conf.QuotaCacheTTL = DefaultQuotaCacheTTL
+	}
+	c.quotas.spaces = map[SpaceId]*spaceQuotas{}
 	return c
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type ClientConfig struct {
@@ -20,6 +21,8 @@ type Config struct {
 	ClientID     string `yaml:"client_id"`
 	Secret       string `yaml:"secret"`
 	PerPage      int    `yaml:"per_page"`
+	// QuotaCacheTTL is how long the organization and space quotas are cached
+	QuotaCacheTTL time.Duration `yaml:"quota_cache_ttl"`
 }
 
 func (conf *Config) Validate() error {
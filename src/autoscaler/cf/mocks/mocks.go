@@ -125,6 +125,19 @@ func (a AddMock) ScaleAppWebProcess() AddMock {
 	return a
 }
 
+// SpaceQuotas serves an unlimited organization quota without space quota and no usage for every space.
+func (a AddMock) SpaceQuotas() AddMock {
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/spaces/[^/]+$`),
+		ghttp.RespondWith(http.StatusOK, `{"guid":"test-space-guid","relationships":{"organization":{"data":{"guid":"test-org-guid"}},"quota":{"data":null}}}`))
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/organizations/[^/]+$`),
+		ghttp.RespondWith(http.StatusOK, `{"guid":"test-org-guid","relationships":{"quota":{"data":{"guid":"test-org-quota-guid"}}}}`))
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/organization_quotas/[^/]+$`),
+		ghttp.RespondWithJSONEncoded(http.StatusOK, cf.Quota{Guid: "test-org-quota-guid", Name: "default"}))
+	a.server.RouteToHandler("GET", regexp.MustCompile(`^/v3/organizations/[^/]+/usage_summary$`),
+		ghttp.RespondWith(http.StatusOK, `{"usage_summary":{"started_instances":0,"memory_in_mb":0}}`))
+	return a
+}
+
 func (a AddMock) Roles(statusCode int, roles ...cf.Role) AddMock {
 	a.server.RouteToHandler("GET", "/v3/roles",
 		ghttp.RespondWithJSONEncoded(statusCode, definedResponsesOR(statusCode, cf.Response[cf.Role]{Resources: roles})))
@@ -0,0 +1,53 @@
+package cf
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	InstanceStateRunning  = "RUNNING"
+	InstanceStateCrashed  = "CRASHED"
+	InstanceStateStarting = "STARTING"
+	InstanceStateDown     = "DOWN"
+)
+
+type (
+	//InstanceStats the state of an instance of a process, for full version look at https://v3-apidocs.cloudfoundry.org/version/3.122.0/index.html#the-process-stats-object
+	InstanceStats struct {
+		Type  string `json:"type"`
+		Index int    `json:"index"`
+		State string `json:"state"`
+	}
+	ProcessStats []InstanceStats
+)
+
+// CountStates returns the number of instances in each state, only the instances with an index below the given
+// number of instances are counted, as the others are being stopped by a scale-in.
+func (s ProcessStats) CountStates(instances int) map[string]int {
+	states := map[string]int{}
+	for _, stats := range s {
+		if stats.Index < instances {
+			states[stats.State]++
+		}
+	}
+	return states
+}
+
+/*GetAppProcessStats
+ * Get the state of every instance of the process of the given type of an app
+ * from the v3 api https://v3-apidocs.cloudfoundry.org/version/3.122.0/index.html#get-stats-for-a-process
+ * using GET /v3/apps/:guid/processes/:type/stats
+ */
+func (c *Client) GetAppProcessStats(appId Guid, processType string) (ProcessStats, error) {
+	return c.CtxClient.GetAppProcessStats(context.Background(), appId, processType)
+}
+
+func (c *CtxClient) GetAppProcessStats(ctx context.Context, appId Guid, processType string) (ProcessStats, error) {
+	url := fmt.Sprintf("/v3/apps/%s/processes/%s/stats", appId, processType)
+	resp, err := ResourceRetriever[Response[InstanceStats]]{AuthenticatedClient{c}}.Get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed GetAppProcessStats '%s' process '%s': %w", appId, processType, err)
+	}
+	return resp.Resources, nil
+}
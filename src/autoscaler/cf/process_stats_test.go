@@ -0,0 +1,65 @@
+package cf_test
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Cf client Process Stats", func() {
+
+	BeforeEach(login)
+
+	Describe("GetAppProcessStats", func() {
+
+		When("get process stats succeeds", func() {
+			BeforeEach(func() {
+				fakeCC.AppendHandlers(
+					CombineHandlers(
+						VerifyRequest("GET", "/v3/apps/test-app-id/processes/web/stats"),
+						VerifyHeaderKV("Authorization", "Bearer test-access-token"),
+						RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+							"resources": []map[string]interface{}{
+								{"type": "web", "index": 0, "state": "RUNNING", "uptime": 1000},
+								{"type": "web", "index": 1, "state": "STARTING", "uptime": 0},
+								{"type": "web", "index": 2, "state": "CRASHED", "uptime": 0},
+							},
+						}),
+					),
+				)
+			})
+
+			It("returns the state of every instance", func() {
+				stats, err := cfc.GetAppProcessStats("test-app-id", cf.ProcessTypeWeb)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stats).To(Equal(cf.ProcessStats{
+					{Type: "web", Index: 0, State: cf.InstanceStateRunning},
+					{Type: "web", Index: 1, State: cf.InstanceStateStarting},
+					{Type: "web", Index: 2, State: cf.InstanceStateCrashed},
+				}))
+				Expect(stats.CountStates(3)).To(Equal(map[string]int{cf.InstanceStateRunning: 1, cf.InstanceStateStarting: 1, cf.InstanceStateCrashed: 1}))
+				Expect(stats.CountStates(2)).To(Equal(map[string]int{cf.InstanceStateRunning: 1, cf.InstanceStateStarting: 1}))
+			})
+		})
+
+		When("get process stats returns a 500 status code", func() {
+			BeforeEach(func() {
+				fakeCC.AppendHandlers(
+					CombineHandlers(
+						RespondWithJSONEncoded(http.StatusInternalServerError, ""),
+					),
+				)
+			})
+
+			It("should error", func() {
+				stats, err := cfc.GetAppProcessStats("test-app-id", cf.ProcessTypeWeb)
+				Expect(stats).To(BeNil())
+				Expect(err).To(MatchError(ContainSubstring("failed GetAppProcessStats 'test-app-id' process 'web'")))
+			})
+		})
+	})
+})
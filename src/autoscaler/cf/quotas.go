@@ -0,0 +1,184 @@
+package cf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const DefaultQuotaCacheTTL = 5 * time.Minute
+
+type (
+	//Quota the app limits of an organization or space quota, for full version look at https://v3-apidocs.cloudfoundry.org/version/3.122.0/index.html#organization-quotas
+	Quota struct {
+		Guid string    `json:"guid"`
+		Name string    `json:"name"`
+		Apps QuotaApps `json:"apps"`
+	}
+	// QuotaApps holds the app limits of a quota, nil means unlimited.
+	QuotaApps struct {
+		TotalMemoryInMb *int `json:"total_memory_in_mb"`
+		TotalInstances  *int `json:"total_instances"`
+	}
+
+	//UsageSummary the resources used by the started apps of an organization or space https://v3-apidocs.cloudfoundry.org/version/3.122.0/index.html#get-usage-summary
+	UsageSummary struct {
+		StartedInstances int `json:"started_instances"`
+		MemoryInMb       int `json:"memory_in_mb"`
+	}
+	usageSummaryResponse struct {
+		UsageSummary UsageSummary `json:"usage_summary"`
+	}
+
+	QuotaUsage struct {
+		Quota Quota
+		Usage UsageSummary
+	}
+
+	// SpaceQuotaUsage holds the quotas which apply to the apps of a space and their current usage. Space is nil if the
+	// space has no space quota.
+	SpaceQuotaUsage struct {
+		Org   *QuotaUsage
+		Space *QuotaUsage
+	}
+
+	spaceResource struct {
+		Guid          string             `json:"guid"`
+		Relationships spaceRelationships `json:"relationships"`
+	}
+	spaceRelationships struct {
+		Organization relationship  `json:"organization"`
+		Quota        *relationship `json:"quota"`
+	}
+	organizationResource struct {
+		Guid          string                    `json:"guid"`
+		Relationships organizationRelationships `json:"relationships"`
+	}
+	organizationRelationships struct {
+		Quota relationship `json:"quota"`
+	}
+	relationship struct {
+		Data *relationshipData `json:"data"`
+	}
+	relationshipData struct {
+		Guid string `json:"guid"`
+	}
+
+	// spaceQuotas are the quotas of a space, which are cached as they rarely change
+	spaceQuotas struct {
+		orgGuid    string
+		orgQuota   Quota
+		spaceQuota *Quota
+		expireAt   time.Time
+	}
+	quotaCache struct {
+		mu     sync.Mutex
+		spaces map[SpaceId]*spaceQuotas
+	}
+)
+
+/*GetSpaceQuotaUsage
+ * Gets the organization and space quotas which apply to the apps of a space, together with their current usage.
+ * The quotas are cached for the quota cache ttl of the client, the usage is always retrieved.
+ * v3 api docs https://v3-apidocs.cloudfoundry.org/version/3.122.0/index.html#organization-quotas
+ */
+func (c *Client) GetSpaceQuotaUsage(spaceId SpaceId) (*SpaceQuotaUsage, error) {
+	return c.CtxClient.GetSpaceQuotaUsage(context.Background(), spaceId)
+}
+
+func (c *CtxClient) GetSpaceQuotaUsage(ctx context.Context, spaceId SpaceId) (*SpaceQuotaUsage, error) {
+	quotas, err := c.getSpaceQuotas(ctx, spaceId)
+	if err != nil {
+		return nil, fmt.Errorf("failed GetSpaceQuotaUsage(%s): %w", spaceId, err)
+	}
+
+	orgUsage, err := ResourceRetriever[usageSummaryResponse]{AuthenticatedClient{c}}.Get(ctx, fmt.Sprintf("/v3/organizations/%s/usage_summary", quotas.orgGuid))
+	if err != nil {
+		return nil, fmt.Errorf("failed GetSpaceQuotaUsage(%s): %w", spaceId, err)
+	}
+	result := &SpaceQuotaUsage{Org: &QuotaUsage{Quota: quotas.orgQuota, Usage: orgUsage.UsageSummary}}
+
+	if quotas.spaceQuota != nil {
+		spaceUsage, err := ResourceRetriever[usageSummaryResponse]{AuthenticatedClient{c}}.Get(ctx, fmt.Sprintf("/v3/spaces/%s/usage_summary", spaceId))
+		if err != nil {
+			return nil, fmt.Errorf("failed GetSpaceQuotaUsage(%s): %w", spaceId, err)
+		}
+		result.Space = &QuotaUsage{Quota: *quotas.spaceQuota, Usage: spaceUsage.UsageSummary}
+	}
+	return result, nil
+}
+
+func (c *CtxClient) getSpaceQuotas(ctx context.Context, spaceId SpaceId) (*spaceQuotas, error) {
+	c.quotas.mu.Lock()
+	cached, found := c.quotas.spaces[spaceId]
+	c.quotas.mu.Unlock()
+	if found && c.clk.Now().Before(cached.expireAt) {
+		return cached, nil
+	}
+
+	space, err := ResourceRetriever[spaceResource]{AuthenticatedClient{c}}.Get(ctx, fmt.Sprintf("/v3/spaces/%s", spaceId))
+	if err != nil {
+		return nil, err
+	}
+	if space.Relationships.Organization.Data == nil {
+		return nil, fmt.Errorf("space %s has no organization", spaceId)
+	}
+	quotas := &spaceQuotas{orgGuid: space.Relationships.Organization.Data.Guid}
+
+	org, err := ResourceRetriever[organizationResource]{AuthenticatedClient{c}}.Get(ctx, fmt.Sprintf("/v3/organizations/%s", quotas.orgGuid))
+	if err != nil {
+		return nil, err
+	}
+	if org.Relationships.Quota.Data == nil {
+		return nil, fmt.Errorf("organization %s has no quota", quotas.orgGuid)
+	}
+	quotas.orgQuota, err = ResourceRetriever[Quota]{AuthenticatedClient{c}}.Get(ctx, fmt.Sprintf("/v3/organization_quotas/%s", org.Relationships.Quota.Data.Guid))
+	if err != nil {
+		return nil, err
+	}
+
+	if space.Relationships.Quota != nil && space.Relationships.Quota.Data != nil {
+		spaceQuota, err := ResourceRetriever[Quota]{AuthenticatedClient{c}}.Get(ctx, fmt.Sprintf("/v3/space_quotas/%s", space.Relationships.Quota.Data.Guid))
+		if err != nil {
+			return nil, err
+		}
+		quotas.spaceQuota = &spaceQuota
+	}
+
+	quotas.expireAt = c.clk.Now().Add(c.conf.QuotaCacheTTL)
+	c.quotas.mu.Lock()
+	c.quotas.spaces[spaceId] = quotas
+	c.quotas.mu.Unlock()
+	return quotas, nil
+}
+
+// FitInstances returns how many of the new instances of a process with the given memory per instance fit into the
+// quotas, but never less than the current instances. The returned message names the quota which has limited the
+// instances, it is empty if all new instances fit.
+func (q *SpaceQuotaUsage) FitInstances(currentInstances int, newInstances int, memoryInMb int) (int, string) {
+	if newInstances <= currentInstances {
+		return newInstances, ""
+	}
+	fitting, message := newInstances, ""
+	if q.Space != nil {
+		if instances := q.Space.fitInstances(currentInstances, fitting, memoryInMb); instances < fitting {
+			fitting, message = instances, "limited by space quota"
+		}
+	}
+	if instances := q.Org.fitInstances(currentInstances, fitting, memoryInMb); instances < fitting {
+		fitting, message = instances, "limited by org quota"
+	}
+	return fitting, message
+}
+
+func (q *QuotaUsage) fitInstances(currentInstances int, newInstances int, memoryInMb int) int {
+	additional := newInstances - currentInstances
+	if limit := q.Quota.Apps.TotalInstances; limit != nil {
+		additional = min(additional, *limit-q.Usage.StartedInstances)
+	}
+	if limit := q.Quota.Apps.TotalMemoryInMb; limit != nil && memoryInMb > 0 {
+		additional = min(additional, (*limit-q.Usage.MemoryInMb)/memoryInMb)
+	}
+	return currentInstances + max(additional, 0)
+}
@@ -0,0 +1,213 @@
+package cf_test
+
+import (
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Cf client Quotas", func() {
+	intPtr := func(i int) *int { return &i }
+
+	Describe("GetSpaceQuotaUsage", func() {
+		var (
+			quotaUsage *cf.SpaceQuotaUsage
+			spaceJson  map[string]interface{}
+		)
+
+		BeforeEach(func() {
+			login()
+			spaceJson = map[string]interface{}{
+				"guid": "test-space-id",
+				"relationships": map[string]interface{}{
+					"organization": map[string]interface{}{"data": map[string]interface{}{"guid": "test-org-id"}},
+					"quota":        map[string]interface{}{"data": nil},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			quotaUsage, err = cfc.GetSpaceQuotaUsage("test-space-id")
+		})
+
+		quotaHandlers := func() []http.HandlerFunc {
+			return []http.HandlerFunc{
+				CombineHandlers(
+					VerifyRequest("GET", "/v3/spaces/test-space-id"),
+					VerifyHeaderKV("Authorization", "Bearer test-access-token"),
+					RespondWithJSONEncoded(http.StatusOK, spaceJson),
+				),
+				CombineHandlers(
+					VerifyRequest("GET", "/v3/organizations/test-org-id"),
+					RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"guid":          "test-org-id",
+						"relationships": map[string]interface{}{"quota": map[string]interface{}{"data": map[string]interface{}{"guid": "test-org-quota-id"}}},
+					}),
+				),
+				CombineHandlers(
+					VerifyRequest("GET", "/v3/organization_quotas/test-org-quota-id"),
+					RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"guid": "test-org-quota-id",
+						"name": "default",
+						"apps": map[string]interface{}{"total_memory_in_mb": 10240, "total_instances": nil},
+					}),
+				),
+			}
+		}
+		orgUsageHandler := CombineHandlers(
+			VerifyRequest("GET", "/v3/organizations/test-org-id/usage_summary"),
+			RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+				"usage_summary": map[string]interface{}{"started_instances": 3, "memory_in_mb": 768},
+			}),
+		)
+
+		When("the space has no space quota", func() {
+			BeforeEach(func() {
+				fakeCC.AppendHandlers(append(quotaHandlers(), orgUsageHandler)...)
+			})
+
+			It("returns the org quota and its usage", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(quotaUsage).To(Equal(&cf.SpaceQuotaUsage{
+					Org: &cf.QuotaUsage{
+						Quota: cf.Quota{Guid: "test-org-quota-id", Name: "default", Apps: cf.QuotaApps{TotalMemoryInMb: intPtr(10240)}},
+						Usage: cf.UsageSummary{StartedInstances: 3, MemoryInMb: 768},
+					},
+				}))
+			})
+
+			When("the quotas are retrieved again", func() {
+				BeforeEach(func() {
+					fakeCC.AppendHandlers(orgUsageHandler)
+				})
+
+				It("caches the quotas but not the usage", func() {
+					_, err = cfc.GetSpaceQuotaUsage("test-space-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeCC.ReceivedRequests()).To(HaveLen(6))
+				})
+			})
+
+			When("the quotas are retrieved again after the cache ttl", func() {
+				BeforeEach(func() {
+					fakeCC.AppendHandlers(append(quotaHandlers(), orgUsageHandler)...)
+				})
+
+				It("retrieves the quotas again", func() {
+					fclock.Increment(cf.DefaultQuotaCacheTTL + time.Second)
+					_, err = cfc.GetSpaceQuotaUsage("test-space-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fakeCC.ReceivedRequests()).To(HaveLen(9))
+				})
+			})
+		})
+
+		When("the space has a space quota", func() {
+			BeforeEach(func() {
+				spaceJson["relationships"].(map[string]interface{})["quota"] = map[string]interface{}{"data": map[string]interface{}{"guid": "test-space-quota-id"}}
+				fakeCC.AppendHandlers(append(quotaHandlers(),
+					CombineHandlers(
+						VerifyRequest("GET", "/v3/space_quotas/test-space-quota-id"),
+						RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+							"guid": "test-space-quota-id",
+							"name": "small",
+							"apps": map[string]interface{}{"total_memory_in_mb": nil, "total_instances": 5},
+						}),
+					),
+					orgUsageHandler,
+					CombineHandlers(
+						VerifyRequest("GET", "/v3/spaces/test-space-id/usage_summary"),
+						RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+							"usage_summary": map[string]interface{}{"started_instances": 2, "memory_in_mb": 512},
+						}),
+					),
+				)...)
+			})
+
+			It("returns the org and space quotas and their usage", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(quotaUsage.Org.Usage).To(Equal(cf.UsageSummary{StartedInstances: 3, MemoryInMb: 768}))
+				Expect(quotaUsage.Space).To(Equal(&cf.QuotaUsage{
+					Quota: cf.Quota{Guid: "test-space-quota-id", Name: "small", Apps: cf.QuotaApps{TotalInstances: intPtr(5)}},
+					Usage: cf.UsageSummary{StartedInstances: 2, MemoryInMb: 512},
+				}))
+			})
+		})
+
+		When("getting the space fails", func() {
+			BeforeEach(func() {
+				fakeCC.AppendHandlers(
+					CombineHandlers(
+						VerifyRequest("GET", "/v3/spaces/test-space-id"),
+						RespondWithJSONEncoded(http.StatusNotFound, ""),
+					),
+				)
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(MatchError(ContainSubstring("failed GetSpaceQuotaUsage(test-space-id)")))
+				Expect(quotaUsage).To(BeNil())
+			})
+		})
+	})
+
+	Describe("SpaceQuotaUsage.FitInstances", func() {
+		var quotaUsage *cf.SpaceQuotaUsage
+
+		BeforeEach(func() {
+			quotaUsage = &cf.SpaceQuotaUsage{
+				Org: &cf.QuotaUsage{
+					Quota: cf.Quota{Apps: cf.QuotaApps{TotalMemoryInMb: intPtr(2048), TotalInstances: intPtr(10)}},
+					Usage: cf.UsageSummary{StartedInstances: 4, MemoryInMb: 1024},
+				},
+			}
+		})
+
+		It("returns the new instances if they fit", func() {
+			Expect(quotaUsage.FitInstances(2, 4, 256)).To(Equal(4))
+		})
+
+		It("limits the new instances by the org memory", func() {
+			instances, message := quotaUsage.FitInstances(2, 8, 256)
+			Expect(instances).To(Equal(6))
+			Expect(message).To(Equal("limited by org quota"))
+		})
+
+		It("limits the new instances by the org instances", func() {
+			instances, message := quotaUsage.FitInstances(2, 10, 64)
+			Expect(instances).To(Equal(8))
+			Expect(message).To(Equal("limited by org quota"))
+		})
+
+		It("never returns less than the current instances", func() {
+			quotaUsage.Org.Usage.MemoryInMb = 4096
+			instances, message := quotaUsage.FitInstances(2, 4, 256)
+			Expect(instances).To(Equal(2))
+			Expect(message).To(Equal("limited by org quota"))
+		})
+
+		It("does not limit scale-ins", func() {
+			quotaUsage.Org.Usage.MemoryInMb = 4096
+			Expect(quotaUsage.FitInstances(4, 2, 256)).To(Equal(2))
+		})
+
+		When("the space quota is lower", func() {
+			BeforeEach(func() {
+				quotaUsage.Space = &cf.QuotaUsage{
+					Quota: cf.Quota{Apps: cf.QuotaApps{TotalInstances: intPtr(3)}},
+					Usage: cf.UsageSummary{StartedInstances: 2, MemoryInMb: 512},
+				}
+			})
+
+			It("limits the new instances by the space quota", func() {
+				instances, message := quotaUsage.FitInstances(2, 8, 256)
+				Expect(instances).To(Equal(3))
+				Expect(message).To(Equal("limited by space quota"))
+			})
+		})
+	})
+})
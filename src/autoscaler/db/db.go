@@ -43,6 +43,17 @@ type DatabaseConfig struct {
 	ConnectionMaxIdleTime time.Duration `yaml:"connection_max_idletime"`
 }
 
+// ScalingHistoryFilter narrows down the scaling histories of an app. Without statuses, ignored scalings are only
+// included with IncludeAll. Reason matches the histories whose reason contains it, ignoring the case.
+type ScalingHistoryFilter struct {
+	IncludeAll   bool
+	Statuses     []models.ScalingStatus
+	ScalingTypes []models.ScalingType
+	Reason       string
+	// Simulated only keeps the entries which are simulated, or not, if set.
+	Simulated *bool
+}
+
 type InstanceMetricsDB interface {
 	healthendpoint.DatabaseStatus
 	RetrieveInstanceMetrics(appid string, instanceIndex int, name string, start int64, end int64, orderType OrderType) ([]*models.AppInstanceMetric, error)
@@ -57,7 +68,7 @@ type PolicyDB interface {
 	healthendpoint.Pinger
 	GetAppIds(ctx context.Context) (map[string]bool, error)
 	GetAppPolicy(ctx context.Context, appId string) (*models.ScalingPolicy, error)
-	SaveAppPolicy(ctx context.Context, appId string, policy *models.ScalingPolicy, policyGuid string) error
+	SaveAppPolicy(ctx context.Context, appId string, policy *models.ScalingPolicy, policyGuid string, author string) error
 	SetOrUpdateDefaultAppPolicy(ctx context.Context, appIds []string, oldPolicyGuid string, newPolicy *models.ScalingPolicy, newPolicyGuid string) ([]string, error)
 	DeletePoliciesByPolicyGuid(ctx context.Context, policyGuid string) ([]string, error)
 	RetrievePolicies() ([]*models.PolicyJson, error)
@@ -66,6 +77,19 @@ type PolicyDB interface {
 	SaveCredential(ctx context.Context, appId string, cred models.Credential) error
 	DeleteCredential(ctx context.Context, appId string) error
 	GetCredential(appId string) (*models.Credential, error)
+	SuspendApp(ctx context.Context, suspension *models.AppSuspension) error
+	ResumeApp(ctx context.Context, appId string) error
+	GetAppSuspension(ctx context.Context, appId string) (*models.AppSuspension, error)
+	DeleteExpiredAppSuspensions(ctx context.Context, before int64) error
+	RetrievePolicyRevisions(ctx context.Context, appId string) ([]*models.PolicyRevision, error)
+	GetPolicyRevision(ctx context.Context, appId string, version int) (*models.PolicyRevision, error)
+	DeletePolicyRevisions(ctx context.Context, appId string) error
+	SaveWebhookSubscriptions(ctx context.Context, subscriptions []*models.WebhookSubscription) error
+	GetWebhookSubscription(ctx context.Context, appId string, guid string) (*models.WebhookSubscription, error)
+	RetrieveWebhookSubscriptions(ctx context.Context, appId string) ([]*models.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, guid string) error
+	DeleteWebhookSubscriptions(ctx context.Context, appId string) error
+	DeleteServiceInstanceWebhookSubscriptions(ctx context.Context, serviceInstanceId string) error
 }
 
 type BindingDB interface {
@@ -93,22 +117,42 @@ type AppMetricDB interface {
 	SaveAppMetricsInBulk(metrics []*models.AppMetric) error
 	RetrieveAppMetrics(appId string, metricType string, start int64, end int64, orderType OrderType) ([]*models.AppMetric, error)
 	PruneAppMetrics(ctx context.Context, before int64) error
+	SaveEventGeneratorHeartbeat(nodeId string, heartbeatAt int64) error
+	RetrieveEventGeneratorMembers(since int64) ([]string, error)
+	DeleteEventGeneratorMember(nodeId string) error
+	SaveEvaluationCooldown(appId string, cooldown *models.ScalingCooldown) error
+	SaveEvaluationBreakerState(appId string, state models.BreakerState) error
+	RetrieveAppEvaluationState(appId string, now int64) (*models.AppEvaluationState, error)
 	io.Closer
 }
 
 type ScalingEngineDB interface {
 	healthendpoint.DatabaseStatus
 	SaveScalingHistory(history *models.AppScalingHistory) error
+	UpdateScalingHistoryOutcome(history *models.AppScalingHistory) error
 
-	CountScalingHistories(ctx context.Context, appId string, start int64, end int64, includeAll bool) (int, error)
-	RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType OrderType, includeAll bool, page int, resultsPerPAge int) ([]*models.AppScalingHistory, error)
+	CountScalingHistories(ctx context.Context, appId string, start int64, end int64, filter ScalingHistoryFilter) (int, error)
+	RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType OrderType, filter ScalingHistoryFilter, page int, resultsPerPAge int) ([]*models.AppScalingHistory, error)
 	PruneScalingHistories(ctx context.Context, before int64) error
-	UpdateScalingCooldownExpireTime(appId string, expireAt int64) error
-	CanScaleApp(appId string) (bool, int64, error)
+	UpdateScalingCooldownExpireTime(appId string, processType string, direction string, expireAt int64) error
+	ExtendScalingCooldownExpireTime(appId string, processType string, direction string, expireAt int64, newExpireAt int64) error
+	CanScaleApp(appId string, processType string, direction string) (bool, int64, error)
+	RetrieveScalingCooldowns(appId string) ([]*models.ScalingCooldown, error)
+	SaveScalingRecommendation(recommendation *models.ScalingRecommendation) error
+	RetrieveScalingRecommendations(appId string, since int64) ([]*models.ScalingRecommendation, error)
+	PruneScalingRecommendations(ctx context.Context, before int64) error
+	SaveWebhookDeliveries(deliveries []*models.WebhookDelivery) error
+	RetrieveDueWebhookDeliveries(now int64, limit int) ([]*models.WebhookDelivery, error)
+	ClaimWebhookDelivery(delivery *models.WebhookDelivery, leaseUntil int64) (bool, error)
+	UpdateWebhookDelivery(delivery *models.WebhookDelivery) error
+	RetrieveWebhookDeliveries(ctx context.Context, appId string, status string, limit int) ([]*models.WebhookDelivery, error)
+	PruneWebhookDeliveries(ctx context.Context, before int64) error
 	GetActiveSchedule(appId string) (*models.ActiveSchedule, error)
 	GetActiveSchedules() (map[string]string, error)
 	SetActiveSchedule(appId string, schedule *models.ActiveSchedule) error
 	RemoveActiveSchedule(appId string) error
+	AcquireAppLease(appId string, owner string, now int64, expireAt int64) (bool, error)
+	ReleaseAppLease(appId string, owner string) error
 	io.Closer
 }
 
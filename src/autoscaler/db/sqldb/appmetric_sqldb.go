@@ -9,6 +9,7 @@ import (
 	"github.com/uptrace/opentelemetry-go-extra/otelsqlx"
 
 	"database/sql"
+	"errors"
 	"time"
 
 	"code.cloudfoundry.org/lager/v3"
@@ -149,6 +150,127 @@ func (adb *AppMetricSQLDB) PruneAppMetrics(ctx context.Context, before int64) er
 
 	return err
 }
+
+// SaveEventGeneratorHeartbeat records that the eventgenerator node is alive at the given time.
+func (adb *AppMetricSQLDB) SaveEventGeneratorHeartbeat(nodeId string, heartbeatAt int64) error {
+	var query string
+	queryPrefix := "INSERT INTO eventgenerator_member(node_id, heartbeat_at) VALUES (?, ?) "
+	switch adb.sqldb.DriverName() {
+	case "pgx":
+		query = adb.sqldb.Rebind(queryPrefix + "ON CONFLICT(node_id) DO UPDATE SET heartbeat_at=EXCLUDED.heartbeat_at")
+	case "mysql":
+		query = adb.sqldb.Rebind(queryPrefix + "ON DUPLICATE KEY UPDATE heartbeat_at=VALUES(heartbeat_at)")
+	}
+	_, err := adb.sqldb.Exec(query, nodeId, heartbeatAt)
+	if err != nil {
+		adb.logger.Error("save-eventgenerator-heartbeat", err, lager.Data{"query": query, "nodeId": nodeId, "heartbeatAt": heartbeatAt})
+	}
+	return err
+}
+
+// RetrieveEventGeneratorMembers returns the ids of the eventgenerator nodes with a heartbeat at or after since, ordered
+// by id.
+func (adb *AppMetricSQLDB) RetrieveEventGeneratorMembers(since int64) ([]string, error) {
+	query := adb.sqldb.Rebind("SELECT node_id FROM eventgenerator_member WHERE heartbeat_at >= ? ORDER BY node_id")
+	rows, err := adb.sqldb.Query(query, since)
+	if err != nil {
+		adb.logger.Error("retrieve-eventgenerator-members", err, lager.Data{"query": query, "since": since})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	members := []string{}
+	for rows.Next() {
+		var nodeId string
+		if err = rows.Scan(&nodeId); err != nil {
+			adb.logger.Error("scan-eventgenerator-member", err)
+			return nil, err
+		}
+		members = append(members, nodeId)
+	}
+	return members, rows.Err()
+}
+
+func (adb *AppMetricSQLDB) DeleteEventGeneratorMember(nodeId string) error {
+	query := adb.sqldb.Rebind("DELETE FROM eventgenerator_member WHERE node_id = ?")
+	_, err := adb.sqldb.Exec(query, nodeId)
+	if err != nil {
+		adb.logger.Error("delete-eventgenerator-member", err, lager.Data{"query": query, "nodeId": nodeId})
+	}
+	return err
+}
+
+// SaveEvaluationCooldown records the cooldown of a process of an app in a direction and removes all expired cooldowns.
+func (adb *AppMetricSQLDB) SaveEvaluationCooldown(appId string, cooldown *models.ScalingCooldown) error {
+	query := adb.sqldb.Rebind("DELETE FROM evaluation_cooldown WHERE (app_id = ? AND process_type = ? AND direction = ?) OR expire_at <= ?")
+	_, err := adb.sqldb.Exec(query, appId, cooldown.ProcessType, cooldown.Direction, time.Now().UnixNano())
+	if err != nil {
+		adb.logger.Error("save-evaluation-cooldown-delete", err, lager.Data{"query": query, "appId": appId, "cooldown": cooldown})
+		return err
+	}
+
+	query = adb.sqldb.Rebind("INSERT INTO evaluation_cooldown(app_id, process_type, direction, expire_at) VALUES (?, ?, ?, ?)")
+	_, err = adb.sqldb.Exec(query, appId, cooldown.ProcessType, cooldown.Direction, cooldown.ExpireAt)
+	if err != nil {
+		adb.logger.Error("save-evaluation-cooldown-insert", err, lager.Data{"query": query, "appId": appId, "cooldown": cooldown})
+	}
+	return err
+}
+
+// SaveEvaluationBreakerState records the state of the circuit breaker of an app. The state of a breaker without
+// failures is not kept.
+func (adb *AppMetricSQLDB) SaveEvaluationBreakerState(appId string, state models.BreakerState) error {
+	query := adb.sqldb.Rebind("DELETE FROM evaluation_breaker WHERE app_id = ?")
+	_, err := adb.sqldb.Exec(query, appId)
+	if err != nil {
+		adb.logger.Error("save-evaluation-breaker-state-delete", err, lager.Data{"query": query, "appId": appId})
+		return err
+	}
+	if state == (models.BreakerState{}) {
+		return nil
+	}
+
+	query = adb.sqldb.Rebind("INSERT INTO evaluation_breaker(app_id, consecutive_failures, tripped, tripped_at) VALUES (?, ?, ?, ?)")
+	_, err = adb.sqldb.Exec(query, appId, state.ConsecutiveFailures, state.Tripped, state.TrippedAt)
+	if err != nil {
+		adb.logger.Error("save-evaluation-breaker-state-insert", err, lager.Data{"query": query, "appId": appId, "state": state})
+	}
+	return err
+}
+
+// RetrieveAppEvaluationState returns the cooldowns of an app which have not expired at now and the state of its
+// circuit breaker.
+func (adb *AppMetricSQLDB) RetrieveAppEvaluationState(appId string, now int64) (*models.AppEvaluationState, error) {
+	query := adb.sqldb.Rebind("SELECT process_type, direction, expire_at FROM evaluation_cooldown WHERE app_id = ? AND expire_at > ? ORDER BY process_type, direction")
+	rows, err := adb.sqldb.Query(query, appId, now)
+	if err != nil {
+		adb.logger.Error("retrieve-app-evaluation-state-cooldowns", err, lager.Data{"query": query, "appId": appId})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	state := &models.AppEvaluationState{Cooldowns: []*models.ScalingCooldown{}}
+	for rows.Next() {
+		cooldown := &models.ScalingCooldown{}
+		if err = rows.Scan(&cooldown.ProcessType, &cooldown.Direction, &cooldown.ExpireAt); err != nil {
+			adb.logger.Error("scan-app-evaluation-state-cooldown", err)
+			return nil, err
+		}
+		state.Cooldowns = append(state.Cooldowns, cooldown)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = adb.sqldb.Rebind("SELECT consecutive_failures, tripped, tripped_at FROM evaluation_breaker WHERE app_id = ?")
+	err = adb.sqldb.QueryRow(query, appId).Scan(&state.Breaker.ConsecutiveFailures, &state.Breaker.Tripped, &state.Breaker.TrippedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		adb.logger.Error("retrieve-app-evaluation-state-breaker", err, lager.Data{"query": query, "appId": appId})
+		return nil, err
+	}
+	return state, nil
+}
+
 func (adb *AppMetricSQLDB) GetDBStatus() sql.DBStats {
 	return adb.sqldb.Stats()
 }
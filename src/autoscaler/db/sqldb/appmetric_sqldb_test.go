@@ -455,4 +455,91 @@ var _ = Describe("AppMetricSQLDB", func() {
 		})
 
 	})
+
+	Context("EventGenerator membership", func() {
+		var nodeA, nodeB string
+
+		BeforeEach(func() {
+			nodeA = addProcessIdTo("node-a")
+			nodeB = addProcessIdTo("node-b")
+			DeferCleanup(func() {
+				_ = adb.DeleteEventGeneratorMember(nodeA)
+				_ = adb.DeleteEventGeneratorMember(nodeB)
+			})
+
+			Expect(adb.SaveEventGeneratorHeartbeat(nodeA, 100)).To(Succeed())
+			Expect(adb.SaveEventGeneratorHeartbeat(nodeB, 200)).To(Succeed())
+		})
+
+		It("retrieves the members with a heartbeat since the given time", func() {
+			members, err := adb.RetrieveEventGeneratorMembers(100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(ContainElements(nodeA, nodeB))
+
+			members, err = adb.RetrieveEventGeneratorMembers(150)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(ContainElement(nodeB))
+			Expect(members).NotTo(ContainElement(nodeA))
+		})
+
+		It("updates the heartbeat of a member", func() {
+			Expect(adb.SaveEventGeneratorHeartbeat(nodeA, 300)).To(Succeed())
+
+			members, err := adb.RetrieveEventGeneratorMembers(250)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(ContainElement(nodeA))
+			Expect(members).NotTo(ContainElement(nodeB))
+		})
+
+		It("deletes a member", func() {
+			Expect(adb.DeleteEventGeneratorMember(nodeA)).To(Succeed())
+
+			members, err := adb.RetrieveEventGeneratorMembers(0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).NotTo(ContainElement(nodeA))
+			Expect(members).To(ContainElement(nodeB))
+		})
+	})
+
+	Context("Evaluation state", func() {
+		var appId string
+
+		BeforeEach(func() {
+			appId = addProcessIdTo("an-app-id")
+			DeferCleanup(func() {
+				_ = adb.SaveEvaluationBreakerState(appId, models.BreakerState{})
+			})
+		})
+
+		It("retrieves an empty state for an unknown app", func() {
+			state, err := adb.RetrieveAppEvaluationState(appId, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(&models.AppEvaluationState{Cooldowns: []*models.ScalingCooldown{}}))
+		})
+
+		It("retrieves the cooldowns which have not expired", func() {
+			expireAt := time.Now().Add(time.Hour).UnixNano()
+			Expect(adb.SaveEvaluationCooldown(appId, &models.ScalingCooldown{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: expireAt})).To(Succeed())
+			Expect(adb.SaveEvaluationCooldown(appId, &models.ScalingCooldown{ProcessType: "web", Direction: models.ScalingDirectionIn, ExpireAt: expireAt + 100})).To(Succeed())
+			Expect(adb.SaveEvaluationCooldown(appId, &models.ScalingCooldown{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: expireAt + 200})).To(Succeed())
+
+			state, err := adb.RetrieveAppEvaluationState(appId, expireAt+100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Cooldowns).To(Equal([]*models.ScalingCooldown{{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: expireAt + 200}}))
+		})
+
+		It("saves and deletes the breaker state", func() {
+			breakerState := models.BreakerState{ConsecutiveFailures: 3, Tripped: true, TrippedAt: 100}
+			Expect(adb.SaveEvaluationBreakerState(appId, breakerState)).To(Succeed())
+
+			state, err := adb.RetrieveAppEvaluationState(appId, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Breaker).To(Equal(breakerState))
+
+			Expect(adb.SaveEvaluationBreakerState(appId, models.BreakerState{})).To(Succeed())
+			state, err = adb.RetrieveAppEvaluationState(appId, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Breaker).To(Equal(models.BreakerState{}))
+		})
+	})
 })
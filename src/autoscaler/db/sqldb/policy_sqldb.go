@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
@@ -144,7 +145,8 @@ func (pdb *PolicySQLDB) GetAppPolicy(ctx context.Context, appId string) (*models
 	return scalingPolicy, nil
 }
 
-func (pdb *PolicySQLDB) SaveAppPolicy(ctx context.Context, appId string, policy *models.ScalingPolicy, policyGuid string) error {
+// SaveAppPolicy stores the policy of an app and keeps it as a new revision, both in one transaction.
+func (pdb *PolicySQLDB) SaveAppPolicy(ctx context.Context, appId string, policy *models.ScalingPolicy, policyGuid string, author string) error {
 	var query string
 	queryPrefix := "INSERT INTO policy_json (app_id, policy_json, guid) VALUES (?,?,?) "
 	switch pdb.sqldb.DriverName() {
@@ -157,9 +159,47 @@ func (pdb *PolicySQLDB) SaveAppPolicy(ctx context.Context, appId string, policy
 	if err != nil {
 		return fmt.Errorf("SaveAppPolicy failed to marshal policy:  %w", err)
 	}
-	_, err = pdb.sqldb.ExecContext(ctx, query, appId, policyJSON, policyGuid)
+
+	tx, err := pdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		pdb.logger.Error("save-app-policy-begin-transaction", err, lager.Data{"app_id": appId})
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, query, appId, policyJSON, policyGuid)
 	if err != nil {
 		pdb.logger.Error("save-app-policy", err, lager.Data{"query": query, "app_id": appId, "policyJSON": policyJSON, "policyGuid": policyGuid})
+		return err
+	}
+
+	// lock the policy row so that concurrent saves for the same app get consecutive versions
+	var lockedPolicyGuid string
+	query = tx.Rebind("SELECT guid FROM policy_json WHERE app_id = ? FOR UPDATE")
+	err = tx.QueryRowContext(ctx, query, appId).Scan(&lockedPolicyGuid)
+	if err != nil {
+		pdb.logger.Error("save-app-policy-lock-policy", err, lager.Data{"query": query, "app_id": appId})
+		return err
+	}
+
+	var version int
+	query = tx.Rebind("SELECT COALESCE(MAX(version), 0) FROM policy_revision WHERE app_id = ?")
+	err = tx.QueryRowContext(ctx, query, appId).Scan(&version)
+	if err != nil {
+		pdb.logger.Error("save-app-policy-get-latest-revision", err, lager.Data{"query": query, "app_id": appId})
+		return err
+	}
+
+	query = tx.Rebind("INSERT INTO policy_revision (app_id, version, policy_json, guid, author, created_at) VALUES (?,?,?,?,?,?)")
+	_, err = tx.ExecContext(ctx, query, appId, version+1, policyJSON, policyGuid, author, time.Now().UnixNano())
+	if err != nil {
+		pdb.logger.Error("save-app-policy-revision", err, lager.Data{"query": query, "app_id": appId, "version": version + 1})
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		pdb.logger.Error("save-app-policy-commit", err, lager.Data{"app_id": appId})
 	}
 	return err
 }
@@ -390,3 +430,205 @@ func (pdb *PolicySQLDB) DeleteCredential(ctx context.Context, appId string) erro
 	}
 	return err
 }
+
+func (pdb *PolicySQLDB) SuspendApp(ctx context.Context, suspension *models.AppSuspension) error {
+	var query string
+	queryPrefix := "INSERT INTO app_suspension (app_id, suspended_at, expire_at, reason) VALUES (?, ?, ?, ?) "
+	switch pdb.sqldb.DriverName() {
+	case "pgx":
+		query = pdb.sqldb.Rebind(queryPrefix + "ON CONFLICT(app_id) DO UPDATE SET suspended_at=EXCLUDED.suspended_at, expire_at=EXCLUDED.expire_at, reason=EXCLUDED.reason")
+	case "mysql":
+		query = pdb.sqldb.Rebind(queryPrefix + "ON DUPLICATE KEY UPDATE suspended_at=VALUES(suspended_at), expire_at=VALUES(expire_at), reason=VALUES(reason)")
+	}
+	_, err := pdb.sqldb.ExecContext(ctx, query, suspension.AppId, suspension.SuspendedAt, suspension.ExpireAt, suspension.Reason)
+	if err != nil {
+		pdb.logger.Error("suspend-app", err, lager.Data{"query": query, "suspension": suspension})
+	}
+	return err
+}
+
+func (pdb *PolicySQLDB) ResumeApp(ctx context.Context, appId string) error {
+	query := pdb.sqldb.Rebind("DELETE FROM app_suspension WHERE app_id = ?")
+	_, err := pdb.sqldb.ExecContext(ctx, query, appId)
+	if err != nil {
+		pdb.logger.Error("resume-app", err, lager.Data{"query": query, "appId": appId})
+	}
+	return err
+}
+
+func (pdb *PolicySQLDB) GetAppSuspension(ctx context.Context, appId string) (*models.AppSuspension, error) {
+	suspension := &models.AppSuspension{AppId: appId}
+	query := pdb.sqldb.Rebind("SELECT suspended_at, expire_at, reason FROM app_suspension WHERE app_id = ?")
+	err := pdb.sqldb.QueryRowContext(ctx, query, appId).Scan(&suspension.SuspendedAt, &suspension.ExpireAt, &suspension.Reason)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		pdb.logger.Error("get-app-suspension", err, lager.Data{"query": query, "appId": appId})
+		return nil, err
+	}
+	return suspension, nil
+}
+
+// DeleteExpiredAppSuspensions deletes the suspensions that expired before the given time. Suspensions without
+// expiry stay until the app is resumed.
+func (pdb *PolicySQLDB) DeleteExpiredAppSuspensions(ctx context.Context, before int64) error {
+	query := pdb.sqldb.Rebind("DELETE FROM app_suspension WHERE expire_at > 0 AND expire_at < ?")
+	_, err := pdb.sqldb.ExecContext(ctx, query, before)
+	if err != nil {
+		pdb.logger.Error("delete-expired-app-suspensions", err, lager.Data{"query": query, "before": before})
+	}
+	return err
+}
+
+// RetrievePolicyRevisions returns the revisions of the policy of an app without the policies, newest first.
+func (pdb *PolicySQLDB) RetrievePolicyRevisions(ctx context.Context, appId string) ([]*models.PolicyRevision, error) {
+	query := pdb.sqldb.Rebind("SELECT version, guid, author, created_at FROM policy_revision WHERE app_id = ? ORDER BY version DESC")
+	rows, err := pdb.sqldb.QueryContext(ctx, query, appId)
+	if err != nil {
+		pdb.logger.Error("retrieve-policy-revisions", err, lager.Data{"query": query, "appId": appId})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	revisions := []*models.PolicyRevision{}
+	for rows.Next() {
+		revision := &models.PolicyRevision{AppId: appId}
+		if err = rows.Scan(&revision.Version, &revision.PolicyGuid, &revision.Author, &revision.CreatedAt); err != nil {
+			pdb.logger.Error("retrieve-policy-revisions-scan", err)
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+// GetPolicyRevision returns a revision of the policy of an app including the policy, or nil if it does not exist.
+func (pdb *PolicySQLDB) GetPolicyRevision(ctx context.Context, appId string, version int) (*models.PolicyRevision, error) {
+	var policyJson []byte
+	revision := &models.PolicyRevision{AppId: appId, Version: version}
+	query := pdb.sqldb.Rebind("SELECT policy_json, guid, author, created_at FROM policy_revision WHERE app_id = ? AND version = ?")
+	err := pdb.sqldb.QueryRowContext(ctx, query, appId, version).Scan(&policyJson, &revision.PolicyGuid, &revision.Author, &revision.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		pdb.logger.Error("get-policy-revision", err, lager.Data{"query": query, "appId": appId, "version": version})
+		return nil, err
+	}
+
+	err = json.Unmarshal(policyJson, &revision.Policy)
+	if err != nil {
+		pdb.logger.Error("get-policy-revision-unmarshal", err, lager.Data{"policyJson": string(policyJson)})
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (pdb *PolicySQLDB) DeletePolicyRevisions(ctx context.Context, appId string) error {
+	query := pdb.sqldb.Rebind("DELETE FROM policy_revision WHERE app_id = ?")
+	_, err := pdb.sqldb.ExecContext(ctx, query, appId)
+	if err != nil {
+		pdb.logger.Error("delete-policy-revisions", err, lager.Data{"query": query, "appId": appId})
+	}
+	return err
+}
+
+func (pdb *PolicySQLDB) SaveWebhookSubscriptions(ctx context.Context, subscriptions []*models.WebhookSubscription) error {
+	tx, err := pdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		pdb.logger.Error("save-webhook-subscriptions-begin-transaction", err)
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := tx.Rebind("INSERT INTO webhook_subscription (guid, app_id, service_instance_id, url, secret, created_at) VALUES (?, ?, ?, ?, ?, ?)")
+	for _, subscription := range subscriptions {
+		_, err = tx.ExecContext(ctx, query, subscription.Guid, subscription.AppId, subscription.ServiceInstanceId, subscription.Url, subscription.Secret, subscription.CreatedAt)
+		if err != nil {
+			pdb.logger.Error("save-webhook-subscription", err, lager.Data{"query": query, "guid": subscription.Guid, "appId": subscription.AppId})
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		pdb.logger.Error("save-webhook-subscriptions-commit", err)
+	}
+	return err
+}
+
+// webhookSubscriptionsOfApp selects the webhook subscriptions of an app and of the service instance it is bound to. The
+// apps of a service instance subscription are resolved from the binding table, which is created in the policy database
+// as well, so that apps bound later are notified without copying the subscription.
+const webhookSubscriptionsOfApp = "SELECT guid, app_id, service_instance_id, url, secret, created_at FROM webhook_subscription " +
+	"WHERE (app_id = ? AND service_instance_id = '') " +
+	"OR (app_id = '' AND service_instance_id IN (SELECT service_instance_id FROM binding WHERE app_id = ?))"
+
+// GetWebhookSubscription returns a webhook subscription of an app or of its service instance including its secret, or
+// nil if it does not exist.
+func (pdb *PolicySQLDB) GetWebhookSubscription(ctx context.Context, appId string, guid string) (*models.WebhookSubscription, error) {
+	subscription := &models.WebhookSubscription{}
+	query := pdb.sqldb.Rebind("SELECT * FROM (" + webhookSubscriptionsOfApp + ") s WHERE guid = ?")
+	err := pdb.sqldb.QueryRowContext(ctx, query, appId, appId, guid).Scan(&subscription.Guid, &subscription.AppId, &subscription.ServiceInstanceId, &subscription.Url, &subscription.Secret, &subscription.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		pdb.logger.Error("get-webhook-subscription", err, lager.Data{"query": query, "appId": appId, "guid": guid})
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// RetrieveWebhookSubscriptions returns the webhook subscriptions of an app and of its service instance including their
+// secrets, oldest first.
+func (pdb *PolicySQLDB) RetrieveWebhookSubscriptions(ctx context.Context, appId string) ([]*models.WebhookSubscription, error) {
+	query := pdb.sqldb.Rebind(webhookSubscriptionsOfApp + " ORDER BY created_at ASC")
+	rows, err := pdb.sqldb.QueryContext(ctx, query, appId, appId)
+	if err != nil {
+		pdb.logger.Error("retrieve-webhook-subscriptions", err, lager.Data{"query": query, "appId": appId})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	subscriptions := []*models.WebhookSubscription{}
+	for rows.Next() {
+		subscription := &models.WebhookSubscription{}
+		if err = rows.Scan(&subscription.Guid, &subscription.AppId, &subscription.ServiceInstanceId, &subscription.Url, &subscription.Secret, &subscription.CreatedAt); err != nil {
+			pdb.logger.Error("retrieve-webhook-subscriptions-scan", err)
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (pdb *PolicySQLDB) DeleteWebhookSubscription(ctx context.Context, guid string) error {
+	query := pdb.sqldb.Rebind("DELETE FROM webhook_subscription WHERE guid = ?")
+	_, err := pdb.sqldb.ExecContext(ctx, query, guid)
+	if err != nil {
+		pdb.logger.Error("delete-webhook-subscription", err, lager.Data{"query": query, "guid": guid})
+	}
+	return err
+}
+
+// DeleteWebhookSubscriptions deletes the webhook subscriptions of an app, the subscriptions of its service instance are
+// kept for the other bound apps.
+func (pdb *PolicySQLDB) DeleteWebhookSubscriptions(ctx context.Context, appId string) error {
+	query := pdb.sqldb.Rebind("DELETE FROM webhook_subscription WHERE app_id = ? AND service_instance_id = ''")
+	_, err := pdb.sqldb.ExecContext(ctx, query, appId)
+	if err != nil {
+		pdb.logger.Error("delete-webhook-subscriptions", err, lager.Data{"query": query, "appId": appId})
+	}
+	return err
+}
+
+func (pdb *PolicySQLDB) DeleteServiceInstanceWebhookSubscriptions(ctx context.Context, serviceInstanceId string) error {
+	query := pdb.sqldb.Rebind("DELETE FROM webhook_subscription WHERE app_id = '' AND service_instance_id = ?")
+	_, err := pdb.sqldb.ExecContext(ctx, query, serviceInstanceId)
+	if err != nil {
+		pdb.logger.Error("delete-service-instance-webhook-subscriptions", err, lager.Data{"query": query, "serviceInstanceId": serviceInstanceId})
+	}
+	return err
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/go-sql-driver/mysql"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gstruct"
 )
 
 var _ = Describe("PolicySQLDB", func() {
@@ -82,6 +83,9 @@ var _ = Describe("PolicySQLDB", func() {
 		deletePolicies(pdb, logger, policyGuid, policyGuid2, policyGuid3)
 		deleteApps(pdb, logger, appId, appId2, appId3)
 		deleteCredentials(pdb, logger, appId, appId2, appId3)
+		resumeApps(pdb, logger, appId, appId2, appId3)
+		deletePolicyRevisions(pdb, logger, appId, appId2, appId3)
+		deleteWebhookSubscriptions(pdb, logger, appId, appId2, appId3)
 	})
 
 	Describe("NewPolicySQLDB", func() {
@@ -275,7 +279,7 @@ var _ = Describe("PolicySQLDB", func() {
 					}]
 				}`
 				Expect(json.Unmarshal([]byte(policyJsonStr), &policy)).ToNot(HaveOccurred())
-				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid)
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid, "a-user")
 			})
 			It("saves the policy", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -283,6 +287,15 @@ var _ = Describe("PolicySQLDB", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(formatPolicyString(getAppPolicy(appId))).To(Equal(policyString))
 			})
+
+			It("saves the first revision of the policy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				revision, err := pdb.GetPolicyRevision(context.Background(), appId, 1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revision.PolicyGuid).To(Equal(policyGuid))
+				Expect(revision.Author).To(Equal("a-user"))
+				Expect(revision.Policy).To(Equal(policy))
+			})
 		})
 
 		Context("when a policy is already present for the app_id", func() {
@@ -312,7 +325,7 @@ var _ = Describe("PolicySQLDB", func() {
 					}]
 				}`
 				Expect(json.Unmarshal([]byte(policyJsonStr), &policy)).ToNot(HaveOccurred())
-				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid)
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy, policyGuid, "a-user")
 			})
 			It("updates the policy", func() {
 				Expect(err).NotTo(HaveOccurred())
@@ -323,6 +336,76 @@ var _ = Describe("PolicySQLDB", func() {
 		})
 	})
 
+	Describe("RetrievePolicyRevisions and GetPolicyRevision", func() {
+		var (
+			revisions []*models.PolicyRevision
+			revision  *models.PolicyRevision
+			policy1   *models.ScalingPolicy
+			policy2   *models.ScalingPolicy
+		)
+
+		BeforeEach(func() {
+			policy1 = &models.ScalingPolicy{InstanceMin: 1, InstanceMax: 3}
+			policy2 = &models.ScalingPolicy{InstanceMin: 2, InstanceMax: 5}
+		})
+
+		JustBeforeEach(func() {
+			revisions, err = pdb.RetrievePolicyRevisions(context.Background(), appId)
+			Expect(err).NotTo(HaveOccurred())
+			revision, err = pdb.GetPolicyRevision(context.Background(), appId, 1)
+		})
+
+		Context("when no policy has been saved for the app", func() {
+			It("returns no revisions", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(BeEmpty())
+				Expect(revision).To(BeNil())
+			})
+		})
+
+		Context("when policies have been saved for the app", func() {
+			BeforeEach(func() {
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy1, policyGuid, "first-user")
+				Expect(err).NotTo(HaveOccurred())
+				err = pdb.SaveAppPolicy(context.Background(), appId, policy2, policyGuid2, "second-user")
+				Expect(err).NotTo(HaveOccurred())
+				err = pdb.SaveAppPolicy(context.Background(), appId2, policy2, policyGuid3, "third-user")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns the revisions of the app newest first", func() {
+				Expect(revisions).To(HaveLen(2))
+				Expect(revisions[0]).To(gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"AppId": Equal(appId), "Version": Equal(2), "PolicyGuid": Equal(policyGuid2), "Author": Equal("second-user"), "Policy": BeNil(),
+				})))
+				Expect(revisions[1]).To(gstruct.PointTo(gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"AppId": Equal(appId), "Version": Equal(1), "PolicyGuid": Equal(policyGuid), "Author": Equal("first-user"), "Policy": BeNil(),
+				})))
+			})
+
+			It("returns a revision with its policy", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revision.PolicyGuid).To(Equal(policyGuid))
+				Expect(revision.Author).To(Equal("first-user"))
+				Expect(revision.CreatedAt).To(BeNumerically(">", 0))
+				Expect(revision.Policy).To(Equal(policy1))
+			})
+
+			Context("when the revisions have been deleted", func() {
+				BeforeEach(func() {
+					err = pdb.DeletePolicyRevisions(context.Background(), appId)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns no revisions", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(revisions).To(BeEmpty())
+					Expect(revision).To(BeNil())
+				})
+			})
+		})
+	})
+
 	Describe("DeletePolicy", func() {
 		JustBeforeEach(func() {
 			err = pdb.DeletePolicy(context.Background(), appId)
@@ -549,8 +632,193 @@ var _ = Describe("PolicySQLDB", func() {
 		})
 	})
 
+	Describe("SuspendApp and GetAppSuspension", func() {
+		var suspension *models.AppSuspension
+
+		JustBeforeEach(func() {
+			suspension, err = pdb.GetAppSuspension(context.Background(), appId)
+		})
+
+		Context("when the app is not suspended", func() {
+			It("returns no suspension", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(suspension).To(BeNil())
+			})
+		})
+
+		Context("when the app is suspended", func() {
+			BeforeEach(func() {
+				err = pdb.SuspendApp(context.Background(), &models.AppSuspension{AppId: appId, SuspendedAt: 111111, ExpireAt: 222222, Reason: "load test"})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns the suspension", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(suspension).To(Equal(&models.AppSuspension{AppId: appId, SuspendedAt: 111111, ExpireAt: 222222, Reason: "load test"}))
+			})
+
+			Context("when the app is suspended again", func() {
+				BeforeEach(func() {
+					err = pdb.SuspendApp(context.Background(), &models.AppSuspension{AppId: appId, SuspendedAt: 333333})
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("replaces the suspension", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(suspension).To(Equal(&models.AppSuspension{AppId: appId, SuspendedAt: 333333}))
+				})
+			})
+		})
+
+		Context("when there is database error", func() {
+			BeforeEach(func() {
+				_ = pdb.Close()
+			})
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("ResumeApp", func() {
+		BeforeEach(func() {
+			err = pdb.SuspendApp(context.Background(), &models.AppSuspension{AppId: appId, SuspendedAt: 111111})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			err = pdb.ResumeApp(context.Background(), appId)
+		})
+
+		It("removes the suspension", func() {
+			Expect(err).NotTo(HaveOccurred())
+			suspension, err := pdb.GetAppSuspension(context.Background(), appId)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suspension).To(BeNil())
+		})
+	})
+
+	Describe("DeleteExpiredAppSuspensions", func() {
+		BeforeEach(func() {
+			err = pdb.SuspendApp(context.Background(), &models.AppSuspension{AppId: appId, SuspendedAt: 111111})
+			Expect(err).NotTo(HaveOccurred())
+			err = pdb.SuspendApp(context.Background(), &models.AppSuspension{AppId: appId2, SuspendedAt: 222222, ExpireAt: 333333})
+			Expect(err).NotTo(HaveOccurred())
+			err = pdb.SuspendApp(context.Background(), &models.AppSuspension{AppId: appId3, SuspendedAt: 222222, ExpireAt: 555555})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			err = pdb.DeleteExpiredAppSuspensions(context.Background(), 444444)
+		})
+
+		It("deletes only the suspensions that expired", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdb.GetAppSuspension(context.Background(), appId)).NotTo(BeNil())
+			Expect(pdb.GetAppSuspension(context.Background(), appId2)).To(BeNil())
+			Expect(pdb.GetAppSuspension(context.Background(), appId3)).NotTo(BeNil())
+		})
+	})
+	Describe("WebhookSubscriptions", func() {
+		var (
+			subscriptions   []*models.WebhookSubscription
+			appSubscription *models.WebhookSubscription
+			siSubscription  *models.WebhookSubscription
+			serviceInstance string
+		)
+
+		BeforeEach(func() {
+			serviceInstance = addProcessIdTo("a-service-instance")
+			Expect(insertServiceInstance(serviceInstance)).To(Succeed())
+			Expect(insertServiceBinding(serviceInstance, appId)).To(Succeed())
+			Expect(insertServiceBinding(serviceInstance, appId2)).To(Succeed())
+			DeferCleanup(func() {
+				deleteServiceInstanceWebhookSubscriptions(pdb, logger, serviceInstance)
+				deleteServiceBindings(serviceInstance)
+			})
+
+			appSubscription = &models.WebhookSubscription{Guid: addProcessIdTo("app-webhook"), AppId: appId, Url: "https://example.com/app", Secret: "app-secret", CreatedAt: 111111}
+			siSubscription = &models.WebhookSubscription{Guid: addProcessIdTo("si-webhook"), ServiceInstanceId: serviceInstance, Url: "https://example.com/si", Secret: "si-secret", CreatedAt: 222222}
+			err = pdb.SaveWebhookSubscriptions(context.Background(), []*models.WebhookSubscription{appSubscription, siSubscription})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("retrieves the subscriptions of an app and of its service instance oldest first", func() {
+			subscriptions, err = pdb.RetrieveWebhookSubscriptions(context.Background(), appId)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subscriptions).To(Equal([]*models.WebhookSubscription{appSubscription, siSubscription}))
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId2)).To(Equal([]*models.WebhookSubscription{siSubscription}))
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId3)).To(BeEmpty())
+		})
+
+		It("resolves the subscriptions of the service instance for apps bound later", func() {
+			Expect(insertServiceBinding(serviceInstance, appId3)).To(Succeed())
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId3)).To(Equal([]*models.WebhookSubscription{siSubscription}))
+		})
+
+		It("gets a subscription of an app or of its service instance", func() {
+			Expect(pdb.GetWebhookSubscription(context.Background(), appId, appSubscription.Guid)).To(Equal(appSubscription))
+			Expect(pdb.GetWebhookSubscription(context.Background(), appId2, siSubscription.Guid)).To(Equal(siSubscription))
+			Expect(pdb.GetWebhookSubscription(context.Background(), appId2, appSubscription.Guid)).To(BeNil())
+			Expect(pdb.GetWebhookSubscription(context.Background(), appId3, siSubscription.Guid)).To(BeNil())
+		})
+
+		It("deletes a subscription", func() {
+			err = pdb.DeleteWebhookSubscription(context.Background(), siSubscription.Guid)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId)).To(Equal([]*models.WebhookSubscription{appSubscription}))
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId2)).To(BeEmpty())
+		})
+
+		It("deletes the subscriptions of an app but keeps those of its service instance", func() {
+			err = pdb.DeleteWebhookSubscriptions(context.Background(), appId)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId)).To(Equal([]*models.WebhookSubscription{siSubscription}))
+		})
+
+		It("deletes the subscriptions of a service instance", func() {
+			err = pdb.DeleteServiceInstanceWebhookSubscriptions(context.Background(), serviceInstance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId)).To(Equal([]*models.WebhookSubscription{appSubscription}))
+			Expect(pdb.RetrieveWebhookSubscriptions(context.Background(), appId2)).To(BeEmpty())
+		})
+	})
 })
 
+func deleteWebhookSubscriptions(pdb *PolicySQLDB, logger lager.Logger, appIds ...string) {
+	for _, appId := range appIds {
+		err := pdb.DeleteWebhookSubscriptions(context.Background(), appId)
+		if err != nil {
+			logger.Error(fmt.Sprintf("DeleteWebhookSubscriptions app: %s", appId), err)
+		}
+	}
+}
+
+func deleteServiceInstanceWebhookSubscriptions(pdb *PolicySQLDB, logger lager.Logger, serviceInstanceId string) {
+	err := pdb.DeleteServiceInstanceWebhookSubscriptions(context.Background(), serviceInstanceId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("DeleteServiceInstanceWebhookSubscriptions service instance: %s", serviceInstanceId), err)
+	}
+}
+
+func resumeApps(pdb *PolicySQLDB, logger lager.Logger, appIds ...string) {
+	for _, appId := range appIds {
+		err := pdb.ResumeApp(context.Background(), appId)
+		if err != nil {
+			logger.Error(fmt.Sprintf("ResumeApp app: %s", appId), err)
+		}
+	}
+}
+
+func deletePolicyRevisions(pdb *PolicySQLDB, logger lager.Logger, appIds ...string) {
+	for _, appId := range appIds {
+		err := pdb.DeletePolicyRevisions(context.Background(), appId)
+		if err != nil {
+			logger.Error(fmt.Sprintf("DeletePolicyRevisions app: %s", appId), err)
+		}
+	}
+}
+
 func deleteCredentials(pdb *PolicySQLDB, logger lager.Logger, appIds ...string) {
 	for _, appId := range appIds {
 		err := pdb.DeleteCredential(context.Background(), appId)
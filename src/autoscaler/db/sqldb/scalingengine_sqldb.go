@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
@@ -67,10 +68,11 @@ func (sdb *ScalingEngineSQLDB) Close() error {
 
 func (sdb *ScalingEngineSQLDB) SaveScalingHistory(history *models.AppScalingHistory) error {
 	query := sdb.sqldb.Rebind("INSERT INTO scalinghistory" +
-		"(appid, timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error) " +
-		" VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		"(appid, timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error, processtype, simulated, rule, metricvalue, correlationid) " +
+		" VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	_, err := sdb.sqldb.Exec(query, history.AppId, history.Timestamp, history.ScalingType, history.Status,
-		history.OldInstances, history.NewInstances, history.Reason, history.Message, history.Error)
+		history.OldInstances, history.NewInstances, history.Reason, history.Message, history.Error, processTypeOrDefault(history.ProcessType), history.Simulated,
+		history.Rule, history.MetricValue, history.CorrelationId)
 
 	if err != nil {
 		return fmt.Errorf("saveScalingHistory failed appId(%s) scalingtype(%d) reason(%s): %w", history.AppId, history.ScalingType, history.Reason, err)
@@ -78,15 +80,28 @@ func (sdb *ScalingEngineSQLDB) SaveScalingHistory(history *models.AppScalingHist
 	return nil
 }
 
-func (sdb *ScalingEngineSQLDB) CountScalingHistories(ctx context.Context, appId string, start int64, end int64, includeAll bool) (int, error) {
-	query := sdb.sqldb.Rebind("SELECT COUNT(*) FROM scalinghistory WHERE appid = ? AND timestamp >= ? AND timestamp <= ?" + statusFilter(includeAll))
+// UpdateScalingHistoryOutcome updates the status, message and error of a saved scaling history record.
+func (sdb *ScalingEngineSQLDB) UpdateScalingHistoryOutcome(history *models.AppScalingHistory) error {
+	query := sdb.sqldb.Rebind("UPDATE scalinghistory SET status = ?, message = ?, error = ? WHERE appid = ? AND timestamp = ?")
+	_, err := sdb.sqldb.Exec(query, history.Status, history.Message, history.Error, history.AppId, history.Timestamp)
+	if err != nil {
+		sdb.logger.Error("update-scaling-history-outcome", err, lager.Data{"query": query, "appid": history.AppId, "timestamp": history.Timestamp})
+		return err
+	}
+	return nil
+}
 
+func (sdb *ScalingEngineSQLDB) CountScalingHistories(ctx context.Context, appId string, start int64, end int64, filter db.ScalingHistoryFilter) (int, error) {
 	if end < 0 {
 		end = time.Now().UnixNano()
 	}
 
+	where, args := historyFilter(filter)
+	query := sdb.sqldb.Rebind("SELECT COUNT(*) FROM scalinghistory WHERE appid = ? AND timestamp >= ? AND timestamp <= ?" + where)
+	args = append([]interface{}{appId, start, end}, args...)
+
 	var count int
-	err := sdb.sqldb.GetContext(ctx, &count, query, appId, start, end)
+	err := sdb.sqldb.GetContext(ctx, &count, query, args...)
 	if err != nil {
 		sdb.logger.Error("count-scaling-histories", err,
 			lager.Data{"query": query, "appid": appId, "start": start, "end": end})
@@ -96,21 +111,24 @@ func (sdb *ScalingEngineSQLDB) CountScalingHistories(ctx context.Context, appId
 	return count, nil
 }
 
-func (sdb *ScalingEngineSQLDB) RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType db.OrderType, includeAll bool, page int, resultsPerPage int) ([]*models.AppScalingHistory, error) {
-	query := sdb.sqldb.Rebind("SELECT timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error FROM scalinghistory WHERE" +
+func (sdb *ScalingEngineSQLDB) RetrieveScalingHistories(ctx context.Context, appId string, start int64, end int64, orderType db.OrderType, filter db.ScalingHistoryFilter, page int, resultsPerPage int) ([]*models.AppScalingHistory, error) {
+	if end < 0 {
+		end = time.Now().UnixNano()
+	}
+
+	where, args := historyFilter(filter)
+	query := sdb.sqldb.Rebind("SELECT timestamp, scalingtype, status, oldinstances, newinstances, reason, message, error, processtype, simulated, rule, metricvalue, correlationid FROM scalinghistory WHERE" +
 		" appid = ? " +
 		" AND timestamp >= ?" +
 		" AND timestamp <= ?" +
-		statusFilter(includeAll) +
+		where +
 		" ORDER BY timestamp " + orderTypeToString(orderType) +
 		" LIMIT ? OFFSET ?")
-
-	if end < 0 {
-		end = time.Now().UnixNano()
-	}
+	args = append([]interface{}{appId, start, end}, args...)
+	args = append(args, resultsPerPage, (page-1)*resultsPerPage)
 
 	histories := []*models.AppScalingHistory{}
-	rows, err := sdb.sqldb.QueryContext(ctx, query, appId, start, end, resultsPerPage, (page-1)*resultsPerPage)
+	rows, err := sdb.sqldb.QueryContext(ctx, query, args...)
 	if err != nil {
 		sdb.logger.Error("retrieve-scaling-histories", err,
 			lager.Data{"query": query, "appid": appId, "start": start, "end": end, "orderType": orderType})
@@ -121,36 +139,80 @@ func (sdb *ScalingEngineSQLDB) RetrieveScalingHistories(ctx context.Context, app
 
 	var timestamp int64
 	var scalingType, status, oldInstances, newInstances int
-	var reason, message, errorMsg string
+	var reason, message, errorMsg, processType, rule, correlationId string
+	var simulated bool
+	var metricValue sql.NullFloat64
 
 	for rows.Next() {
-		if err = rows.Scan(&timestamp, &scalingType, &status, &oldInstances, &newInstances, &reason, &message, &errorMsg); err != nil {
+		if err = rows.Scan(&timestamp, &scalingType, &status, &oldInstances, &newInstances, &reason, &message, &errorMsg, &processType, &simulated,
+			&rule, &metricValue, &correlationId); err != nil {
 			sdb.logger.Error("retrieve-scaling-history-scan", err)
 			return nil, err
 		}
 
 		history := models.AppScalingHistory{
-			AppId:        appId,
-			Timestamp:    timestamp,
-			ScalingType:  models.ScalingType(scalingType),
-			Status:       models.ScalingStatus(status),
-			OldInstances: oldInstances,
-			NewInstances: newInstances,
-			Reason:       reason,
-			Message:      message,
-			Error:        errorMsg,
+			AppId:         appId,
+			ProcessType:   processType,
+			Timestamp:     timestamp,
+			ScalingType:   models.ScalingType(scalingType),
+			Status:        models.ScalingStatus(status),
+			OldInstances:  oldInstances,
+			NewInstances:  newInstances,
+			Reason:        reason,
+			Message:       message,
+			Error:         errorMsg,
+			Simulated:     simulated,
+			Rule:          rule,
+			CorrelationId: correlationId,
+		}
+		if metricValue.Valid {
+			value := metricValue.Float64
+			history.MetricValue = &value
 		}
 		histories = append(histories, &history)
 	}
 	return histories, rows.Err()
 }
 
-func statusFilter(includeAll bool) string {
-	statusFilter := " AND status != " + strconv.Itoa(int(models.ScalingStatusIgnored))
-	if includeAll {
-		statusFilter = ""
+// historyFilter returns the conditions of the filter to be appended to the WHERE clause of a scaling history query
+// and their arguments.
+func historyFilter(filter db.ScalingHistoryFilter) (string, []interface{}) {
+	where := ""
+	args := []interface{}{}
+
+	if len(filter.Statuses) > 0 {
+		where += " AND status IN (" + placeholders(len(filter.Statuses)) + ")"
+		for _, status := range filter.Statuses {
+			args = append(args, int(status))
+		}
+	} else if !filter.IncludeAll {
+		where += " AND status != " + strconv.Itoa(int(models.ScalingStatusIgnored))
 	}
-	return statusFilter
+
+	if len(filter.ScalingTypes) > 0 {
+		where += " AND scalingtype IN (" + placeholders(len(filter.ScalingTypes)) + ")"
+		for _, scalingType := range filter.ScalingTypes {
+			args = append(args, int(scalingType))
+		}
+	}
+
+	if filter.Reason != "" {
+		where += " AND LOWER(reason) LIKE ?"
+		args = append(args, "%"+likeEscaper.Replace(strings.ToLower(filter.Reason))+"%")
+	}
+
+	if filter.Simulated != nil {
+		where += " AND simulated = ?"
+		args = append(args, *filter.Simulated)
+	}
+
+	return where, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
 }
 
 func orderTypeToString(orderType db.OrderType) string {
@@ -170,11 +232,13 @@ func (sdb *ScalingEngineSQLDB) PruneScalingHistories(ctx context.Context, before
 	return err
 }
 
-func (sdb *ScalingEngineSQLDB) CanScaleApp(appId string) (bool, int64, error) {
-	query := sdb.sqldb.Rebind("SELECT expireat FROM scalingcooldown WHERE appid = ?")
-	rows, err := sdb.sqldb.Query(query, appId)
+// CanScaleApp checks the cooldown of the given scaling direction. Cooldowns stored before scaling directions were
+// introduced have no direction and apply to both directions.
+func (sdb *ScalingEngineSQLDB) CanScaleApp(appId string, processType string, direction string) (bool, int64, error) {
+	query := sdb.sqldb.Rebind("SELECT expireat FROM scalingcooldown WHERE appid = ? AND processtype = ? AND direction IN (?, '') ORDER BY expireat DESC")
+	rows, err := sdb.sqldb.Query(query, appId, processTypeOrDefault(processType), direction)
 	if err != nil {
-		sdb.logger.Error("can-scale-app-query-record", err, lager.Data{"query": query, "appid": appId})
+		sdb.logger.Error("can-scale-app-query-record", err, lager.Data{"query": query, "appid": appId, "processtype": processType, "direction": direction})
 		return false, 0, err
 	}
 	defer func() { _ = rows.Close() }()
@@ -182,7 +246,7 @@ func (sdb *ScalingEngineSQLDB) CanScaleApp(appId string) (bool, int64, error) {
 	var expireAt int64 = 0
 	if rows.Next() {
 		if err = rows.Scan(&expireAt); err != nil {
-			sdb.logger.Error("can-scale-app-scan", err, lager.Data{"query": query, "appid": appId})
+			sdb.logger.Error("can-scale-app-scan", err, lager.Data{"query": query, "appid": appId, "processtype": processType})
 			return false, expireAt, err
 		}
 		if expireAt < time.Now().UnixNano() {
@@ -194,21 +258,265 @@ func (sdb *ScalingEngineSQLDB) CanScaleApp(appId string) (bool, int64, error) {
 	return true, expireAt, rows.Err()
 }
 
-func (sdb *ScalingEngineSQLDB) UpdateScalingCooldownExpireTime(appId string, expireAt int64) error {
-	_, err := sdb.sqldb.Exec(sdb.sqldb.Rebind("DELETE FROM scalingcooldown WHERE appid = ?"), appId)
+func (sdb *ScalingEngineSQLDB) UpdateScalingCooldownExpireTime(appId string, processType string, direction string, expireAt int64) error {
+	processType = processTypeOrDefault(processType)
+	_, err := sdb.sqldb.Exec(sdb.sqldb.Rebind("DELETE FROM scalingcooldown WHERE appid = ? AND processtype = ? AND direction = ?"), appId, processType, direction)
 	if err != nil {
-		sdb.logger.Error("update-scaling-cooldown-time-delete", err, lager.Data{"appid": appId})
+		sdb.logger.Error("update-scaling-cooldown-time-delete", err, lager.Data{"appid": appId, "processtype": processType, "direction": direction})
 		return err
 	}
 
-	_, err = sdb.sqldb.Exec(sdb.sqldb.Rebind("INSERT INTO scalingcooldown(appid, expireat) values(?, ?)"), appId, expireAt)
+	_, err = sdb.sqldb.Exec(sdb.sqldb.Rebind("INSERT INTO scalingcooldown(appid, processtype, direction, expireat) values(?, ?, ?, ?)"), appId, processType, direction, expireAt)
 	if err != nil {
-		sdb.logger.Error("update-scaling-cooldown-time-insert", err, lager.Data{"appid": appId, "expireAt": expireAt})
+		sdb.logger.Error("update-scaling-cooldown-time-insert", err, lager.Data{"appid": appId, "processtype": processType, "direction": direction, "expireAt": expireAt})
 		return err
 	}
 	return nil
 }
 
+// ExtendScalingCooldownExpireTime moves the cooldown of a direction to newExpireAt if it still expires at expireAt, so
+// that it does not overwrite the cooldown of a later scaling.
+func (sdb *ScalingEngineSQLDB) ExtendScalingCooldownExpireTime(appId string, processType string, direction string, expireAt int64, newExpireAt int64) error {
+	processType = processTypeOrDefault(processType)
+	query := sdb.sqldb.Rebind("UPDATE scalingcooldown SET expireat = ? WHERE appid = ? AND processtype = ? AND direction = ? AND expireat = ?")
+	_, err := sdb.sqldb.Exec(query, newExpireAt, appId, processType, direction, expireAt)
+	if err != nil {
+		sdb.logger.Error("extend-scaling-cooldown-time", err, lager.Data{"query": query, "appid": appId, "processtype": processType, "direction": direction, "expireAt": expireAt})
+	}
+	return err
+}
+
+func (sdb *ScalingEngineSQLDB) RetrieveScalingCooldowns(appId string) ([]*models.ScalingCooldown, error) {
+	query := sdb.sqldb.Rebind("SELECT processtype, direction, expireat FROM scalingcooldown WHERE appid = ? ORDER BY processtype, direction")
+	rows, err := sdb.sqldb.Query(query, appId)
+	if err != nil {
+		sdb.logger.Error("retrieve-scaling-cooldowns-query", err, lager.Data{"query": query, "appid": appId})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	cooldowns := []*models.ScalingCooldown{}
+	for rows.Next() {
+		cooldown := &models.ScalingCooldown{}
+		if err = rows.Scan(&cooldown.ProcessType, &cooldown.Direction, &cooldown.ExpireAt); err != nil {
+			sdb.logger.Error("retrieve-scaling-cooldowns-scan", err, lager.Data{"query": query, "appid": appId})
+			return nil, err
+		}
+		cooldowns = append(cooldowns, cooldown)
+	}
+	return cooldowns, rows.Err()
+}
+
+func (sdb *ScalingEngineSQLDB) SaveScalingRecommendation(recommendation *models.ScalingRecommendation) error {
+	query := sdb.sqldb.Rebind("INSERT INTO scalingrecommendation(appid, processtype, timestamp, instances) values(?, ?, ?, ?)")
+	_, err := sdb.sqldb.Exec(query, recommendation.AppId, processTypeOrDefault(recommendation.ProcessType), recommendation.Timestamp, recommendation.Instances)
+	if err != nil {
+		sdb.logger.Error("save-scaling-recommendation", err, lager.Data{"query": query, "recommendation": recommendation})
+	}
+	return err
+}
+
+func (sdb *ScalingEngineSQLDB) RetrieveScalingRecommendations(appId string, since int64) ([]*models.ScalingRecommendation, error) {
+	query := sdb.sqldb.Rebind("SELECT processtype, timestamp, instances FROM scalingrecommendation WHERE appid = ? AND timestamp >= ? ORDER BY timestamp")
+	rows, err := sdb.sqldb.Query(query, appId, since)
+	if err != nil {
+		sdb.logger.Error("retrieve-scaling-recommendations-query", err, lager.Data{"query": query, "appid": appId, "since": since})
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	recommendations := []*models.ScalingRecommendation{}
+	for rows.Next() {
+		recommendation := &models.ScalingRecommendation{AppId: appId}
+		if err = rows.Scan(&recommendation.ProcessType, &recommendation.Timestamp, &recommendation.Instances); err != nil {
+			sdb.logger.Error("retrieve-scaling-recommendations-scan", err, lager.Data{"query": query, "appid": appId, "since": since})
+			return nil, err
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, rows.Err()
+}
+
+func (sdb *ScalingEngineSQLDB) PruneScalingRecommendations(ctx context.Context, before int64) error {
+	query := sdb.sqldb.Rebind("DELETE FROM scalingrecommendation WHERE timestamp < ?")
+	_, err := sdb.sqldb.ExecContext(ctx, query, before)
+	if err != nil {
+		sdb.logger.Error("failed-prune-scaling-recommendations", err, lager.Data{"query": query, "before": before})
+	}
+	return err
+}
+
+func (sdb *ScalingEngineSQLDB) SaveWebhookDeliveries(deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFunc()
+	txn, err := sdb.sqldb.BeginTxx(ctx, nil)
+	if err != nil {
+		sdb.logger.Error("save-webhook-deliveries-begin-transaction", err)
+		return err
+	}
+	defer func() { _ = txn.Rollback() }()
+
+	query := txn.Rebind("INSERT INTO webhook_delivery(guid, subscription_guid, appid, payload, status, attempts, next_attempt_at, last_error, created_at, updated_at) " +
+		"VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	for _, delivery := range deliveries {
+		_, err = txn.ExecContext(ctx, query, delivery.Guid, delivery.SubscriptionGuid, delivery.AppId, delivery.Payload, delivery.Status,
+			delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.CreatedAt, delivery.UpdatedAt)
+		if err != nil {
+			sdb.logger.Error("save-webhook-delivery", err, lager.Data{"query": query, "guid": delivery.Guid, "appid": delivery.AppId})
+			return err
+		}
+	}
+
+	err = txn.Commit()
+	if err != nil {
+		sdb.logger.Error("save-webhook-deliveries-commit", err)
+	}
+	return err
+}
+
+// RetrieveDueWebhookDeliveries returns the pending webhook deliveries whose next attempt is due, the longest waiting
+// first.
+func (sdb *ScalingEngineSQLDB) RetrieveDueWebhookDeliveries(now int64, limit int) ([]*models.WebhookDelivery, error) {
+	query := sdb.sqldb.Rebind("SELECT " + webhookDeliveryColumns + " FROM webhook_delivery WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?")
+	rows, err := sdb.sqldb.Query(query, models.WebhookDeliveryStatusPending, now, limit)
+	if err != nil {
+		sdb.logger.Error("retrieve-due-webhook-deliveries", err, lager.Data{"query": query, "now": now})
+		return nil, err
+	}
+	return sdb.scanWebhookDeliveries(rows)
+}
+
+// ClaimWebhookDelivery moves the next attempt of a pending delivery to leaseUntil, unless another scaling engine
+// instance claimed it since it was retrieved. It reports whether the delivery was claimed.
+func (sdb *ScalingEngineSQLDB) ClaimWebhookDelivery(delivery *models.WebhookDelivery, leaseUntil int64) (bool, error) {
+	query := sdb.sqldb.Rebind("UPDATE webhook_delivery SET next_attempt_at = ? WHERE guid = ? AND status = ? AND next_attempt_at = ?")
+	result, err := sdb.sqldb.Exec(query, leaseUntil, delivery.Guid, models.WebhookDeliveryStatusPending, delivery.NextAttemptAt)
+	if err != nil {
+		sdb.logger.Error("claim-webhook-delivery", err, lager.Data{"query": query, "guid": delivery.Guid})
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		sdb.logger.Error("claim-webhook-delivery-rows-affected", err, lager.Data{"guid": delivery.Guid})
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+func (sdb *ScalingEngineSQLDB) UpdateWebhookDelivery(delivery *models.WebhookDelivery) error {
+	query := sdb.sqldb.Rebind("UPDATE webhook_delivery SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ? WHERE guid = ?")
+	_, err := sdb.sqldb.Exec(query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.UpdatedAt, delivery.Guid)
+	if err != nil {
+		sdb.logger.Error("update-webhook-delivery", err, lager.Data{"query": query, "guid": delivery.Guid})
+	}
+	return err
+}
+
+// RetrieveWebhookDeliveries returns the latest webhook deliveries of an app, newest first. An empty status returns
+// the deliveries in any status.
+func (sdb *ScalingEngineSQLDB) RetrieveWebhookDeliveries(ctx context.Context, appId string, status string, limit int) ([]*models.WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_delivery WHERE appid = ?"
+	args := []interface{}{appId}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query = sdb.sqldb.Rebind(query + " ORDER BY created_at DESC LIMIT ?")
+	rows, err := sdb.sqldb.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		sdb.logger.Error("retrieve-webhook-deliveries", err, lager.Data{"query": query, "appid": appId, "status": status})
+		return nil, err
+	}
+	return sdb.scanWebhookDeliveries(rows)
+}
+
+// PruneWebhookDeliveries deletes the delivered and failed webhook deliveries last updated before the given time.
+func (sdb *ScalingEngineSQLDB) PruneWebhookDeliveries(ctx context.Context, before int64) error {
+	query := sdb.sqldb.Rebind("DELETE FROM webhook_delivery WHERE status <> ? AND updated_at < ?")
+	_, err := sdb.sqldb.ExecContext(ctx, query, models.WebhookDeliveryStatusPending, before)
+	if err != nil {
+		sdb.logger.Error("failed-prune-webhook-deliveries", err, lager.Data{"query": query, "before": before})
+	}
+	return err
+}
+
+const webhookDeliveryColumns = "guid, subscription_guid, appid, payload, status, attempts, next_attempt_at, last_error, created_at, updated_at"
+
+func (sdb *ScalingEngineSQLDB) scanWebhookDeliveries(rows *sql.Rows) ([]*models.WebhookDelivery, error) {
+	defer func() { _ = rows.Close() }()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{}
+		if err := rows.Scan(&delivery.Guid, &delivery.SubscriptionGuid, &delivery.AppId, &delivery.Payload, &delivery.Status,
+			&delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError, &delivery.CreatedAt, &delivery.UpdatedAt); err != nil {
+			sdb.logger.Error("scan-webhook-deliveries", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// AcquireAppLease takes or extends the scaling lease of an app for the owner until expireAt. It reports false if another
+// owner holds a lease which has not expired at now.
+func (sdb *ScalingEngineSQLDB) AcquireAppLease(appId string, owner string, now int64, expireAt int64) (bool, error) {
+	query := sdb.sqldb.Rebind("UPDATE scaling_lease SET owner = ?, expire_at = ? WHERE appid = ? AND (owner = ? OR expire_at <= ?)")
+	result, err := sdb.sqldb.Exec(query, owner, expireAt, appId, owner, now)
+	if err != nil {
+		sdb.logger.Error("acquire-app-lease-update", err, lager.Data{"query": query, "appid": appId, "owner": owner})
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		sdb.logger.Error("acquire-app-lease-update-rows-affected", err, lager.Data{"appid": appId, "owner": owner})
+		return false, err
+	}
+	if updated == 1 {
+		return true, nil
+	}
+
+	queryPrefix := "INSERT INTO scaling_lease(appid, owner, expire_at) VALUES (?, ?, ?) "
+	switch sdb.sqldb.DriverName() {
+	case "pgx":
+		query = sdb.sqldb.Rebind(queryPrefix + "ON CONFLICT DO NOTHING")
+	case "mysql":
+		query = sdb.sqldb.Rebind(queryPrefix + "ON DUPLICATE KEY UPDATE appid = appid")
+	}
+	result, err = sdb.sqldb.Exec(query, appId, owner, expireAt)
+	if err != nil {
+		sdb.logger.Error("acquire-app-lease-insert", err, lager.Data{"query": query, "appid": appId, "owner": owner})
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		sdb.logger.Error("acquire-app-lease-insert-rows-affected", err, lager.Data{"appid": appId, "owner": owner})
+		return false, err
+	}
+	return inserted == 1, nil
+}
+
+// ReleaseAppLease gives up the scaling lease of an app if the owner holds it.
+func (sdb *ScalingEngineSQLDB) ReleaseAppLease(appId string, owner string) error {
+	query := sdb.sqldb.Rebind("DELETE FROM scaling_lease WHERE appid = ? AND owner = ?")
+	_, err := sdb.sqldb.Exec(query, appId, owner)
+	if err != nil {
+		sdb.logger.Error("release-app-lease", err, lager.Data{"query": query, "appid": appId, "owner": owner})
+	}
+	return err
+}
+
+func processTypeOrDefault(processType string) string {
+	if processType == "" {
+		return models.DefaultProcessType
+	}
+	return processType
+}
+
 func (sdb *ScalingEngineSQLDB) GetActiveSchedule(appId string) (*models.ActiveSchedule, error) {
 	query := sdb.sqldb.Rebind("SELECT scheduleid, instancemincount, instancemaxcount, initialmininstancecount" +
 		" FROM activeschedule WHERE appid = ?")
@@ -41,7 +41,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		activeSchedule    *models.ActiveSchedule
 		schedules         map[string]string
 		before            int64
-		includeAll        bool
+		filter            db.ScalingHistoryFilter
 	)
 
 	dbUrl := GetDbUrl()
@@ -185,12 +185,47 @@ var _ = Describe("ScalingEngineSqldb", func() {
 
 	})
 
+	Describe("UpdateScalingHistoryOutcome", func() {
+		BeforeEach(func() {
+			history = &models.AppScalingHistory{
+				AppId:        appId,
+				Timestamp:    111111,
+				ScalingType:  models.ScalingTypeDynamic,
+				Status:       models.ScalingStatusSucceeded,
+				OldInstances: 2,
+				NewInstances: 4,
+				Reason:       "a reason",
+			}
+			Expect(sdb.SaveScalingHistory(history)).To(Succeed())
+			history.Timestamp = 222222
+			Expect(sdb.SaveScalingHistory(history)).To(Succeed())
+
+			history.Timestamp = 111111
+			history.Status = models.ScalingStatusFailed
+			history.Message = "limited by max instances 4"
+			history.Error = "scaled but 1 instances crashed"
+			err = sdb.UpdateScalingHistoryOutcome(history)
+		})
+
+		It("updates the outcome of the scaling history record", func() {
+			Expect(err).NotTo(HaveOccurred())
+			histories, err = sdb.RetrieveScalingHistories(context.Background(), appId, 0, -1, db.ASC, db.ScalingHistoryFilter{}, 1, 50)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(histories).To(HaveLen(2))
+			Expect(histories[0].Status).To(Equal(models.ScalingStatusFailed))
+			Expect(histories[0].Message).To(Equal("limited by max instances 4"))
+			Expect(histories[0].Error).To(Equal("scaled but 1 instances crashed"))
+			Expect(histories[1].Status).To(Equal(models.ScalingStatusSucceeded))
+			Expect(histories[1].Message).To(BeEmpty())
+		})
+	})
+
 	Describe("RetrieveScalingHistories", func() {
 		BeforeEach(func() {
 			start = 0
 			end = -1
 			orderType = db.DESC
-			includeAll = true
+			filter = db.ScalingHistoryFilter{IncludeAll: true}
 
 			history = &models.AppScalingHistory{
 				AppId:        appId,
@@ -230,18 +265,136 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		})
 
 		JustBeforeEach(func() {
-			histories, err = sdb.RetrieveScalingHistories(context.TODO(), appId, start, end, orderType, includeAll, 1, 50)
+			histories, err = sdb.RetrieveScalingHistories(context.TODO(), appId, start, end, orderType, filter, 1, 50)
 		})
 
 		Context("When the app has no history", func() {
 			It("returns empty metrics", func() {
-				histories, err = sdb.RetrieveScalingHistories(context.TODO(), "app-id-no-history", start, end, orderType, includeAll, 1, 50)
+				histories, err = sdb.RetrieveScalingHistories(context.TODO(), "app-id-no-history", start, end, orderType, filter, 1, 50)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(histories).To(BeEmpty())
 			})
 
 		})
 
+		Context("when a history is simulated", func() {
+			BeforeEach(func() {
+				history.Timestamp = 777777
+				history.ScalingType = models.ScalingTypeDynamic
+				history.Status = models.ScalingStatusSucceeded
+				history.Error = ""
+				history.Simulated = true
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+
+				start = 777777
+			})
+
+			It("returns the history flagged as simulated", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(1))
+				Expect(histories[0].Simulated).To(BeTrue())
+			})
+
+			Context("when filtering out simulated histories", func() {
+				BeforeEach(func() {
+					simulated := false
+					filter = db.ScalingHistoryFilter{Simulated: &simulated}
+				})
+
+				It("does not return or count it", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(histories).To(BeEmpty())
+					Expect(sdb.CountScalingHistories(context.TODO(), appId, start, end, filter)).To(BeZero())
+				})
+			})
+		})
+
+		Context("when a history has a rule, metric value and correlation id", func() {
+			BeforeEach(func() {
+				metricValue := 87.5
+				history.Timestamp = 777777
+				history.ScalingType = models.ScalingTypeDynamic
+				history.Status = models.ScalingStatusSucceeded
+				history.Error = ""
+				history.Rule = "cpu > 80"
+				history.MetricValue = &metricValue
+				history.CorrelationId = "a-correlation-id"
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+
+				start = 777777
+			})
+
+			It("returns them", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(1))
+				Expect(histories[0].Rule).To(Equal("cpu > 80"))
+				Expect(histories[0].MetricValue).To(HaveValue(Equal(87.5)))
+				Expect(histories[0].CorrelationId).To(Equal("a-correlation-id"))
+			})
+		})
+
+		Context("when filtering by status", func() {
+			BeforeEach(func() {
+				filter = db.ScalingHistoryFilter{Statuses: []models.ScalingStatus{models.ScalingStatusIgnored, models.ScalingStatusSucceeded}}
+			})
+
+			It("returns the histories with the statuses only", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(2))
+				Expect(histories[0].Timestamp).To(Equal(int64(666666)))
+				Expect(histories[1].Timestamp).To(Equal(int64(333333)))
+			})
+
+			It("counts the histories with the statuses only", func() {
+				Expect(sdb.CountScalingHistories(context.TODO(), appId, start, end, filter)).To(Equal(2))
+			})
+		})
+
+		Context("when filtering by scaling type", func() {
+			BeforeEach(func() {
+				filter = db.ScalingHistoryFilter{ScalingTypes: []models.ScalingType{models.ScalingTypeSchedule}}
+			})
+
+			It("returns the histories of the scaling types only, without ignored ones", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(1))
+				Expect(histories[0].Timestamp).To(Equal(int64(555555)))
+			})
+		})
+
+		Context("when filtering by reason", func() {
+			BeforeEach(func() {
+				history.Timestamp = 444444
+				history.ScalingType = models.ScalingTypeDynamic
+				history.Status = models.ScalingStatusSucceeded
+				history.Error = ""
+				history.Reason = "+1 instance(s) because CPU > 80% for 120 seconds"
+				err = sdb.SaveScalingHistory(history)
+				FailOnError("Failed to add scaling history", err)
+
+				filter = db.ScalingHistoryFilter{IncludeAll: true, Reason: "cpu > 80%"}
+			})
+
+			It("returns the histories whose reason contains the text ignoring the case", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(histories).To(HaveLen(1))
+				Expect(histories[0].Timestamp).To(Equal(int64(444444)))
+			})
+
+			Context("when the text contains wildcards", func() {
+				BeforeEach(func() {
+					filter.Reason = "_ reason"
+				})
+
+				It("matches them literally", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(histories).To(BeEmpty())
+				})
+			})
+		})
+
 		Context("when end time is now (end = -1)", func() {
 			BeforeEach(func() {
 				start = 333333
@@ -302,6 +455,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 				Expect(histories).To(Equal([]*models.AppScalingHistory{
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    222222,
 						ScalingType:  models.ScalingTypeDynamic,
 						Status:       models.ScalingStatusFailed,
@@ -313,6 +467,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    333333,
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusIgnored,
@@ -323,6 +478,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    555555,
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusFailed,
@@ -334,6 +490,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    666666,
 						ScalingType:  models.ScalingTypeDynamic,
 						Status:       models.ScalingStatusSucceeded,
@@ -354,6 +511,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 				Expect(histories).To(Equal([]*models.AppScalingHistory{
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    666666,
 						ScalingType:  models.ScalingTypeDynamic,
 						Status:       models.ScalingStatusSucceeded,
@@ -364,6 +522,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    555555,
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusFailed,
@@ -375,6 +534,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    333333,
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusIgnored,
@@ -385,6 +545,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    222222,
 						ScalingType:  models.ScalingTypeDynamic,
 						Status:       models.ScalingStatusFailed,
@@ -411,6 +572,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 				Expect(histories).To(Equal([]*models.AppScalingHistory{
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    555555,
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusFailed,
@@ -421,6 +583,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 						Error:        "an error",
 					}, {
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    333333,
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusIgnored,
@@ -436,7 +599,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 
 		Context("when only retrieving succeeded and failed history", func() {
 			BeforeEach(func() {
-				includeAll = false
+				filter = db.ScalingHistoryFilter{}
 			})
 
 			It("skips ignored scaling history", func() {
@@ -444,6 +607,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 				Expect(histories).To(Equal([]*models.AppScalingHistory{
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    666666,
 						ScalingType:  models.ScalingTypeDynamic,
 						Status:       models.ScalingStatusSucceeded,
@@ -454,6 +618,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    555555,
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusFailed,
@@ -465,6 +630,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 					},
 					{
 						AppId:        appId,
+						ProcessType:  "web",
 						Timestamp:    222222,
 						ScalingType:  models.ScalingTypeDynamic,
 						Status:       models.ScalingStatusFailed,
@@ -548,7 +714,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 	Describe("UpdateScalingCooldownExpireTime", func() {
 
 		JustBeforeEach(func() {
-			err = sdb.UpdateScalingCooldownExpireTime(appId, 222222)
+			err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, 222222)
 		})
 
 		Context("when there is no previous app cooldown record", func() {
@@ -560,7 +726,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 
 		Context("when there is previous app cooldown record", func() {
 			BeforeEach(func() {
-				err = sdb.UpdateScalingCooldownExpireTime(appId, 111111)
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, 111111)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -570,11 +736,63 @@ var _ = Describe("ScalingEngineSqldb", func() {
 				Expect(hasScalingCooldownRecord(appId, 222222)).To(BeTrue())
 			})
 		})
+
+		Context("when there is previous app cooldown record of the other direction", func() {
+			BeforeEach(func() {
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionIn, 111111)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("keeps the record of the other direction", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasScalingCooldownRecord(appId, 111111)).To(BeTrue())
+				Expect(hasScalingCooldownRecord(appId, 222222)).To(BeTrue())
+			})
+		})
+
+		Context("when the other direction has a record with the same expire time", func() {
+			BeforeEach(func() {
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionIn, 222222)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("creates the record", func() {
+				Expect(err).NotTo(HaveOccurred())
+				cooldowns, err := sdb.RetrieveScalingCooldowns(appId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cooldowns).To(HaveLen(2))
+			})
+		})
+	})
+
+	Describe("ExtendScalingCooldownExpireTime", func() {
+		BeforeEach(func() {
+			err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, 111111)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the cooldown still expires at the given time", func() {
+			It("moves the expire time", func() {
+				err = sdb.ExtendScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, 111111, 222222)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasScalingCooldownRecord(appId, 111111)).To(BeFalse())
+				Expect(hasScalingCooldownRecord(appId, 222222)).To(BeTrue())
+			})
+		})
+
+		Context("when a later scaling restarted the cooldown", func() {
+			It("keeps the expire time of the later scaling", func() {
+				err = sdb.ExtendScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, 100000, 222222)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hasScalingCooldownRecord(appId, 111111)).To(BeTrue())
+				Expect(hasScalingCooldownRecord(appId, 222222)).To(BeFalse())
+			})
+		})
 	})
 
 	Describe("CanScaleApp", func() {
 		JustBeforeEach(func() {
-			canScale, cooldownExpiredAt, err = sdb.CanScaleApp(appId)
+			canScale, cooldownExpiredAt, err = sdb.CanScaleApp(appId, "web", models.ScalingDirectionOut)
 		})
 
 		Context("when there is no cooldown record before", func() {
@@ -588,7 +806,7 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		Context("when the app is still in cooldown period", func() {
 			fakeCoolDownExpiredTime := time.Now().Add(100 * time.Second).UnixNano()
 			BeforeEach(func() {
-				err = sdb.UpdateScalingCooldownExpireTime(appId, fakeCoolDownExpiredTime)
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, fakeCoolDownExpiredTime)
 				Expect(err).NotTo(HaveOccurred())
 			})
 			It("returns false", func() {
@@ -598,10 +816,47 @@ var _ = Describe("ScalingEngineSqldb", func() {
 			})
 		})
 
+		Context("when the app is still in cooldown period of the other direction", func() {
+			BeforeEach(func() {
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionIn, time.Now().Add(100*time.Second).UnixNano())
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns true", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(canScale).To(BeTrue())
+				Expect(cooldownExpiredAt).To(Equal(int64(0)))
+			})
+		})
+
+		Context("when the app is still in a cooldown period stored without direction", func() {
+			fakeCoolDownExpiredTime := time.Now().Add(100 * time.Second).UnixNano()
+			BeforeEach(func() {
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "web", "", fakeCoolDownExpiredTime)
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns false", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(canScale).To(BeFalse())
+				Expect(cooldownExpiredAt).To(Equal(fakeCoolDownExpiredTime))
+			})
+		})
+
+		Context("when another process type of the app is still in cooldown period", func() {
+			BeforeEach(func() {
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "worker", models.ScalingDirectionOut, time.Now().Add(100*time.Second).UnixNano())
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("returns true", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(canScale).To(BeTrue())
+				Expect(cooldownExpiredAt).To(Equal(int64(0)))
+			})
+		})
+
 		Context("when the app passes cooldown period", func() {
 			fakeCoolDownExpiredTime := time.Now().Add(0 - 100*time.Second).UnixNano()
 			BeforeEach(func() {
-				err = sdb.UpdateScalingCooldownExpireTime(appId, fakeCoolDownExpiredTime)
+				err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, fakeCoolDownExpiredTime)
 				Expect(err).NotTo(HaveOccurred())
 			})
 			It("returns true", func() {
@@ -612,6 +867,174 @@ var _ = Describe("ScalingEngineSqldb", func() {
 		})
 	})
 
+	Describe("RetrieveScalingCooldowns", func() {
+		var cooldowns []*models.ScalingCooldown
+
+		BeforeEach(func() {
+			err = sdb.UpdateScalingCooldownExpireTime(appId, "worker", models.ScalingDirectionIn, 333333)
+			Expect(err).NotTo(HaveOccurred())
+			err = sdb.UpdateScalingCooldownExpireTime(appId, "web", models.ScalingDirectionOut, 111111)
+			Expect(err).NotTo(HaveOccurred())
+			err = sdb.UpdateScalingCooldownExpireTime(appId2, "web", models.ScalingDirectionOut, 222222)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			cooldowns, err = sdb.RetrieveScalingCooldowns(appId)
+		})
+
+		It("retrieves the cooldowns of the app", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cooldowns).To(Equal([]*models.ScalingCooldown{
+				{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: 111111},
+				{ProcessType: "worker", Direction: models.ScalingDirectionIn, ExpireAt: 333333},
+			}))
+		})
+
+		Context("when db fails", func() {
+			BeforeEach(func() {
+				_ = sdb.Close()
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError(MatchRegexp("sql: .*")))
+			})
+		})
+	})
+
+	Describe("SaveScalingRecommendation and RetrieveScalingRecommendations", func() {
+		var recommendations []*models.ScalingRecommendation
+
+		BeforeEach(func() {
+			for _, recommendation := range []*models.ScalingRecommendation{
+				{AppId: appId, ProcessType: "web", Timestamp: 333333, Instances: 3},
+				{AppId: appId, ProcessType: "web", Timestamp: 111111, Instances: 1},
+				{AppId: appId, ProcessType: "worker", Timestamp: 222222, Instances: 2},
+				{AppId: appId2, ProcessType: "web", Timestamp: 222222, Instances: 4},
+			} {
+				err = sdb.SaveScalingRecommendation(recommendation)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		JustBeforeEach(func() {
+			recommendations, err = sdb.RetrieveScalingRecommendations(appId, 222222)
+		})
+
+		It("retrieves the recommendations of the app since the given time ordered by timestamp", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recommendations).To(Equal([]*models.ScalingRecommendation{
+				{AppId: appId, ProcessType: "worker", Timestamp: 222222, Instances: 2},
+				{AppId: appId, ProcessType: "web", Timestamp: 333333, Instances: 3},
+			}))
+		})
+
+		Context("when db fails", func() {
+			BeforeEach(func() {
+				_ = sdb.Close()
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError(MatchRegexp("sql: .*")))
+			})
+		})
+	})
+
+	Describe("PruneScalingRecommendations", Serial, func() {
+		BeforeEach(func() {
+			for _, timestamp := range []int64{111111, 222222, 333333} {
+				err = sdb.SaveScalingRecommendation(&models.ScalingRecommendation{AppId: appId, ProcessType: "web", Timestamp: timestamp, Instances: 2})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = sdb.PruneScalingRecommendations(context.TODO(), 222222)
+		})
+
+		It("removes the recommendations before the time specified", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getScalingRecommendationsForApp(appId)).To(Equal(2))
+		})
+
+		Context("when db fails", func() {
+			BeforeEach(func() {
+				_ = sdb.Close()
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError(MatchRegexp("sql: .*")))
+			})
+		})
+	})
+
+	Describe("WebhookDeliveries", func() {
+		var deliveries []*models.WebhookDelivery
+
+		newDelivery := func(appId string, guid string, status string, nextAttemptAt int64, updatedAt int64) *models.WebhookDelivery {
+			return &models.WebhookDelivery{Guid: addProcessIdTo(guid), SubscriptionGuid: "a-subscription", AppId: appId, Payload: `{"event":"scaling"}`,
+				Status: status, NextAttemptAt: nextAttemptAt, CreatedAt: nextAttemptAt, UpdatedAt: updatedAt}
+		}
+
+		BeforeEach(func() {
+			deliveries = []*models.WebhookDelivery{
+				newDelivery(appId, "due", models.WebhookDeliveryStatusPending, 200, 200),
+				newDelivery(appId, "later", models.WebhookDeliveryStatusPending, 400, 400),
+				newDelivery(appId, "delivered", models.WebhookDeliveryStatusDelivered, 100, 100),
+				newDelivery(appId2, "failed", models.WebhookDeliveryStatusFailed, 300, 300),
+			}
+			err = sdb.SaveWebhookDeliveries(deliveries)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("retrieves the pending deliveries which are due", func() {
+			due, err := sdb.RetrieveDueWebhookDeliveries(300, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(due).To(ContainElement(deliveries[0]))
+			Expect(due).NotTo(ContainElements(deliveries[1], deliveries[2], deliveries[3]))
+		})
+
+		It("claims a delivery only once", func() {
+			Expect(sdb.ClaimWebhookDelivery(deliveries[0], 500)).To(BeTrue())
+			Expect(sdb.ClaimWebhookDelivery(deliveries[0], 500)).To(BeFalse())
+			Expect(sdb.RetrieveDueWebhookDeliveries(300, 10)).NotTo(ContainElement(HaveField("Guid", deliveries[0].Guid)))
+		})
+
+		It("updates a delivery", func() {
+			deliveries[0].Status = models.WebhookDeliveryStatusFailed
+			deliveries[0].Attempts = 3
+			deliveries[0].LastError = "status code 500"
+			deliveries[0].UpdatedAt = 600
+			err = sdb.UpdateWebhookDelivery(deliveries[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sdb.RetrieveWebhookDeliveries(context.Background(), appId, models.WebhookDeliveryStatusFailed, 10)).To(Equal([]*models.WebhookDelivery{deliveries[0]}))
+		})
+
+		It("retrieves the deliveries of an app newest first", func() {
+			Expect(sdb.RetrieveWebhookDeliveries(context.Background(), appId, "", 2)).To(Equal([]*models.WebhookDelivery{deliveries[1], deliveries[0]}))
+		})
+
+		It("prunes the finished deliveries updated before the time specified", func() {
+			err = sdb.PruneWebhookDeliveries(context.Background(), 350)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sdb.RetrieveWebhookDeliveries(context.Background(), appId, "", 10)).To(Equal([]*models.WebhookDelivery{deliveries[1], deliveries[0]}))
+			Expect(sdb.RetrieveWebhookDeliveries(context.Background(), appId2, "", 10)).To(BeEmpty())
+		})
+	})
+
+	Describe("AcquireAppLease and ReleaseAppLease", func() {
+		It("grants the lease to one owner until it expires or is released", func() {
+			Expect(sdb.AcquireAppLease(appId, "owner-a", 100, 200)).To(BeTrue())
+			Expect(sdb.AcquireAppLease(appId, "owner-b", 150, 250)).To(BeFalse())
+			Expect(sdb.AcquireAppLease(appId, "owner-a", 150, 250)).To(BeTrue())
+
+			Expect(sdb.AcquireAppLease(appId, "owner-b", 250, 350)).To(BeTrue())
+			Expect(sdb.AcquireAppLease(appId, "owner-a", 300, 400)).To(BeFalse())
+
+			Expect(sdb.ReleaseAppLease(appId, "owner-a")).To(Succeed())
+			Expect(sdb.AcquireAppLease(appId, "owner-a", 300, 400)).To(BeFalse())
+			Expect(sdb.ReleaseAppLease(appId, "owner-b")).To(Succeed())
+			Expect(sdb.AcquireAppLease(appId, "owner-a", 300, 400)).To(BeTrue())
+		})
+	})
+
 	Describe("GetActiveSchedule", func() {
 		JustBeforeEach(func() {
 			activeSchedule, err = sdb.GetActiveSchedule(appId)
@@ -776,5 +1199,8 @@ var _ = Describe("ScalingEngineSqldb", func() {
 func cleanupForApp(appId string) {
 	removeScalingHistoryForApp(appId)
 	removeCooldownForApp(appId)
+	removeScalingRecommendationsForApp(appId)
 	removeActiveScheduleForApp(appId)
+	removeWebhookDeliveriesForApp(appId)
+	removeScalingLeaseForApp(appId)
 }
@@ -141,6 +141,28 @@ func hasServiceBinding(bindingId string, serviceInstanceId string) bool {
 	return item
 }
 
+func insertServiceInstance(serviceInstanceId string) error {
+	query := dbHelper.Rebind("INSERT INTO service_instance(service_instance_id, org_id, space_id) VALUES(?, ?, ?)")
+	_, err := dbHelper.Exec(query, serviceInstanceId, "an-org-id", "a-space-id")
+	return err
+}
+
+// insertServiceBinding binds an app to a service instance, the app id is used as binding id as well.
+func insertServiceBinding(serviceInstanceId string, appId string) error {
+	query := dbHelper.Rebind("INSERT INTO binding(binding_id, service_instance_id, app_id, created_at) VALUES(?, ?, ?, ?)")
+	_, err := dbHelper.Exec(query, appId, serviceInstanceId, appId, time.Now())
+	return err
+}
+
+func deleteServiceBindings(serviceInstanceId string) {
+	query := dbHelper.Rebind("DELETE FROM binding WHERE service_instance_id = ?")
+	_, err := dbHelper.Exec(query, serviceInstanceId)
+	FailOnError("can not clean table binding", err)
+	query = dbHelper.Rebind("DELETE FROM service_instance WHERE service_instance_id = ?")
+	_, err = dbHelper.Exec(query, serviceInstanceId)
+	FailOnError("can not clean table service_instance", err)
+}
+
 func cleanPolicyTable() {
 	_, e := dbHelper.Exec("DELETE from policy_json")
 	if e != nil {
@@ -225,6 +247,25 @@ func removeCooldownForApp(appId string) {
 	_, err := dbHelper.Exec(query, appId)
 	FailOnError("can not clean table scalingcooldown: ", err)
 }
+
+func removeScalingRecommendationsForApp(appId string) {
+	query := dbHelper.Rebind("DELETE from scalingrecommendation where appId = ?")
+	_, err := dbHelper.Exec(query, appId)
+	FailOnError("can not clean table scalingrecommendation: ", err)
+}
+
+func removeWebhookDeliveriesForApp(appId string) {
+	query := dbHelper.Rebind("DELETE from webhook_delivery where appid = ?")
+	_, err := dbHelper.Exec(query, appId)
+	FailOnError("can not clean table webhook_delivery: ", err)
+}
+
+func removeScalingLeaseForApp(appId string) {
+	query := dbHelper.Rebind("DELETE from scaling_lease where appid = ?")
+	_, err := dbHelper.Exec(query, appId)
+	FailOnError("can not clean table scaling_lease: ", err)
+}
+
 func removeActiveScheduleForApp(appId string) {
 	query := dbHelper.Rebind("DELETE from activeschedule where appId = ?")
 	_, err := dbHelper.Exec(query, appId)
@@ -250,6 +291,15 @@ func getScalingHistoryForApp(appId string) int {
 	return num
 }
 
+func getScalingRecommendationsForApp(appId string) int {
+	var num int
+	query := dbHelper.Rebind("SELECT COUNT(*) FROM scalingrecommendation WHERE appid = ?")
+	row := dbHelper.QueryRow(query, appId)
+	err := row.Scan(&num)
+	FailOnError("can not count the number of records in table scalingrecommendation: ", err)
+	return num
+}
+
 func hasScalingCooldownRecord(appId string, expireAt int64) bool {
 	query := dbHelper.Rebind("SELECT * FROM scalingcooldown WHERE appid = ? AND expireat = ?")
 	rows, e := dbHelper.Query(query, appId, expireAt)
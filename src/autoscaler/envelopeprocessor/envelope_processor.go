@@ -3,6 +3,7 @@ package envelopeprocessor
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 
@@ -99,10 +100,120 @@ func GetHttpStartStopInstanceMetrics(envelopes []*loggregator_v2.Envelope, appID
 
 	metrics = append(metrics, throughputMetrics...)
 	metrics = append(metrics, responseTimeMetric...)
+	metrics = append(metrics, getResponseTimePercentileInstanceMetrics(envelopes, appID, currentTimestamp)...)
+	metrics = append(metrics, getErrorRateInstanceMetrics(envelopes, appID, numRequestsPerAppIdx, currentTimestamp)...)
 
 	return metrics
 }
 
+var responseTimePercentiles = []struct {
+	name       string
+	percentile float64
+}{
+	{models.MetricNameResponseTimeP90, 90},
+	{models.MetricNameResponseTimeP95, 95},
+	{models.MetricNameResponseTimeP99, 99},
+}
+
+func getResponseTimePercentileInstanceMetrics(envelopes []*loggregator_v2.Envelope, appID string, currentTimestamp int64) []models.AppInstanceMetric {
+	var metrics []models.AppInstanceMetric
+
+	if len(envelopes) == 0 {
+		for _, p := range responseTimePercentiles {
+			metrics = append(metrics, models.AppInstanceMetric{
+				AppId:         appID,
+				InstanceIndex: 0,
+				Name:          p.name,
+				Unit:          models.UnitMilliseconds,
+				Value:         "0",
+				CollectedAt:   currentTimestamp,
+				Timestamp:     currentTimestamp,
+			})
+		}
+		return metrics
+	}
+
+	responseTimesPerAppIdx := map[uint64][]int64{}
+	for _, envelope := range envelopes {
+		instanceIdx, _ := strconv.ParseUint(envelope.InstanceId, 10, 32)
+		responseTimesPerAppIdx[instanceIdx] = append(responseTimesPerAppIdx[instanceIdx], envelope.GetTimer().Stop-envelope.GetTimer().Start)
+	}
+
+	for instanceIndex, responseTimes := range responseTimesPerAppIdx {
+		sort.Slice(responseTimes, func(i, j int) bool { return responseTimes[i] < responseTimes[j] })
+		for _, p := range responseTimePercentiles {
+			// nearest-rank percentile, i.e. the smallest response time which is greater than or equal to p percent of
+			// the response times
+			rank := int(math.Ceil(p.percentile / 100 * float64(len(responseTimes))))
+			metrics = append(metrics, models.AppInstanceMetric{
+				AppId:         appID,
+				InstanceIndex: uint32(instanceIndex),
+				Name:          p.name,
+				Unit:          models.UnitMilliseconds,
+				Value:         fmt.Sprintf("%d", int64(math.Ceil(float64(responseTimes[rank-1])/(1000*1000)))),
+				CollectedAt:   currentTimestamp,
+				Timestamp:     currentTimestamp,
+			})
+		}
+	}
+	return metrics
+}
+
+// getErrorRateInstanceMetrics computes the percentage of requests answered with a 5xx status code, rounded to two
+// decimal places.
+func getErrorRateInstanceMetrics(envelopes []*loggregator_v2.Envelope, appID string, numRequestsPerAppIdx map[uint64]int64, currentTimestamp int64) []models.AppInstanceMetric {
+	var metrics []models.AppInstanceMetric
+
+	if len(envelopes) == 0 {
+		metrics = append(metrics, models.AppInstanceMetric{
+			AppId:         appID,
+			InstanceIndex: 0,
+			Name:          models.MetricNameErrorRate,
+			Unit:          models.UnitPercentage,
+			Value:         "0",
+			CollectedAt:   currentTimestamp,
+			Timestamp:     currentTimestamp,
+		})
+		return metrics
+	}
+
+	numServerErrorsPerAppIdx := map[uint64]int64{}
+	for _, envelope := range envelopes {
+		instanceIdx, _ := strconv.ParseUint(envelope.InstanceId, 10, 32)
+		if statusCode := getStatusCode(envelope); statusCode >= 500 && statusCode < 600 {
+			numServerErrorsPerAppIdx[instanceIdx] += 1
+		}
+	}
+
+	for _, instanceIndex := range maps.Keys(numRequestsPerAppIdx) {
+		errorRate := float64(numServerErrorsPerAppIdx[instanceIndex]) / float64(numRequestsPerAppIdx[instanceIndex]) * 100
+		metrics = append(metrics, models.AppInstanceMetric{
+			AppId:         appID,
+			InstanceIndex: uint32(instanceIndex),
+			Name:          models.MetricNameErrorRate,
+			Unit:          models.UnitPercentage,
+			Value:         strconv.FormatFloat(math.Round(errorRate*100)/100, 'f', -1, 64),
+			CollectedAt:   currentTimestamp,
+			Timestamp:     currentTimestamp,
+		})
+	}
+	return metrics
+}
+
+// getStatusCode returns the status code of the response of an http timer envelope, which is tagged by the gorouter,
+// or 0 if the envelope has none.
+func getStatusCode(envelope *loggregator_v2.Envelope) int {
+	statusCode := envelope.GetTags()["status_code"]
+	if statusCodeFromDeprecatedTags := envelope.GetDeprecatedTags()["status_code"]; statusCode == "" && statusCodeFromDeprecatedTags != nil {
+		if statusCodeFromDeprecatedTags.GetText() == "" {
+			return int(statusCodeFromDeprecatedTags.GetInteger())
+		}
+		statusCode = statusCodeFromDeprecatedTags.GetText()
+	}
+	code, _ := strconv.Atoi(statusCode)
+	return code
+}
+
 func getResponsetimeInstanceMetrics(envelopes []*loggregator_v2.Envelope, appID string, numRequestsPerAppIdx map[uint64]int64, sumReponseTimesPerAppIdx map[uint64]int64, currentTimestamp int64) []models.AppInstanceMetric {
 	var metrics []models.AppInstanceMetric
 
@@ -192,13 +303,12 @@ func calcNumReqs(envelopes []*loggregator_v2.Envelope) (numRequestsPerAppIdx map
 }
 
 func isContainerMetricEnvelope(e *loggregator_v2.Envelope) bool {
-	// TODO: Check for all container metrics not only memory quota
 	keys := maps.Keys(e.GetGauge().GetMetrics())
 
 	var matchingKeys []string
 
 	for i := range keys {
-		if keys[i] == "memory_quota" || keys[i] == "memory" {
+		if keys[i] == "memory_quota" || keys[i] == "memory" || keys[i] == "disk_quota" || keys[i] == "cpu_entitlement" {
 			matchingKeys = append(matchingKeys, keys[i])
 		}
 	}
@@ -247,6 +357,33 @@ func processContainerMetrics(e *loggregator_v2.Envelope, currentTimeStamp int64)
 		metrics = append(metrics, appInstanceMetric)
 	}
 
+	if disk, exist := g.GetMetrics()["disk"]; exist {
+		appInstanceMetric := getDiskInstanceMetric(disk.GetValue())
+		err := mergo.Merge(&appInstanceMetric, baseAppInstanceMetric)
+		if err != nil {
+			return []models.AppInstanceMetric{}, err
+		}
+		metrics = append(metrics, appInstanceMetric)
+	}
+
+	if diskQuota, exist := g.GetMetrics()["disk_quota"]; exist && diskQuota.GetValue() != 0 {
+		appInstanceMetric := getDiskQuotaInstanceMetric(g.GetMetrics()["disk"].GetValue(), diskQuota.GetValue())
+		err := mergo.Merge(&appInstanceMetric, baseAppInstanceMetric)
+		if err != nil {
+			return []models.AppInstanceMetric{}, err
+		}
+		metrics = append(metrics, appInstanceMetric)
+	}
+
+	if cpuEntitlement, exist := g.GetMetrics()["cpu_entitlement"]; exist {
+		appInstanceMetric := getCPUEntitlementInstanceMetric(cpuEntitlement.GetValue())
+		err := mergo.Merge(&appInstanceMetric, baseAppInstanceMetric)
+		if err != nil {
+			return []models.AppInstanceMetric{}, err
+		}
+		metrics = append(metrics, appInstanceMetric)
+	}
+
 	return metrics, nil
 }
 
@@ -274,6 +411,30 @@ func getCPUInstanceMetric(cpuValue float64) models.AppInstanceMetric {
 	}
 }
 
+func getDiskInstanceMetric(diskValue float64) models.AppInstanceMetric {
+	return models.AppInstanceMetric{
+		Name:  models.MetricNameDiskUsed,
+		Unit:  models.UnitMegaBytes,
+		Value: fmt.Sprintf("%d", int(math.Ceil(diskValue/(1024*1024)))),
+	}
+}
+
+func getDiskQuotaInstanceMetric(diskValue float64, diskQuotaValue float64) models.AppInstanceMetric {
+	return models.AppInstanceMetric{
+		Name:  models.MetricNameDiskUtil,
+		Unit:  models.UnitPercentage,
+		Value: fmt.Sprintf("%d", int(math.Ceil(diskValue/diskQuotaValue*100))),
+	}
+}
+
+func getCPUEntitlementInstanceMetric(cpuEntitlementValue float64) models.AppInstanceMetric {
+	return models.AppInstanceMetric{
+		Name:  models.MetricNameCPUEntitlement,
+		Unit:  models.UnitPercentage,
+		Value: fmt.Sprintf("%d", int64(math.Ceil(cpuEntitlementValue))),
+	}
+}
+
 func processCustomMetrics(e *loggregator_v2.Envelope, currentTimestamp int64) ([]models.AppInstanceMetric, error) {
 	var metrics []models.AppInstanceMetric
 	instanceIndex, _ := strconv.ParseInt(e.InstanceId, 10, 32)
@@ -285,7 +446,7 @@ func processCustomMetrics(e *loggregator_v2.Envelope, currentTimestamp int64) ([
 			CollectedAt:   currentTimestamp,
 			Name:          n,
 			Unit:          v.Unit,
-			Value:         fmt.Sprintf("%d", int64(math.Ceil(v.Value))),
+			Value:         strconv.FormatFloat(v.Value, 'f', -1, 64),
 			Timestamp:     e.Timestamp,
 		})
 	}
@@ -39,7 +39,7 @@ var _ = Describe("Envelopeprocessor", func() {
 				envelopes = append(envelopes, generateCustomMetrics("test-app-id", "0", "custom_name", "custom_unit", 11.08, 1111))
 			})
 
-			It("sends standard app instance metrics to channel", func() {
+			It("sends custom app instance metrics with their decimal values to channel", func() {
 				timestamp := time.Now().UnixNano()
 				metrics, err := envelopeprocessor.GetGaugeInstanceMetrics(envelopes, timestamp)
 				Expect(err).NotTo(HaveOccurred())
@@ -49,7 +49,7 @@ var _ = Describe("Envelopeprocessor", func() {
 					CollectedAt:   timestamp,
 					Name:          "custom_name",
 					Unit:          "custom_unit",
-					Value:         "12",
+					Value:         "11.88",
 					Timestamp:     1111,
 				}))
 
@@ -59,7 +59,7 @@ var _ = Describe("Envelopeprocessor", func() {
 					CollectedAt:   timestamp,
 					Name:          "custom_name",
 					Unit:          "custom_unit",
-					Value:         "12",
+					Value:         "11.08",
 					Timestamp:     1111,
 				}))
 
@@ -149,6 +149,65 @@ var _ = Describe("Envelopeprocessor", func() {
 				}))
 			})
 		})
+		Context("processing disk and cpu entitlement container metrics", func() {
+			BeforeEach(func() {
+				envelopes = append(envelopes, generateDiskContainerMetrics("test-app-id", "0", 128.2, 100*1024*1024, 1024*1024*1024, 1111))
+				envelopes = append(envelopes, generateDiskContainerMetrics("test-app-id", "1", 40, 300.5*1024*1024, 0, 1111))
+			})
+
+			It("sends disk and cpu entitlement app instance metrics to channel", func() {
+				timestamp := time.Now().UnixNano()
+				metrics, err := processor.GetGaugeMetrics(envelopes, timestamp)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(metrics).To(ConsistOf(
+					models.AppInstanceMetric{
+						AppId:         "test-app-id",
+						InstanceIndex: 0,
+						CollectedAt:   timestamp,
+						Name:          models.MetricNameDiskUsed,
+						Unit:          models.UnitMegaBytes,
+						Value:         "100",
+						Timestamp:     1111,
+					},
+					models.AppInstanceMetric{
+						AppId:         "test-app-id",
+						InstanceIndex: 0,
+						CollectedAt:   timestamp,
+						Name:          models.MetricNameDiskUtil,
+						Unit:          models.UnitPercentage,
+						Value:         "10",
+						Timestamp:     1111,
+					},
+					models.AppInstanceMetric{
+						AppId:         "test-app-id",
+						InstanceIndex: 0,
+						CollectedAt:   timestamp,
+						Name:          models.MetricNameCPUEntitlement,
+						Unit:          models.UnitPercentage,
+						Value:         "129",
+						Timestamp:     1111,
+					},
+					models.AppInstanceMetric{
+						AppId:         "test-app-id",
+						InstanceIndex: 1,
+						CollectedAt:   timestamp,
+						Name:          models.MetricNameDiskUsed,
+						Unit:          models.UnitMegaBytes,
+						Value:         "301",
+						Timestamp:     1111,
+					},
+					models.AppInstanceMetric{
+						AppId:         "test-app-id",
+						InstanceIndex: 1,
+						CollectedAt:   timestamp,
+						Name:          models.MetricNameCPUEntitlement,
+						Unit:          models.UnitPercentage,
+						Value:         "40",
+						Timestamp:     1111,
+					},
+				))
+			})
+		})
 	})
 
 	Describe("#CompactEnvelopes", func() {
@@ -261,10 +320,67 @@ var _ = Describe("Envelopeprocessor", func() {
 					Value:         "0",
 					Timestamp:     timestamp,
 				}))
+				Expect(metrics).To(ContainElement(models.AppInstanceMetric{
+					AppId:         "another-test-app-id",
+					InstanceIndex: 0,
+					CollectedAt:   timestamp,
+					Name:          models.MetricNameResponseTimeP99,
+					Unit:          models.UnitMilliseconds,
+					Value:         "0",
+					Timestamp:     timestamp,
+				}))
+				Expect(metrics).To(ContainElement(models.AppInstanceMetric{
+					AppId:         "another-test-app-id",
+					InstanceIndex: 0,
+					CollectedAt:   timestamp,
+					Name:          models.MetricNameErrorRate,
+					Unit:          models.UnitPercentage,
+					Value:         "0",
+					Timestamp:     timestamp,
+				}))
 
 			})
 
 		})
+
+		Context("when the requests have different response times and status codes", func() {
+			BeforeEach(func() {
+				envelopes = []*loggregator_v2.Envelope{}
+				statusCodes := []string{"200", "500", "200", "404", "503", "200", "200", "", "200", "200"}
+				for i, statusCode := range statusCodes {
+					envelope := generateHttpStartStopEnvelope("test-app-id", "0", 0, int64(i+1)*1000*1000, 1111)
+					if statusCode != "" {
+						envelope.Tags = map[string]string{"status_code": statusCode}
+					}
+					envelopes = append(envelopes, envelope)
+				}
+				envelopes = append(envelopes, generateHttpStartStopEnvelope("test-app-id", "1", 0, 5*1000*1000, 1111))
+				envelopes[len(envelopes)-1].DeprecatedTags = map[string]*loggregator_v2.Value{
+					"status_code": {Data: &loggregator_v2.Value_Integer{Integer: 502}},
+				}
+			})
+
+			It("computes the response time percentiles and the error rate per instance", func() {
+				timestamp := time.Now().UnixNano()
+				metrics := processor.GetTimerMetrics(envelopes, "test-app-id", timestamp)
+
+				valueOf := func(instanceIndex uint32, name string) string {
+					for _, metric := range metrics {
+						if metric.InstanceIndex == instanceIndex && metric.Name == name {
+							Expect(metric.CollectedAt).To(Equal(timestamp))
+							return metric.Value
+						}
+					}
+					return "missing"
+				}
+				Expect(valueOf(0, models.MetricNameResponseTimeP90)).To(Equal("9"))
+				Expect(valueOf(0, models.MetricNameResponseTimeP95)).To(Equal("10"))
+				Expect(valueOf(0, models.MetricNameResponseTimeP99)).To(Equal("10"))
+				Expect(valueOf(0, models.MetricNameErrorRate)).To(Equal("20"))
+				Expect(valueOf(1, models.MetricNameResponseTimeP90)).To(Equal("5"))
+				Expect(valueOf(1, models.MetricNameErrorRate)).To(Equal("100"))
+			})
+		})
 	})
 })
 
@@ -311,6 +427,33 @@ func generateContainerMetrics(sourceID, instance string, cpu, memory, memoryQuot
 	return e
 }
 
+func generateDiskContainerMetrics(sourceID, instance string, cpuEntitlement, disk, diskQuota float64, timestamp int64) *loggregator_v2.Envelope {
+	e := &loggregator_v2.Envelope{
+		SourceId:   sourceID,
+		InstanceId: instance,
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: map[string]*loggregator_v2.GaugeValue{
+					"cpu_entitlement": {
+						Unit:  "percentage",
+						Value: cpuEntitlement,
+					},
+					"disk": {
+						Unit:  "bytes",
+						Value: disk,
+					},
+					"disk_quota": {
+						Unit:  "bytes",
+						Value: diskQuota,
+					},
+				},
+			},
+		},
+		Timestamp: timestamp,
+	}
+	return e
+}
+
 func generateCustomMetrics(sourceID, instance, name, unit string, value float64, timestamp int64) *loggregator_v2.Envelope {
 	e := &loggregator_v2.Envelope{
 		SourceId:   sourceID,
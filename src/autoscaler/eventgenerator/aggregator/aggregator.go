@@ -50,11 +50,13 @@ func (a *Aggregator) getAppMonitors(policyMap map[string]*models.AppPolicy) map[
 	}
 	appMonitors := map[string]*models.AppMonitor{}
 	for appID, appPolicy := range policyMap {
-		for _, rule := range appPolicy.ScalingPolicy.ScalingRules {
-			appMonitors[fmt.Sprintf("%s-%s", appID, rule.MetricType)] = &models.AppMonitor{
-				AppId:      appID,
-				MetricType: rule.MetricType,
-				StatWindow: time.Second * time.Duration(a.defaultStatWindowSecs),
+		for _, aggregatedMetricType := range appPolicy.ScalingPolicy.AggregatedMetricTypes() {
+			metricType, aggregation := models.ParseAggregatedMetricType(aggregatedMetricType)
+			appMonitors[fmt.Sprintf("%s-%s", appID, aggregatedMetricType)] = &models.AppMonitor{
+				AppId:       appID,
+				MetricType:  metricType,
+				Aggregation: aggregation,
+				StatWindow:  time.Second * time.Duration(a.defaultStatWindowSecs),
 			}
 		}
 	}
@@ -8,7 +8,6 @@ import (
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/collection"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
-	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"code.cloudfoundry.org/clock"
@@ -21,35 +20,38 @@ type SaveAppMetricToCacheFunc func(*models.AppMetric) bool
 type QueryAppMetricsFunc func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error)
 
 type AppManager struct {
-	logger                lager.Logger
-	interval              time.Duration
-	nodeNum               int
-	nodeIndex             int
-	metricCacheSizePerApp int
-	metricCache           map[string]*collection.TSDCache
-	policyDB              db.PolicyDB
-	appMetricDB           db.AppMetricDB
-	clock                 clock.Clock
-	doneChan              chan bool
-	policyMap             map[string]*models.AppPolicy
-	pLock                 sync.RWMutex
-	mLock                 sync.RWMutex
+	logger                    lager.Logger
+	interval                  time.Duration
+	sharding                  Sharding
+	metricCacheSizePerApp     int
+	defaultBreachDurationSecs int
+	metricCache               map[string]*collection.TSDCache
+	policyDB                  db.PolicyDB
+	appMetricDB               db.AppMetricDB
+	clock                     clock.Clock
+	doneChan                  chan bool
+	policyMap                 map[string]*models.AppPolicy
+	pLock                     sync.RWMutex
+	mLock                     sync.RWMutex
 }
 
-func NewAppManager(logger lager.Logger, clock clock.Clock, interval time.Duration, nodeNum, nodeIndex int,
-	metricCacheSizePerApp int, policyDB db.PolicyDB, appMetricDB db.AppMetricDB) *AppManager {
+// NewAppManager creates an AppManager for the apps the sharding makes the node responsible for. When it starts to manage
+// an app, e.g. because the app moved from another node, it warms the metric cache of the app with the metrics the
+// evaluation of its policy looks back to from the AppMetricDB. A default breach duration of 0 disables the warm up.
+func NewAppManager(logger lager.Logger, clock clock.Clock, interval time.Duration, sharding Sharding,
+	metricCacheSizePerApp int, defaultBreachDurationSecs int, policyDB db.PolicyDB, appMetricDB db.AppMetricDB) *AppManager {
 	return &AppManager{
-		logger:                logger.Session("AppManager"),
-		clock:                 clock,
-		interval:              interval,
-		nodeNum:               nodeNum,
-		nodeIndex:             nodeIndex,
-		metricCacheSizePerApp: metricCacheSizePerApp,
-		metricCache:           make(map[string]*collection.TSDCache),
-		policyDB:              policyDB,
-		appMetricDB:           appMetricDB,
-		doneChan:              make(chan bool),
-		policyMap:             make(map[string]*models.AppPolicy),
+		logger:                    logger.Session("AppManager"),
+		clock:                     clock,
+		interval:                  interval,
+		sharding:                  sharding,
+		metricCacheSizePerApp:     metricCacheSizePerApp,
+		defaultBreachDurationSecs: defaultBreachDurationSecs,
+		metricCache:               make(map[string]*collection.TSDCache),
+		policyDB:                  policyDB,
+		appMetricDB:               appMetricDB,
+		doneChan:                  make(chan bool),
+		policyMap:                 make(map[string]*models.AppPolicy),
 	}
 }
 func (am *AppManager) GetPolicies() map[string]*models.AppPolicy {
@@ -102,14 +104,10 @@ func (am *AppManager) retrievePolicies() ([]*models.PolicyJson, error) {
 	return policyJsons, nil
 }
 
-func (am *AppManager) isEventgeneratorRespForApp(appID string) bool {
-	return helpers.FNVHash(appID)%uint32(am.nodeNum) == uint32(am.nodeIndex)
-}
-
 func (am *AppManager) computePolicies(policyJsons []*models.PolicyJson) map[string]*models.AppPolicy {
 	policyMap := make(map[string]*models.AppPolicy)
 	for _, policyJSON := range policyJsons {
-		if (am.nodeNum == 1) || am.isEventgeneratorRespForApp(policyJSON.AppId) {
+		if am.sharding.IsResponsibleFor(policyJSON.AppId) {
 			appPolicy, err := policyJSON.GetAppPolicy()
 			if err != nil {
 				am.logger.Error("get-app-policy", err)
@@ -122,8 +120,9 @@ func (am *AppManager) computePolicies(policyJsons []*models.PolicyJson) map[stri
 }
 
 func (am *AppManager) refreshMetricCache(policies map[string]*models.AppPolicy) {
+	newCaches := map[string]*collection.TSDCache{}
+
 	am.mLock.Lock()
-	defer am.mLock.Unlock()
 	for id := range am.metricCache {
 		_, exist := policies[id]
 		if !exist {
@@ -135,6 +134,30 @@ func (am *AppManager) refreshMetricCache(policies map[string]*models.AppPolicy)
 		_, exist := am.metricCache[id]
 		if !exist {
 			am.metricCache[id] = collection.NewTSDCache(am.metricCacheSizePerApp)
+			newCaches[id] = am.metricCache[id]
+		}
+	}
+	am.mLock.Unlock()
+
+	for id, appCache := range newCaches {
+		am.warmUpMetricCache(id, policies[id].ScalingPolicy, appCache)
+	}
+}
+
+func (am *AppManager) warmUpMetricCache(appID string, policy *models.ScalingPolicy, appCache *collection.TSDCache) {
+	if am.defaultBreachDurationSecs <= 0 || policy == nil {
+		return
+	}
+	end := am.clock.Now()
+	start := end.Add(-policy.MetricLookBack(am.defaultBreachDurationSecs))
+	for _, metricType := range policy.AggregatedMetricTypes() {
+		appMetrics, err := am.appMetricDB.RetrieveAppMetrics(appID, metricType, start.UnixNano(), end.UnixNano(), db.ASC)
+		if err != nil {
+			am.logger.Error("warm-up-metric-cache", err, lager.Data{"appId": appID, "metricType": metricType})
+			continue
+		}
+		for _, appMetric := range appMetrics {
+			appCache.Put(appMetric)
 		}
 	}
 }
@@ -151,7 +174,20 @@ func (am *AppManager) SaveMetricToCache(metric *models.AppMetric) bool {
 	return false
 }
 
+// QueryAppMetrics returns the metrics of the app from the cache, or from the AppMetricDB if the cache does not cover the
+// time range. Derived metrics of the app's policy are computed from the metrics of their variables.
 func (am *AppManager) QueryAppMetrics(appID string, metricType string, start int64, end int64, order db.OrderType) ([]*models.AppMetric, error) {
+	var policy *models.ScalingPolicy
+	am.pLock.RLock()
+	if appPolicy, found := am.policyMap[appID]; found {
+		policy = appPolicy.ScalingPolicy
+	}
+	am.pLock.RUnlock()
+
+	return WithDerivedMetrics(policy, am.queryStoredAppMetrics)(appID, metricType, start, end, order)
+}
+
+func (am *AppManager) queryStoredAppMetrics(appID string, metricType string, start int64, end int64, order db.OrderType) ([]*models.AppMetric, error) {
 	am.mLock.RLock()
 	appCache := am.metricCache[appID]
 	am.mLock.RUnlock()
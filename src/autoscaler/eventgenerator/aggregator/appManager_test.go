@@ -38,9 +38,10 @@ var _ = Describe("AppManager", func() {
 		      }
 		   ]
 		}`
-		nodeNum         int
-		nodeIndex       int
-		cacheSizePerApp int
+		nodeNum                   int
+		nodeIndex                 int
+		cacheSizePerApp           int
+		defaultBreachDurationSecs int
 	)
 
 	BeforeEach(func() {
@@ -51,10 +52,11 @@ var _ = Describe("AppManager", func() {
 		nodeNum = 1
 		nodeIndex = 0
 		cacheSizePerApp = 5
+		defaultBreachDurationSecs = 0
 	})
 	Context("Start", func() {
 		JustBeforeEach(func() {
-			appManager = NewAppManager(logger, clock, testPolicyPollerInterval, nodeNum, nodeIndex, cacheSizePerApp, policyDB, appMetricDB)
+			appManager = NewAppManager(logger, clock, testPolicyPollerInterval, StaticSharding{NodeNum: nodeNum, NodeIndex: nodeIndex}, cacheSizePerApp, defaultBreachDurationSecs, policyDB, appMetricDB)
 			appManager.Start()
 
 		})
@@ -223,7 +225,7 @@ var _ = Describe("AppManager", func() {
 
 	Context("Save and query metrics", func() {
 		JustBeforeEach(func() {
-			appManager = NewAppManager(logger, clock, testPolicyPollerInterval, nodeNum, nodeIndex, cacheSizePerApp, policyDB, appMetricDB)
+			appManager = NewAppManager(logger, clock, testPolicyPollerInterval, StaticSharding{NodeNum: nodeNum, NodeIndex: nodeIndex}, cacheSizePerApp, defaultBreachDurationSecs, policyDB, appMetricDB)
 			appManager.Start()
 
 		})
@@ -314,6 +316,42 @@ var _ = Describe("AppManager", func() {
 			})
 		})
 
+		Context("when the policy defines derived metrics", func() {
+			BeforeEach(func() {
+				policyDB.RetrievePoliciesStub = func() ([]*models.PolicyJson, error) {
+					return []*models.PolicyJson{{AppId: testAppId, PolicyStr: `
+					{
+					   "instance_min_count":1,
+					   "instance_max_count":5,
+					   "derived_metrics":[{"name":"queue_per_worker","expression":"queue_depth / 2","unit":"jobs"}],
+					   "scaling_rules":[
+					      {
+					         "metric_type":"queue_per_worker",
+					         "threshold":30,
+					         "operator":">",
+					         "adjustment":"+1"
+					      }
+					   ]
+					}`}}, nil
+				}
+			})
+
+			It("computes the derived metrics from the cached metrics", func() {
+				Eventually(appManager.GetPolicies).Should(HaveKey(testAppId))
+
+				Expect(appManager.SaveMetricToCache(&models.AppMetric{AppId: testAppId, MetricType: "queue_depth", Value: "10", Timestamp: 100})).To(BeTrue())
+				Expect(appManager.SaveMetricToCache(&models.AppMetric{AppId: testAppId, MetricType: "queue_depth", Value: "30", Timestamp: 200})).To(BeTrue())
+
+				data, err := appManager.QueryAppMetrics(testAppId, "queue_per_worker", 100, 200, db.DESC)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal([]*models.AppMetric{
+					{AppId: testAppId, MetricType: "queue_per_worker", Value: "15", Unit: "jobs", Timestamp: 200},
+					{AppId: testAppId, MetricType: "queue_per_worker", Value: "5", Unit: "jobs", Timestamp: 100},
+				}))
+				Expect(appMetricDB.RetrieveAppMetricsCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("when running with 3 nodes and current node index is 0", func() {
 			BeforeEach(func() {
 				nodeNum = 3
@@ -388,9 +426,66 @@ var _ = Describe("AppManager", func() {
 
 	})
 
+	Context("Warm up metric cache", func() {
+		var warmedUpMetrics []*models.AppMetric
+
+		JustBeforeEach(func() {
+			appManager = NewAppManager(logger, clock, testPolicyPollerInterval, StaticSharding{NodeNum: nodeNum, NodeIndex: nodeIndex}, cacheSizePerApp, defaultBreachDurationSecs, policyDB, appMetricDB)
+			appManager.Start()
+		})
+
+		AfterEach(func() {
+			appManager.Stop()
+		})
+
+		BeforeEach(func() {
+			defaultBreachDurationSecs = 120
+			policyDB.RetrievePoliciesReturns([]*models.PolicyJson{{AppId: testAppId, PolicyStr: policyStr}}, nil)
+			warmedUpMetrics = []*models.AppMetric{
+				{AppId: testAppId, MetricType: "test-metric-name", Value: "100", Unit: "test-unit", Timestamp: clock.Now().Add(-4 * time.Minute).UnixNano()},
+				{AppId: testAppId, MetricType: "test-metric-name", Value: "200", Unit: "test-unit", Timestamp: clock.Now().Add(-2 * time.Minute).UnixNano()},
+			}
+			appMetricDB.RetrieveAppMetricsReturnsOnCall(0, warmedUpMetrics, nil)
+			appMetricDB.RetrieveAppMetricsReturns([]*models.AppMetric{}, nil)
+		})
+
+		It("warms up the metric cache of a new app with the metric look back of its policy from the database", func() {
+			Eventually(appManager.GetPolicies).Should(HaveLen(1))
+			Eventually(appMetricDB.RetrieveAppMetricsCallCount).Should(Equal(1))
+			appId, metricType, start, end, order := appMetricDB.RetrieveAppMetricsArgsForCall(0)
+			Expect(appId).To(Equal(testAppId))
+			Expect(metricType).To(Equal("test-metric-name"))
+			Expect(start).To(Equal(clock.Now().Add(-10 * time.Minute).UnixNano()))
+			Expect(end).To(Equal(clock.Now().UnixNano()))
+			Expect(order).To(Equal(db.ASC))
+
+			Eventually(func() ([]*models.AppMetric, error) {
+				return appManager.QueryAppMetrics(testAppId, "test-metric-name", warmedUpMetrics[0].Timestamp, clock.Now().UnixNano(), db.ASC)
+			}).Should(Equal(warmedUpMetrics))
+		})
+
+		It("warms up the metric cache of an app only once", func() {
+			Eventually(appMetricDB.RetrieveAppMetricsCallCount).Should(Equal(1))
+			clock.Increment(1 * testPolicyPollerInterval)
+			Eventually(policyDB.RetrievePoliciesCallCount).Should(Equal(2))
+			Consistently(appMetricDB.RetrieveAppMetricsCallCount).Should(Equal(1))
+		})
+
+		Context("when the default breach duration is 0", func() {
+			BeforeEach(func() {
+				defaultBreachDurationSecs = 0
+			})
+
+			It("does not warm up the metric cache", func() {
+				Eventually(appManager.GetPolicies).Should(HaveLen(1))
+				Consistently(appMetricDB.RetrieveAppMetricsCallCount).Should(BeZero())
+			})
+		})
+	})
+
 	Context("Stop", func() {
 		BeforeEach(func() {
-			appManager = NewAppManager(logger, clock, testPolicyPollerInterval, nodeNum, nodeIndex, cacheSizePerApp, policyDB, appMetricDB)
+			appManager = NewAppManager(logger, clock, testPolicyPollerInterval, StaticSharding{NodeNum: nodeNum, NodeIndex: nodeIndex}, cacheSizePerApp, defaultBreachDurationSecs, policyDB, appMetricDB)
 			appManager.Start()
 			Eventually(policyDB.RetrievePoliciesCallCount).Should(Equal(1))
 
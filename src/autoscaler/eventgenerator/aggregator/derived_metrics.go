@@ -0,0 +1,85 @@
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+)
+
+// WithDerivedMetrics computes the derived metrics of the policy from the metrics the given query returns for their
+// variables. Queries of other metric types are passed through.
+//
+// A derived metric is computed at every timestamp at which one of its variables has a metric, using the latest value
+// of each variable up to that timestamp. It has no value if a variable has no value yet or the expression cannot be
+// evaluated, e.g. because of a division by zero.
+func WithDerivedMetrics(policy *models.ScalingPolicy, query QueryAppMetricsFunc) QueryAppMetricsFunc {
+	return func(appID string, metricType string, start int64, end int64, order db.OrderType) ([]*models.AppMetric, error) {
+		derivedMetric := policy.GetDerivedMetric(metricType)
+		if derivedMetric == nil {
+			return query(appID, metricType, start, end, order)
+		}
+		return queryDerivedMetric(appID, derivedMetric, query, start, end, order)
+	}
+}
+
+func queryDerivedMetric(appID string, derivedMetric *models.DerivedMetric, query QueryAppMetricsFunc, start int64, end int64, order db.OrderType) ([]*models.AppMetric, error) {
+	expression, err := models.ParseExpression(derivedMetric.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression of derived metric %s: %w", derivedMetric.Name, err)
+	}
+
+	series := map[string][]*models.AppMetric{}
+	timestampSet := map[int64]bool{}
+	for _, variable := range expression.Variables() {
+		appMetrics, err := query(appID, models.DerivedMetricVariableMetricType(variable), start, end, db.ASC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s metrics of derived metric %s: %w", variable, derivedMetric.Name, err)
+		}
+		series[variable] = appMetrics
+		for _, appMetric := range appMetrics {
+			timestampSet[appMetric.Timestamp] = true
+		}
+	}
+	timestamps := make([]int64, 0, len(timestampSet))
+	for timestamp := range timestampSet {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	result := make([]*models.AppMetric, 0, len(timestamps))
+	values := map[string]float64{}
+	next := map[string]int{}
+	for _, timestamp := range timestamps {
+		for variable, appMetrics := range series {
+			for ; next[variable] < len(appMetrics) && appMetrics[next[variable]].Timestamp <= timestamp; next[variable]++ {
+				value, err := strconv.ParseFloat(appMetrics[next[variable]].Value, 64)
+				if err != nil {
+					delete(values, variable)
+				} else {
+					values[variable] = value
+				}
+			}
+		}
+
+		appMetric := &models.AppMetric{
+			AppId:      appID,
+			MetricType: derivedMetric.Name,
+			Unit:       derivedMetric.Unit,
+			Timestamp:  timestamp,
+		}
+		if value, err := expression.Evaluate(values); err == nil {
+			appMetric.Value = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		result = append(result, appMetric)
+	}
+
+	if order == db.DESC {
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package aggregator_test
+
+import (
+	"errors"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithDerivedMetrics", func() {
+	var (
+		testAppId   = "testAppId"
+		policy      *models.ScalingPolicy
+		storedTypes []string
+		stored      map[string][]*models.AppMetric
+		queryErr    error
+		query       QueryAppMetricsFunc
+		result      []*models.AppMetric
+		err         error
+	)
+
+	appMetric := func(metricType string, value string, timestamp int64) *models.AppMetric {
+		return &models.AppMetric{AppId: testAppId, MetricType: metricType, Value: value, Unit: "", Timestamp: timestamp}
+	}
+
+	BeforeEach(func() {
+		policy = &models.ScalingPolicy{
+			DerivedMetrics: []*models.DerivedMetric{
+				{Name: "throughput_per_instance", Expression: "throughput / instances", Unit: "rps"},
+			},
+		}
+		storedTypes = nil
+		queryErr = nil
+		stored = map[string][]*models.AppMetric{
+			"throughput": {appMetric("throughput", "300", 100), appMetric("throughput", "400", 300), appMetric("throughput", "500", 400)},
+			"cpu:count":  {appMetric("cpu:count", "3", 100), appMetric("cpu:count", "4", 200), appMetric("cpu:count", "0", 400)},
+		}
+		query = func(appID string, metricType string, start int64, end int64, order db.OrderType) ([]*models.AppMetric, error) {
+			Expect(appID).To(Equal(testAppId))
+			Expect(start).To(Equal(int64(0)))
+			Expect(end).To(Equal(int64(500)))
+			storedTypes = append(storedTypes, metricType)
+			return stored[metricType], queryErr
+		}
+	})
+
+	JustBeforeEach(func() {
+		result, err = WithDerivedMetrics(policy, query)(testAppId, "throughput_per_instance", 0, 500, db.ASC)
+	})
+
+	It("computes the derived metric from the latest values of its variables", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storedTypes).To(Equal([]string{"throughput", "cpu:count"}))
+		Expect(result).To(Equal([]*models.AppMetric{
+			{AppId: testAppId, MetricType: "throughput_per_instance", Value: "100", Unit: "rps", Timestamp: 100},
+			{AppId: testAppId, MetricType: "throughput_per_instance", Value: "75", Unit: "rps", Timestamp: 200},
+			{AppId: testAppId, MetricType: "throughput_per_instance", Value: "100", Unit: "rps", Timestamp: 300},
+			{AppId: testAppId, MetricType: "throughput_per_instance", Value: "", Unit: "rps", Timestamp: 400},
+		}))
+	})
+
+	Context("when a variable has no value yet", func() {
+		BeforeEach(func() {
+			stored["throughput"][0] = appMetric("throughput", "", 100)
+		})
+
+		It("computes no value", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result[0].Value).To(BeEmpty())
+			Expect(result[1].Value).To(BeEmpty())
+			Expect(result[2].Value).To(Equal("100"))
+		})
+	})
+
+	Context("when queried in descending order", func() {
+		JustBeforeEach(func() {
+			result, err = WithDerivedMetrics(policy, query)(testAppId, "throughput_per_instance", 0, 500, db.DESC)
+		})
+
+		It("returns the latest derived metric first", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(HaveLen(4))
+			Expect(result[0].Timestamp).To(Equal(int64(400)))
+			Expect(result[3].Timestamp).To(Equal(int64(100)))
+		})
+	})
+
+	Context("when the metrics of a variable cannot be retrieved", func() {
+		BeforeEach(func() {
+			queryErr = errors.New("an error")
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError(ContainSubstring("an error")))
+		})
+	})
+
+	Context("when the metric type is not a derived metric", func() {
+		JustBeforeEach(func() {
+			result, err = WithDerivedMetrics(policy, query)(testAppId, "throughput", 0, 500, db.ASC)
+		})
+
+		It("passes the query through", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(stored["throughput"]))
+		})
+	})
+
+	Context("when there is no policy", func() {
+		BeforeEach(func() {
+			policy = nil
+		})
+
+		It("passes the query through", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storedTypes).To(Equal([]string{"throughput_per_instance"}))
+		})
+	})
+})
@@ -3,30 +3,43 @@ package aggregator
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/client"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/healthendpoint"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/lager/v3"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+var staleAppMetricCounter = prometheus.CounterOpts{
+	Namespace: "autoscaler",
+	Subsystem: "eventgenerator",
+	Name:      "stale_app_metrics",
+	Help:      "the total number of aggregated app metrics without value, because no instance reported the metric",
+}
+
 type MetricPoller struct {
-	logger          lager.Logger
-	doneChan        chan bool
-	metricClient    client.MetricClient
-	appMonitorsChan chan *models.AppMonitor
-	appMetricChan   chan *models.AppMetric
+	logger           lager.Logger
+	doneChan         chan bool
+	metricClient     client.MetricClient
+	appMonitorsChan  chan *models.AppMonitor
+	appMetricChan    chan *models.AppMetric
+	counterCollector healthendpoint.CounterCollector
 }
 
-func NewMetricPoller(logger lager.Logger, metricClient client.MetricClient, appMonitorsChan chan *models.AppMonitor, appMetricChan chan *models.AppMetric) *MetricPoller {
+func NewMetricPoller(logger lager.Logger, metricClient client.MetricClient, appMonitorsChan chan *models.AppMonitor, appMetricChan chan *models.AppMetric, counterCollector healthendpoint.CounterCollector) *MetricPoller {
+	counterCollector.AddCounters(staleAppMetricCounter)
 	return &MetricPoller{
-		logger:          logger.Session("MetricPoller"),
-		appMonitorsChan: appMonitorsChan,
-		metricClient:    metricClient,
-		doneChan:        make(chan bool),
-		appMetricChan:   appMetricChan,
+		logger:           logger.Session("MetricPoller"),
+		appMonitorsChan:  appMonitorsChan,
+		metricClient:     metricClient,
+		doneChan:         make(chan bool),
+		appMetricChan:    appMetricChan,
+		counterCollector: counterCollector,
 	}
 }
 
@@ -67,44 +80,100 @@ func (m *MetricPoller) retrieveMetric(appMonitor *models.AppMonitor) error {
 	if err != nil {
 		return fmt.Errorf("retriveMetric Failed: %w", err)
 	}
-	avgMetric := m.aggregate(appId, metricType, metrics)
-	m.logger.Debug("save-aggregated-appmetric", lager.Data{"appMetric": avgMetric})
-	m.appMetricChan <- avgMetric
+	appMetric := m.aggregate(appId, metricType, appMonitor.Aggregation, metrics)
+	m.logger.Debug("save-aggregated-appmetric", lager.Data{"appMetric": appMetric})
+	m.appMetricChan <- appMetric
 	return nil
 }
 
-func (m *MetricPoller) aggregate(appId string, metricType string, metrics []models.AppInstanceMetric) *models.AppMetric {
-	var count int64
-	var sum int64
+func (m *MetricPoller) aggregate(appId string, metricType string, aggregation string, metrics []models.AppInstanceMetric) *models.AppMetric {
 	var unit string
+	values := []float64{}
+	instances := map[uint32]bool{}
+	allIntegers := true
 	timestamp := time.Now().UnixNano()
 	for _, metric := range metrics {
 		unit = metric.Unit
-		metricValue, err := strconv.ParseInt(metric.Value, 10, 64)
+		metricValue, err := strconv.ParseFloat(metric.Value, 64)
 		if err != nil {
 			m.logger.Error("failed-to-aggregate", err, lager.Data{"appid": appId, "metrictype": metricType, "value": metric.Value})
 		} else {
-			count++
-			sum += metricValue
+			values = append(values, metricValue)
+			instances[metric.InstanceIndex] = true
+			if metricValue != math.Trunc(metricValue) {
+				allIntegers = false
+			}
 		}
 	}
 
-	if count == 0 {
+	aggregatedMetricType := models.AggregatedMetricType(metricType, aggregation)
+	if len(values) == 0 {
+		m.logger.Debug("stale-appmetric", lager.Data{"appid": appId, "metrictype": aggregatedMetricType})
+		m.counterCollector.Add(staleAppMetricCounter, 1)
 		return &models.AppMetric{
 			AppId:      appId,
-			MetricType: metricType,
+			MetricType: aggregatedMetricType,
 			Value:      "",
 			Unit:       "",
 			Timestamp:  timestamp,
 		}
 	}
 
-	avgValue := int64(math.Ceil(float64(sum) / float64(count)))
+	if aggregation == models.AggregationCount {
+		return &models.AppMetric{
+			AppId:      appId,
+			MetricType: aggregatedMetricType,
+			Value:      strconv.Itoa(len(instances)),
+			Unit:       "",
+			Timestamp:  timestamp,
+		}
+	}
+
+	value := aggregateValues(aggregation, values)
+	if allIntegers {
+		// integer metrics keep being rounded up so that existing integer thresholds behave as before
+		value = math.Ceil(value)
+	}
 	return &models.AppMetric{
 		AppId:      appId,
-		MetricType: metricType,
-		Value:      fmt.Sprintf("%d", avgValue),
+		MetricType: aggregatedMetricType,
+		Value:      strconv.FormatFloat(value, 'f', -1, 64),
 		Unit:       unit,
 		Timestamp:  timestamp,
 	}
 }
+
+// aggregateValues reduces the metric values with the given aggregation, unknown aggregations fall back to the average.
+// Percentiles use the nearest-rank method, so they are always one of the values.
+func aggregateValues(aggregation string, values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	switch aggregation {
+	case models.AggregationMax:
+		return sorted[len(sorted)-1]
+	case models.AggregationMin:
+		return sorted[0]
+	case models.AggregationSum:
+		return sum(values)
+	case models.AggregationMedian:
+		middle := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[middle-1] + sorted[middle]) / 2
+		}
+		return sorted[middle]
+	case models.AggregationP95:
+		rank := int(math.Ceil(0.95 * float64(len(sorted))))
+		return sorted[rank-1]
+	default:
+		return sum(values) / float64(len(values))
+	}
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
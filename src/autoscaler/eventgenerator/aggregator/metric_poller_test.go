@@ -3,6 +3,7 @@ package aggregator_test
 import (
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/client"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/healthendpoint"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
@@ -12,6 +13,7 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/ghttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"net/http"
 	"time"
@@ -19,17 +21,18 @@ import (
 
 var _ = Describe("MetricPoller", func() {
 	var (
-		testAppId       = "testAppId"
-		timestamp       = time.Now().UnixNano()
-		testMetricType  = "a-metric-type"
-		testMetricUnit  = "a-metric-unit"
-		logger          *lagertest.TestLogger
-		appMonitorsChan chan *models.AppMonitor
-		appMetricChan   chan *models.AppMetric
-		metricPoller    *MetricPoller
-		metricClient    MetricClient
-		metricServer    *ghttp.Server
-		metrics         = []*models.AppInstanceMetric{
+		testAppId        = "testAppId"
+		timestamp        = time.Now().UnixNano()
+		testMetricType   = "a-metric-type"
+		testMetricUnit   = "a-metric-unit"
+		logger           *lagertest.TestLogger
+		appMonitorsChan  chan *models.AppMonitor
+		appMetricChan    chan *models.AppMetric
+		metricPoller     *MetricPoller
+		metricClient     MetricClient
+		metricServer     *ghttp.Server
+		counterCollector healthendpoint.CounterCollector
+		metrics          = []*models.AppInstanceMetric{
 			{
 				AppId:         testAppId,
 				InstanceIndex: 0,
@@ -78,6 +81,7 @@ var _ = Describe("MetricPoller", func() {
 		appMonitorsChan = make(chan *models.AppMonitor, 1)
 		appMetricChan = make(chan *models.AppMetric, 1)
 		metricServer = nil
+		counterCollector = healthendpoint.NewCounterCollector()
 
 		path, err := routes.MetricsCollectorRoutes().Get(routes.GetMetricHistoriesRouteName).URLPath("appid", testAppId, "metrictype", testMetricType)
 		Expect(err).NotTo(HaveOccurred())
@@ -98,7 +102,7 @@ var _ = Describe("MetricPoller", func() {
 			httpClient, err := helpers.CreateHTTPClient(nil, helpers.DefaultClientConfig(), lager.NewLogger("metrics_server"))
 			Expect(err).ToNot(HaveOccurred())
 			metricClient = NewMetricServerClient(logger, metricServer.URL(), httpClient)
-			metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan)
+			metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan, counterCollector)
 			metricPoller.Start()
 
 			Expect(appMonitorsChan).Should(BeSent(appMonitor))
@@ -134,7 +138,7 @@ var _ = Describe("MetricPoller", func() {
 		})
 
 		JustBeforeEach(func() {
-			metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan)
+			metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan, counterCollector)
 			metricPoller.Start()
 
 			Expect(appMonitorsChan).Should(BeSent(appMonitor))
@@ -146,6 +150,11 @@ var _ = Describe("MetricPoller", func() {
 		})
 
 		Context("when metrics are successfully retrieved", func() {
+			It("does not count stale metrics", func() {
+				Eventually(appMetricChan).Should(Receive())
+				Expect(testutil.ToFloat64(counterCollector)).To(Equal(float64(0)))
+			})
+
 			It("send the average metrics to appMetric channel", func() {
 				appMetric = <-appMetricChan
 				appMetric.Timestamp = timestamp
@@ -159,6 +168,28 @@ var _ = Describe("MetricPoller", func() {
 			})
 		})
 
+		Context("when metrics with decimal values are retrieved", func() {
+			BeforeEach(func() {
+				metricServer.RouteToHandler("GET", urlPath, ghttp.RespondWithJSONEncoded(http.StatusOK,
+					&[]*models.AppInstanceMetric{
+						{AppId: testAppId, InstanceIndex: 0, Name: testMetricType, Unit: testMetricUnit, Value: "0.5", Timestamp: 111100},
+						{AppId: testAppId, InstanceIndex: 1, Name: testMetricType, Unit: testMetricUnit, Value: "1", Timestamp: 110000},
+					}))
+			})
+
+			It("send the precise average metrics to appMetric channel", func() {
+				appMetric = <-appMetricChan
+				appMetric.Timestamp = timestamp
+
+				Expect(appMetric).To(Equal(&models.AppMetric{
+					AppId:      testAppId,
+					MetricType: testMetricType,
+					Value:      "0.75",
+					Unit:       testMetricUnit,
+					Timestamp:  timestamp}))
+			})
+		})
+
 		Context("when the metrics are not valid JSON", func() {
 			BeforeEach(func() {
 				metricServer.RouteToHandler("GET", urlPath, ghttp.RespondWith(http.StatusOK,
@@ -191,6 +222,11 @@ var _ = Describe("MetricPoller", func() {
 					Unit:       "",
 					Timestamp:  timestamp}))
 			})
+
+			It("counts the stale metric", func() {
+				Eventually(appMetricChan).Should(Receive())
+				Expect(testutil.ToFloat64(counterCollector)).To(Equal(float64(1)))
+			})
 		})
 
 		Context("when an error ocurrs retrieving metrics", func() {
@@ -208,13 +244,62 @@ var _ = Describe("MetricPoller", func() {
 
 	})
 
+	Context("Aggregation", func() {
+		BeforeEach(func() {
+			metricServer = ghttp.NewServer()
+			metricServer.RouteToHandler("GET", urlPath, ghttp.RespondWithJSONEncoded(http.StatusOK,
+				&metrics))
+
+			httpClient, err := helpers.CreateHTTPClient(nil, helpers.DefaultClientConfig(), lager.NewLogger("metric_server"))
+			Expect(err).ToNot(HaveOccurred())
+			metricClient = NewMetricServerClient(logger, metricServer.URL(), httpClient)
+		})
+
+		AfterEach(func() {
+			metricPoller.Stop()
+			metricServer.Close()
+		})
+
+		DescribeTable("sends the metrics aggregated with the aggregation of the app monitor to appMetric channel",
+			func(aggregation string, metricType string, value string) {
+				appMonitor.Aggregation = aggregation
+				metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan, counterCollector)
+				metricPoller.Start()
+				Expect(appMonitorsChan).Should(BeSent(appMonitor))
+
+				appMetric := <-appMetricChan
+				Expect(appMetric.MetricType).To(Equal(metricType))
+				Expect(appMetric.Value).To(Equal(value))
+				Expect(appMetric.Unit).To(Equal(testMetricUnit))
+			},
+			Entry("avg", models.AggregationAvg, testMetricType, "251"),
+			Entry("max", models.AggregationMax, testMetricType+":max", "401"),
+			Entry("min", models.AggregationMin, testMetricType+":min", "100"),
+			Entry("sum", models.AggregationSum, testMetricType+":sum", "1001"),
+			Entry("median", models.AggregationMedian, testMetricType+":median", "250"),
+			Entry("p95", models.AggregationP95, testMetricType+":p95", "401"),
+		)
+
+		It("sends the number of distinct instances reporting the metric for count to appMetric channel", func() {
+			appMonitor.Aggregation = models.AggregationCount
+			metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan, counterCollector)
+			metricPoller.Start()
+			Expect(appMonitorsChan).Should(BeSent(appMonitor))
+
+			appMetric := <-appMetricChan
+			Expect(appMetric.MetricType).To(Equal(testMetricType + ":count"))
+			Expect(appMetric.Value).To(Equal("2"))
+			Expect(appMetric.Unit).To(BeEmpty())
+		})
+	})
+
 	Context("Stop", func() {
 		BeforeEach(func() {
 			metricServer = ghttp.NewServer()
 			metricServer.RouteToHandler("GET", urlPath, ghttp.RespondWithJSONEncoded(http.StatusOK,
 				&metrics))
 
-			metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan)
+			metricPoller = NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan, counterCollector)
 			metricPoller.Start()
 			metricPoller.Stop()
 			Eventually(logger.Buffer).Should(Say("stopped"))
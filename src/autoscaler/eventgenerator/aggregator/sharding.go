@@ -0,0 +1,133 @@
+package aggregator
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// Sharding decides which apps an eventgenerator node is responsible for.
+type Sharding interface {
+	IsResponsibleFor(appID string) bool
+}
+
+// StaticSharding splits the apps across a fixed number of nodes.
+type StaticSharding struct {
+	NodeNum   int
+	NodeIndex int
+}
+
+func (s StaticSharding) IsResponsibleFor(appID string) bool {
+	return s.NodeNum <= 1 || helpers.FNVHash(appID)%uint32(s.NodeNum) == uint32(s.NodeIndex)
+}
+
+// Membership spreads the apps across the live eventgenerator nodes with a consistent hash ring. Every node records a
+// heartbeat in the AppMetricDB and considers the nodes with a heartbeat within the member ttl as members, so the apps of
+// a node which dies move to the others once its heartbeat expires, and the apps of a joining node move to it.
+//
+// If the members cannot be retrieved, the last known members are kept. Until they have been retrieved once, the node is
+// responsible for no app.
+type Membership struct {
+	logger            lager.Logger
+	clock             clock.Clock
+	nodeID            string
+	heartbeatInterval time.Duration
+	memberTTL         time.Duration
+	appMetricDB       db.AppMetricDB
+	members           []string
+	ring              *helpers.HashRing
+	lock              sync.RWMutex
+	doneChan          chan bool
+}
+
+func NewMembership(logger lager.Logger, clock clock.Clock, nodeID string, heartbeatInterval time.Duration, memberTTL time.Duration, appMetricDB db.AppMetricDB) *Membership {
+	return &Membership{
+		logger:            logger.Session("Membership"),
+		clock:             clock,
+		nodeID:            nodeID,
+		heartbeatInterval: heartbeatInterval,
+		memberTTL:         memberTTL,
+		appMetricDB:       appMetricDB,
+		ring:              helpers.NewHashRing(nil, helpers.DefaultHashRingVirtualNodes),
+		doneChan:          make(chan bool),
+	}
+}
+
+// Start records the first heartbeat and retrieves the members before it returns, so that the apps are spread right
+// from the start.
+func (m *Membership) Start() {
+	m.refresh()
+	go m.startHeartbeat()
+	m.logger.Info("started", lager.Data{"nodeID": m.nodeID, "heartbeatInterval": m.heartbeatInterval, "memberTTL": m.memberTTL})
+}
+
+// Stop removes the node from the members, so that the others take over its apps with their next heartbeat.
+func (m *Membership) Stop() {
+	close(m.doneChan)
+	err := m.appMetricDB.DeleteEventGeneratorMember(m.nodeID)
+	if err != nil {
+		m.logger.Error("failed-to-leave", err)
+	}
+	m.logger.Info("stopped")
+}
+
+func (m *Membership) startHeartbeat() {
+	tick := m.clock.NewTicker(m.heartbeatInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-m.doneChan:
+			return
+		case <-tick.C():
+			m.refresh()
+		}
+	}
+}
+
+func (m *Membership) refresh() {
+	now := m.clock.Now()
+	err := m.appMetricDB.SaveEventGeneratorHeartbeat(m.nodeID, now.UnixNano())
+	if err != nil {
+		m.logger.Error("failed-to-save-heartbeat", err)
+		return
+	}
+
+	members, err := m.appMetricDB.RetrieveEventGeneratorMembers(now.Add(-m.memberTTL).UnixNano())
+	if err != nil {
+		m.logger.Error("failed-to-retrieve-members", err)
+		return
+	}
+	if !slices.Contains(members, m.nodeID) {
+		members = append(members, m.nodeID)
+		slices.Sort(members)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !slices.Equal(members, m.members) {
+		m.logger.Info("members-changed", lager.Data{"members": members})
+		m.members = members
+		m.ring = helpers.NewHashRing(members, helpers.DefaultHashRingVirtualNodes)
+	}
+}
+
+// GetMembers returns the ids of the nodes the apps are currently spread across.
+func (m *Membership) GetMembers() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return append([]string{}, m.members...)
+}
+
+func (m *Membership) IsResponsibleFor(appID string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.ring.Get(appID) == m.nodeID
+}
@@ -0,0 +1,153 @@
+package aggregator_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Sharding", func() {
+	appIds := make([]string, 300)
+	for i := range appIds {
+		appIds[i] = fmt.Sprintf("app-id-%d", i)
+	}
+
+	responsibleApps := func(sharding Sharding) []string {
+		apps := []string{}
+		for _, appId := range appIds {
+			if sharding.IsResponsibleFor(appId) {
+				apps = append(apps, appId)
+			}
+		}
+		return apps
+	}
+
+	Describe("StaticSharding", func() {
+		It("is responsible for all apps when running with 1 node", func() {
+			Expect(responsibleApps(StaticSharding{NodeNum: 1, NodeIndex: 0})).To(Equal(appIds))
+		})
+
+		It("splits the apps across the nodes", func() {
+			apps := append(responsibleApps(StaticSharding{NodeNum: 2, NodeIndex: 0}), responsibleApps(StaticSharding{NodeNum: 2, NodeIndex: 1})...)
+			Expect(apps).To(ConsistOf(appIds))
+		})
+	})
+
+	Describe("Membership", func() {
+		const heartbeatInterval = 10 * time.Second
+		const memberTTL = 30 * time.Second
+
+		var (
+			appMetricDB *fakes.FakeAppMetricDB
+			clock       *fakeclock.FakeClock
+			logger      *lagertest.TestLogger
+			membership  *Membership
+		)
+
+		BeforeEach(func() {
+			appMetricDB = &fakes.FakeAppMetricDB{}
+			appMetricDB.RetrieveEventGeneratorMembersReturns([]string{"node-a", "node-b"}, nil)
+			clock = fakeclock.NewFakeClock(time.Now())
+			logger = lagertest.NewTestLogger("membership-test")
+		})
+
+		JustBeforeEach(func() {
+			membership = NewMembership(logger, clock, "node-a", heartbeatInterval, memberTTL, appMetricDB)
+			membership.Start()
+		})
+
+		AfterEach(func() {
+			if membership != nil {
+				membership.Stop()
+			}
+		})
+
+		It("records a heartbeat and retrieves the live members on start", func() {
+			Expect(appMetricDB.SaveEventGeneratorHeartbeatCallCount()).To(Equal(1))
+			nodeId, heartbeatAt := appMetricDB.SaveEventGeneratorHeartbeatArgsForCall(0)
+			Expect(nodeId).To(Equal("node-a"))
+			Expect(heartbeatAt).To(Equal(clock.Now().UnixNano()))
+			Expect(appMetricDB.RetrieveEventGeneratorMembersArgsForCall(0)).To(Equal(clock.Now().Add(-memberTTL).UnixNano()))
+			Expect(membership.GetMembers()).To(Equal([]string{"node-a", "node-b"}))
+		})
+
+		It("records a heartbeat every heartbeat interval", func() {
+			clock.WaitForWatcherAndIncrement(heartbeatInterval)
+			Eventually(appMetricDB.SaveEventGeneratorHeartbeatCallCount).Should(Equal(2))
+			clock.WaitForWatcherAndIncrement(heartbeatInterval)
+			Eventually(appMetricDB.SaveEventGeneratorHeartbeatCallCount).Should(Equal(3))
+		})
+
+		It("splits the apps with the other members", func() {
+			apps := responsibleApps(membership)
+			Expect(apps).NotTo(BeEmpty())
+			Expect(len(apps)).To(BeNumerically("<", len(appIds)))
+		})
+
+		It("takes over the apps of a member which left", func() {
+			apps := responsibleApps(membership)
+
+			appMetricDB.RetrieveEventGeneratorMembersReturns([]string{"node-a"}, nil)
+			clock.WaitForWatcherAndIncrement(heartbeatInterval)
+			Eventually(membership.GetMembers).Should(Equal([]string{"node-a"}))
+			Expect(responsibleApps(membership)).To(Equal(appIds))
+			Eventually(logger.Buffer()).Should(gbytes.Say("members-changed"))
+
+			appMetricDB.RetrieveEventGeneratorMembersReturns([]string{"node-a", "node-b"}, nil)
+			clock.WaitForWatcherAndIncrement(heartbeatInterval)
+			Eventually(membership.GetMembers).Should(HaveLen(2))
+			Expect(responsibleApps(membership)).To(Equal(apps))
+		})
+
+		Context("when the node is missing from the retrieved members", func() {
+			BeforeEach(func() {
+				appMetricDB.RetrieveEventGeneratorMembersReturns([]string{"node-b", "node-c"}, nil)
+			})
+
+			It("adds itself to the members", func() {
+				Expect(membership.GetMembers()).To(Equal([]string{"node-a", "node-b", "node-c"}))
+			})
+		})
+
+		Context("when retrieving the members fails", func() {
+			BeforeEach(func() {
+				appMetricDB.RetrieveEventGeneratorMembersReturns(nil, errors.New("an error"))
+			})
+
+			It("is responsible for no app", func() {
+				Expect(logger.Buffer()).To(gbytes.Say("failed-to-retrieve-members"))
+				Expect(responsibleApps(membership)).To(BeEmpty())
+			})
+
+			It("keeps the last known members", func() {
+				appMetricDB.RetrieveEventGeneratorMembersReturns([]string{"node-a"}, nil)
+				clock.WaitForWatcherAndIncrement(heartbeatInterval)
+				Eventually(membership.GetMembers).Should(Equal([]string{"node-a"}))
+
+				appMetricDB.RetrieveEventGeneratorMembersReturns(nil, errors.New("an error"))
+				clock.WaitForWatcherAndIncrement(heartbeatInterval)
+				Eventually(appMetricDB.RetrieveEventGeneratorMembersCallCount).Should(Equal(3))
+				Expect(membership.GetMembers()).To(Equal([]string{"node-a"}))
+				Expect(responsibleApps(membership)).To(Equal(appIds))
+			})
+		})
+
+		Context("Stop", func() {
+			It("removes the node from the members", func() {
+				membership.Stop()
+				Expect(appMetricDB.DeleteEventGeneratorMemberCallCount()).To(Equal(1))
+				Expect(appMetricDB.DeleteEventGeneratorMemberArgsForCall(0)).To(Equal("node-a"))
+				membership = nil
+			})
+		})
+	})
+})
@@ -181,14 +181,19 @@ func logCacheFiltersFor(endTime time.Time, metricType string) (readOptions []log
 	readOptions = append(readOptions, logcache.WithEndTime(endTime))
 	readOptions = append(readOptions, logcache.WithEnvelopeTypes(logMetricType))
 
-	switch metricType {
-	case models.MetricNameMemoryUtil:
+	switch {
+	case metricType == models.MetricNameMemoryUtil:
 		readOptions = append(readOptions, logcache.WithNameFilter("memory|memory_quota"))
-	case models.MetricNameMemoryUsed:
+	case metricType == models.MetricNameMemoryUsed:
 		readOptions = append(readOptions, logcache.WithNameFilter("memory"))
-	case models.MetricNameCPUUtil:
+	case metricType == models.MetricNameCPUUtil:
 		readOptions = append(readOptions, logcache.WithNameFilter("cpu"))
-	case models.MetricNameResponseTime, models.MetricNameThroughput:
+	case metricType == models.MetricNameDiskUtil || metricType == models.MetricNameDiskUsed:
+		// disk envelopes are only recognised as container metrics together with their disk_quota
+		readOptions = append(readOptions, logcache.WithNameFilter("disk|disk_quota"))
+	case metricType == models.MetricNameCPUEntitlement:
+		readOptions = append(readOptions, logcache.WithNameFilter("cpu_entitlement"))
+	case models.IsHttpMetric(metricType):
 		readOptions = append(readOptions, logcache.WithNameFilter("http"))
 	default:
 		readOptions = append(readOptions, logcache.WithNameFilter(metricType))
@@ -198,14 +203,10 @@ func logCacheFiltersFor(endTime time.Time, metricType string) (readOptions []log
 }
 
 func getEnvelopeType(metricType string) rpc.EnvelopeType {
-	var metricName rpc.EnvelopeType
-	switch metricType {
-	case models.MetricNameThroughput, models.MetricNameResponseTime:
-		metricName = rpc.EnvelopeType_TIMER
-	default:
-		metricName = rpc.EnvelopeType_GAUGE
+	if models.IsHttpMetric(metricType) {
+		return rpc.EnvelopeType_TIMER
 	}
-	return metricName
+	return rpc.EnvelopeType_GAUGE
 }
 
 func (c *LogCacheClient) getUaaHttpClient() logcache.HTTPClient {
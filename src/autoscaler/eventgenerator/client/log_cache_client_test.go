@@ -271,6 +271,10 @@ var _ = Describe("LogCacheClient", func() {
 			},
 			Entry("When metric type is MetricNameThroughput", models.MetricNameThroughput, []string{"endtime", "envelope_type", "http"}),
 			Entry("When metric type is MetricNameResponseTime", models.MetricNameResponseTime, []string{"endtime", "envelope_type", "http"}),
+			Entry("When metric type is MetricNameResponseTimeP90", models.MetricNameResponseTimeP90, []string{"endtime", "envelope_type", "http"}),
+			Entry("When metric type is MetricNameResponseTimeP95", models.MetricNameResponseTimeP95, []string{"endtime", "envelope_type", "http"}),
+			Entry("When metric type is MetricNameResponseTimeP99", models.MetricNameResponseTimeP99, []string{"endtime", "envelope_type", "http"}),
+			Entry("When metric type is MetricNameErrorRate", models.MetricNameErrorRate, []string{"endtime", "envelope_type", "http"}),
 		)
 
 		DescribeTable("GetMetrics for Gauge Metrics",
@@ -310,6 +314,9 @@ var _ = Describe("LogCacheClient", func() {
 			Entry("When metric type is MetricNameMemoryUtil", models.MetricNameMemoryUtil, []string{"endtime", "envelope_type", "memory|memory_quota"}),
 			Entry("When metric type is MetricNameMemoryUsed", models.MetricNameMemoryUsed, []string{"endtime", "envelope_type", "memory"}),
 			Entry("When metric type is MetricNameCPUUtil", models.MetricNameCPUUtil, []string{"endtime", "envelope_type", "cpu"}),
+			Entry("When metric type is MetricNameDiskUtil", models.MetricNameDiskUtil, []string{"endtime", "envelope_type", "disk|disk_quota"}),
+			Entry("When metric type is MetricNameDiskUsed", models.MetricNameDiskUsed, []string{"endtime", "envelope_type", "disk|disk_quota"}),
+			Entry("When metric type is MetricNameCPUEntitlement", models.MetricNameCPUEntitlement, []string{"endtime", "envelope_type", "cpu_entitlement"}),
 			Entry("When metric type is CustomMetrics", "a-custom-metric", []string{"endtime", "envelope_type", "a-custom-metric"}),
 		)
 
@@ -4,6 +4,7 @@ import (
 	"io"
 	"time"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db/sqldb"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/client"
@@ -54,24 +55,34 @@ func main() {
 	defer func() { _ = policyDb.Close() }()
 
 	httpStatusCollector := healthendpoint.NewHTTPStatusCollector("autoscaler", "eventgenerator")
+	staleAppMetricCounterCollector := healthendpoint.NewCounterCollector()
+	evaluationMetrics := generator.NewEvaluationMetrics()
 	promRegistry := prometheus.NewRegistry()
-	healthendpoint.RegisterCollectors(promRegistry, []prometheus.Collector{
+	healthendpoint.RegisterCollectors(promRegistry, append([]prometheus.Collector{
 		healthendpoint.NewDatabaseStatusCollector("autoscaler", "eventgenerator", "appMetricDB", appMetricDB),
 		healthendpoint.NewDatabaseStatusCollector("autoscaler", "eventgenerator", "policyDB", policyDb),
 		httpStatusCollector,
-	}, true, logger.Session("eventgenerator-prometheus"))
+		staleAppMetricCounterCollector,
+	}, evaluationMetrics.Collectors()...), true, logger.Session("eventgenerator-prometheus"))
 
-	appManager := aggregator.NewAppManager(logger, egClock, conf.Aggregator.PolicyPollerInterval, len(conf.Server.NodeAddrs), conf.Server.NodeIndex, conf.Aggregator.MetricCacheSizePerApp, policyDb, appMetricDB)
+	var sharding aggregator.Sharding = aggregator.StaticSharding{NodeNum: len(conf.Server.NodeAddrs), NodeIndex: conf.Server.NodeIndex}
+	var membership *aggregator.Membership
+	if conf.Membership.Enabled {
+		membership = aggregator.NewMembership(logger, egClock, conf.Server.NodeAddrs[conf.Server.NodeIndex], conf.Membership.HeartbeatInterval, conf.Membership.MemberTTL, appMetricDB)
+		sharding = membership
+	}
+
+	appManager := aggregator.NewAppManager(logger, egClock, conf.Aggregator.PolicyPollerInterval, sharding, conf.Aggregator.MetricCacheSizePerApp, conf.DefaultBreachDurationSecs, policyDb, appMetricDB)
 
-	triggersChan := make(chan []*models.Trigger, conf.Evaluator.TriggerArrayChannelSize)
+	evaluationQueue := generator.NewEvaluationQueue(egClock, conf.Evaluator.EvaluatorCount, conf.Evaluator.TriggerArrayChannelSize, conf.Evaluator.EvaluationDeadline, evaluationMetrics)
 
-	evaluationManager, err := generator.NewAppEvaluationManager(logger, conf.Evaluator.EvaluationManagerInterval, egClock, triggersChan, appManager.GetPolicies, conf.CircuitBreaker)
+	evaluationManager, err := generator.NewAppEvaluationManager(logger, conf.Evaluator.EvaluationManagerInterval, egClock, evaluationQueue.Enqueue, appManager.GetPolicies, conf.CircuitBreaker, appMetricDB)
 	if err != nil {
 		logger.Error("failed to create Evaluation Manager", err)
 		os.Exit(1)
 	}
 
-	evaluators, err := createEvaluators(logger, conf, triggersChan, appManager.QueryAppMetrics, evaluationManager.GetBreaker, evaluationManager.SetCoolDownExpired)
+	evaluators, err := createEvaluators(logger, conf, evaluationQueue, appManager.QueryAppMetrics, evaluationManager.GetBreaker, evaluationManager.SetCoolDownExpired)
 	if err != nil {
 		logger.Error("failed to create Evaluators", err)
 		os.Exit(1)
@@ -80,7 +91,7 @@ func main() {
 	appMonitorsChan := make(chan *models.AppMonitor, conf.Aggregator.AppMonitorChannelSize)
 	appMetricChan := make(chan *models.AppMetric, conf.Aggregator.AppMetricChannelSize)
 	metricClient := client.NewMetricClientFactory().GetMetricClient(logger, conf)
-	metricPollers, err := createMetricPollers(logger, conf, appMonitorsChan, appMetricChan, metricClient)
+	metricPollers, err := createMetricPollers(logger, conf, appMonitorsChan, appMetricChan, metricClient, staleAppMetricCounterCollector)
 	if err != nil {
 		logger.Error("failed to create MetricPoller", err)
 		os.Exit(1)
@@ -91,9 +102,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	eventGenerator := ifrit.RunFunc(runFunc(appManager, evaluators, evaluationManager, metricPollers, anAggregator))
+	eventGenerator := ifrit.RunFunc(runFunc(membership, appManager, evaluators, evaluationManager, metricPollers, anAggregator))
+
+	backtester := generator.NewBacktester(logger, appManager.QueryAppMetrics, conf.Evaluator.EvaluationManagerInterval, conf.DefaultBreachDurationSecs)
 
-	httpServer, err := server.NewServer(logger.Session("http_server"), conf, appManager.QueryAppMetrics, httpStatusCollector)
+	httpServer, err := server.NewServer(logger.Session("http_server"), conf, appManager.QueryAppMetrics, backtester.Backtest, evaluationManager.GetAppEvaluationState, httpStatusCollector)
 	if err != nil {
 		logger.Error("failed to create http server", err)
 		os.Exit(1)
@@ -121,8 +134,11 @@ func main() {
 	logger.Info("exited")
 }
 
-func runFunc(appManager *aggregator.AppManager, evaluators []*generator.Evaluator, evaluationManager *generator.AppEvaluationManager, metricPollers []*aggregator.MetricPoller, anAggregator *aggregator.Aggregator) func(signals <-chan os.Signal, ready chan<- struct{}) error {
+func runFunc(membership *aggregator.Membership, appManager *aggregator.AppManager, evaluators []*generator.Evaluator, evaluationManager *generator.AppEvaluationManager, metricPollers []*aggregator.MetricPoller, anAggregator *aggregator.Aggregator) func(signals <-chan os.Signal, ready chan<- struct{}) error {
 	return func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		if membership != nil {
+			membership.Start()
+		}
 		appManager.Start()
 
 		for _, evaluator := range evaluators {
@@ -141,6 +157,9 @@ func runFunc(appManager *aggregator.AppManager, evaluators []*generator.Evaluato
 		anAggregator.Stop()
 		evaluationManager.Stop()
 		appManager.Stop()
+		if membership != nil {
+			membership.Stop()
+		}
 
 		return nil
 	}
@@ -169,7 +188,7 @@ func loadConfig(path string) (*config.Config, error) {
 	return conf, nil
 }
 
-func createEvaluators(logger lager.Logger, conf *config.Config, triggersChan chan []*models.Trigger, queryMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, int64)) ([]*generator.Evaluator, error) {
+func createEvaluators(logger lager.Logger, conf *config.Config, evaluationQueue *generator.EvaluationQueue, queryMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, string, string, int64)) ([]*generator.Evaluator, error) {
 	count := conf.Evaluator.EvaluatorCount
 
 	aClient, err := helpers.CreateHTTPClient(&conf.ScalingEngine.TLSClientCerts, helpers.DefaultClientConfig(), logger.Session("scaling_client"))
@@ -178,19 +197,25 @@ func createEvaluators(logger lager.Logger, conf *config.Config, triggersChan cha
 		os.Exit(1)
 	}
 
+	appLog, err := applog.NewEmitter(logger, conf.AppLog, "eventgenerator")
+	if err != nil {
+		logger.Error("failed to create app log emitter", err)
+		os.Exit(1)
+	}
+
 	evaluators := make([]*generator.Evaluator, count)
 	for i := 0; i < count; i++ {
-		evaluators[i] = generator.NewEvaluator(logger, aClient, conf.ScalingEngine.ScalingEngineURL, triggersChan,
-			conf.DefaultBreachDurationSecs, queryMetrics, getBreaker, setCoolDownExpired)
+		evaluators[i] = generator.NewEvaluator(logger, aClient, conf.ScalingEngine.ScalingEngineURL, evaluationQueue, i,
+			conf.DefaultBreachDurationSecs, queryMetrics, getBreaker, setCoolDownExpired, appLog)
 	}
 
 	return evaluators, nil
 }
 
-func createMetricPollers(logger lager.Logger, conf *config.Config, appMonitorsChan chan *models.AppMonitor, appMetricChan chan *models.AppMetric, metricClient client.MetricClient) ([]*aggregator.MetricPoller, error) {
+func createMetricPollers(logger lager.Logger, conf *config.Config, appMonitorsChan chan *models.AppMonitor, appMetricChan chan *models.AppMetric, metricClient client.MetricClient, counterCollector healthendpoint.CounterCollector) ([]*aggregator.MetricPoller, error) {
 	pollers := make([]*aggregator.MetricPoller, conf.Aggregator.MetricPollerCount)
 	for i := 0; i < len(pollers); i++ {
-		pollers[i] = aggregator.NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan)
+		pollers[i] = aggregator.NewMetricPoller(logger, metricClient, appMonitorsChan, appMetricChan, counterCollector)
 	}
 	return pollers, nil
 }
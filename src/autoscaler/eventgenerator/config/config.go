@@ -8,6 +8,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
@@ -31,6 +32,8 @@ const (
 	DefaultBreakerConsecutiveFailureCount int64  = 3
 	DefaultHttpClientTimeout                     = 5 * time.Second
 	DefaultMetricCacheSizePerApp                 = 100
+	DefaultHeartbeatInterval                     = 10 * time.Second
+	DefaultMemberTTL                             = 30 * time.Second
 )
 
 type ServerConfig struct {
@@ -57,6 +60,7 @@ type EvaluatorConfig struct {
 	EvaluatorCount            int           `yaml:"evaluator_count"`
 	TriggerArrayChannelSize   int           `yaml:"trigger_array_channel_size"`
 	EvaluationManagerInterval time.Duration `yaml:"evaluation_manager_execute_interval"`
+	EvaluationDeadline        time.Duration `yaml:"evaluation_deadline"`
 }
 
 type ScalingEngineConfig struct {
@@ -76,6 +80,15 @@ type CircuitBreakerConfig struct {
 	BackOffMaxInterval      time.Duration `yaml:"back_off_max_interval"`
 	ConsecutiveFailureCount int64         `yaml:"consecutive_failure_count"`
 }
+
+// MembershipConfig enables spreading the apps across the live eventgenerator nodes instead of the node_addrs. The
+// node address at the node index is the id of the node and must be unique.
+type MembershipConfig struct {
+	Enabled           bool          `yaml:"enabled"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	MemberTTL         time.Duration `yaml:"member_ttl"`
+}
+
 type Config struct {
 	Logging                   helpers.LoggingConfig `yaml:"logging"`
 	Server                    ServerConfig          `yaml:"server"`
@@ -89,6 +102,8 @@ type Config struct {
 	DefaultBreachDurationSecs int                   `yaml:"defaultBreachDurationSecs"`
 	CircuitBreaker            CircuitBreakerConfig  `yaml:"circuitBreaker"`
 	HttpClientTimeout         time.Duration         `yaml:"http_client_timeout"`
+	AppLog                    applog.Config         `yaml:"app_log"`
+	Membership                MembershipConfig      `yaml:"membership"`
 }
 
 func LoadConfig(config []byte) (*Config, error) {
@@ -121,6 +136,13 @@ func LoadConfig(config []byte) (*Config, error) {
 			TriggerArrayChannelSize:   DefaultTriggerArrayChannelSize,
 		},
 		HttpClientTimeout: DefaultHttpClientTimeout,
+		AppLog: applog.Config{
+			MetronAddress: applog.DefaultMetronAddress,
+		},
+		Membership: MembershipConfig{
+			HeartbeatInterval: DefaultHeartbeatInterval,
+			MemberTTL:         DefaultMemberTTL,
+		},
 	}
 	dec := yaml.NewDecoder(bytes.NewBuffer(config))
 	dec.KnownFields(true)
@@ -131,6 +153,9 @@ func LoadConfig(config []byte) (*Config, error) {
 	}
 
 	conf.Logging.Level = strings.ToLower(conf.Logging.Level)
+	if conf.Evaluator.EvaluationDeadline == 0 {
+		conf.Evaluator.EvaluationDeadline = conf.Evaluator.EvaluationManagerInterval
+	}
 	if conf.CircuitBreaker.ConsecutiveFailureCount == 0 {
 		conf.CircuitBreaker.ConsecutiveFailureCount = DefaultBreakerConsecutiveFailureCount
 	}
@@ -188,6 +213,9 @@ func (c *Config) Validate() error {
 	if c.Evaluator.TriggerArrayChannelSize <= 0 {
 		return fmt.Errorf("Configuration error: evaluator.trigger_array_channel_size is less-equal than 0")
 	}
+	if c.Evaluator.EvaluationDeadline <= time.Duration(0) {
+		return fmt.Errorf("Configuration error: evaluator.evaluation_deadline is less-equal than 0")
+	}
 	if c.DefaultBreachDurationSecs < 60 || c.DefaultBreachDurationSecs > 3600 {
 		return fmt.Errorf("Configuration error: defaultBreachDurationSecs should be between 60 and 3600")
 	}
@@ -199,10 +227,23 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("Configuration error: server.node_index out of range")
 	}
 
+	if c.Membership.Enabled {
+		if c.Membership.HeartbeatInterval <= time.Duration(0) {
+			return fmt.Errorf("Configuration error: membership.heartbeat_interval is less-equal than 0")
+		}
+		if c.Membership.MemberTTL <= c.Membership.HeartbeatInterval {
+			return fmt.Errorf("Configuration error: membership.member_ttl is less-equal than membership.heartbeat_interval")
+		}
+	}
+
 	if c.HttpClientTimeout <= time.Duration(0) {
 		return fmt.Errorf("Configuration error: http_client_timeout is less-equal than 0")
 	}
 
+	if err := c.AppLog.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Health.Validate(); err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package config_test
 import (
 	"time"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/config"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
@@ -66,6 +67,7 @@ evaluator:
   evaluation_manager_execute_interval: 30s
   evaluator_count: 10
   trigger_array_channel_size: 100
+  evaluation_deadline: 20s
 scalingEngine:
   scaling_engine_url: http://localhost:8082
   tls:
@@ -85,6 +87,17 @@ circuitBreaker:
   back_off_initial_interval: 10s
   back_off_max_interval: 60m
   consecutive_failure_count: 5
+app_log:
+  enabled: true
+  metron_address: 127.0.0.1:3459
+  tls:
+    key_file: /var/vcap/jobs/autoscaler/config/certs/metron.key
+    cert_file: /var/vcap/jobs/autoscaler/config/certs/metron.crt
+    ca_file: /var/vcap/jobs/autoscaler/config/certs/loggregator-ca.crt
+membership:
+  enabled: true
+  heartbeat_interval: 5s
+  member_ttl: 20s
 `)
 			})
 
@@ -136,7 +149,8 @@ circuitBreaker:
 					Evaluator: EvaluatorConfig{
 						EvaluationManagerInterval: 30 * time.Second,
 						EvaluatorCount:            10,
-						TriggerArrayChannelSize:   100},
+						TriggerArrayChannelSize:   100,
+						EvaluationDeadline:        20 * time.Second},
 					ScalingEngine: ScalingEngineConfig{
 						ScalingEngineURL: "http://localhost:8082",
 						TLSClientCerts: models.TLSCerts{
@@ -160,6 +174,20 @@ circuitBreaker:
 						BackOffMaxInterval:      1 * time.Hour,
 						ConsecutiveFailureCount: 5,
 					},
+					AppLog: applog.Config{
+						Enabled:       true,
+						MetronAddress: "127.0.0.1:3459",
+						TLS: models.TLSCerts{
+							KeyFile:    "/var/vcap/jobs/autoscaler/config/certs/metron.key",
+							CertFile:   "/var/vcap/jobs/autoscaler/config/certs/metron.crt",
+							CACertFile: "/var/vcap/jobs/autoscaler/config/certs/loggregator-ca.crt",
+						},
+					},
+					Membership: MembershipConfig{
+						Enabled:           true,
+						HeartbeatInterval: 5 * time.Second,
+						MemberTTL:         20 * time.Second,
+					},
 				}))
 			})
 		})
@@ -266,6 +294,7 @@ defaultBreachDurationSecs: 600
 						EvaluationManagerInterval: DefaultEvaluationExecuteInterval,
 						EvaluatorCount:            DefaultEvaluatorCount,
 						TriggerArrayChannelSize:   DefaultTriggerArrayChannelSize,
+						EvaluationDeadline:        DefaultEvaluationExecuteInterval,
 					},
 					ScalingEngine: ScalingEngineConfig{
 						ScalingEngineURL: "http://localhost:8082"},
@@ -279,6 +308,13 @@ defaultBreachDurationSecs: 600
 						BackOffMaxInterval:      DefaultBackOffMaxInterval,
 						ConsecutiveFailureCount: DefaultBreakerConsecutiveFailureCount,
 					},
+					AppLog: applog.Config{
+						MetronAddress: applog.DefaultMetronAddress,
+					},
+					Membership: MembershipConfig{
+						HeartbeatInterval: DefaultHeartbeatInterval,
+						MemberTTL:         DefaultMemberTTL,
+					},
 				}))
 			})
 		})
@@ -1106,7 +1142,8 @@ health:
 				Evaluator: EvaluatorConfig{
 					EvaluationManagerInterval: 30 * time.Second,
 					EvaluatorCount:            10,
-					TriggerArrayChannelSize:   100},
+					TriggerArrayChannelSize:   100,
+					EvaluationDeadline:        30 * time.Second},
 				ScalingEngine: ScalingEngineConfig{
 					ScalingEngineURL: "http://localhost:8082"},
 				MetricCollector: MetricCollectorConfig{
@@ -1254,6 +1291,15 @@ health:
 			})
 		})
 
+		Context("when EvaluationDeadline <= 0", func() {
+			BeforeEach(func() {
+				conf.Evaluator.EvaluationDeadline = 0
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: evaluator.evaluation_deadline is less-equal than 0"))
+			})
+		})
+
 		Context("when DefaultBreachDurationSecs < 60", func() {
 			BeforeEach(func() {
 				conf.DefaultBreachDurationSecs = 10
@@ -1321,5 +1367,32 @@ health:
 			})
 		})
 
+		Context("when the app log is enabled and app_log.metron_address is empty", func() {
+			BeforeEach(func() {
+				conf.AppLog = applog.Config{Enabled: true}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: app_log.metron_address is empty"))
+			})
+		})
+
+		Context("when the membership is enabled and membership.heartbeat_interval is <= 0", func() {
+			BeforeEach(func() {
+				conf.Membership = MembershipConfig{Enabled: true, MemberTTL: 30 * time.Second}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: membership.heartbeat_interval is less-equal than 0"))
+			})
+		})
+
+		Context("when the membership is enabled and membership.member_ttl is <= membership.heartbeat_interval", func() {
+			BeforeEach(func() {
+				conf.Membership = MembershipConfig{Enabled: true, HeartbeatInterval: 30 * time.Second, MemberTTL: 30 * time.Second}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: membership.member_ttl is less-equal than membership.heartbeat_interval"))
+			})
+		})
+
 	})
 })
@@ -1,9 +1,11 @@
 package generator
 
 import (
+	"sort"
 	"sync"
 	"time"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/config"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
@@ -15,35 +17,44 @@ import (
 )
 
 type ConsumeAppMonitorMap func(map[string][]*models.Trigger, chan []*models.Trigger)
+type EnqueueTriggersFunc func(triggers []*models.Trigger) bool
+type GetAppEvaluationStateFunc func(appID string) (*models.AppEvaluationState, bool)
 
 type AppEvaluationManager struct {
 	evaluateInterval time.Duration
 	logger           lager.Logger
 	emClock          clock.Clock
 	doneChan         chan bool
-	triggerChan      chan []*models.Trigger
+	enqueueTriggers  EnqueueTriggersFunc
 	getPolicies      aggregator.GetPoliciesFunc
 	breakerConfig    config.CircuitBreakerConfig
 	breakers         map[string]*circuit.Breaker
-	cooldownExpired  map[string]int64
+	breakerStates    map[string]models.BreakerState
+	cooldownExpired  map[string]map[string]map[string]int64
 	breakerLock      *sync.RWMutex
 	cooldownLock     *sync.RWMutex
+	appMetricDB      db.AppMetricDB
 }
 
+// NewAppEvaluationManager creates an AppEvaluationManager which persists the cooldowns and the circuit breaker states
+// of the apps in the AppMetricDB. It loads them when it first evaluates an app, e.g. after a restart or when the app
+// moved from another eventgenerator node.
 func NewAppEvaluationManager(logger lager.Logger, evaluateInterval time.Duration, emClock clock.Clock,
-	triggerChan chan []*models.Trigger, getPolicies aggregator.GetPoliciesFunc,
-	breakerConfig config.CircuitBreakerConfig) (*AppEvaluationManager, error) {
+	enqueueTriggers EnqueueTriggersFunc, getPolicies aggregator.GetPoliciesFunc,
+	breakerConfig config.CircuitBreakerConfig, appMetricDB db.AppMetricDB) (*AppEvaluationManager, error) {
 	return &AppEvaluationManager{
 		evaluateInterval: evaluateInterval,
 		logger:           logger.Session("AppEvaluationManager"),
 		emClock:          emClock,
 		doneChan:         make(chan bool),
-		triggerChan:      triggerChan,
+		enqueueTriggers:  enqueueTriggers,
 		getPolicies:      getPolicies,
 		breakerConfig:    breakerConfig,
-		cooldownExpired:  map[string]int64{},
+		breakerStates:    map[string]models.BreakerState{},
+		cooldownExpired:  map[string]map[string]map[string]int64{},
 		breakerLock:      &sync.RWMutex{},
 		cooldownLock:     &sync.RWMutex{},
+		appMetricDB:      appMetricDB,
 	}, nil
 }
 
@@ -53,32 +64,99 @@ func (a *AppEvaluationManager) getTriggers(policyMap map[string]*models.AppPolic
 	}
 	triggersByApp := make(map[string][]*models.Trigger)
 	for appID, policy := range policyMap {
-		now := a.emClock.Now().UnixNano()
-		a.cooldownLock.RLock()
-		cooldownExpiredAt, found := a.cooldownExpired[appID]
-		a.cooldownLock.RUnlock()
-		if found {
-			if cooldownExpiredAt > now {
-				continue
-			}
-		}
-		triggers := []*models.Trigger{}
-		for _, rule := range policy.ScalingPolicy.ScalingRules {
-			triggers = append(triggers, &models.Trigger{
-				AppId:                 appID,
-				MetricType:            rule.MetricType,
-				BreachDurationSeconds: rule.BreachDurationSeconds,
-				CoolDownSeconds:       rule.CoolDownSeconds,
-				Threshold:             rule.Threshold,
-				Operator:              rule.Operator,
-				Adjustment:            rule.Adjustment,
-			})
+		triggers := a.withoutCoolingDown(appID, buildTriggers(appID, policy.ScalingPolicy))
+		if len(triggers) == 0 {
+			continue
 		}
 		triggersByApp[appID] = triggers
 	}
 	return triggersByApp
 }
 
+// buildTriggers creates one trigger per dynamic scaling rule of the policy.
+func buildTriggers(appID string, policy *models.ScalingPolicy) []*models.Trigger {
+	triggers := []*models.Trigger{}
+	for _, rule := range policy.ScalingRules {
+		triggers = append(triggers, &models.Trigger{
+			AppId:                             appID,
+			ProcessType:                       policy.GetProcessType(rule.ProcessType),
+			Shadow:                            policy.IsShadow(),
+			ScaleInStabilizationWindowSeconds: policy.ScaleInStabilizationWindowSeconds,
+			MetricType:                        models.AggregatedMetricType(rule.MetricType, rule.Aggregation),
+			BreachDurationSeconds:             rule.BreachDurationSeconds,
+			CoolDownSeconds:                   rule.CoolDownSeconds,
+			Threshold:                         rule.Threshold,
+			Operator:                          rule.Operator,
+			Adjustment:                        rule.Adjustment,
+			MissingData:                       rule.MissingData,
+		})
+	}
+	for _, rule := range policy.CompoundScalingRules {
+		conditions := []*models.TriggerCondition{}
+		for _, condition := range rule.Conditions {
+			conditions = append(conditions, &models.TriggerCondition{
+				MetricType:            models.AggregatedMetricType(condition.MetricType, condition.Aggregation),
+				BreachDurationSeconds: condition.BreachDurationSeconds,
+				Threshold:             condition.Threshold,
+				Operator:              condition.Operator,
+				MissingData:           condition.MissingData,
+			})
+		}
+		triggers = append(triggers, &models.Trigger{
+			AppId:                             appID,
+			ProcessType:                       policy.GetProcessType(rule.ProcessType),
+			Shadow:                            policy.IsShadow(),
+			ScaleInStabilizationWindowSeconds: policy.ScaleInStabilizationWindowSeconds,
+			CoolDownSeconds:                   rule.CoolDownSeconds,
+			Adjustment:                        rule.Adjustment,
+			Combinator:                        rule.Combinator,
+			Conditions:                        conditions,
+		})
+	}
+	for _, rule := range policy.TargetTrackingRules {
+		triggers = append(triggers, &models.Trigger{
+			AppId:                             appID,
+			ProcessType:                       policy.GetProcessType(rule.ProcessType),
+			Shadow:                            policy.IsShadow(),
+			ScaleInStabilizationWindowSeconds: policy.ScaleInStabilizationWindowSeconds,
+			MetricType:                        models.AggregatedMetricType(rule.MetricType, rule.Aggregation),
+			BreachDurationSeconds:             rule.BreachDurationSeconds,
+			CoolDownSeconds:                   rule.CoolDownSeconds,
+			TargetValue:                       rule.TargetValue,
+			Tolerance:                         rule.Tolerance,
+			MissingData:                       rule.MissingData,
+			ScaleOutDampening:                 rule.ScaleOutDampening,
+			ScaleInDampening:                  rule.ScaleInDampening,
+		})
+	}
+	return triggers
+}
+
+// withoutCoolingDown drops the triggers of process types which are still in the cooldown period of the direction the
+// trigger scales in. Target tracking triggers scale in either direction, they are only dropped if both directions
+// are cooling down. Triggers of policies with a scale-in stabilization window are kept but marked as cooling down, so
+// that the evaluator still records a recommendation for their process type.
+func (a *AppEvaluationManager) withoutCoolingDown(appID string, triggers []*models.Trigger) []*models.Trigger {
+	now := a.emClock.Now().UnixNano()
+	a.cooldownLock.RLock()
+	defer a.cooldownLock.RUnlock()
+	result := []*models.Trigger{}
+	for _, trigger := range triggers {
+		cooldownExpired := a.cooldownExpired[appID][trigger.GetProcessType()]
+		coolingDown := func(direction string) bool { return cooldownExpired[direction] > now }
+		if direction := trigger.ScalingDirection(); direction != "" {
+			trigger.CoolingDown = coolingDown(direction)
+		} else {
+			trigger.CoolingDown = coolingDown(models.ScalingDirectionOut) && coolingDown(models.ScalingDirectionIn)
+		}
+		if trigger.CoolingDown && trigger.ScaleInStabilizationWindowSeconds == 0 {
+			continue
+		}
+		result = append(result, trigger)
+	}
+	return result
+}
+
 func (a *AppEvaluationManager) Start() {
 	go a.doEvaluate()
 	a.logger.Info("started")
@@ -104,40 +182,163 @@ func (a *AppEvaluationManager) doEvaluate() {
 				if found {
 					newBreakers[appID] = cb
 				} else {
-					bf := backoff.NewExponentialBackOff()
-					bf.InitialInterval = a.breakerConfig.BackOffInitialInterval
-					bf.MaxInterval = a.breakerConfig.BackOffMaxInterval
-					bf.MaxElapsedTime = 0      // never stop retry
-					bf.RandomizationFactor = 0 // do not randomize
-					bf.Multiplier = 2
-					bf.Reset()
-					newBreakers[appID] = circuit.NewBreakerWithOptions(&circuit.Options{
-						BackOff:    bf,
-						ShouldTrip: circuit.ConsecutiveTripFunc(a.breakerConfig.ConsecutiveFailureCount),
-					})
+					newBreakers[appID] = a.loadAppEvaluationState(appID)
 				}
 			}
 
 			a.breakerLock.Lock()
 			a.breakers = newBreakers
 			a.breakerLock.Unlock()
+			a.saveBreakerStates(newBreakers)
 
 			triggers := a.getTriggers(policies)
 			for _, triggerArray := range triggers {
-				a.triggerChan <- triggerArray
+				if !a.enqueueTriggers(triggerArray) {
+					a.logger.Info("evaluation-dropped", lager.Data{"appId": triggerArray[0].AppId})
+				}
 			}
 		}
 	}
 }
 
+func (a *AppEvaluationManager) newBreaker() *circuit.Breaker {
+	bf := backoff.NewExponentialBackOff()
+	bf.InitialInterval = a.breakerConfig.BackOffInitialInterval
+	bf.MaxInterval = a.breakerConfig.BackOffMaxInterval
+	bf.MaxElapsedTime = 0      // never stop retry
+	bf.RandomizationFactor = 0 // do not randomize
+	bf.Multiplier = 2
+	bf.Reset()
+	return circuit.NewBreakerWithOptions(&circuit.Options{
+		BackOff:    bf,
+		ShouldTrip: circuit.ConsecutiveTripFunc(a.breakerConfig.ConsecutiveFailureCount),
+	})
+}
+
+// loadAppEvaluationState restores the cooldowns of an app and returns its circuit breaker with the persisted
+// consecutive failures. A restored tripped breaker starts its back off again. If the state cannot be loaded, the app
+// starts without cooldowns and with a new breaker.
+func (a *AppEvaluationManager) loadAppEvaluationState(appID string) *circuit.Breaker {
+	cb := a.newBreaker()
+	state, err := a.appMetricDB.RetrieveAppEvaluationState(appID, a.emClock.Now().UnixNano())
+	if err != nil {
+		a.logger.Error("failed-to-load-app-evaluation-state", err, lager.Data{"appId": appID})
+		return cb
+	}
+
+	a.cooldownLock.Lock()
+	for _, cooldown := range state.Cooldowns {
+		if cooldown.ExpireAt > a.cooldownExpired[appID][cooldown.ProcessType][cooldown.Direction] {
+			a.setCoolDownExpired(appID, cooldown.ProcessType, cooldown.Direction, cooldown.ExpireAt)
+		}
+	}
+	a.cooldownLock.Unlock()
+
+	for i := int64(0); i < state.Breaker.ConsecutiveFailures; i++ {
+		cb.Fail()
+	}
+	if state.Breaker.Tripped && !cb.Tripped() {
+		cb.Trip()
+	}
+	a.breakerStates[appID] = state.Breaker
+	return cb
+}
+
+// saveBreakerStates persists the states of the breakers which changed since they were last persisted.
+func (a *AppEvaluationManager) saveBreakerStates(breakers map[string]*circuit.Breaker) {
+	for appID := range a.breakerStates {
+		if _, found := breakers[appID]; !found {
+			delete(a.breakerStates, appID)
+		}
+	}
+	for appID, cb := range breakers {
+		savedState := a.breakerStates[appID]
+		state := models.BreakerState{ConsecutiveFailures: cb.ConsecFailures(), Tripped: cb.Tripped()}
+		if state.Tripped {
+			state.TrippedAt = savedState.TrippedAt
+			if !savedState.Tripped {
+				state.TrippedAt = a.emClock.Now().UnixNano()
+			}
+		}
+		if state == savedState {
+			continue
+		}
+		err := a.appMetricDB.SaveEvaluationBreakerState(appID, state)
+		if err != nil {
+			a.logger.Error("failed-to-save-breaker-state", err, lager.Data{"appId": appID})
+			continue
+		}
+		a.breakerStates[appID] = state
+	}
+}
+
 func (a *AppEvaluationManager) GetBreaker(appID string) *circuit.Breaker {
 	a.breakerLock.RLock()
 	defer a.breakerLock.RUnlock()
 	return a.breakers[appID]
 }
 
-func (a *AppEvaluationManager) SetCoolDownExpired(appID string, expiredAt int64) {
+// SetCoolDownExpired records and persists the cooldown of a process of an app in the given direction. Cooldowns without
+// direction apply to both directions.
+func (a *AppEvaluationManager) SetCoolDownExpired(appID string, processType string, direction string, expiredAt int64) {
 	a.cooldownLock.Lock()
-	defer a.cooldownLock.Unlock()
-	a.cooldownExpired[appID] = expiredAt
+	a.setCoolDownExpired(appID, processType, direction, expiredAt)
+	a.cooldownLock.Unlock()
+
+	directions := []string{direction}
+	if direction == "" {
+		directions = []string{models.ScalingDirectionOut, models.ScalingDirectionIn}
+	}
+	for _, d := range directions {
+		err := a.appMetricDB.SaveEvaluationCooldown(appID, &models.ScalingCooldown{ProcessType: processType, Direction: d, ExpireAt: expiredAt})
+		if err != nil {
+			a.logger.Error("failed-to-save-cooldown", err, lager.Data{"appId": appID, "processType": processType, "direction": d})
+		}
+	}
+}
+
+func (a *AppEvaluationManager) setCoolDownExpired(appID string, processType string, direction string, expiredAt int64) {
+	if _, found := a.cooldownExpired[appID]; !found {
+		a.cooldownExpired[appID] = map[string]map[string]int64{}
+	}
+	if _, found := a.cooldownExpired[appID][processType]; !found {
+		a.cooldownExpired[appID][processType] = map[string]int64{}
+	}
+	if direction == "" {
+		a.cooldownExpired[appID][processType][models.ScalingDirectionOut] = expiredAt
+		a.cooldownExpired[appID][processType][models.ScalingDirectionIn] = expiredAt
+		return
+	}
+	a.cooldownExpired[appID][processType][direction] = expiredAt
+}
+
+// GetAppEvaluationState returns the cooldowns which have not expired and the circuit breaker state of an app. It
+// returns false if the app is not evaluated by this eventgenerator node.
+func (a *AppEvaluationManager) GetAppEvaluationState(appID string) (*models.AppEvaluationState, bool) {
+	cb := a.GetBreaker(appID)
+	if cb == nil {
+		return nil, false
+	}
+	state := &models.AppEvaluationState{
+		Cooldowns: []*models.ScalingCooldown{},
+		Breaker:   models.BreakerState{ConsecutiveFailures: cb.ConsecFailures(), Tripped: cb.Tripped()},
+	}
+
+	now := a.emClock.Now().UnixNano()
+	a.cooldownLock.RLock()
+	for processType, directions := range a.cooldownExpired[appID] {
+		for direction, expireAt := range directions {
+			if expireAt > now {
+				state.Cooldowns = append(state.Cooldowns, &models.ScalingCooldown{ProcessType: processType, Direction: direction, ExpireAt: expireAt})
+			}
+		}
+	}
+	a.cooldownLock.RUnlock()
+	sort.Slice(state.Cooldowns, func(i, j int) bool {
+		if state.Cooldowns[i].ProcessType != state.Cooldowns[j].ProcessType {
+			return state.Cooldowns[i].ProcessType < state.Cooldowns[j].ProcessType
+		}
+		return state.Cooldowns[i].Direction < state.Cooldowns[j].Direction
+	})
+	return state, true
 }
@@ -1,12 +1,14 @@
 package generator_test
 
 import (
+	"errors"
 	"reflect"
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/config"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"code.cloudfoundry.org/clock/fakeclock"
@@ -14,6 +16,7 @@ import (
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	circuit "github.com/rubyist/circuitbreaker"
 )
 
@@ -21,11 +24,13 @@ var _ = Describe("AppEvaluationManager", func() {
 
 	var (
 		getPolicies          aggregator.GetPoliciesFunc
+		appMetricDB          *fakes.FakeAppMetricDB
 		logger               lager.Logger
 		fclock               *fakeclock.FakeClock
 		manager              *AppEvaluationManager
 		testEvaluateInterval time.Duration
 		triggerArrayChan     chan []*models.Trigger
+		enqueueTriggers      EnqueueTriggersFunc
 		testAppId1           = "testAppId1"
 		testAppId2           = "testAppId2"
 		testMetricName       = "Test-Metric-Name"
@@ -63,6 +68,7 @@ var _ = Describe("AppEvaluationManager", func() {
 						Threshold:             20,
 						Operator:              "<=",
 						Adjustment:            "-1",
+						MissingData:           models.MissingDataBreaching,
 					},
 				},
 			},
@@ -74,12 +80,19 @@ var _ = Describe("AppEvaluationManager", func() {
 		testEvaluateInterval = 1 * time.Second
 		logger = lagertest.NewTestLogger("ApplicationManager-test")
 		triggerArrayChan = make(chan []*models.Trigger, 10)
+		triggerChan := triggerArrayChan
+		enqueueTriggers = func(triggers []*models.Trigger) bool {
+			triggerChan <- triggers
+			return true
+		}
+		appMetricDB = &fakes.FakeAppMetricDB{}
+		appMetricDB.RetrieveAppEvaluationStateReturns(&models.AppEvaluationState{}, nil)
 	})
 
 	Describe("Start", func() {
 		JustBeforeEach(func() {
 			var err error
-			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, triggerArrayChan, getPolicies, testBreakerConfig)
+			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, enqueueTriggers, getPolicies, testBreakerConfig, appMetricDB)
 			Expect(err).NotTo(HaveOccurred())
 			manager.Start()
 			Eventually(fclock.WatcherCount).Should(Equal(1))
@@ -112,6 +125,7 @@ var _ = Describe("AppEvaluationManager", func() {
 					Expect(triggerArray).Should(ContainElement(
 						[]*models.Trigger{{
 							AppId:                 testAppId1,
+							ProcessType:           "web",
 							MetricType:            testMetricName,
 							BreachDurationSeconds: 200,
 							CoolDownSeconds:       200,
@@ -122,16 +136,131 @@ var _ = Describe("AppEvaluationManager", func() {
 					Expect(triggerArray).Should(ContainElement(
 						[]*models.Trigger{{
 							AppId:                 testAppId2,
+							ProcessType:           "web",
 							MetricType:            testMetricName,
 							BreachDurationSeconds: 300,
 							CoolDownSeconds:       300,
 							Threshold:             20,
 							Operator:              "<=",
 							Adjustment:            "-1",
+							MissingData:           models.MissingDataBreaching,
 						}}))
 				})
 			})
 
+			Context("when the policy has compound scaling rules", func() {
+				BeforeEach(func() {
+					getPolicies = func() map[string]*models.AppPolicy {
+						return map[string]*models.AppPolicy{
+							testAppId1: {
+								AppId: testAppId1,
+								ScalingPolicy: &models.ScalingPolicy{
+									InstanceMax: 5,
+									InstanceMin: 1,
+									CompoundScalingRules: []*models.CompoundScalingRule{{
+										Combinator: models.CombinatorAnd,
+										Conditions: []*models.ScalingCondition{
+											{MetricType: "cpu", BreachDurationSeconds: 120, Threshold: 70, Operator: ">"},
+											{MetricType: "responsetime", Aggregation: models.AggregationP95, Threshold: 300, Operator: ">", MissingData: models.MissingDataLastKnown},
+										},
+										CoolDownSeconds: 200,
+										Adjustment:      "+1",
+									}},
+								},
+							},
+						}
+					}
+				})
+
+				It("should add a compound trigger with aggregated metric types to evaluate", func() {
+					fclock.Increment(10 * testEvaluateInterval)
+					Eventually(triggerArrayChan).Should(Receive(Equal([]*models.Trigger{{
+						AppId:           testAppId1,
+						ProcessType:     "web",
+						CoolDownSeconds: 200,
+						Adjustment:      "+1",
+						Combinator:      models.CombinatorAnd,
+						Conditions: []*models.TriggerCondition{
+							{MetricType: "cpu", BreachDurationSeconds: 120, Threshold: 70, Operator: ">"},
+							{MetricType: "responsetime:p95", Threshold: 300, Operator: ">", MissingData: models.MissingDataLastKnown},
+						},
+					}})))
+				})
+			})
+
+			Context("when the policy has target tracking rules", func() {
+				BeforeEach(func() {
+					getPolicies = func() map[string]*models.AppPolicy {
+						return map[string]*models.AppPolicy{
+							testAppId1: {
+								AppId: testAppId1,
+								ScalingPolicy: &models.ScalingPolicy{
+									InstanceMax: 5,
+									InstanceMin: 1,
+									TargetTrackingRules: []*models.TargetTrackingRule{{
+										MetricType:        "cpu",
+										TargetValue:       60,
+										Tolerance:         0.05,
+										CoolDownSeconds:   200,
+										ScaleOutDampening: 1,
+										ScaleInDampening:  0.5,
+										MissingData:       models.MissingDataIgnore,
+									}},
+								},
+							},
+						}
+					}
+				})
+
+				It("should add a target tracking trigger to evaluate", func() {
+					fclock.Increment(10 * testEvaluateInterval)
+					Eventually(triggerArrayChan).Should(Receive(Equal([]*models.Trigger{{
+						AppId:             testAppId1,
+						ProcessType:       "web",
+						MetricType:        "cpu",
+						CoolDownSeconds:   200,
+						TargetValue:       60,
+						Tolerance:         0.05,
+						ScaleOutDampening: 1,
+						ScaleInDampening:  0.5,
+						MissingData:       models.MissingDataIgnore,
+					}})))
+				})
+			})
+
+			Context("when the policy is in shadow mode", func() {
+				BeforeEach(func() {
+					getPolicies = func() map[string]*models.AppPolicy {
+						return map[string]*models.AppPolicy{
+							testAppId1: {
+								AppId: testAppId1,
+								ScalingPolicy: &models.ScalingPolicy{
+									InstanceMax:  5,
+									InstanceMin:  1,
+									Mode:         models.PolicyModeShadow,
+									ScalingRules: appPolicy1.ScalingPolicy.ScalingRules,
+								},
+							},
+						}
+					}
+				})
+
+				It("should add shadow triggers to evaluate", func() {
+					fclock.Increment(10 * testEvaluateInterval)
+					Eventually(triggerArrayChan).Should(Receive(Equal([]*models.Trigger{{
+						AppId:                 testAppId1,
+						ProcessType:           "web",
+						Shadow:                true,
+						MetricType:            testMetricName,
+						BreachDurationSeconds: 200,
+						CoolDownSeconds:       200,
+						Threshold:             80,
+						Operator:              ">=",
+						Adjustment:            "1",
+					}})))
+				})
+			})
+
 			Context("when there is cooldownExpiredAt setting for testAppId2", func() {
 
 				BeforeEach(func() {
@@ -143,7 +272,7 @@ var _ = Describe("AppEvaluationManager", func() {
 				})
 
 				JustBeforeEach(func() {
-					manager.SetCoolDownExpired(testAppId2, fakeTime.Add(30*testEvaluateInterval).UnixNano())
+					manager.SetCoolDownExpired(testAppId2, "web", models.ScalingDirectionIn, fakeTime.Add(30*testEvaluateInterval).UnixNano())
 				})
 
 				It("should add triggers to evaluate after cooldown expired", func() {
@@ -162,17 +291,181 @@ var _ = Describe("AppEvaluationManager", func() {
 					Expect(triggerArray).Should(ContainElement(
 						[]*models.Trigger{{
 							AppId:                 testAppId2,
+							ProcessType:           "web",
 							MetricType:            testMetricName,
 							BreachDurationSeconds: 300,
 							CoolDownSeconds:       300,
 							Threshold:             20,
 							Operator:              "<=",
 							Adjustment:            "-1",
+							MissingData:           models.MissingDataBreaching,
 						}}))
 				})
 			})
 		})
 
+		Context("when an app was in cooldown before the eventgenerator restarted", func() {
+			BeforeEach(func() {
+				getPolicies = func() map[string]*models.AppPolicy {
+					return map[string]*models.AppPolicy{testAppId2: appPolicy2}
+				}
+				appMetricDB.RetrieveAppEvaluationStateReturns(&models.AppEvaluationState{
+					Cooldowns: []*models.ScalingCooldown{{ProcessType: "web", Direction: models.ScalingDirectionIn, ExpireAt: fakeTime.Add(20 * testEvaluateInterval).UnixNano()}},
+				}, nil)
+			})
+
+			It("should add the triggers only after the persisted cooldown expired", func() {
+				fclock.Increment(10 * testEvaluateInterval)
+				Eventually(appMetricDB.RetrieveAppEvaluationStateCallCount).Should(Equal(1))
+				appId, now := appMetricDB.RetrieveAppEvaluationStateArgsForCall(0)
+				Expect(appId).To(Equal(testAppId2))
+				Expect(now).To(Equal(fakeTime.Add(10 * testEvaluateInterval).UnixNano()))
+				Consistently(triggerArrayChan).ShouldNot(Receive())
+
+				fclock.Increment(10 * testEvaluateInterval)
+				Eventually(triggerArrayChan).Should(Receive())
+				Expect(appMetricDB.RetrieveAppEvaluationStateCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when loading the evaluation state fails", func() {
+			BeforeEach(func() {
+				getPolicies = func() map[string]*models.AppPolicy {
+					return map[string]*models.AppPolicy{testAppId2: appPolicy2}
+				}
+				appMetricDB.RetrieveAppEvaluationStateReturns(nil, errors.New("an error"))
+			})
+
+			It("should add the triggers to evaluate", func() {
+				fclock.Increment(10 * testEvaluateInterval)
+				Eventually(triggerArrayChan).Should(Receive())
+				Expect(logger.(*lagertest.TestLogger).Buffer()).To(gbytes.Say("failed-to-load-app-evaluation-state"))
+			})
+		})
+
+		Context("when only the worker process of an app is in cooldown", func() {
+			BeforeEach(func() {
+				getPolicies = func() map[string]*models.AppPolicy {
+					return map[string]*models.AppPolicy{
+						testAppId1: {
+							AppId: testAppId1,
+							ScalingPolicy: &models.ScalingPolicy{
+								InstanceMax: 5,
+								InstanceMin: 1,
+								ScalingRules: []*models.ScalingRule{
+									{MetricType: testMetricName, Threshold: 80, Operator: ">=", Adjustment: "+1"},
+									{MetricType: testMetricName, Threshold: 80, Operator: ">=", Adjustment: "+1", ProcessType: "worker"},
+								},
+							},
+						},
+					}
+				}
+			})
+
+			JustBeforeEach(func() {
+				manager.SetCoolDownExpired(testAppId1, "worker", models.ScalingDirectionOut, fakeTime.Add(30*testEvaluateInterval).UnixNano())
+			})
+
+			It("should add the triggers of the web process only", func() {
+				fclock.Increment(10 * testEvaluateInterval)
+				Eventually(triggerArrayChan).Should(Receive(Equal([]*models.Trigger{{
+					AppId:       testAppId1,
+					ProcessType: "web",
+					MetricType:  testMetricName,
+					Threshold:   80,
+					Operator:    ">=",
+					Adjustment:  "+1",
+				}})))
+			})
+		})
+
+		Context("when a process of an app with a scale-in stabilization window is in cooldown", func() {
+			BeforeEach(func() {
+				getPolicies = func() map[string]*models.AppPolicy {
+					return map[string]*models.AppPolicy{
+						testAppId1: {
+							AppId: testAppId1,
+							ScalingPolicy: &models.ScalingPolicy{
+								InstanceMax:                       5,
+								InstanceMin:                       1,
+								ScaleInStabilizationWindowSeconds: 300,
+								ScalingRules: []*models.ScalingRule{
+									{MetricType: testMetricName, Threshold: 80, Operator: ">=", Adjustment: "+1"},
+								},
+							},
+						},
+					}
+				}
+			})
+
+			JustBeforeEach(func() {
+				manager.SetCoolDownExpired(testAppId1, "web", models.ScalingDirectionOut, fakeTime.Add(30*testEvaluateInterval).UnixNano())
+			})
+
+			It("should add the trigger marked as cooling down", func() {
+				fclock.Increment(10 * testEvaluateInterval)
+				Eventually(triggerArrayChan).Should(Receive(Equal([]*models.Trigger{{
+					AppId:                             testAppId1,
+					ProcessType:                       "web",
+					MetricType:                        testMetricName,
+					Threshold:                         80,
+					Operator:                          ">=",
+					Adjustment:                        "+1",
+					ScaleInStabilizationWindowSeconds: 300,
+					CoolingDown:                       true,
+				}})))
+			})
+		})
+
+		Context("when only the scale-in of an app is in cooldown", func() {
+			BeforeEach(func() {
+				getPolicies = func() map[string]*models.AppPolicy {
+					return map[string]*models.AppPolicy{
+						testAppId1: {
+							AppId: testAppId1,
+							ScalingPolicy: &models.ScalingPolicy{
+								InstanceMax: 5,
+								InstanceMin: 1,
+								ScalingRules: []*models.ScalingRule{
+									{MetricType: testMetricName, Threshold: 80, Operator: ">=", Adjustment: "+1"},
+									{MetricType: testMetricName, Threshold: 20, Operator: "<", Adjustment: "-1"},
+								},
+								TargetTrackingRules: []*models.TargetTrackingRule{{MetricType: testMetricName, TargetValue: 50}},
+							},
+						},
+					}
+				}
+			})
+
+			JustBeforeEach(func() {
+				manager.SetCoolDownExpired(testAppId1, "web", models.ScalingDirectionIn, fakeTime.Add(30*testEvaluateInterval).UnixNano())
+			})
+
+			It("should add the scale-out and target tracking triggers only", func() {
+				fclock.Increment(10 * testEvaluateInterval)
+				Eventually(triggerArrayChan).Should(Receive(Equal([]*models.Trigger{
+					{AppId: testAppId1, ProcessType: "web", MetricType: testMetricName, Threshold: 80, Operator: ">=", Adjustment: "+1"},
+					{AppId: testAppId1, ProcessType: "web", MetricType: testMetricName, TargetValue: 50},
+				})))
+			})
+		})
+
+		Context("when the evaluation cannot be enqueued", func() {
+			BeforeEach(func() {
+				getPolicies = func() map[string]*models.AppPolicy {
+					return map[string]*models.AppPolicy{testAppId1: appPolicy1}
+				}
+				enqueueTriggers = func(triggers []*models.Trigger) bool {
+					return false
+				}
+			})
+
+			It("should log the dropped evaluation", func() {
+				fclock.Increment(10 * testEvaluateInterval)
+				Eventually(logger.(*lagertest.TestLogger).Buffer()).Should(gbytes.Say("evaluation-dropped"))
+			})
+		})
+
 		Context("when there is no trigger", func() {
 			BeforeEach(func() {
 				getPolicies = func() map[string]*models.AppPolicy {
@@ -195,7 +488,7 @@ var _ = Describe("AppEvaluationManager", func() {
 			}
 
 			var err error
-			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, triggerArrayChan, getPolicies, testBreakerConfig)
+			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, enqueueTriggers, getPolicies, testBreakerConfig, appMetricDB)
 			Expect(err).NotTo(HaveOccurred())
 			manager.Start()
 			Eventually(fclock.WatcherCount).Should(Equal(1))
@@ -225,7 +518,7 @@ var _ = Describe("AppEvaluationManager", func() {
 				return map[string]*models.AppPolicy{testAppId1: appPolicy1}
 			}
 			var err error
-			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, triggerArrayChan, getPolicies, testBreakerConfig)
+			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, enqueueTriggers, getPolicies, testBreakerConfig, appMetricDB)
 			Expect(err).NotTo(HaveOccurred())
 			manager.Start()
 			Eventually(fclock.WatcherCount).Should(Equal(1))
@@ -252,6 +545,137 @@ var _ = Describe("AppEvaluationManager", func() {
 		})
 	})
 
+	Describe("Breaker state", func() {
+		var breakerConfig config.CircuitBreakerConfig
+
+		BeforeEach(func() {
+			getPolicies = func() map[string]*models.AppPolicy {
+				return map[string]*models.AppPolicy{testAppId1: appPolicy1}
+			}
+			breakerConfig = config.CircuitBreakerConfig{
+				BackOffInitialInterval:  5 * time.Minute,
+				BackOffMaxInterval:      2 * time.Hour,
+				ConsecutiveFailureCount: 3,
+			}
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, enqueueTriggers, getPolicies, breakerConfig, appMetricDB)
+			Expect(err).NotTo(HaveOccurred())
+			manager.Start()
+			Eventually(fclock.WatcherCount).Should(Equal(1))
+			fclock.Increment(1 * testEvaluateInterval)
+			Eventually(func() *circuit.Breaker { return manager.GetBreaker(testAppId1) }).ShouldNot(BeNil())
+		})
+
+		AfterEach(func() {
+			manager.Stop()
+		})
+
+		It("does not persist an unchanged breaker state", func() {
+			fclock.Increment(1 * testEvaluateInterval)
+			Eventually(appMetricDB.RetrieveAppEvaluationStateCallCount).Should(Equal(1))
+			Consistently(appMetricDB.SaveEvaluationBreakerStateCallCount).Should(Equal(0))
+		})
+
+		It("persists the breaker state when it changes", func() {
+			breaker := manager.GetBreaker(testAppId1)
+			breaker.Fail()
+			breaker.Fail()
+			breaker.Fail()
+			Expect(breaker.Tripped()).To(BeTrue())
+
+			fclock.Increment(1 * testEvaluateInterval)
+			Eventually(appMetricDB.SaveEvaluationBreakerStateCallCount).Should(Equal(1))
+			appId, state := appMetricDB.SaveEvaluationBreakerStateArgsForCall(0)
+			Expect(appId).To(Equal(testAppId1))
+			Expect(state).To(Equal(models.BreakerState{ConsecutiveFailures: 3, Tripped: true, TrippedAt: fakeTime.Add(2 * testEvaluateInterval).UnixNano()}))
+
+			fclock.Increment(1 * testEvaluateInterval)
+			Consistently(appMetricDB.SaveEvaluationBreakerStateCallCount).Should(Equal(1))
+
+			breaker.Reset()
+			fclock.Increment(1 * testEvaluateInterval)
+			Eventually(appMetricDB.SaveEvaluationBreakerStateCallCount).Should(Equal(2))
+			_, state = appMetricDB.SaveEvaluationBreakerStateArgsForCall(1)
+			Expect(state).To(Equal(models.BreakerState{}))
+		})
+
+		Context("when the breaker was tripped before the eventgenerator restarted", func() {
+			BeforeEach(func() {
+				appMetricDB.RetrieveAppEvaluationStateReturns(&models.AppEvaluationState{
+					Breaker: models.BreakerState{ConsecutiveFailures: 3, Tripped: true, TrippedAt: fakeTime.Add(-time.Minute).UnixNano()},
+				}, nil)
+			})
+
+			It("restores the tripped breaker", func() {
+				breaker := manager.GetBreaker(testAppId1)
+				Expect(breaker.Tripped()).To(BeTrue())
+				Expect(breaker.ConsecFailures()).To(Equal(int64(3)))
+				Consistently(appMetricDB.SaveEvaluationBreakerStateCallCount).Should(Equal(0))
+			})
+		})
+
+		Context("when the breaker had failures before the eventgenerator restarted", func() {
+			BeforeEach(func() {
+				appMetricDB.RetrieveAppEvaluationStateReturns(&models.AppEvaluationState{
+					Breaker: models.BreakerState{ConsecutiveFailures: 2},
+				}, nil)
+			})
+
+			It("restores the consecutive failures", func() {
+				breaker := manager.GetBreaker(testAppId1)
+				Expect(breaker.Tripped()).To(BeFalse())
+				Expect(breaker.ConsecFailures()).To(Equal(int64(2)))
+
+				breaker.Fail()
+				Expect(breaker.Tripped()).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("GetAppEvaluationState", func() {
+		BeforeEach(func() {
+			getPolicies = func() map[string]*models.AppPolicy {
+				return map[string]*models.AppPolicy{testAppId1: appPolicy1}
+			}
+
+			var err error
+			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, enqueueTriggers, getPolicies, testBreakerConfig, appMetricDB)
+			Expect(err).NotTo(HaveOccurred())
+			manager.Start()
+			Eventually(fclock.WatcherCount).Should(Equal(1))
+		})
+
+		AfterEach(func() {
+			manager.Stop()
+		})
+
+		It("returns the state of the evaluated apps only", func() {
+			_, found := manager.GetAppEvaluationState(testAppId1)
+			Expect(found).To(BeFalse())
+
+			fclock.Increment(1 * testEvaluateInterval)
+			Eventually(func() *circuit.Breaker { return manager.GetBreaker(testAppId1) }).ShouldNot(BeNil())
+			manager.SetCoolDownExpired(testAppId1, "worker", "", fakeTime.Add(20*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId1, "web", models.ScalingDirectionOut, fakeTime.Add(30*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId1, "web", models.ScalingDirectionIn, fakeTime.UnixNano())
+			manager.GetBreaker(testAppId1).Fail()
+
+			state, found := manager.GetAppEvaluationState(testAppId1)
+			Expect(found).To(BeTrue())
+			Expect(state).To(Equal(&models.AppEvaluationState{
+				Cooldowns: []*models.ScalingCooldown{
+					{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: fakeTime.Add(30 * time.Second).UnixNano()},
+					{ProcessType: "worker", Direction: models.ScalingDirectionIn, ExpireAt: fakeTime.Add(20 * time.Second).UnixNano()},
+					{ProcessType: "worker", Direction: models.ScalingDirectionOut, ExpireAt: fakeTime.Add(20 * time.Second).UnixNano()},
+				},
+				Breaker: models.BreakerState{ConsecutiveFailures: 1},
+			}))
+		})
+	})
+
 	Describe("SetCoolDownExpired", func() {
 		BeforeEach(func() {
 			getPolicies = func() map[string]*models.AppPolicy {
@@ -262,7 +686,7 @@ var _ = Describe("AppEvaluationManager", func() {
 			}
 
 			var err error
-			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, triggerArrayChan, getPolicies, testBreakerConfig)
+			manager, err = NewAppEvaluationManager(logger, testEvaluateInterval, fclock, enqueueTriggers, getPolicies, testBreakerConfig, appMetricDB)
 			Expect(err).NotTo(HaveOccurred())
 			manager.Start()
 			Eventually(fclock.WatcherCount).Should(Equal(1))
@@ -270,25 +694,49 @@ var _ = Describe("AppEvaluationManager", func() {
 
 		It("insert the cooldownExpiredAt records in map", func() {
 
-			manager.SetCoolDownExpired(testAppId1, fakeTime.Add(time.Duration(20)*time.Second).UnixNano())
-			manager.SetCoolDownExpired(testAppId2, fakeTime.Add(time.Duration(30)*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId1, "web", models.ScalingDirectionOut, fakeTime.Add(time.Duration(20)*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId2, "web", models.ScalingDirectionIn, fakeTime.Add(time.Duration(30)*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId2, "worker", "", fakeTime.Add(time.Duration(40)*time.Second).UnixNano())
 
 			v := reflect.ValueOf(manager).Elem()
 			coolDownExpiredReflect := v.FieldByName("cooldownExpired")
 			Expect(coolDownExpiredReflect.Len()).Should(Equal(2))
+			cooldownOf := func(byProcessType reflect.Value, processType string, direction string) reflect.Value {
+				return byProcessType.MapIndex(reflect.ValueOf(processType)).MapIndex(reflect.ValueOf(direction))
+			}
 			for _, key := range coolDownExpiredReflect.MapKeys() {
-				value := coolDownExpiredReflect.MapIndex(key).Int()
+				byProcessType := coolDownExpiredReflect.MapIndex(key)
 				if key.String() == testAppId1 {
-					Expect(value).Should(Equal(fakeTime.Add(time.Duration(20) * time.Second).UnixNano()))
+					Expect(byProcessType.Len()).Should(Equal(1))
+					Expect(byProcessType.MapIndex(reflect.ValueOf("web")).Len()).Should(Equal(1))
+					Expect(cooldownOf(byProcessType, "web", models.ScalingDirectionOut).Int()).Should(Equal(fakeTime.Add(time.Duration(20) * time.Second).UnixNano()))
 				}
 				if key.String() == testAppId2 {
-					Expect(value).Should(Equal(fakeTime.Add(time.Duration(30) * time.Second).UnixNano()))
+					Expect(byProcessType.Len()).Should(Equal(2))
+					Expect(cooldownOf(byProcessType, "web", models.ScalingDirectionIn).Int()).Should(Equal(fakeTime.Add(time.Duration(30) * time.Second).UnixNano()))
+					Expect(cooldownOf(byProcessType, "worker", models.ScalingDirectionOut).Int()).Should(Equal(fakeTime.Add(time.Duration(40) * time.Second).UnixNano()))
+					Expect(cooldownOf(byProcessType, "worker", models.ScalingDirectionIn).Int()).Should(Equal(fakeTime.Add(time.Duration(40) * time.Second).UnixNano()))
 				}
 
 			}
 
 		})
 
+		It("persists the cooldowns", func() {
+			manager.SetCoolDownExpired(testAppId1, "web", models.ScalingDirectionOut, fakeTime.Add(time.Duration(20)*time.Second).UnixNano())
+			manager.SetCoolDownExpired(testAppId2, "worker", "", fakeTime.Add(time.Duration(40)*time.Second).UnixNano())
+
+			Expect(appMetricDB.SaveEvaluationCooldownCallCount()).To(Equal(3))
+			appId, cooldown := appMetricDB.SaveEvaluationCooldownArgsForCall(0)
+			Expect(appId).To(Equal(testAppId1))
+			Expect(cooldown).To(Equal(&models.ScalingCooldown{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: fakeTime.Add(time.Duration(20) * time.Second).UnixNano()}))
+			appId, cooldown = appMetricDB.SaveEvaluationCooldownArgsForCall(1)
+			Expect(appId).To(Equal(testAppId2))
+			Expect(cooldown).To(Equal(&models.ScalingCooldown{ProcessType: "worker", Direction: models.ScalingDirectionOut, ExpireAt: fakeTime.Add(time.Duration(40) * time.Second).UnixNano()}))
+			_, cooldown = appMetricDB.SaveEvaluationCooldownArgsForCall(2)
+			Expect(cooldown).To(Equal(&models.ScalingCooldown{ProcessType: "worker", Direction: models.ScalingDirectionIn, ExpireAt: fakeTime.Add(time.Duration(40) * time.Second).UnixNano()}))
+		})
+
 		AfterEach(func() {
 			manager.Stop()
 		})
@@ -0,0 +1,230 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+type BacktestFunc func(appID string, request *models.BacktestRequest) (*models.BacktestResult, error)
+
+// Backtester replays a scaling policy against the stored metrics of an app. At every evaluation interval the dynamic
+// scaling rules are evaluated like the Evaluator does and breached triggers are applied like the scaling engine does,
+// but only to a simulated number of instances. Schedules are not replayed.
+type Backtester struct {
+	logger                    lager.Logger
+	queryAppMetrics           aggregator.QueryAppMetricsFunc
+	evaluationInterval        time.Duration
+	defaultBreachDurationSecs int
+}
+
+func NewBacktester(logger lager.Logger, queryAppMetrics aggregator.QueryAppMetricsFunc, evaluationInterval time.Duration,
+	defaultBreachDurationSecs int) *Backtester {
+	return &Backtester{
+		logger:                    logger.Session("Backtester"),
+		queryAppMetrics:           queryAppMetrics,
+		evaluationInterval:        evaluationInterval,
+		defaultBreachDurationSecs: defaultBreachDurationSecs,
+	}
+}
+
+func (b *Backtester) Backtest(appID string, request *models.BacktestRequest) (*models.BacktestResult, error) {
+	logger := b.logger.WithData(lager.Data{"appId": appID, "start": request.Start, "end": request.End})
+	policy := request.Policy
+	start := time.Unix(0, request.Start)
+	end := time.Unix(0, request.End)
+
+	metrics, err := b.loadAppMetrics(appID, policy, start, end)
+	if err != nil {
+		logger.Error("failed-to-load-app-metrics", err)
+		return nil, err
+	}
+	evaluator := &Evaluator{
+		logger:                    logger,
+		defaultBreachDurationSecs: b.defaultBreachDurationSecs,
+		queryAppMetrics:           aggregator.WithDerivedMetrics(policy, metrics.query),
+	}
+
+	result := &models.BacktestResult{
+		Timeline:  []*models.BacktestInstances{},
+		Decisions: []*models.AppScalingHistory{},
+	}
+	initialInstances := request.Instances
+	if initialInstances <= 0 {
+		initialInstances = policy.InstanceMin
+	}
+	instances := map[string]int{}
+	for _, trigger := range buildTriggers(appID, policy) {
+		processType := trigger.GetProcessType()
+		if _, found := instances[processType]; !found {
+			instances[processType] = initialInstances
+			result.Timeline = append(result.Timeline, &models.BacktestInstances{Timestamp: request.Start, ProcessType: processType, Instances: initialInstances})
+		}
+	}
+
+	state := &backtestState{cooldownExpiredAt: map[string]map[string]int64{}}
+	for now := start; !now.After(end); now = now.Add(b.evaluationInterval) {
+		alarmedProcessTypes := map[string]bool{}
+		for _, trigger := range buildTriggers(appID, policy) {
+			processType := trigger.GetProcessType()
+			if alarmedProcessTypes[processType] || state.coolingDown(processType, trigger.ScalingDirection(), now) {
+				continue
+			}
+			if !evaluator.evaluate(trigger, now) {
+				continue
+			}
+			alarmedProcessTypes[processType] = true
+
+			decision := b.scale(policy, trigger, instances[processType], state, now)
+			result.Decisions = append(result.Decisions, decision)
+			if decision.Status == models.ScalingStatusSucceeded {
+				instances[processType] = decision.NewInstances
+				result.Timeline = append(result.Timeline, &models.BacktestInstances{Timestamp: decision.Timestamp, ProcessType: processType, Instances: decision.NewInstances})
+			}
+		}
+		if window := policy.ScaleInStabilizationWindow(); window > 0 {
+			for processType, processInstances := range instances {
+				if !alarmedProcessTypes[processType] {
+					state.recommend(&models.ScalingRecommendation{AppId: appID, ProcessType: processType, Timestamp: now.UnixNano(), Instances: processInstances}, window, now)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// backtestState is the state the scaling engine keeps between the scaling decisions of an app.
+type backtestState struct {
+	cooldownExpiredAt map[string]map[string]int64
+	recommendations   []*models.ScalingRecommendation
+	scalingActions    []int64
+}
+
+// coolingDown reports whether the process type is in the cooldown of the direction. Without direction, as for target
+// tracking triggers, both directions have to be cooling down.
+func (s *backtestState) coolingDown(processType string, direction string, now time.Time) bool {
+	coolingDown := func(direction string) bool { return s.cooldownExpiredAt[processType][direction] > now.UnixNano() }
+	if direction == "" {
+		return coolingDown(models.ScalingDirectionOut) && coolingDown(models.ScalingDirectionIn)
+	}
+	return coolingDown(direction)
+}
+
+// scalingActionsWithinHour returns the number of scaling actions taken within the hour before now.
+func (s *backtestState) scalingActionsWithinHour(now time.Time) int {
+	actions := 0
+	for _, timestamp := range s.scalingActions {
+		if timestamp >= now.Add(-time.Hour).UnixNano() {
+			actions++
+		}
+	}
+	return actions
+}
+
+// recommend records a scale-in stabilization recommendation and returns the recommendations within the window before
+// it, like the scaling engine does.
+func (s *backtestState) recommend(recommendation *models.ScalingRecommendation, window time.Duration, now time.Time) []*models.ScalingRecommendation {
+	recommendations := []*models.ScalingRecommendation{}
+	for _, previous := range s.recommendations {
+		if previous.Timestamp >= now.Add(-window).UnixNano() {
+			recommendations = append(recommendations, previous)
+		}
+	}
+	s.recommendations = append(recommendations, recommendation)
+	return recommendations
+}
+
+// scale decides on a breached trigger the same way the scaling engine does for an app without active schedule.
+func (b *Backtester) scale(policy *models.ScalingPolicy, trigger *models.Trigger, instances int, state *backtestState, now time.Time) *models.AppScalingHistory {
+	history := &models.AppScalingHistory{
+		AppId:        trigger.AppId,
+		ProcessType:  trigger.GetProcessType(),
+		Timestamp:    now.UnixNano(),
+		ScalingType:  models.ScalingTypeDynamic,
+		OldInstances: instances,
+		NewInstances: instances,
+		Reason:       models.DynamicScalingReason(trigger),
+		Simulated:    true,
+	}
+
+	newInstances, err := models.ComputeInstances(instances, trigger)
+	if err != nil {
+		history.Status = models.ScalingStatusFailed
+		history.NewInstances = -1
+		history.Error = "failed to compute new app instances"
+		return history
+	}
+	history.NewInstances, history.Message = models.LimitInstances(newInstances, policy.InstanceMin, policy.InstanceMax)
+	direction := models.ScalingDirection(instances, history.NewInstances)
+
+	var recommendations []*models.ScalingRecommendation
+	window := policy.ScaleInStabilizationWindow()
+	if window > 0 {
+		recommendations = state.recommend(&models.ScalingRecommendation{AppId: trigger.AppId, ProcessType: history.ProcessType, Timestamp: now.UnixNano(), Instances: history.NewInstances}, window, now)
+	}
+
+	if state.coolingDown(history.ProcessType, direction, now) {
+		history.Status = models.ScalingStatusIgnored
+		history.NewInstances = instances
+		history.Message = fmt.Sprintf("app in scale-%s cooldown period", direction)
+		return history
+	}
+
+	if window > 0 {
+		if stabilizedInstances, message := models.StabilizeInstances(instances, history.NewInstances, history.ProcessType, recommendations); message != "" {
+			history.NewInstances, history.Message = stabilizedInstances, message
+		}
+	}
+	if limitedInstances, message := policy.LimitScalingStep(instances, history.NewInstances); message != "" {
+		history.NewInstances, history.Message = limitedInstances, message
+	}
+
+	if history.NewInstances == instances {
+		history.Status = models.ScalingStatusIgnored
+		return history
+	}
+	if limit := policy.ScalingActionsPerHourLimit(); limit > 0 && state.scalingActionsWithinHour(now) >= limit {
+		history.Status = models.ScalingStatusIgnored
+		history.NewInstances = instances
+		history.Message = fmt.Sprintf("limited by max scaling actions per hour %d", limit)
+		return history
+	}
+	history.Status = models.ScalingStatusSucceeded
+	state.scalingActions = append(state.scalingActions, now.UnixNano())
+	if _, found := state.cooldownExpiredAt[history.ProcessType]; !found {
+		state.cooldownExpiredAt[history.ProcessType] = map[string]int64{}
+	}
+	state.cooldownExpiredAt[history.ProcessType][direction] = now.Add(trigger.CoolDown(models.DefaultCoolDownSecs)).UnixNano()
+	return history
+}
+
+// loadAppMetrics retrieves all the metrics the replay needs at once, including the look back of the first evaluation.
+func (b *Backtester) loadAppMetrics(appID string, policy *models.ScalingPolicy, start time.Time, end time.Time) (backtestMetrics, error) {
+	lookBack := policy.MetricLookBack(b.defaultBreachDurationSecs)
+
+	metrics := backtestMetrics{}
+	for _, metricType := range policy.AggregatedMetricTypes() {
+		appMetrics, err := b.queryAppMetrics(appID, metricType, start.Add(-lookBack).UnixNano(), end.UnixNano(), db.ASC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve %s metrics: %w", metricType, err)
+		}
+		metrics[metricType] = appMetrics
+	}
+	return metrics, nil
+}
+
+// backtestMetrics holds the metrics of an app by metric type, ordered by timestamp.
+type backtestMetrics map[string][]*models.AppMetric
+
+func (m backtestMetrics) query(_ string, metricType string, start int64, end int64, _ db.OrderType) ([]*models.AppMetric, error) {
+	appMetrics := m[metricType]
+	from := sort.Search(len(appMetrics), func(i int) bool { return appMetrics[i].Timestamp >= start })
+	to := sort.Search(len(appMetrics), func(i int) bool { return appMetrics[i].Timestamp > end })
+	return appMetrics[from:to], nil
+}
@@ -0,0 +1,196 @@
+package generator_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backtester", func() {
+	var (
+		backtester      *Backtester
+		queryAppMetrics aggregator.QueryAppMetricsFunc
+		request         *models.BacktestRequest
+		result          *models.BacktestResult
+		err             error
+		queriedTypes    []string
+		testAppId       = "testAppId"
+		base            = time.Unix(1000000, 0)
+	)
+
+	// cpu is 90% until 300 seconds after base and 10% afterwards, reported every 20 seconds
+	cpuMetrics := func() []*models.AppMetric {
+		appMetrics := []*models.AppMetric{}
+		for offset := -120; offset <= 600; offset += 20 {
+			value := "90"
+			if offset > 300 {
+				value = "10"
+			}
+			appMetrics = append(appMetrics, &models.AppMetric{
+				AppId:      testAppId,
+				MetricType: "cpu",
+				Value:      value,
+				Unit:       "%",
+				Timestamp:  base.Add(time.Duration(offset) * time.Second).UnixNano(),
+			})
+		}
+		return appMetrics
+	}
+
+	at := func(offsetSecs int) int64 {
+		return base.Add(time.Duration(offsetSecs) * time.Second).UnixNano()
+	}
+
+	BeforeEach(func() {
+		queriedTypes = []string{}
+		queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+			queriedTypes = append(queriedTypes, metricType)
+			Expect(orderType).To(Equal(db.ASC))
+			Expect(start).To(BeNumerically("<=", at(-120)))
+			Expect(end).To(Equal(at(600)))
+			return cpuMetrics(), nil
+		}
+		request = &models.BacktestRequest{
+			Policy: &models.ScalingPolicy{
+				InstanceMin: 1,
+				InstanceMax: 5,
+				ScalingRules: []*models.ScalingRule{
+					{MetricType: "cpu", BreachDurationSeconds: 60, CoolDownSeconds: 120, Threshold: 80, Operator: ">", Adjustment: "+1"},
+					{MetricType: "cpu", BreachDurationSeconds: 60, CoolDownSeconds: 120, Threshold: 20, Operator: "<", Adjustment: "-1"},
+				},
+			},
+			Start: at(0),
+			End:   at(600),
+		}
+	})
+
+	JustBeforeEach(func() {
+		backtester = NewBacktester(lagertest.NewTestLogger("backtester-test"), queryAppMetrics, 40*time.Second, 120)
+		result, err = backtester.Backtest(testAppId, request)
+	})
+
+	It("retrieves the metrics of every metric type once", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queriedTypes).To(Equal([]string{"cpu"}))
+	})
+
+	It("replays the policy respecting breach durations and cooldowns", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Timeline).To(Equal([]*models.BacktestInstances{
+			{Timestamp: at(0), ProcessType: "web", Instances: 1},
+			{Timestamp: at(0), ProcessType: "web", Instances: 2},
+			{Timestamp: at(120), ProcessType: "web", Instances: 3},
+			{Timestamp: at(240), ProcessType: "web", Instances: 4},
+			{Timestamp: at(400), ProcessType: "web", Instances: 3},
+			{Timestamp: at(520), ProcessType: "web", Instances: 2},
+		}))
+		Expect(result.Decisions).To(HaveLen(5))
+		Expect(result.Decisions[0]).To(Equal(&models.AppScalingHistory{
+			AppId:        testAppId,
+			ProcessType:  "web",
+			Timestamp:    at(0),
+			ScalingType:  models.ScalingTypeDynamic,
+			Status:       models.ScalingStatusSucceeded,
+			OldInstances: 1,
+			NewInstances: 2,
+			Reason:       "+1 instance(s) because cpu > 80% for 60 seconds",
+			Simulated:    true,
+		}))
+		Expect(result.Decisions[3].Reason).To(Equal("-1 instance(s) because cpu < 20% for 60 seconds"))
+	})
+
+	Context("when the max instances are reached", func() {
+		BeforeEach(func() {
+			request.Policy.InstanceMax = 3
+			request.Instances = 3
+		})
+
+		It("records ignored decisions without changing the instances", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Timeline[0]).To(Equal(&models.BacktestInstances{Timestamp: at(0), ProcessType: "web", Instances: 3}))
+			Expect(result.Decisions[0].Status).To(Equal(models.ScalingStatusIgnored))
+			Expect(result.Decisions[0].NewInstances).To(Equal(3))
+			Expect(result.Decisions[0].Message).To(Equal("limited by max instances 3"))
+		})
+	})
+
+	Context("when the policy has a scale-in stabilization window", func() {
+		BeforeEach(func() {
+			request.Policy.ScaleInStabilizationWindowSeconds = 600
+		})
+
+		It("does not scale in below the highest recommendation within the window", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Timeline[len(result.Timeline)-1]).To(Equal(&models.BacktestInstances{Timestamp: at(240), ProcessType: "web", Instances: 4}))
+			lastDecision := result.Decisions[len(result.Decisions)-1]
+			Expect(lastDecision.Status).To(Equal(models.ScalingStatusIgnored))
+			Expect(lastDecision.NewInstances).To(Equal(4))
+			Expect(lastDecision.Message).To(Equal("limited by scale-in stabilization window to 4"))
+		})
+	})
+
+	Context("when the scale-in stabilization window only covers evaluations without scaling", func() {
+		BeforeEach(func() {
+			request.Policy.ScaleInStabilizationWindowSeconds = 100
+		})
+
+		It("keeps the instances of those evaluations as recommendations", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Decisions[3].Timestamp).To(Equal(at(400)))
+			Expect(result.Decisions[3].Message).To(Equal("limited by scale-in stabilization window to 4"))
+		})
+	})
+
+	Context("when the policy limits the scaling step", func() {
+		BeforeEach(func() {
+			request.Policy.ScalingRules[0].Adjustment = "+300%"
+			request.Policy.MaxScaleOutStep = 1
+		})
+
+		It("does not scale out by more than the step", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Decisions[0].NewInstances).To(Equal(2))
+			Expect(result.Decisions[0].Message).To(Equal("limited by max scale-out step 1"))
+		})
+	})
+
+	Context("when the policy limits the scaling actions per hour", func() {
+		BeforeEach(func() {
+			request.Policy.MaxScalingActionsPerHour = 2
+		})
+
+		It("ignores the scaling actions above the limit", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Timeline).To(Equal([]*models.BacktestInstances{
+				{Timestamp: at(0), ProcessType: "web", Instances: 1},
+				{Timestamp: at(0), ProcessType: "web", Instances: 2},
+				{Timestamp: at(120), ProcessType: "web", Instances: 3},
+			}))
+			Expect(result.Decisions[2].Timestamp).To(Equal(at(240)))
+			Expect(result.Decisions[2].Status).To(Equal(models.ScalingStatusIgnored))
+			Expect(result.Decisions[2].NewInstances).To(Equal(3))
+			Expect(result.Decisions[2].Message).To(Equal("limited by max scaling actions per hour 2"))
+		})
+	})
+
+	Context("when retrieving the metrics fails", func() {
+		BeforeEach(func() {
+			queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+				return nil, errors.New("an error")
+			}
+		})
+
+		It("returns an error", func() {
+			Expect(err).To(MatchError("failed to retrieve cpu metrics: an error"))
+			Expect(result).To(BeNil())
+		})
+	})
+})
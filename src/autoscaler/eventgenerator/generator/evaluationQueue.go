@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EvaluationTask is the evaluation of the triggers of an app in one evaluation cycle.
+type EvaluationTask struct {
+	Triggers   []*models.Trigger
+	EnqueuedAt time.Time
+	Deadline   time.Time
+}
+
+// EvaluationMetrics describes the backpressure of the evaluation queue.
+type EvaluationMetrics struct {
+	QueueDepth         prometheus.Histogram
+	EvaluationLag      prometheus.Histogram
+	DroppedEvaluations prometheus.Counter
+	LateEvaluations    prometheus.Counter
+}
+
+func NewEvaluationMetrics() *EvaluationMetrics {
+	return &EvaluationMetrics{
+		QueueDepth: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "autoscaler",
+			Subsystem: "eventgenerator",
+			Name:      "evaluation_queue_depth",
+			Help:      "the number of evaluations waiting in the queue of an evaluator when an evaluation is enqueued",
+			Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500},
+		}),
+		EvaluationLag: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "autoscaler",
+			Subsystem: "eventgenerator",
+			Name:      "evaluation_lag_seconds",
+			Help:      "the time an evaluation waited in the queue before an evaluator started it",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 120},
+		}),
+		DroppedEvaluations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "autoscaler",
+			Subsystem: "eventgenerator",
+			Name:      "dropped_evaluations_total",
+			Help:      "the number of evaluations dropped because the queue of the evaluator was full",
+		}),
+		LateEvaluations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "autoscaler",
+			Subsystem: "eventgenerator",
+			Name:      "late_evaluations_total",
+			Help:      "the number of evaluations skipped because they were not started before the deadline of their evaluation cycle",
+		}),
+	}
+}
+
+func (m *EvaluationMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.QueueDepth, m.EvaluationLag, m.DroppedEvaluations, m.LateEvaluations}
+}
+
+// EvaluationQueue distributes the evaluations of the apps to a fixed number of evaluators with a bounded queue each.
+// All evaluations of an app go to the same evaluator, so that they run one after another in the order of the
+// evaluation cycles, and a slow scaling engine call only delays the apps sharing the evaluator.
+type EvaluationQueue struct {
+	clock    clock.Clock
+	deadline time.Duration
+	queues   []chan *EvaluationTask
+	metrics  *EvaluationMetrics
+}
+
+func NewEvaluationQueue(clock clock.Clock, workerCount int, queueSize int, deadline time.Duration, metrics *EvaluationMetrics) *EvaluationQueue {
+	queues := make([]chan *EvaluationTask, workerCount)
+	for i := range queues {
+		queues[i] = make(chan *EvaluationTask, queueSize)
+	}
+	return &EvaluationQueue{
+		clock:    clock,
+		deadline: deadline,
+		queues:   queues,
+		metrics:  metrics,
+	}
+}
+
+// Enqueue adds the evaluation of the triggers of an app to the queue of its evaluator. It never blocks: if the queue
+// is full, the evaluation is dropped and false returned, the app is evaluated again in the next evaluation cycle.
+func (q *EvaluationQueue) Enqueue(triggers []*models.Trigger) bool {
+	if len(triggers) == 0 {
+		return true
+	}
+	queue := q.queues[helpers.FNVHash(triggers[0].AppId)%uint32(len(q.queues))]
+	now := q.clock.Now()
+	q.metrics.QueueDepth.Observe(float64(len(queue)))
+	select {
+	case queue <- &EvaluationTask{Triggers: triggers, EnqueuedAt: now, Deadline: now.Add(q.deadline)}:
+		return true
+	default:
+		q.metrics.DroppedEvaluations.Inc()
+		return false
+	}
+}
+
+// Tasks returns the queue of an evaluator.
+func (q *EvaluationQueue) Tasks(worker int) <-chan *EvaluationTask {
+	return q.queues[worker]
+}
+
+// Take records the lag of a task taken from a queue and reports whether it is still to be evaluated, which is not
+// the case once the deadline of its evaluation cycle passed.
+func (q *EvaluationQueue) Take(task *EvaluationTask) bool {
+	now := q.clock.Now()
+	q.metrics.EvaluationLag.Observe(now.Sub(task.EnqueuedAt).Seconds())
+	if now.After(task.Deadline) {
+		q.metrics.LateEvaluations.Inc()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package generator_test
+
+import (
+	"fmt"
+	"time"
+
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("EvaluationQueue", func() {
+	const deadline = 30 * time.Second
+
+	var (
+		fclock  *fakeclock.FakeClock
+		metrics *EvaluationMetrics
+		queue   *EvaluationQueue
+	)
+
+	triggersOf := func(appId string) []*models.Trigger {
+		return []*models.Trigger{{AppId: appId, MetricType: "a-metric-type"}}
+	}
+
+	histogram := func(h prometheus.Histogram) (uint64, float64) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(h)
+		metricFamilies, err := registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metricFamilies).To(HaveLen(1))
+		sample := metricFamilies[0].GetMetric()[0].GetHistogram()
+		return sample.GetSampleCount(), sample.GetSampleSum()
+	}
+
+	BeforeEach(func() {
+		fclock = fakeclock.NewFakeClock(time.Now())
+		metrics = NewEvaluationMetrics()
+		queue = NewEvaluationQueue(fclock, 4, 2, deadline, metrics)
+	})
+
+	It("enqueues all evaluations of an app for the same worker in order", func() {
+		Expect(queue.Enqueue(triggersOf("an-app-id"))).To(BeTrue())
+		fclock.Increment(time.Second)
+		Expect(queue.Enqueue(triggersOf("an-app-id"))).To(BeTrue())
+
+		var tasks []*EvaluationTask
+		for worker := 0; worker < 4; worker++ {
+			for len(queue.Tasks(worker)) > 0 {
+				tasks = append(tasks, <-queue.Tasks(worker))
+			}
+		}
+		Expect(tasks).To(HaveLen(2))
+		Expect(tasks[0].EnqueuedAt).To(Equal(fclock.Now().Add(-time.Second)))
+		Expect(tasks[0].Deadline).To(Equal(tasks[0].EnqueuedAt.Add(deadline)))
+		Expect(tasks[1].EnqueuedAt).To(Equal(fclock.Now()))
+	})
+
+	It("spreads the apps across the workers", func() {
+		for i := 0; i < 8; i++ {
+			queue.Enqueue(triggersOf(fmt.Sprintf("app-id-%d", i)))
+		}
+		busyWorkers := 0
+		for worker := 0; worker < 4; worker++ {
+			if len(queue.Tasks(worker)) > 0 {
+				busyWorkers++
+			}
+		}
+		Expect(busyWorkers).To(BeNumerically(">", 1))
+	})
+
+	It("drops the evaluation when the queue of the worker is full", func() {
+		Expect(queue.Enqueue(triggersOf("an-app-id"))).To(BeTrue())
+		Expect(queue.Enqueue(triggersOf("an-app-id"))).To(BeTrue())
+		Expect(queue.Enqueue(triggersOf("an-app-id"))).To(BeFalse())
+
+		Expect(testutil.ToFloat64(metrics.DroppedEvaluations)).To(Equal(1.0))
+		count, sum := histogram(metrics.QueueDepth)
+		Expect(count).To(Equal(uint64(3)))
+		Expect(sum).To(Equal(3.0))
+	})
+
+	Describe("Take", func() {
+		var task *EvaluationTask
+
+		BeforeEach(func() {
+			Expect(queue.Enqueue(triggersOf("an-app-id"))).To(BeTrue())
+			for worker := 0; worker < 4; worker++ {
+				if len(queue.Tasks(worker)) > 0 {
+					task = <-queue.Tasks(worker)
+				}
+			}
+		})
+
+		It("records the lag and accepts a task before its deadline", func() {
+			fclock.Increment(10 * time.Second)
+			Expect(queue.Take(task)).To(BeTrue())
+
+			count, sum := histogram(metrics.EvaluationLag)
+			Expect(count).To(Equal(uint64(1)))
+			Expect(sum).To(Equal(10.0))
+			Expect(testutil.ToFloat64(metrics.LateEvaluations)).To(BeZero())
+		})
+
+		It("rejects a task after its deadline", func() {
+			fclock.Increment(deadline + time.Second)
+			Expect(queue.Take(task)).To(BeFalse())
+			Expect(testutil.ToFloat64(metrics.LateEvaluations)).To(Equal(1.0))
+		})
+	})
+})
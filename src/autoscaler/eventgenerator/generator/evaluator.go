@@ -3,18 +3,21 @@ package generator
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"time"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
 
 	"code.cloudfoundry.org/lager/v3"
+	uuid "github.com/nu7hatch/gouuid"
 	circuit "github.com/rubyist/circuitbreaker"
 )
 
@@ -24,26 +27,32 @@ type Evaluator struct {
 	logger                    lager.Logger
 	httpClient                *http.Client
 	scalingEngineUrl          string
-	triggerChan               chan []*models.Trigger
+	queue                     *EvaluationQueue
+	worker                    int
 	doneChan                  chan bool
 	defaultBreachDurationSecs int
 	queryAppMetrics           aggregator.QueryAppMetricsFunc
 	getBreaker                func(string) *circuit.Breaker
-	setCoolDownExpired        func(string, int64)
+	setCoolDownExpired        func(string, string, string, int64)
+	appLog                    applog.Emitter
 }
 
-func NewEvaluator(logger lager.Logger, httpClient *http.Client, scalingEngineUrl string, triggerChan chan []*models.Trigger,
-	defaultBreachDurationSecs int, queryAppMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, int64)) *Evaluator {
+// NewEvaluator creates an Evaluator which evaluates the tasks of the given worker of the queue.
+func NewEvaluator(logger lager.Logger, httpClient *http.Client, scalingEngineUrl string, queue *EvaluationQueue, worker int,
+	defaultBreachDurationSecs int, queryAppMetrics aggregator.QueryAppMetricsFunc, getBreaker func(string) *circuit.Breaker, setCoolDownExpired func(string, string, string, int64),
+	appLog applog.Emitter) *Evaluator {
 	return &Evaluator{
 		logger:                    logger.Session("Evaluator"),
 		httpClient:                httpClient,
 		scalingEngineUrl:          scalingEngineUrl,
-		triggerChan:               triggerChan,
+		queue:                     queue,
+		worker:                    worker,
 		doneChan:                  make(chan bool),
 		defaultBreachDurationSecs: defaultBreachDurationSecs,
 		queryAppMetrics:           queryAppMetrics,
 		getBreaker:                getBreaker,
 		setCoolDownExpired:        setCoolDownExpired,
+		appLog:                    appLog,
 	}
 }
 
@@ -57,8 +66,12 @@ func (e *Evaluator) start() {
 		select {
 		case <-e.doneChan:
 			return
-		case triggerArray := <-e.triggerChan:
-			e.doEvaluate(triggerArray)
+		case task := <-e.queue.Tasks(e.worker):
+			if !e.queue.Take(task) {
+				e.logger.Info("evaluation-late", lager.Data{"appId": task.Triggers[0].AppId, "deadline": task.Deadline})
+				continue
+			}
+			e.doEvaluate(task.Triggers)
 		}
 	}
 }
@@ -69,60 +82,265 @@ func (e *Evaluator) Stop() {
 	e.logger.Info("stopped")
 }
 
+// doEvaluate sends at most one trigger alarm per process type, the first breached trigger of a process type wins.
+// Process types of policies with a scale-in stabilization window which are not alarmed get a recommendation of their
+// current instances recorded instead.
 func (e *Evaluator) doEvaluate(triggerArray []*models.Trigger) {
+	alarmedProcessTypes := map[string]bool{}
 	for _, trigger := range triggerArray {
-		if trigger.BreachDurationSeconds <= 0 {
-			trigger.BreachDurationSeconds = e.defaultBreachDurationSecs
-		}
-		threshold := trigger.Threshold
-		operator := trigger.Operator
-		if !e.isValidOperator(operator) {
-			e.logger.Error("operator-is-invalid", nil, lager.Data{"trigger": trigger})
-			continue
-		}
-
-		appMetricList, err := e.retrieveAppMetrics(trigger)
-		if err != nil {
-			continue
-		}
-		if len(appMetricList) == 0 {
-			e.logger.Debug("no-available-appmetric", lager.Data{"trigger": trigger})
+		if alarmedProcessTypes[trigger.GetProcessType()] || trigger.CoolingDown {
 			continue
 		}
 
-		isBreached, appMetric := checkForBreach(appMetricList, e, trigger, operator, threshold)
-
-		if isBreached {
-			trigger.MetricUnit = appMetricList[0].Unit
-			e.logger.Info("send trigger alarm to scaling engine", lager.Data{"trigger": trigger, "last_metric": appMetric})
+		if e.evaluate(trigger, time.Now()) {
+			trigger.CorrelationId = newCorrelationId()
+			e.logger.Info("send trigger alarm to scaling engine", lager.Data{"trigger": trigger, "correlationId": trigger.CorrelationId})
+			e.appLog.Info(trigger.AppId, fmt.Sprintf("Breached trigger of process %s: %s", trigger.GetProcessType(), models.DynamicScalingReason(trigger)))
 
+			var err error
 			if appBreaker := e.getBreaker(trigger.AppId); appBreaker != nil {
 				if appBreaker.Tripped() {
 					e.logger.Info("circuit-tripped", lager.Data{"appId": trigger.AppId, "consecutiveFailures": appBreaker.ConsecFailures()})
 				}
 				err = appBreaker.Call(func() error { return e.sendTriggerAlarm(trigger) }, 0)
-				if err != nil {
-					e.logger.Error("circuit-alarm-failed", err, lager.Data{"appId": trigger.AppId})
-				}
 			} else {
 				err = e.sendTriggerAlarm(trigger)
-				if err != nil {
-					e.logger.Error("circuit-alarm-failed", err, lager.Data{"appId": trigger.AppId})
-				}
 			}
-			return
+			if errors.Is(err, circuit.ErrBreakerOpen) {
+				e.logger.Error("circuit-alarm-failed", err, lager.Data{"appId": trigger.AppId})
+				e.appLog.Error(trigger.AppId, fmt.Sprintf("Skipped scaling of process %s: the scaling engine failed too often, retrying later", trigger.GetProcessType()))
+			} else if err != nil {
+				e.logger.Error("circuit-alarm-failed", err, lager.Data{"appId": trigger.AppId})
+				e.appLog.Error(trigger.AppId, fmt.Sprintf("Failed to request scaling of process %s: %s", trigger.GetProcessType(), err.Error()))
+			}
+			alarmedProcessTypes[trigger.GetProcessType()] = true
 		}
 	}
+
+	for _, trigger := range triggerArray {
+		if alarmedProcessTypes[trigger.GetProcessType()] || trigger.Shadow || trigger.ScaleInStabilizationWindowSeconds == 0 {
+			continue
+		}
+		alarmedProcessTypes[trigger.GetProcessType()] = true
+		err := e.sendRecommendation(trigger)
+		if err != nil {
+			e.logger.Error("failed-to-send-recommendation", err, lager.Data{"appId": trigger.AppId, "processType": trigger.GetProcessType()})
+		}
+	}
+}
+
+// evaluate reports whether the trigger is breached by the metrics reported up to the given evaluation time.
+func (e *Evaluator) evaluate(trigger *models.Trigger, evaluationTime time.Time) bool {
+	if trigger.IsCompound() {
+		return e.evaluateCompoundTrigger(trigger, evaluationTime)
+	}
+	if trigger.IsTargetTracking() {
+		return e.evaluateTargetTrackingTrigger(trigger, evaluationTime)
+	}
+	return e.evaluateTrigger(trigger, evaluationTime)
 }
 
-func checkForBreach(appMetricList []*models.AppMetric, e *Evaluator, trigger *models.Trigger, operator string, threshold int64) (bool, *models.AppMetric) {
+func (e *Evaluator) evaluateTrigger(trigger *models.Trigger, evaluationTime time.Time) bool {
+	if trigger.BreachDurationSeconds <= 0 {
+		trigger.BreachDurationSeconds = e.defaultBreachDurationSecs
+	}
+	threshold := trigger.Threshold
+	operator := trigger.Operator
+	if !e.isValidOperator(operator) {
+		e.logger.Error("operator-is-invalid", nil, lager.Data{"trigger": trigger})
+		return false
+	}
+
+	appMetricList, err := e.retrieveAppMetrics(trigger, evaluationTime)
+	if err != nil {
+		return false
+	}
+	appMetricList, trigger.MissingDataPoints = treatMissingData(appMetricList, trigger.MissingData)
+	if trigger.MissingDataPoints > 0 {
+		e.logger.Debug("missing-appmetric-values", lager.Data{"trigger": trigger, "missingDataPoints": trigger.MissingDataPoints})
+	}
+	if len(appMetricList) == 0 {
+		e.logger.Debug("no-available-appmetric", lager.Data{"trigger": trigger})
+		return false
+	}
+
+	isBreached, appMetric := checkForBreach(appMetricList, e, trigger, operator, threshold)
+	if isBreached {
+		latest := latestAppMetricWithValue(appMetricList)
+		trigger.MetricUnit = latest.Unit
+		trigger.MetricValue, _ = strconv.ParseFloat(latest.Value, 64)
+		e.logger.Debug("trigger-breached", lager.Data{"trigger": trigger, "last_metric": appMetric})
+	}
+	return isBreached
+}
+
+// treatMissingData applies the missing data treatment of a trigger to the app metrics of its breach duration, which
+// are ordered from the latest to the oldest, and returns them together with the number of metrics without value.
+// Ignored metrics are dropped, metrics without value are replaced with the latest value reported before them within
+// the breach duration or dropped if there is none. Breaching and not breaching metrics are kept for checkForBreach.
+func treatMissingData(appMetricList []*models.AppMetric, missingData string) ([]*models.AppMetric, int) {
+	missing := 0
+	for _, appMetric := range appMetricList {
+		if appMetric.Value == "" {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return appMetricList, 0
+	}
+
+	switch missingData {
+	case models.MissingDataIgnore:
+		result := []*models.AppMetric{}
+		for _, appMetric := range appMetricList {
+			if appMetric.Value != "" {
+				result = append(result, appMetric)
+			}
+		}
+		return result, missing
+	case models.MissingDataLastKnown:
+		var lastKnown *models.AppMetric
+		result := []*models.AppMetric{}
+		for i := len(appMetricList) - 1; i >= 0; i-- {
+			appMetric := appMetricList[i]
+			if appMetric.Value != "" {
+				lastKnown = appMetric
+			} else if lastKnown != nil {
+				// the app metrics are shared with the metric cache, so they must not be modified
+				filled := *appMetric
+				filled.Value = lastKnown.Value
+				filled.Unit = lastKnown.Unit
+				appMetric = &filled
+			} else {
+				continue
+			}
+			result = append([]*models.AppMetric{appMetric}, result...)
+		}
+		return result, missing
+	default:
+		return appMetricList, missing
+	}
+}
+
+// latestAppMetricWithValue returns the latest of the app metrics which has a value, or the latest app metric if none
+// has one, as it happens when missing data is treated as breaching.
+func latestAppMetricWithValue(appMetricList []*models.AppMetric) *models.AppMetric {
+	for _, appMetric := range appMetricList {
+		if appMetric.Value != "" {
+			return appMetric
+		}
+	}
+	return appMetricList[0]
+}
+
+// evaluateCompoundTrigger evaluates every condition of a compound trigger as if it was a trigger on its own and
+// combines the results. Only the conditions which contributed to the breach are kept in the trigger.
+func (e *Evaluator) evaluateCompoundTrigger(trigger *models.Trigger, evaluationTime time.Time) bool {
+	contributing := []*models.TriggerCondition{}
+	for _, condition := range trigger.Conditions {
+		conditionTrigger := &models.Trigger{
+			AppId:                 trigger.AppId,
+			MetricType:            condition.MetricType,
+			BreachDurationSeconds: condition.BreachDurationSeconds,
+			Threshold:             condition.Threshold,
+			Operator:              condition.Operator,
+			MissingData:           condition.MissingData,
+		}
+		isBreached := e.evaluateTrigger(conditionTrigger, evaluationTime)
+		condition.BreachDurationSeconds = conditionTrigger.BreachDurationSeconds
+		condition.MetricUnit = conditionTrigger.MetricUnit
+		condition.MissingDataPoints = conditionTrigger.MissingDataPoints
+
+		if isBreached {
+			contributing = append(contributing, condition)
+		} else if trigger.Combinator != models.CombinatorOr {
+			e.logger.Debug("compound-condition-not-breached", lager.Data{"trigger": trigger, "condition": condition})
+			return false
+		}
+	}
+
+	if len(contributing) == 0 {
+		return false
+	}
+	trigger.Conditions = contributing
+	return true
+}
+
+// evaluateTargetTrackingTrigger reports whether the metric stayed on the same side of the target value, beyond the
+// tolerance of the trigger, during the whole breach duration. The missing data treatment of the trigger applies as for
+// threshold rules, breaching missing data counts as off target. The average of the reported metric values over that
+// period is stored in the trigger so that the scaling engine can derive the number of instances from it.
+func (e *Evaluator) evaluateTargetTrackingTrigger(trigger *models.Trigger, evaluationTime time.Time) bool {
+	if trigger.BreachDurationSeconds <= 0 {
+		trigger.BreachDurationSeconds = e.defaultBreachDurationSecs
+	}
+
+	appMetricList, err := e.retrieveAppMetrics(trigger, evaluationTime)
+	if err != nil {
+		return false
+	}
+	appMetricList, trigger.MissingDataPoints = treatMissingData(appMetricList, trigger.MissingData)
+	if trigger.MissingDataPoints > 0 {
+		e.logger.Debug("missing-appmetric-values", lager.Data{"trigger": trigger, "missingDataPoints": trigger.MissingDataPoints})
+	}
+	if len(appMetricList) == 0 {
+		e.logger.Debug("no-available-appmetric", lager.Data{"trigger": trigger})
+		return false
+	}
+
+	upperBound := trigger.TargetValue * (1 + trigger.GetTolerance())
+	lowerBound := trigger.TargetValue * (1 - trigger.GetTolerance())
+	var sum float64
+	values, above, below := 0, 0, 0
+	for _, appMetric := range appMetricList {
+		if appMetric.Value == "" && trigger.MissingData == models.MissingDataBreaching {
+			continue
+		}
+		if appMetric.Value == "" {
+			e.logger.Debug("should not send trigger alarm to scaling engine because there is empty value metric", lager.Data{"trigger": trigger, "appMetric": appMetric})
+			return false
+		}
+		value, err := strconv.ParseFloat(appMetric.Value, 64)
+		if err != nil {
+			e.logger.Debug("should not send trigger alarm to scaling engine because parse metric value fails", lager.Data{"trigger": trigger, "appMetric": appMetric})
+			return false
+		}
+		if value > upperBound {
+			above++
+		} else if value < lowerBound {
+			below++
+		}
+		sum += value
+		values++
+	}
+
+	if values == 0 {
+		e.logger.Debug("should not send trigger alarm to scaling engine because there is no metric value to track", lager.Data{"trigger": trigger})
+		return false
+	}
+	if above != values && below != values {
+		e.logger.Debug("should not send trigger alarm to scaling engine because metric is within the tolerance of the target", lager.Data{"trigger": trigger})
+		return false
+	}
+
+	latest := latestAppMetricWithValue(appMetricList)
+	trigger.MetricValue = sum / float64(values)
+	trigger.MetricUnit = latest.Unit
+	e.logger.Debug("trigger-off-target", lager.Data{"trigger": trigger})
+	return true
+}
+
+func checkForBreach(appMetricList []*models.AppMetric, e *Evaluator, trigger *models.Trigger, operator string, threshold float64) (bool, *models.AppMetric) {
 	var appMetric *models.AppMetric
 	for _, appMetric = range appMetricList {
+		if appMetric.Value == "" && trigger.MissingData == models.MissingDataBreaching {
+			continue
+		}
 		if appMetric.Value == "" {
 			e.logger.Debug("should not send trigger alarm to scaling engine because there is empty value metric", lager.Data{"trigger": trigger, "appMetric": appMetric})
 			return false, appMetric
 		}
-		value, err := strconv.ParseInt(appMetric.Value, 10, 64)
+		value, err := strconv.ParseFloat(appMetric.Value, 64)
 		if err != nil {
 			e.logger.Debug("should not send trigger alarm to scaling engine because parse metric value fails", lager.Data{"trigger": trigger, "appMetric": appMetric})
 			return false, appMetric
@@ -152,8 +370,8 @@ func checkForBreach(appMetricList []*models.AppMetric, e *Evaluator, trigger *mo
 	return true, appMetric
 }
 
-func (e *Evaluator) retrieveAppMetrics(trigger *models.Trigger) ([]*models.AppMetric, error) {
-	queryEndTime := time.Now()
+func (e *Evaluator) retrieveAppMetrics(trigger *models.Trigger, evaluationTime time.Time) ([]*models.AppMetric, error) {
+	queryEndTime := evaluationTime
 	queryStartTime := queryEndTime.Add(0 - 2*trigger.BreachDuration())
 	breachStartTime := queryEndTime.Add(0 - trigger.BreachDuration())
 
@@ -193,7 +411,14 @@ func (e *Evaluator) sendTriggerAlarm(trigger *models.Trigger) error {
 		return fmt.Errorf("failed to create url ScaleRouteName, %s: %w", trigger.AppId, err)
 	}
 
-	resp, err := e.httpClient.Post(e.scalingEngineUrl+path.Path, "application/json", bytes.NewReader(jsonBytes))
+	req, err := http.NewRequest(http.MethodPost, e.scalingEngineUrl+path.Path, bytes.NewReader(jsonBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create trigger alarm request, %s: %w", trigger.AppId, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(models.CorrelationIdHeader, trigger.CorrelationId)
+
+	resp, err := e.httpClient.Do(req)
 	if err != nil {
 		e.logger.Error("failed-send-trigger-alarm-request", err, lager.Data{"trigger": trigger})
 		return err
@@ -215,7 +440,11 @@ func (e *Evaluator) sendTriggerAlarm(trigger *models.Trigger) error {
 		}
 		e.logger.Debug("successfully-send-trigger-alarm with trigger", lager.Data{"trigger": trigger, "responseBody": string(respBody)})
 		if scalingResult.CooldownExpiredAt != 0 {
-			e.setCoolDownExpired(trigger.AppId, scalingResult.CooldownExpiredAt)
+			direction := scalingResult.Direction
+			if direction == "" {
+				direction = trigger.ScalingDirection()
+			}
+			e.setCoolDownExpired(trigger.AppId, trigger.GetProcessType(), direction, scalingResult.CooldownExpiredAt)
 		}
 		return nil
 	}
@@ -224,6 +453,39 @@ func (e *Evaluator) sendTriggerAlarm(trigger *models.Trigger) error {
 	return err
 }
 
+// sendRecommendation asks the scaling engine to record the current instances of the process type of the trigger as
+// scale-in stabilization recommendation.
+func (e *Evaluator) sendRecommendation(trigger *models.Trigger) error {
+	jsonBytes, err := json.Marshal(trigger)
+	if err != nil {
+		return err
+	}
+
+	path, err := routes.ScalingEngineRoutes().Get(routes.RecommendRouteName).URLPath("appid", trigger.AppId)
+	if err != nil {
+		return fmt.Errorf("failed to create url RecommendRouteName, %s: %w", trigger.AppId, err)
+	}
+
+	resp, err := e.httpClient.Post(e.scalingEngineUrl+path.Path, "application/json", bytes.NewReader(jsonBytes))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got %d when sending recommendation", resp.StatusCode)
+	}
+	return nil
+}
+
+func newCorrelationId() string {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ""
+	}
+	return id.String()
+}
+
 func (e *Evaluator) isValidOperator(operator string) bool {
 	for _, o := range validOperators {
 		if o == operator {
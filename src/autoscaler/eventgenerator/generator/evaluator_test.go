@@ -9,10 +9,13 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/routes"
 
 	"code.cloudfoundry.org/cfhttp/v2"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	"github.com/cenkalti/backoff/v4"
 	. "github.com/onsi/ginkgo/v2"
@@ -25,7 +28,7 @@ var _ = Describe("Evaluator", func() {
 	var (
 		logger             *lagertest.TestLogger
 		httpClient         *http.Client
-		triggerChan        chan []*models.Trigger
+		queue              *EvaluationQueue
 		scalingEngine      *ghttp.Server
 		evaluator          *Evaluator
 		testAppId          = "testAppId"
@@ -35,9 +38,11 @@ var _ = Describe("Evaluator", func() {
 		breachDurationSecs = 30
 		queryAppMetrics    aggregator.QueryAppMetricsFunc
 		getBreaker         func(string) *circuit.Breaker
-		setCoolDownExpired func(string, int64)
+		setCoolDownExpired func(string, string, string, int64)
+		appLog             *fakes.FakeAppLogEmitter
 		cbEventChan        <-chan circuit.BreakerEvent
 		cooldownExpired    map[string]int64
+		cooldownDirection  map[string]string
 		fakeTime           = time.Now()
 		lock               = &sync.Mutex{}
 		scalingResult      *models.AppScalingResult
@@ -102,7 +107,8 @@ var _ = Describe("Evaluator", func() {
 	BeforeEach(func() {
 		logger = lagertest.NewTestLogger("Evaluator-test")
 		httpClient = cfhttp.NewClient()
-		triggerChan = make(chan []*models.Trigger, 1)
+		queue = NewEvaluationQueue(clock.NewClock(), 1, 1, time.Minute, NewEvaluationMetrics())
+		appLog = &fakes.FakeAppLogEmitter{}
 
 		path, err := routes.ScalingEngineRoutes().Get(routes.ScaleRouteName).URLPath("appid", testAppId)
 		Expect(err).NotTo(HaveOccurred())
@@ -123,20 +129,18 @@ var _ = Describe("Evaluator", func() {
 		}
 
 		cooldownExpired = map[string]int64{}
-		setCoolDownExpired = func(appId string, expiredAt int64) {
+		cooldownDirection = map[string]string{}
+		setCoolDownExpired = func(appId string, processType string, direction string, expiredAt int64) {
 			lock.Lock()
 			defer lock.Unlock()
 			cooldownExpired[appId] = expiredAt
+			cooldownDirection[appId] = direction
 		}
 
 	})
-	AfterEach(func() {
-		close(triggerChan)
-	})
-
 	Context("Start", func() {
 		JustBeforeEach(func() {
-			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired)
+			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), queue, 0, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired, appLog)
 			evaluator.Start()
 		})
 
@@ -154,7 +158,7 @@ var _ = Describe("Evaluator", func() {
 			Context("when the appMetrics are not enough", func() {
 				BeforeEach(func() {
 					scalingEngine.RouteToHandler("POST", urlPath, ghttp.RespondWith(http.StatusOK, "successful"))
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{600, 650, 620}, breachDurationSecs, false)
 					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
 						return appMetrics, nil
@@ -171,7 +175,7 @@ var _ = Describe("Evaluator", func() {
 				})
 				Context(">", func() {
 					BeforeEach(func() {
-						Expect(triggerChan).To(BeSent(triggerArrayGT))
+						Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					})
 					Context("when the appMetrics breach the trigger", func() {
 						BeforeEach(func() {
@@ -185,12 +189,16 @@ var _ = Describe("Evaluator", func() {
 										AppId:                 testAppId,
 										MetricType:            testMetricType,
 										MetricUnit:            testMetricUnit,
+										MetricValue:           620,
 										BreachDurationSeconds: breachDurationSecs,
 										CoolDownSeconds:       300,
 										Threshold:             500,
 										Operator:              ">",
 										Adjustment:            "+1",
 									}),
+									func(_ http.ResponseWriter, req *http.Request) {
+										Expect(req.Header.Get(models.CorrelationIdHeader)).NotTo(BeEmpty())
+									},
 									ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult)),
 							)
 						})
@@ -199,6 +207,13 @@ var _ = Describe("Evaluator", func() {
 							Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("send trigger alarm to scaling engine")))
 						})
 
+						It("should write the breach into the log stream of the app", func() {
+							Eventually(appLog.InfoCallCount).Should(Equal(1))
+							appId, message := appLog.InfoArgsForCall(0)
+							Expect(appId).To(Equal(testAppId))
+							Expect(message).To(Equal("Breached trigger of process web: +1 instance(s) because testMetricType > 500testMetricUnit for 30 seconds"))
+						})
+
 					})
 					Context("when the appMetrics do not breach the trigger", func() {
 						BeforeEach(func() {
@@ -242,9 +257,51 @@ var _ = Describe("Evaluator", func() {
 						})
 					})
 				})
+				Context("with a decimal threshold", func() {
+					BeforeEach(func() {
+						Expect(queue.Enqueue([]*models.Trigger{{
+							AppId:           testAppId,
+							MetricType:      testMetricType,
+							CoolDownSeconds: 300,
+							Threshold:       0.7,
+							Operator:        ">",
+							Adjustment:      "+1",
+						}})).To(BeTrue())
+					})
+					Context("when the decimal appMetrics breach the trigger", func() {
+						BeforeEach(func() {
+							appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{}, breachDurationSecs, true)
+							for _, value := range []string{"0.75", "0.8", "0.71"} {
+								appMetrics = append(appMetrics, &models.AppMetric{AppId: testAppId, MetricType: testMetricType, Value: value, Unit: testMetricUnit, Timestamp: time.Now().UnixNano()})
+							}
+							queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+								return appMetrics, nil
+							}
+						})
+						It("should send trigger alarm to scaling engine", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						})
+					})
+
+					Context("when the decimal appMetrics do not breach the trigger", func() {
+						BeforeEach(func() {
+							appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{}, breachDurationSecs, true)
+							for _, value := range []string{"0.75", "0.7", "0.71"} {
+								appMetrics = append(appMetrics, &models.AppMetric{AppId: testAppId, MetricType: testMetricType, Value: value, Unit: testMetricUnit, Timestamp: time.Now().UnixNano()})
+							}
+							queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+								return appMetrics, nil
+							}
+						})
+						It("should not send trigger alarm to scaling engine", func() {
+							Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+							Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("should not send trigger alarm to scaling engine")))
+						})
+					})
+				})
 				Context(">=", func() {
 					BeforeEach(func() {
-						Expect(triggerChan).To(BeSent(triggerArrayGE))
+						Expect(queue.Enqueue(triggerArrayGE)).To(BeTrue())
 					})
 
 					Context("when the appMetrics breach the trigger", func() {
@@ -305,7 +362,7 @@ var _ = Describe("Evaluator", func() {
 				})
 				Context("<", func() {
 					BeforeEach(func() {
-						Expect(triggerChan).To(BeSent(triggerArrayLT))
+						Expect(queue.Enqueue(triggerArrayLT)).To(BeTrue())
 					})
 					Context("when the appMetrics breach the trigger", func() {
 						BeforeEach(func() {
@@ -365,7 +422,7 @@ var _ = Describe("Evaluator", func() {
 				})
 				Context("<=", func() {
 					BeforeEach(func() {
-						Expect(triggerChan).To(BeSent(triggerArrayLE))
+						Expect(queue.Enqueue(triggerArrayLE)).To(BeTrue())
 					})
 					Context("when the appMetrics breach the trigger", func() {
 						BeforeEach(func() {
@@ -428,14 +485,16 @@ var _ = Describe("Evaluator", func() {
 
 			Context("multiple triggers", func() {
 				BeforeEach(func() {
-					Expect(triggerChan).To(BeSent(triggerArrayMultipleTriggers))
+					Expect(queue.Enqueue(triggerArrayMultipleTriggers)).To(BeTrue())
 				})
 				Context("when only the first trigger breaches", func() {
 					BeforeEach(func() {
+						breached := firstTrigger
+						breached.MetricValue = 600
 						scalingEngine.AppendHandlers(
 							ghttp.CombineHandlers(
 								ghttp.VerifyRequest("POST", urlPath),
-								ghttp.VerifyJSONRepresenting(firstTrigger),
+								ghttp.VerifyJSONRepresenting(breached),
 								ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
 							),
 						)
@@ -454,10 +513,12 @@ var _ = Describe("Evaluator", func() {
 
 				Context("when only second tigger breaches", func() {
 					BeforeEach(func() {
+						breached := secondTrigger
+						breached.MetricValue = 500
 						scalingEngine.AppendHandlers(
 							ghttp.CombineHandlers(
 								ghttp.VerifyRequest("POST", urlPath),
-								ghttp.VerifyJSONRepresenting(secondTrigger),
+								ghttp.VerifyJSONRepresenting(breached),
 								ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
 							),
 						)
@@ -476,10 +537,12 @@ var _ = Describe("Evaluator", func() {
 
 				Context("when both tiggers breach", func() {
 					BeforeEach(func() {
+						breached := firstTrigger
+						breached.MetricValue = 500
 						scalingEngine.AppendHandlers(
 							ghttp.CombineHandlers(
 								ghttp.VerifyRequest("POST", urlPath),
-								ghttp.VerifyJSONRepresenting(firstTrigger),
+								ghttp.VerifyJSONRepresenting(breached),
 								ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
 							),
 						)
@@ -497,13 +560,404 @@ var _ = Describe("Evaluator", func() {
 
 			})
 
+			Context("when triggers of different process types breach", func() {
+				BeforeEach(func() {
+					webTrigger := firstTrigger
+					webTrigger.ProcessType = "web"
+					workerTrigger := firstTrigger
+					workerTrigger.ProcessType = "worker"
+					breachedWebTrigger := webTrigger
+					breachedWebTrigger.MetricValue = 600
+					breachedWorkerTrigger := workerTrigger
+					breachedWorkerTrigger.MetricValue = 600
+					scalingEngine.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", urlPath),
+							ghttp.VerifyJSONRepresenting(breachedWebTrigger),
+							ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", urlPath),
+							ghttp.VerifyJSONRepresenting(breachedWorkerTrigger),
+							ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
+						),
+					)
+					appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{500, 550, 600}, breachDurationSecs, true)
+					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+						return appMetrics, nil
+					}
+					Expect(queue.Enqueue([]*models.Trigger{&webTrigger, &workerTrigger})).To(BeTrue())
+				})
+				It("should send an alarm for each process type to scaling engine", func() {
+					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(2))
+				})
+			})
+
+			Context("when the policy has a scale-in stabilization window", func() {
+				var recommendPath string
+
+				BeforeEach(func() {
+					path, err := routes.ScalingEngineRoutes().Get(routes.RecommendRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					recommendPath = path.Path
+
+					webTrigger := firstTrigger
+					webTrigger.ProcessType = "web"
+					webTrigger.ScaleInStabilizationWindowSeconds = 300
+					workerTrigger := webTrigger
+					workerTrigger.ProcessType = "worker"
+					workerTrigger.CoolingDown = true
+					scalingEngine.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", recommendPath),
+							ghttp.VerifyJSONRepresenting(webTrigger),
+							ghttp.RespondWith(http.StatusOK, nil),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("POST", recommendPath),
+							ghttp.VerifyJSONRepresenting(workerTrigger),
+							ghttp.RespondWith(http.StatusOK, nil),
+						),
+					)
+					appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{200, 150, 100}, breachDurationSecs, true)
+					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+						return appMetrics, nil
+					}
+					Expect(queue.Enqueue([]*models.Trigger{&webTrigger, &workerTrigger})).To(BeTrue())
+				})
+
+				It("should send a recommendation for each process type without alarm to scaling engine", func() {
+					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(2))
+					Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(2))
+				})
+			})
+
+			Context("missing data", func() {
+				var (
+					missingDataTrigger *models.Trigger
+					appMetrics         []*models.AppMetric
+				)
+				BeforeEach(func() {
+					missingDataTrigger = &models.Trigger{
+						AppId:                 testAppId,
+						MetricType:            testMetricType,
+						BreachDurationSeconds: breachDurationSecs,
+						CoolDownSeconds:       300,
+						Threshold:             500,
+						Operator:              ">",
+						Adjustment:            "+1",
+					}
+					appMetrics = generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{600, 650, 620}, breachDurationSecs, true)
+					appMetrics = append(appMetrics, &models.AppMetric{AppId: testAppId, MetricType: testMetricType, Timestamp: time.Now().UnixNano()})
+					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+						return appMetrics, nil
+					}
+					scalingEngine.RouteToHandler("POST", urlPath, ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult))
+				})
+				JustBeforeEach(func() {
+					Expect(queue.Enqueue([]*models.Trigger{missingDataTrigger})).To(BeTrue())
+				})
+
+				Context("when no treatment is set", func() {
+					It("should not send trigger alarm to scaling engine", func() {
+						Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+						Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("should not send trigger alarm to scaling engine because there is empty value metric")))
+					})
+				})
+
+				Context("when missing data is not breaching", func() {
+					BeforeEach(func() {
+						missingDataTrigger.MissingData = models.MissingDataNotBreaching
+					})
+					It("should not send trigger alarm to scaling engine", func() {
+						Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+					})
+				})
+
+				Context("when missing data is ignored", func() {
+					BeforeEach(func() {
+						missingDataTrigger.MissingData = models.MissingDataIgnore
+					})
+					It("should send trigger alarm with the latest value and the number of missing data points", func() {
+						Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						Expect(missingDataTrigger.MetricValue).To(Equal(620.0))
+						Expect(missingDataTrigger.MetricUnit).To(Equal(testMetricUnit))
+						Expect(missingDataTrigger.MissingDataPoints).To(Equal(1))
+					})
+
+					Context("when all metrics are missing", func() {
+						BeforeEach(func() {
+							appMetrics = generateTestAppMetrics(testAppId, testMetricType, "", []int64{}, breachDurationSecs, true)
+							appMetrics = append(appMetrics, &models.AppMetric{AppId: testAppId, MetricType: testMetricType, Timestamp: time.Now().UnixNano()})
+						})
+						It("should not send trigger alarm to scaling engine", func() {
+							Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+							Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("no-available-appmetric")))
+						})
+					})
+				})
+
+				Context("when missing data is breaching", func() {
+					BeforeEach(func() {
+						missingDataTrigger.MissingData = models.MissingDataBreaching
+					})
+					It("should send trigger alarm with the latest value", func() {
+						Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						Expect(missingDataTrigger.MetricValue).To(Equal(620.0))
+						Expect(missingDataTrigger.MissingDataPoints).To(Equal(1))
+					})
+
+					Context("when the app stopped reporting metrics", func() {
+						BeforeEach(func() {
+							appMetrics = generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{100}, breachDurationSecs, true)
+							appMetrics[1].Value = ""
+							appMetrics = append(appMetrics, &models.AppMetric{AppId: testAppId, MetricType: testMetricType, Timestamp: time.Now().UnixNano()})
+						})
+						It("should send trigger alarm to scaling engine", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+							Expect(missingDataTrigger.MissingDataPoints).To(Equal(2))
+						})
+					})
+				})
+
+				Context("when the last known value is used", func() {
+					BeforeEach(func() {
+						missingDataTrigger.MissingData = models.MissingDataLastKnown
+					})
+					It("should send trigger alarm with the last known value", func() {
+						Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						Expect(missingDataTrigger.MetricValue).To(Equal(620.0))
+						Expect(missingDataTrigger.MissingDataPoints).To(Equal(1))
+					})
+					It("should not modify the cached metrics", func() {
+						Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						Expect(appMetrics[len(appMetrics)-1].Value).To(BeEmpty())
+					})
+
+					Context("when the last known value does not breach the trigger", func() {
+						BeforeEach(func() {
+							appMetrics = generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{600, 200}, breachDurationSecs, true)
+							appMetrics = append(appMetrics, &models.AppMetric{AppId: testAppId, MetricType: testMetricType, Timestamp: time.Now().UnixNano()})
+						})
+						It("should not send trigger alarm to scaling engine", func() {
+							Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+						})
+					})
+				})
+			})
+
+			Context("compound triggers", func() {
+				var (
+					compoundTrigger *models.Trigger
+					metricsByType   map[string][]*models.AppMetric
+				)
+				BeforeEach(func() {
+					compoundTrigger = &models.Trigger{
+						AppId:           testAppId,
+						CoolDownSeconds: 300,
+						Adjustment:      "+1",
+						Combinator:      models.CombinatorAnd,
+						Conditions: []*models.TriggerCondition{
+							{MetricType: "cpu", BreachDurationSeconds: breachDurationSecs, Threshold: 70, Operator: ">"},
+							{MetricType: "responsetime", BreachDurationSeconds: breachDurationSecs, Threshold: 300, Operator: ">"},
+						},
+					}
+					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+						return metricsByType[metricType], nil
+					}
+					scalingEngine.RouteToHandler("POST", urlPath, ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult))
+				})
+
+				Context("with the and combinator", func() {
+					JustBeforeEach(func() {
+						Expect(queue.Enqueue([]*models.Trigger{compoundTrigger})).To(BeTrue())
+					})
+
+					Context("when all conditions breach", func() {
+						BeforeEach(func() {
+							metricsByType = map[string][]*models.AppMetric{
+								"cpu":          generateTestAppMetrics(testAppId, "cpu", "%", []int64{80, 90, 85}, breachDurationSecs, true),
+								"responsetime": generateTestAppMetrics(testAppId, "responsetime", "ms", []int64{400, 350, 500}, breachDurationSecs, true),
+							}
+						})
+						It("should send the trigger with all conditions to scaling engine", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+							Expect(compoundTrigger.Conditions).To(HaveLen(2))
+							Expect(compoundTrigger.Conditions[0].MetricUnit).To(Equal("%"))
+							Expect(compoundTrigger.Conditions[1].MetricUnit).To(Equal("ms"))
+						})
+					})
+
+					Context("when only one condition breaches", func() {
+						BeforeEach(func() {
+							metricsByType = map[string][]*models.AppMetric{
+								"cpu":          generateTestAppMetrics(testAppId, "cpu", "%", []int64{80, 90, 85}, breachDurationSecs, true),
+								"responsetime": generateTestAppMetrics(testAppId, "responsetime", "ms", []int64{100, 350, 500}, breachDurationSecs, true),
+							}
+						})
+						It("should not send trigger alarm to scaling engine", func() {
+							Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+						})
+					})
+				})
+
+				Context("with the or combinator", func() {
+					BeforeEach(func() {
+						compoundTrigger.Combinator = models.CombinatorOr
+					})
+					JustBeforeEach(func() {
+						Expect(queue.Enqueue([]*models.Trigger{compoundTrigger})).To(BeTrue())
+					})
+
+					Context("when only one condition breaches", func() {
+						BeforeEach(func() {
+							metricsByType = map[string][]*models.AppMetric{
+								"cpu":          generateTestAppMetrics(testAppId, "cpu", "%", []int64{50, 40, 60}, breachDurationSecs, true),
+								"responsetime": generateTestAppMetrics(testAppId, "responsetime", "ms", []int64{400, 350, 500}, breachDurationSecs, true),
+							}
+						})
+						It("should send the trigger with the breached condition only", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+							Expect(compoundTrigger.Conditions).To(HaveLen(1))
+							Expect(compoundTrigger.Conditions[0].MetricType).To(Equal("responsetime"))
+						})
+					})
+
+					Context("when no condition breaches", func() {
+						BeforeEach(func() {
+							metricsByType = map[string][]*models.AppMetric{
+								"cpu":          generateTestAppMetrics(testAppId, "cpu", "%", []int64{50, 40, 60}, breachDurationSecs, true),
+								"responsetime": generateTestAppMetrics(testAppId, "responsetime", "ms", []int64{100, 150, 200}, breachDurationSecs, true),
+							}
+						})
+						It("should not send trigger alarm to scaling engine", func() {
+							Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+						})
+					})
+				})
+			})
+
+			Context("target tracking triggers", func() {
+				var (
+					targetTrackingTrigger *models.Trigger
+					appMetrics            []*models.AppMetric
+				)
+				BeforeEach(func() {
+					targetTrackingTrigger = &models.Trigger{
+						AppId:                 testAppId,
+						MetricType:            "cpu",
+						BreachDurationSeconds: breachDurationSecs,
+						CoolDownSeconds:       300,
+						TargetValue:           60,
+					}
+					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+						return appMetrics, nil
+					}
+					scalingEngine.RouteToHandler("POST", urlPath, ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult))
+				})
+				JustBeforeEach(func() {
+					Expect(queue.Enqueue([]*models.Trigger{targetTrackingTrigger})).To(BeTrue())
+				})
+
+				Context("when the metric stays above the target", func() {
+					BeforeEach(func() {
+						appMetrics = generateTestAppMetrics(testAppId, "cpu", "%", []int64{80, 90, 85}, breachDurationSecs, true)
+					})
+					It("should send the trigger with the average metric value to scaling engine", func() {
+						Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						Expect(targetTrackingTrigger.MetricValue).To(Equal(85.0))
+						Expect(targetTrackingTrigger.MetricUnit).To(Equal("%"))
+					})
+				})
+
+				Context("when the metric stays below the target", func() {
+					BeforeEach(func() {
+						appMetrics = generateTestAppMetrics(testAppId, "cpu", "%", []int64{20, 30, 40}, breachDurationSecs, true)
+					})
+					It("should send the trigger to scaling engine", func() {
+						Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						Expect(targetTrackingTrigger.MetricValue).To(Equal(30.0))
+					})
+				})
+
+				Context("when the metric moves around the target", func() {
+					BeforeEach(func() {
+						appMetrics = generateTestAppMetrics(testAppId, "cpu", "%", []int64{50, 70, 60}, breachDurationSecs, true)
+					})
+					It("should not send trigger alarm to scaling engine", func() {
+						Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+					})
+				})
+
+				Context("when the metric stays within the tolerance of the target", func() {
+					BeforeEach(func() {
+						appMetrics = generateTestAppMetrics(testAppId, "cpu", "%", []int64{64, 65, 62}, breachDurationSecs, true)
+					})
+					It("should not send trigger alarm to scaling engine", func() {
+						Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+					})
+
+					Context("when the tolerance is lower", func() {
+						BeforeEach(func() {
+							targetTrackingTrigger.Tolerance = 0.02
+						})
+						It("should send the trigger to scaling engine", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+						})
+					})
+				})
+
+				Context("when metric values are missing", func() {
+					BeforeEach(func() {
+						appMetrics = generateTestAppMetrics(testAppId, "cpu", "%", []int64{80, 90}, breachDurationSecs, true)
+						appMetrics = append(appMetrics, &models.AppMetric{AppId: testAppId, MetricType: "cpu", Timestamp: time.Now().UnixNano()})
+					})
+
+					Context("when no treatment is set", func() {
+						It("should not send trigger alarm to scaling engine", func() {
+							Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+						})
+					})
+
+					Context("when missing data is ignored", func() {
+						BeforeEach(func() {
+							targetTrackingTrigger.MissingData = models.MissingDataIgnore
+						})
+						It("should send the trigger with the average of the reported values", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+							Expect(targetTrackingTrigger.MetricValue).To(Equal(85.0))
+							Expect(targetTrackingTrigger.MissingDataPoints).To(Equal(1))
+						})
+					})
+
+					Context("when missing data is breaching", func() {
+						BeforeEach(func() {
+							targetTrackingTrigger.MissingData = models.MissingDataBreaching
+						})
+						It("should send the trigger with the average of the reported values", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+							Expect(targetTrackingTrigger.MetricValue).To(Equal(85.0))
+						})
+
+						Context("when no value is reported", func() {
+							BeforeEach(func() {
+								appMetrics = []*models.AppMetric{{AppId: testAppId, MetricType: "cpu", Timestamp: time.Now().UnixNano()}}
+							})
+							It("should not send trigger alarm to scaling engine", func() {
+								Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(0))
+							})
+						})
+					})
+				})
+			})
+
 			Context("sending trigger ", func() {
 				BeforeEach(func() {
 					appMetrics := generateTestAppMetrics(testAppId, testMetricType, testMetricUnit, []int64{600, 650, 620}, breachDurationSecs, true)
 					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
 						return appMetrics, nil
 					}
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 				})
 
 				Context("when the scaling engine returns 200 with different scalingResults", func() {
@@ -524,10 +978,32 @@ var _ = Describe("Evaluator", func() {
 							lock.Lock()
 							Eventually(cooldownExpired).Should(HaveLen(1))
 							Eventually(cooldownExpired[testAppId]).Should(Equal(fakeTime.Add(time.Duration(300) * time.Second).UnixNano()))
+							Expect(cooldownDirection[testAppId]).To(Equal(models.ScalingDirectionOut))
 							lock.Unlock()
 						})
 					})
 
+					Context("when the direction is set in scalingResult", func() {
+						BeforeEach(func() {
+							scalingResult.Direction = models.ScalingDirectionIn
+							scalingEngine.AppendHandlers(
+								ghttp.CombineHandlers(
+									ghttp.VerifyRequest("POST", urlPath),
+									ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult),
+								),
+							)
+						})
+
+						It("should set the cooldown of the direction of the scalingResult", func() {
+							Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+							Eventually(func() string {
+								lock.Lock()
+								defer lock.Unlock()
+								return cooldownDirection[testAppId]
+							}).Should(Equal(models.ScalingDirectionIn))
+						})
+					})
+
 					Context("when cooldownExpiredAt is 0 in scalingResult", func() {
 						BeforeEach(func() {
 							scalingResult.CooldownExpiredAt = 0
@@ -600,6 +1076,13 @@ var _ = Describe("Evaluator", func() {
 						Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
 						Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("failed-send-trigger-alarm")))
 					})
+
+					It("should write the error into the log stream of the app", func() {
+						Eventually(appLog.ErrorCallCount).Should(Equal(1))
+						appId, message := appLog.ErrorArgsForCall(0)
+						Expect(appId).To(Equal(testAppId))
+						Expect(message).To(Equal("Failed to request scaling of process web: got 400 when sending trigger alarm"))
+					})
 				})
 
 				PContext("when the scaling engine's response is too long", func() {
@@ -649,42 +1132,45 @@ var _ = Describe("Evaluator", func() {
 				It("open and close the circuit breaker", func() {
 					By("scaling failure causes circuit breaker to be open")
 					scalingEngine.RouteToHandler("POST", urlPath, ghttp.RespondWithJSONEncoded(http.StatusBadRequest, "error"))
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(1))
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerFail)))
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerTripped)))
 
 					By("return directly when circuit breaker is open")
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("circuit-tripped")))
 					Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(1))
+					Eventually(appLog.ErrorCallCount).Should(Equal(2))
+					_, message := appLog.ErrorArgsForCall(1)
+					Expect(message).To(Equal("Skipped scaling of process web: the scaling engine failed too often, retrying later"))
 
 					By("circuit breaker becomes half open when timeout")
 					time.Sleep(500 * time.Millisecond)
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerReady)))
 					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(2))
 
 					By("circuit breaker goes to open again for retry failuer")
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerFail)))
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(2))
 
 					By("circuit breaker doubles the timeout interval")
 					time.Sleep(500 * time.Millisecond)
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Consistently(scalingEngine.ReceivedRequests).Should(HaveLen(2))
 
 					By("circuit breaker becomes half open second time when time out")
 					time.Sleep(500 * time.Millisecond)
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerReady)))
 					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(3))
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerFail)))
 
 					By("circuit breaker caps the next timeout interval")
 					time.Sleep(1 * time.Second)
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerReady)))
 					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(4))
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerFail)))
@@ -692,22 +1178,22 @@ var _ = Describe("Evaluator", func() {
 					By("circuit breaker becomes closed due to successful scaling")
 					scalingEngine.RouteToHandler("POST", urlPath, ghttp.RespondWithJSONEncoded(http.StatusOK, &scalingResult))
 					time.Sleep(1 * time.Second)
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerReady)))
 					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(5))
 
 					By("Circuit breaker sends request to scaling engine when it is closed")
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(6))
 
 					By("Circuit breaker resets timeout interval when it is closed ")
 					scalingEngine.RouteToHandler("POST", urlPath, ghttp.RespondWithJSONEncoded(http.StatusBadRequest, "error"))
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(scalingEngine.ReceivedRequests).Should(HaveLen(7))
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerFail)))
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerTripped)))
 					time.Sleep(500 * time.Millisecond)
-					Expect(triggerChan).To(BeSent(triggerArrayGT))
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 					Eventually(cbEventChan).Should(Receive(Equal(circuit.BreakerReady)))
 				})
 			})
@@ -735,8 +1221,7 @@ var _ = Describe("Evaluator", func() {
 						Operator:              "invalid_operator",
 						Adjustment:            "1",
 					}}
-					triggerChan = make(chan []*models.Trigger, 1)
-					Eventually(triggerChan).Should(BeSent(invalidTriggerArray))
+					Expect(queue.Enqueue(invalidTriggerArray)).To(BeTrue())
 				})
 
 				It("should log the error", func() {
@@ -744,6 +1229,27 @@ var _ = Describe("Evaluator", func() {
 				})
 			})
 
+			Context("when the evaluation is not started before its deadline", func() {
+				var queried chan bool
+
+				BeforeEach(func() {
+					queried = make(chan bool, 1)
+					queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
+						queried <- true
+						return nil, nil
+					}
+					fclock := fakeclock.NewFakeClock(time.Now())
+					queue = NewEvaluationQueue(fclock, 1, 1, time.Minute, NewEvaluationMetrics())
+					Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
+					fclock.Increment(2 * time.Minute)
+				})
+
+				It("should skip the evaluation", func() {
+					Eventually(logger.LogMessages).Should(ContainElement(ContainSubstring("evaluation-late")))
+					Consistently(queried).ShouldNot(Receive())
+				})
+			})
+
 		})
 	})
 
@@ -753,15 +1259,15 @@ var _ = Describe("Evaluator", func() {
 			queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
 				return nil, nil
 			}
-			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired)
+			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), queue, 0, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired, appLog)
 			evaluator.Start()
-			Expect(triggerChan).To(BeSent(triggerArrayGT))
+			Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 
 			evaluator.Stop()
 		})
 
 		It("should stop to send trigger alarm", func() {
-			Eventually(triggerChan).ShouldNot(BeSent(triggerArrayGT))
+			Eventually(func() bool { return queue.Enqueue(triggerArrayGT) }).Should(BeFalse())
 		})
 	})
 
@@ -772,9 +1278,9 @@ var _ = Describe("Evaluator", func() {
 			queryAppMetrics = func(appID string, metricType string, start int64, end int64, orderType db.OrderType) ([]*models.AppMetric, error) {
 				return appMetrics, nil
 			}
-			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), triggerChan, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired)
+			evaluator = NewEvaluator(logger, httpClient, scalingEngine.URL(), queue, 0, breachDurationSecs, queryAppMetrics, getBreaker, setCoolDownExpired, appLog)
 			evaluator.Start()
-			Expect(triggerChan).To(BeSent(triggerArrayGT))
+			Expect(queue.Enqueue(triggerArrayGT)).To(BeTrue())
 		})
 
 		AfterEach(func() {
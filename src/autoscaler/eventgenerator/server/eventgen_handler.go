@@ -6,24 +6,33 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers/handlers"
 	"code.cloudfoundry.org/lager/v3"
 )
 
+// MaxBacktestRange limits the time range a policy can be replayed for in a single backtest.
+const MaxBacktestRange = 7 * 24 * time.Hour
+
 type EventGenHandler struct {
-	logger         lager.Logger
-	queryAppMetric aggregator.QueryAppMetricsFunc
+	logger                lager.Logger
+	queryAppMetric        aggregator.QueryAppMetricsFunc
+	backtest              generator.BacktestFunc
+	getAppEvaluationState generator.GetAppEvaluationStateFunc
 }
 
-func NewEventGenHandler(logger lager.Logger, queryAppMetric aggregator.QueryAppMetricsFunc) *EventGenHandler {
+func NewEventGenHandler(logger lager.Logger, queryAppMetric aggregator.QueryAppMetricsFunc, backtest generator.BacktestFunc, getAppEvaluationState generator.GetAppEvaluationStateFunc) *EventGenHandler {
 	return &EventGenHandler{
-		logger:         logger,
-		queryAppMetric: queryAppMetric,
+		logger:                logger,
+		queryAppMetric:        queryAppMetric,
+		backtest:              backtest,
+		getAppEvaluationState: getAppEvaluationState,
 	}
 }
 
@@ -123,3 +132,65 @@ func (h *EventGenHandler) GetAggregatedMetricHistories(w http.ResponseWriter, r
 		h.logger.Error("unable to write body", err)
 	}
 }
+
+func (h *EventGenHandler) Backtest(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appID := vars["appid"]
+	logger := h.logger.Session("backtest", lager.Data{"appid": appID})
+
+	request := &models.BacktestRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		logger.Error("failed-to-decode-request", err)
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: "Invalid request body format"})
+		return
+	}
+
+	if request.Policy == nil {
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: "Policy is required"})
+		return
+	}
+	if request.Start >= request.End {
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: "Start time must be before end time"})
+		return
+	}
+	if time.Duration(request.End-request.Start) > MaxBacktestRange {
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: fmt.Sprintf("Time range must not exceed %s", MaxBacktestRange)})
+		return
+	}
+
+	result, err := h.backtest(appID, request)
+	if err != nil {
+		logger.Error("failed-to-backtest-policy", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error backtesting policy"})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// GetEvaluationState returns the cooldowns and the circuit breaker state the eventgenerator keeps for the app, to debug
+// why the app is not scaled.
+func (h *EventGenHandler) GetEvaluationState(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appID := vars["appid"]
+	h.logger.Debug("get-evaluation-state", lager.Data{"appid": appID})
+
+	state, found := h.getAppEvaluationState(appID)
+	if !found {
+		handlers.WriteJSONResponse(w, http.StatusNotFound, models.ErrorResponse{
+			Code:    "Not-Found",
+			Message: "App is not evaluated by this eventgenerator"})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, state)
+}
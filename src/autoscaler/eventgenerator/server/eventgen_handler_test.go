@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/server"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
@@ -17,12 +19,18 @@ import (
 	. "github.com/onsi/gomega"
 )
 
-var testUrlAggregatedMetricHistories = "http://localhost/v1/apps/an-app-id/aggregated_metric_histories/a-metric-type"
+var (
+	testUrlAggregatedMetricHistories = "http://localhost/v1/apps/an-app-id/aggregated_metric_histories/a-metric-type"
+	testUrlBacktest                  = "http://localhost/v1/apps/an-app-id/backtest"
+	testUrlEvaluationState           = "http://localhost/v1/apps/an-app-id/evaluation_state"
+)
 
 var _ = Describe("EventgenHandler", func() {
 	var (
 		handler         *EventGenHandler
 		queryAppMetrics aggregator.QueryAppMetricsFunc
+		backtest        generator.BacktestFunc
+		getState        generator.GetAppEvaluationStateFunc
 
 		resp       *httptest.ResponseRecorder
 		req        *http.Request
@@ -40,7 +48,7 @@ var _ = Describe("EventgenHandler", func() {
 		JustBeforeEach(func() {
 			logger = lager.NewLogger("handler-test")
 			resp = httptest.NewRecorder()
-			handler = NewEventGenHandler(logger, queryAppMetrics)
+			handler = NewEventGenHandler(logger, queryAppMetrics, nil, nil)
 			handler.GetAggregatedMetricHistories(resp, req, map[string]string{"appid": "an-app-id", "metrictype": "a-metric-type"})
 		})
 
@@ -299,4 +307,145 @@ var _ = Describe("EventgenHandler", func() {
 
 		})
 	})
+
+	Describe("Backtest", func() {
+		var (
+			body            string
+			backtestRequest *models.BacktestRequest
+		)
+
+		BeforeEach(func() {
+			body = `{"policy":{"instance_min_count":1,"instance_max_count":5},"start":100,"end":200,"instances":2}`
+			backtestRequest = nil
+			backtest = func(appID string, request *models.BacktestRequest) (*models.BacktestResult, error) {
+				backtestRequest = request
+				return &models.BacktestResult{
+					Timeline: []*models.BacktestInstances{{Timestamp: 100, ProcessType: "web", Instances: 2}},
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			logger = lager.NewLogger("handler-test")
+			resp = httptest.NewRecorder()
+			req, err = http.NewRequest(http.MethodPost, testUrlBacktest, strings.NewReader(body))
+			Expect(err).ToNot(HaveOccurred())
+			handler = NewEventGenHandler(logger, queryAppMetrics, backtest, nil)
+			handler.Backtest(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when the request is valid", func() {
+			It("returns 200 with the backtest result", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{"timeline":[{"timestamp":100,"process_type":"web","instances":2}],"decisions":null}`))
+				Expect(backtestRequest).To(Equal(&models.BacktestRequest{
+					Policy:    &models.ScalingPolicy{InstanceMin: 1, InstanceMax: 5},
+					Start:     100,
+					End:       200,
+					Instances: 2,
+				}))
+			})
+		})
+
+		Context("when the body is not valid json", func() {
+			BeforeEach(func() {
+				body = `{"policy":`
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Bad-Request","message":"Invalid request body format"}`))
+			})
+		})
+
+		Context("when the policy is missing", func() {
+			BeforeEach(func() {
+				body = `{"start":100,"end":200}`
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Bad-Request","message":"Policy is required"}`))
+			})
+		})
+
+		Context("when the start time is not before the end time", func() {
+			BeforeEach(func() {
+				body = `{"policy":{"instance_min_count":1,"instance_max_count":5},"start":200,"end":200}`
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Bad-Request","message":"Start time must be before end time"}`))
+			})
+		})
+
+		Context("when the time range is too long", func() {
+			BeforeEach(func() {
+				body = fmt.Sprintf(`{"policy":{"instance_min_count":1,"instance_max_count":5},"start":0,"end":%d}`, int64(MaxBacktestRange)+1)
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Bad-Request","message":"Time range must not exceed 168h0m0s"}`))
+			})
+		})
+
+		Context("when the backtest fails", func() {
+			BeforeEach(func() {
+				backtest = func(appID string, request *models.BacktestRequest) (*models.BacktestResult, error) {
+					return nil, errors.New("an error")
+				}
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Internal-Server-Error","message":"Error backtesting policy"}`))
+			})
+		})
+	})
+
+	Describe("GetEvaluationState", func() {
+		BeforeEach(func() {
+			getState = func(appID string) (*models.AppEvaluationState, bool) {
+				Expect(appID).To(Equal("an-app-id"))
+				return &models.AppEvaluationState{
+					Cooldowns: []*models.ScalingCooldown{{ProcessType: "web", Direction: models.ScalingDirectionOut, ExpireAt: 300}},
+					Breaker:   models.BreakerState{ConsecutiveFailures: 3, Tripped: true, TrippedAt: 100},
+				}, true
+			}
+		})
+
+		JustBeforeEach(func() {
+			logger = lager.NewLogger("handler-test")
+			resp = httptest.NewRecorder()
+			req, err = http.NewRequest(http.MethodGet, testUrlEvaluationState, nil)
+			Expect(err).ToNot(HaveOccurred())
+			handler = NewEventGenHandler(logger, queryAppMetrics, nil, getState)
+			handler.GetEvaluationState(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when the app is evaluated", func() {
+			It("returns 200 with the evaluation state", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Body.String()).To(MatchJSON(`{
+					"cooldowns":[{"process_type":"web","direction":"out","expire_at":300}],
+					"breaker":{"consecutive_failures":3,"tripped":true,"tripped_at":100}
+				}`))
+			})
+		})
+
+		Context("when the app is not evaluated", func() {
+			BeforeEach(func() {
+				getState = func(appID string) (*models.AppEvaluationState, bool) {
+					return nil, false
+				}
+			})
+
+			It("returns 404", func() {
+				Expect(resp.Code).To(Equal(http.StatusNotFound))
+				Expect(resp.Body.String()).To(MatchJSON(`{"code":"Not-Found","message":"App is not evaluated by this eventgenerator"}`))
+			})
+		})
+	})
 })
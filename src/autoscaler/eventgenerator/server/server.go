@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/aggregator"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/generator"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/eventgenerator/config"
@@ -22,12 +23,14 @@ func (vh VarsFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	vh(w, r, vars)
 }
 
-func NewServer(logger lager.Logger, conf *config.Config, queryAppMetric aggregator.QueryAppMetricsFunc, httpStatusCollector healthendpoint.HTTPStatusCollector) (ifrit.Runner, error) {
-	eh := NewEventGenHandler(logger, queryAppMetric)
+func NewServer(logger lager.Logger, conf *config.Config, queryAppMetric aggregator.QueryAppMetricsFunc, backtest generator.BacktestFunc, getAppEvaluationState generator.GetAppEvaluationStateFunc, httpStatusCollector healthendpoint.HTTPStatusCollector) (ifrit.Runner, error) {
+	eh := NewEventGenHandler(logger, queryAppMetric, backtest, getAppEvaluationState)
 	httpStatusCollectMiddleware := healthendpoint.NewHTTPStatusCollectMiddleware(httpStatusCollector)
 	r := routes.EventGeneratorRoutes()
 	r.Use(httpStatusCollectMiddleware.Collect)
 	r.Get(routes.GetAggregatedMetricHistoriesRouteName).Handler(VarsFunc(eh.GetAggregatedMetricHistories))
+	r.Get(routes.BacktestRouteName).Handler(VarsFunc(eh.Backtest))
+	r.Get(routes.GetEvaluationStateRouteName).Handler(VarsFunc(eh.GetEvaluationState))
 
 	httpServerConfig := helpers.ServerConfig{
 		Port: conf.Server.Port,
@@ -42,8 +42,16 @@ var _ = BeforeSuite(func() {
 		return nil, nil
 	}
 
+	backtest := func(appID string, request *models.BacktestRequest) (*models.BacktestResult, error) {
+		return &models.BacktestResult{}, nil
+	}
+
+	getAppEvaluationState := func(appID string) (*models.AppEvaluationState, bool) {
+		return &models.AppEvaluationState{Cooldowns: []*models.ScalingCooldown{}}, true
+	}
+
 	httpStatusCollector := &fakes.FakeHTTPStatusCollector{}
-	httpServer, err := server.NewServer(lager.NewLogger("test"), conf, queryAppMetrics, httpStatusCollector)
+	httpServer, err := server.NewServer(lager.NewLogger("test"), conf, queryAppMetrics, backtest, getAppEvaluationState, httpStatusCollector)
 	Expect(err).NotTo(HaveOccurred())
 
 	serverUrl, err = url.Parse("http://127.0.0.1:" + strconv.Itoa(port))
@@ -2,12 +2,17 @@ package server_test
 
 import (
 	"net/http"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
-const TestPathAggregatedMetricHistories = "/v1/apps/an-app-id/aggregated_metric_histories/a-metric-type"
+const (
+	TestPathAggregatedMetricHistories = "/v1/apps/an-app-id/aggregated_metric_histories/a-metric-type"
+	TestPathBacktest                  = "/v1/apps/an-app-id/backtest"
+	TestPathEvaluationState           = "/v1/apps/an-app-id/evaluation_state"
+)
 
 var _ = Describe("Server", func() {
 	var (
@@ -31,6 +36,38 @@ var _ = Describe("Server", func() {
 		})
 	})
 
+	Context("when backtesting a policy", func() {
+		BeforeEach(func() {
+			serverUrl.Path = TestPathBacktest
+		})
+
+		JustBeforeEach(func() {
+			rsp, err = http.Post(serverUrl.String(), "application/json", strings.NewReader(`{"policy":{"instance_min_count":1,"instance_max_count":2},"start":100,"end":200}`))
+		})
+
+		It("should return 200", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+			rsp.Body.Close()
+		})
+	})
+
+	Context("when retrieving the evaluation state", func() {
+		BeforeEach(func() {
+			serverUrl.Path = TestPathEvaluationState
+		})
+
+		JustBeforeEach(func() {
+			rsp, err = http.Get(serverUrl.String())
+		})
+
+		It("should return 200", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+			rsp.Body.Close()
+		})
+	})
+
 	Context("when requesting the wrong path", func() {
 		BeforeEach(func() {
 			serverUrl.Path = "/not-exist-path"
@@ -29,3 +29,4 @@ package fakes
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -o ./fakes/fake_emitter.go ./metricsgateway Emitter
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -o ./fakes/fake_operator.go ./operator Operator
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -o ./fakes/fake_sychronizer.go ./scalingengine/schedule ActiveScheduleSychronizer
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -fake-name FakeAppLogEmitter -o ./fakes/fake_app_log_emitter.go ./applog Emitter
@@ -0,0 +1,50 @@
+package helpers
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// AddressGuard keeps outbound requests to user supplied URLs away from internal addresses. It rejects loopback,
+// private, link-local, multicast and unspecified addresses unless they are in one of the allowed networks.
+type AddressGuard struct {
+	allowedNetworks []*net.IPNet
+}
+
+// NewAddressGuard returns a guard which allows the internal addresses in the given networks in CIDR notation.
+func NewAddressGuard(allowedNetworks []string) (*AddressGuard, error) {
+	guard := &AddressGuard{}
+	for _, cidr := range allowedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed network %q: %w", cidr, err)
+		}
+		guard.allowedNetworks = append(guard.allowedNetworks, network)
+	}
+	return guard, nil
+}
+
+func (g *AddressGuard) Allows(ip net.IP) bool {
+	for _, network := range g.allowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// Control can be used as the Control function of a net.Dialer, it checks the resolved address right before connecting
+// so that a host name cannot be rebound to an internal address after it has been checked.
+func (g *AddressGuard) Control(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !g.Allows(ip) {
+		return fmt.Errorf("connecting to %s is not allowed", host)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package helpers_test
+
+import (
+	"net"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AddressGuard", func() {
+	var (
+		guard           *helpers.AddressGuard
+		allowedNetworks []string
+		err             error
+	)
+
+	BeforeEach(func() {
+		allowedNetworks = nil
+	})
+
+	JustBeforeEach(func() {
+		guard, err = helpers.NewAddressGuard(allowedNetworks)
+	})
+
+	It("allows public addresses", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(guard.Allows(net.ParseIP("203.0.113.10"))).To(BeTrue())
+		Expect(guard.Allows(net.ParseIP("2001:db8::1"))).To(BeTrue())
+		Expect(guard.Control("tcp", "203.0.113.10:443", nil)).To(Succeed())
+	})
+
+	It("rejects internal addresses", func() {
+		for _, address := range []string{"127.0.0.1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fd00::1", "fe80::1", "::ffff:127.0.0.1"} {
+			Expect(guard.Allows(net.ParseIP(address))).To(BeFalse(), address)
+		}
+		Expect(guard.Control("tcp", "[::1]:443", nil)).To(MatchError("connecting to ::1 is not allowed"))
+	})
+
+	Context("when internal networks are allowed", func() {
+		BeforeEach(func() {
+			allowedNetworks = []string{"10.0.0.0/8"}
+		})
+
+		It("allows the addresses in these networks only", func() {
+			Expect(guard.Allows(net.ParseIP("10.1.2.3"))).To(BeTrue())
+			Expect(guard.Allows(net.ParseIP("192.168.1.1"))).To(BeFalse())
+		})
+	})
+
+	Context("when an allowed network is invalid", func() {
+		BeforeEach(func() {
+			allowedNetworks = []string{"10.0.0.0"}
+		})
+
+		It("fails", func() {
+			Expect(err).To(MatchError(ContainSubstring(`invalid allowed network "10.0.0.0"`)))
+		})
+	})
+})
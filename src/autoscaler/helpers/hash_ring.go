@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+const DefaultHashRingVirtualNodes = 100
+
+// HashRing is a consistent hash ring mapping keys to nodes. When a node joins or leaves the ring, only the keys of that
+// node move, all other keys stay with their nodes.
+type HashRing struct {
+	points []uint32
+	nodes  map[uint32]string
+}
+
+// NewHashRing places every node on the ring the given number of times to spread the keys evenly.
+func NewHashRing(nodes []string, virtualNodes int) *HashRing {
+	ring := &HashRing{nodes: map[uint32]string{}}
+	for _, node := range nodes {
+		for i := 0; i < virtualNodes; i++ {
+			point := ringHash(fmt.Sprintf("%s#%d", node, i))
+			if _, exists := ring.nodes[point]; exists {
+				continue
+			}
+			ring.nodes[point] = node
+			ring.points = append(ring.points, point)
+		}
+	}
+	sort.Slice(ring.points, func(i, j int) bool { return ring.points[i] < ring.points[j] })
+	return ring
+}
+
+// Get returns the node responsible for the key, or an empty string if the ring has no nodes.
+func (r *HashRing) Get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	hash := ringHash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.nodes[r.points[i]]
+}
+
+// ringHash spreads similar keys like the virtual nodes of a node far better over the ring than FNVHash.
+func ringHash(key string) uint32 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint32(sum[:4])
+}
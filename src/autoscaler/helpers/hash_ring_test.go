@@ -0,0 +1,62 @@
+package helpers_test
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HashRing", func() {
+	var keys []string
+
+	BeforeEach(func() {
+		keys = make([]string, 3000)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("app-id-%d", i)
+		}
+	})
+
+	owners := func(ring *helpers.HashRing) map[string]string {
+		result := map[string]string{}
+		for _, key := range keys {
+			result[key] = ring.Get(key)
+		}
+		return result
+	}
+
+	It("returns no node for an empty ring", func() {
+		Expect(helpers.NewHashRing(nil, helpers.DefaultHashRingVirtualNodes).Get("app-id")).To(BeEmpty())
+	})
+
+	It("spreads the keys over all nodes", func() {
+		ring := helpers.NewHashRing([]string{"node-a", "node-b", "node-c"}, helpers.DefaultHashRingVirtualNodes)
+		counts := map[string]int{}
+		for _, owner := range owners(ring) {
+			counts[owner]++
+		}
+		Expect(counts).To(HaveLen(3))
+		for _, count := range counts {
+			Expect(count).To(BeNumerically("~", 1000, 400))
+		}
+	})
+
+	It("does not depend on the order of the nodes", func() {
+		Expect(owners(helpers.NewHashRing([]string{"node-a", "node-b", "node-c"}, helpers.DefaultHashRingVirtualNodes))).
+			To(Equal(owners(helpers.NewHashRing([]string{"node-c", "node-a", "node-b"}, helpers.DefaultHashRingVirtualNodes))))
+	})
+
+	It("moves only the keys of a leaving node", func() {
+		before := owners(helpers.NewHashRing([]string{"node-a", "node-b", "node-c"}, helpers.DefaultHashRingVirtualNodes))
+		after := owners(helpers.NewHashRing([]string{"node-a", "node-b"}, helpers.DefaultHashRingVirtualNodes))
+		for key, owner := range before {
+			if owner != "node-c" {
+				Expect(after[key]).To(Equal(owner))
+			} else {
+				Expect(after[key]).To(BeElementOf("node-a", "node-b"))
+			}
+		}
+	})
+})
@@ -753,6 +753,7 @@ func startFakeCCNOAAUAA(instanceCount int) {
 		GetApp(models.AppStatusStarted, http.StatusOK, "test_space_guid").
 		GetAppProcesses(instanceCount).
 		ScaleAppWebProcess().
+		SpaceQuotas().
 		Roles(http.StatusOK, cf.Role{Type: cf.RoleSpaceDeveloper}).
 		ServiceInstance("cc-free-plan-id").
 		ServicePlan("autoscaler-free-plan-id").
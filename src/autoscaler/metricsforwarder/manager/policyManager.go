@@ -110,8 +110,8 @@ func (pm *PolicyManager) RefreshAllowedMetricCache(policies map[string]*models.A
 	for applicationId := range allowedMetricMap {
 		if policy, ok := policies[applicationId]; ok {
 			scalingPolicy := policy.ScalingPolicy
-			for _, metrictype := range scalingPolicy.ScalingRules {
-				allowedMetricTypeSet[metrictype.MetricType] = struct{}{}
+			for _, metrictype := range scalingPolicy.MetricTypes() {
+				allowedMetricTypeSet[metrictype] = struct{}{}
 			}
 			err := pm.allowedMetricCache.Replace(applicationId, allowedMetricTypeSet, pm.cacheTTL)
 			if err != nil {
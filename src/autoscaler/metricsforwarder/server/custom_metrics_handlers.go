@@ -123,11 +123,9 @@ func (mh *CustomMetricsHandler) PublishMetrics(w http.ResponseWriter, r *http.Re
 
 func (mh *CustomMetricsHandler) validateCustomMetricTypes(appGUID string, metricsConsumer *models.MetricsConsumer) error {
 	standardMetricsTypes := make(map[string]struct{})
-	standardMetricsTypes[models.MetricNameMemoryUsed] = struct{}{}
-	standardMetricsTypes[models.MetricNameMemoryUtil] = struct{}{}
-	standardMetricsTypes[models.MetricNameThroughput] = struct{}{}
-	standardMetricsTypes[models.MetricNameResponseTime] = struct{}{}
-	standardMetricsTypes[models.MetricNameCPUUtil] = struct{}{}
+	for _, metricType := range models.StandardMetricNames {
+		standardMetricsTypes[metricType] = struct{}{}
+	}
 
 	allowedMetricTypeSet := make(map[string]struct{})
 	res, found := mh.allowedMetricCache.Get(appGUID)
@@ -145,8 +143,8 @@ func (mh *CustomMetricsHandler) validateCustomMetricTypes(appGUID string, metric
 			mh.logger.Debug("no-policy-found", lager.Data{"appId": appGUID})
 			return ErrorNoPolicy
 		}
-		for _, metrictype := range scalingPolicy.ScalingRules {
-			allowedMetricTypeSet[metrictype.MetricType] = struct{}{}
+		for _, metrictype := range scalingPolicy.MetricTypes() {
+			allowedMetricTypeSet[metrictype] = struct{}{}
 		}
 		//update the cache
 		mh.allowedMetricCache.Set(appGUID, allowedMetricTypeSet, mh.cacheTTL)
@@ -133,7 +133,7 @@ var _ = Describe("EnvelopeProcessor", func() {
 					CollectedAt:   fclock.Now().UnixNano(),
 					Name:          "custom_name",
 					Unit:          "custom_unit",
-					Value:         "12",
+					Value:         "11.88",
 					Timestamp:     1111,
 				})))
 
@@ -143,7 +143,7 @@ var _ = Describe("EnvelopeProcessor", func() {
 					CollectedAt:   fclock.Now().UnixNano(),
 					Name:          "custom_name",
 					Unit:          "custom_unit",
-					Value:         "12",
+					Value:         "11.08",
 					Timestamp:     1111,
 				})))
 
@@ -171,7 +171,7 @@ var _ = Describe("EnvelopeProcessor", func() {
 				Eventually(func() int {
 					metrics = append(metrics, <-metricChan)
 					return len(metrics)
-				}).Should(Equal(4))
+				}).Should(Equal(12))
 				Expect(metrics).To(ContainElement(&models.AppInstanceMetric{
 					AppId:         "test-app-id",
 					InstanceIndex: 0,
@@ -212,6 +212,26 @@ var _ = Describe("EnvelopeProcessor", func() {
 					Timestamp:     fclock.Now().UnixNano(),
 				}))
 
+				Expect(metrics).To(ContainElement(&models.AppInstanceMetric{
+					AppId:         "test-app-id",
+					InstanceIndex: 1,
+					CollectedAt:   fclock.Now().UnixNano(),
+					Name:          models.MetricNameResponseTimeP95,
+					Unit:          models.UnitMilliseconds,
+					Value:         "30",
+					Timestamp:     fclock.Now().UnixNano(),
+				}))
+
+				Expect(metrics).To(ContainElement(&models.AppInstanceMetric{
+					AppId:         "test-app-id",
+					InstanceIndex: 0,
+					CollectedAt:   fclock.Now().UnixNano(),
+					Name:          models.MetricNameErrorRate,
+					Unit:          models.UnitPercentage,
+					Value:         "0",
+					Timestamp:     fclock.Now().UnixNano(),
+				}))
+
 				Expect(processor.IsCacheEmpty()).To(BeTrue())
 			})
 
@@ -249,6 +269,26 @@ var _ = Describe("EnvelopeProcessor", func() {
 							Value:         "0",
 							Timestamp:     fclock.Now().UnixNano(),
 						})))
+						for _, name := range []string{models.MetricNameResponseTimeP90, models.MetricNameResponseTimeP95, models.MetricNameResponseTimeP99} {
+							Eventually(metricChan).Should(Receive(Equal(&models.AppInstanceMetric{
+								AppId:         "another-test-app-id",
+								InstanceIndex: 0,
+								CollectedAt:   fclock.Now().UnixNano(),
+								Name:          name,
+								Unit:          models.UnitMilliseconds,
+								Value:         "0",
+								Timestamp:     fclock.Now().UnixNano(),
+							})))
+						}
+						Eventually(metricChan).Should(Receive(Equal(&models.AppInstanceMetric{
+							AppId:         "another-test-app-id",
+							InstanceIndex: 0,
+							CollectedAt:   fclock.Now().UnixNano(),
+							Name:          models.MetricNameErrorRate,
+							Unit:          models.UnitPercentage,
+							Value:         "0",
+							Timestamp:     fclock.Now().UnixNano(),
+						})))
 
 					})
 				})
@@ -287,8 +327,9 @@ var _ = Describe("EnvelopeProcessor", func() {
 
 			Expect(envelopeChan).Should(BeSent(GenerateHttpStartStopEnvelope("test-app-id", "0", 10*1000*1000, 20*1000*1000, 1111)))
 			fclock.WaitForWatcherAndIncrement(TestCollectInterval)
-			Eventually(metricChan).Should(Receive())
-			Eventually(metricChan).Should(Receive())
+			for range models.HttpMetricNames {
+				Eventually(metricChan).Should(Receive())
+			}
 
 			processor.Stop()
 			fclock.Increment(TestCollectInterval)
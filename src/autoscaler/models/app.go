@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"regexp"
+	"time"
+)
 
 type ScalingType int
 type ScalingStatus int
@@ -8,6 +11,7 @@ type ScalingStatus int
 const (
 	ScalingTypeDynamic ScalingType = iota
 	ScalingTypeSchedule
+	ScalingTypeManual
 )
 
 const (
@@ -21,8 +25,24 @@ const (
 	AppStatusStarted = "STARTED"
 )
 
+const DefaultProcessType = "web"
+
+var processTypePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,255}$`)
+
+// IsValidProcessType reports whether a process type is allowed by the policy schema. The empty process type stands for
+// the default process type.
+func IsValidProcessType(processType string) bool {
+	return processType == "" || processTypePattern.MatchString(processType)
+}
+
+const (
+	ScalingDirectionOut = "out"
+	ScalingDirectionIn  = "in"
+)
+
 type AppScalingHistory struct {
 	AppId        string        `json:"app_id"`
+	ProcessType  string        `json:"process_type"`
 	Timestamp    int64         `json:"timestamp"`
 	ScalingType  ScalingType   `json:"scaling_type"`
 	Status       ScalingStatus `json:"status"`
@@ -31,12 +51,19 @@ type AppScalingHistory struct {
 	Reason       string        `json:"reason"`
 	Message      string        `json:"message"`
 	Error        string        `json:"error"`
+	Simulated    bool          `json:"simulated"`
+	// Rule, MetricValue and CorrelationId are only set for dynamic scalings. MetricValue is the last observed value
+	// of the metric, or the average of the metric for target tracking rules. It is not set for compound rules.
+	Rule          string   `json:"rule,omitempty"`
+	MetricValue   *float64 `json:"metric_value,omitempty"`
+	CorrelationId string   `json:"correlation_id,omitempty"`
 }
 
 type AppMonitor struct {
-	AppId      string
-	MetricType string
-	StatWindow time.Duration
+	AppId       string
+	MetricType  string
+	Aggregation string
+	StatWindow  time.Duration
 }
 
 type AppScalingResult struct {
@@ -44,4 +71,66 @@ type AppScalingResult struct {
 	Status            ScalingStatus `json:"status"`
 	Adjustment        int           `json:"adjustment"`
 	CooldownExpiredAt int64         `json:"cool_down_expired_at"`
+	Direction         string        `json:"direction,omitempty"`
+}
+
+// ManualScalingRequest asks the scaling engine to scale a process of an app to the given number of instances. The
+// instance min and max counts of the policy only stop applying with OverrideLimits set. Requester is set by the public
+// API from the token of the request.
+type ManualScalingRequest struct {
+	Instances      int    `json:"instances"`
+	ProcessType    string `json:"process_type,omitempty"`
+	OverrideLimits bool   `json:"override_limits"`
+	Requester      string `json:"requester,omitempty"`
+}
+
+// ScalingCooldown is the time until which a process of an app is not scaled again in the given direction.
+type ScalingCooldown struct {
+	ProcessType string `json:"process_type"`
+	Direction   string `json:"direction"`
+	ExpireAt    int64  `json:"expire_at"`
+}
+
+// ScalingRecommendation is the number of instances a dynamic scaling decision asked for. Scale-ins only go down to
+// the highest recommendation within the scale-in stabilization window of the policy.
+type ScalingRecommendation struct {
+	AppId       string `json:"app_id"`
+	ProcessType string `json:"process_type"`
+	Timestamp   int64  `json:"timestamp"`
+	Instances   int    `json:"instances"`
+}
+
+// AppScalingState is the state the scaling engine keeps between the scaling decisions of an app.
+type AppScalingState struct {
+	Cooldowns       []*ScalingCooldown       `json:"cooldowns"`
+	Recommendations []*ScalingRecommendation `json:"recommendations"`
+}
+
+// AppEvaluationState is the state the eventgenerator keeps between the evaluations of an app. It is persisted, so that
+// it survives restarts of the eventgenerator and moves with the app to another eventgenerator node.
+type AppEvaluationState struct {
+	Cooldowns []*ScalingCooldown `json:"cooldowns"`
+	Breaker   BreakerState       `json:"breaker"`
+}
+
+// BreakerState is the state of the circuit breaker which stops sending the triggers of an app to the scaling engine
+// after consecutive failures.
+type BreakerState struct {
+	ConsecutiveFailures int64 `json:"consecutive_failures"`
+	Tripped             bool  `json:"tripped"`
+	TrippedAt           int64 `json:"tripped_at,omitempty"`
+}
+
+// AppSuspension freezes the autoscaling of an app until it is resumed or, if ExpireAt is set, until ExpireAt.
+type AppSuspension struct {
+	AppId       string `json:"app_id"`
+	SuspendedAt int64  `json:"suspended_at"`
+	ExpireAt    int64  `json:"expire_at,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// IsActive reports whether the autoscaling of the app is suspended at the given time. A nil suspension is never
+// active.
+func (s *AppSuspension) IsActive(now time.Time) bool {
+	return s != nil && (s.ExpireAt == 0 || s.ExpireAt > now.UnixNano())
 }
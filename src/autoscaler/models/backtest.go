@@ -0,0 +1,25 @@
+package models
+
+// BacktestRequest asks to replay a scaling policy against the metrics stored for an app between Start and End, both
+// in nanoseconds since the epoch. Instances is the number of instances the replay starts with, it defaults to the
+// instance min count of the policy.
+type BacktestRequest struct {
+	Policy    *ScalingPolicy `json:"policy"`
+	Start     int64          `json:"start"`
+	End       int64          `json:"end"`
+	Instances int            `json:"instances,omitempty"`
+}
+
+// BacktestResult is the outcome of a replayed scaling policy. The timeline holds the simulated number of instances of
+// every process type at the start of the replay and after every change. The decisions hold every scaling the policy
+// would have triggered, including the ignored ones.
+type BacktestResult struct {
+	Timeline  []*BacktestInstances `json:"timeline"`
+	Decisions []*AppScalingHistory `json:"decisions"`
+}
+
+type BacktestInstances struct {
+	Timestamp   int64  `json:"timestamp"`
+	ProcessType string `json:"process_type"`
+	Instances   int    `json:"instances"`
+}
@@ -0,0 +1,243 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// MetricNameInstances is the variable of derived metric expressions which stands for the number of instances of the
+// app reporting metrics. It is taken from the number of instances reporting the cpu metric.
+const MetricNameInstances = "instances"
+
+// AggregationCount counts the instances reporting a metric. It is only used for the instances variable of derived
+// metrics and cannot be chosen by scaling rules.
+const AggregationCount = "count"
+
+var ErrDivisionByZero = errors.New("division by zero")
+
+// DerivedMetric is computed by the eventgenerator from other metrics of the app with an arithmetic expression, e.g.
+// "throughput / instances". Scaling rules refer to it by its name like to any other metric type.
+type DerivedMetric struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Unit       string `json:"unit,omitempty"`
+}
+
+// Variables returns the distinct variables of the expression of the derived metric, or none if it is invalid.
+func (d *DerivedMetric) Variables() []string {
+	expression, err := ParseExpression(d.Expression)
+	if err != nil {
+		return nil
+	}
+	return expression.Variables()
+}
+
+// DerivedMetricVariableMetricType returns the aggregated metric type the values of a variable of a derived metric
+// expression are taken from.
+func DerivedMetricVariableMetricType(variable string) string {
+	if variable == MetricNameInstances {
+		return AggregatedMetricType(MetricNameCPUUtil, AggregationCount)
+	}
+	return variable
+}
+
+// Expression is a parsed derived metric expression. It supports numbers, variables, the operators +, -, * and /,
+// negation and parentheses. Variables are metric types, optionally followed by an aggregation, e.g. responsetime:p95.
+type Expression struct {
+	root      expressionNode
+	variables []string
+}
+
+type expressionNode interface {
+	evaluate(values map[string]float64) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) evaluate(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+type variableNode string
+
+func (n variableNode) evaluate(values map[string]float64) (float64, error) {
+	value, found := values[string(n)]
+	if !found {
+		return 0, fmt.Errorf("no value for %s", string(n))
+	}
+	return value, nil
+}
+
+type negationNode struct {
+	operand expressionNode
+}
+
+func (n negationNode) evaluate(values map[string]float64) (float64, error) {
+	value, err := n.operand.evaluate(values)
+	return -value, err
+}
+
+type binaryNode struct {
+	operator    byte
+	left, right expressionNode
+}
+
+func (n binaryNode) evaluate(values map[string]float64) (float64, error) {
+	left, err := n.left.evaluate(values)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.evaluate(values)
+	if err != nil {
+		return 0, err
+	}
+	switch n.operator {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, ErrDivisionByZero
+		}
+		return left / right, nil
+	}
+}
+
+// ParseExpression parses a derived metric expression.
+func ParseExpression(expression string) (*Expression, error) {
+	p := &expressionParser{input: expression, seen: map[string]bool{}}
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	return &Expression{root: root, variables: p.variables}, nil
+}
+
+// Variables returns the distinct variables of the expression in the order of their first occurrence.
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// Evaluate computes the expression with the given values of its variables.
+func (e *Expression) Evaluate(values map[string]float64) (float64, error) {
+	return e.root.evaluate(values)
+}
+
+type expressionParser struct {
+	input     string
+	pos       int
+	variables []string
+	seen      map[string]bool
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next character which is not a space, or 0 at the end of the input.
+func (p *expressionParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *expressionParser) parseSum() (expressionNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for operator := p.peek(); operator == '+' || operator == '-'; operator = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: operator, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseProduct() (expressionNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for operator := p.peek(); operator == '*' || operator == '/'; operator = p.peek() {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: operator, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseFactor() (expressionNode, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression at position %d", p.pos)
+	case c == '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return negationNode{operand: operand}, nil
+	case c == '(':
+		p.pos++
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at position %d", p.pos)
+		}
+		p.pos++
+		return node, nil
+	case isDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", p.input[start:p.pos], start)
+		}
+		return numberNode(value), nil
+	case isLetter(c):
+		start := p.pos
+		for p.pos < len(p.input) && (isLetter(p.input[p.pos]) || isDigit(p.input[p.pos]) || p.input[p.pos] == ':') {
+			p.pos++
+		}
+		variable := p.input[start:p.pos]
+		if !p.seen[variable] {
+			p.seen[variable] = true
+			p.variables = append(p.variables, variable)
+		}
+		return variableNode(variable), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
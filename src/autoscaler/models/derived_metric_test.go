@@ -0,0 +1,75 @@
+package models_test
+
+import (
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DerivedMetric", func() {
+	Context("ParseExpression", func() {
+		DescribeTable("should evaluate valid expressions",
+			func(expression string, values map[string]float64, expected float64) {
+				parsed, err := ParseExpression(expression)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parsed.Evaluate(values)).To(BeNumerically("~", expected, 1e-9))
+			},
+			Entry("division by a variable", "throughput / instances", map[string]float64{"throughput": 300, "instances": 3}, 100.0),
+			Entry("division by a number", "custom_queue_len / 50", map[string]float64{"custom_queue_len": 125}, 2.5),
+			Entry("left associative operators", "memoryused / memory_quota * 100", map[string]float64{"memoryused": 256, "memory_quota": 1024}, 25.0),
+			Entry("operator precedence", "1 + 2 * 3 - 4 / 2", nil, 5.0),
+			Entry("parentheses and negation", "-(1 + 2) * -2", nil, 6.0),
+			Entry("aggregated variables", "responsetime:p95 - responsetime", map[string]float64{"responsetime:p95": 300, "responsetime": 120}, 180.0),
+		)
+
+		It("should return the distinct variables in the order of their first occurrence", func() {
+			parsed, err := ParseExpression("(a + b) / a * c_1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.Variables()).To(Equal([]string{"a", "b", "c_1"}))
+		})
+
+		DescribeTable("should reject invalid expressions",
+			func(expression string, expectedError string) {
+				_, err := ParseExpression(expression)
+				Expect(err).To(MatchError(expectedError))
+			},
+			Entry("empty", "", "unexpected end of expression at position 0"),
+			Entry("dangling operator", "throughput /", "unexpected end of expression at position 12"),
+			Entry("unbalanced parentheses", "(throughput / 2", "missing ) at position 15"),
+			Entry("unknown character", "throughput % 2", `unexpected '%' at position 11`),
+			Entry("missing operator", "throughput instances", `unexpected 'i' at position 11`),
+			Entry("invalid number", "1.2.3", `invalid number "1.2.3" at position 0`),
+		)
+
+		It("should fail to evaluate without a value for a variable", func() {
+			parsed, err := ParseExpression("throughput / instances")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = parsed.Evaluate(map[string]float64{"throughput": 300})
+			Expect(err).To(MatchError("no value for instances"))
+		})
+
+		It("should fail to evaluate a division by zero", func() {
+			parsed, err := ParseExpression("throughput / instances")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = parsed.Evaluate(map[string]float64{"throughput": 300, "instances": 0})
+			Expect(err).To(MatchError(ErrDivisionByZero))
+		})
+	})
+
+	Context("Variables", func() {
+		It("should return no variables of an invalid expression", func() {
+			Expect((&DerivedMetric{Name: "invalid", Expression: "throughput /"}).Variables()).To(BeEmpty())
+		})
+	})
+
+	Context("DerivedMetricVariableMetricType", func() {
+		It("should take the number of instances from the instances reporting cpu", func() {
+			Expect(DerivedMetricVariableMetricType(MetricNameInstances)).To(Equal("cpu:count"))
+		})
+
+		It("should keep other variables", func() {
+			Expect(DerivedMetricVariableMetricType("responsetime:p95")).To(Equal("responsetime:p95"))
+		})
+	})
+})
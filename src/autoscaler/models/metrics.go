@@ -17,11 +17,58 @@ const (
 	MetricNameThroughput   = "throughput"
 	MetricNameResponseTime = "responsetime"
 
+	MetricNameResponseTimeP90 = "responsetime_p90"
+	MetricNameResponseTimeP95 = "responsetime_p95"
+	MetricNameResponseTimeP99 = "responsetime_p99"
+	MetricNameErrorRate       = "errorrate"
+
+	MetricNameDiskUtil       = "diskutil"
+	MetricNameDiskUsed       = "diskused"
+	MetricNameCPUEntitlement = "cpu_entitlement"
+
 	MetricLabelAppID         = "app_id"
 	MetricLabelInstanceIndex = "instance_index"
 	MetricLabelName          = "name"
 )
 
+// HttpMetricNames are the metrics computed from the http timer envelopes of an app.
+var HttpMetricNames = []string{
+	MetricNameThroughput,
+	MetricNameResponseTime,
+	MetricNameResponseTimeP90,
+	MetricNameResponseTimeP95,
+	MetricNameResponseTimeP99,
+	MetricNameErrorRate,
+}
+
+// StandardMetricNames are the metrics the autoscaler computes from the container and http metrics of an app.
+var StandardMetricNames = append([]string{
+	MetricNameMemoryUsed,
+	MetricNameMemoryUtil,
+	MetricNameCPUUtil,
+	MetricNameCPUEntitlement,
+	MetricNameDiskUsed,
+	MetricNameDiskUtil,
+}, HttpMetricNames...)
+
+func IsStandardMetric(metricType string) bool {
+	for _, name := range StandardMetricNames {
+		if name == metricType {
+			return true
+		}
+	}
+	return false
+}
+
+func IsHttpMetric(metricType string) bool {
+	for _, name := range HttpMetricNames {
+		if name == metricType {
+			return true
+		}
+	}
+	return false
+}
+
 type AppInstanceMetric struct {
 	AppId         string `json:"app_id" db:"app_id"`
 	InstanceIndex uint32 `json:"instance_index" db:"instance_index"`
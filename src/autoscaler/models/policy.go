@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -38,11 +39,28 @@ func (p *PolicyJson) GetAppPolicy() (*AppPolicy, error) {
 	return &AppPolicy{AppId: p.AppId, ScalingPolicy: &scalingPolicy}, nil
 }
 
+const (
+	PolicyModeActive = "active"
+	PolicyModeShadow = "shadow"
+)
+
+// MaxScaleInStabilizationWindow is the longest scale-in stabilization window a policy may define.
+const MaxScaleInStabilizationWindow = time.Hour
+
 type ScalingPolicy struct {
-	InstanceMin  int               `json:"instance_min_count"`
-	InstanceMax  int               `json:"instance_max_count"`
-	ScalingRules []*ScalingRule    `json:"scaling_rules,omitempty"`
-	Schedules    *ScalingSchedules `json:"schedules,omitempty"`
+	InstanceMin                       int                    `json:"instance_min_count"`
+	InstanceMax                       int                    `json:"instance_max_count"`
+	ProcessType                       string                 `json:"process_type,omitempty"`
+	Mode                              string                 `json:"mode,omitempty"`
+	ScaleInStabilizationWindowSeconds int                    `json:"scale_in_stabilization_window_secs,omitempty"`
+	MaxScaleOutStep                   int                    `json:"max_scale_out_step,omitempty"`
+	MaxScaleInStep                    int                    `json:"max_scale_in_step,omitempty"`
+	MaxScalingActionsPerHour          int                    `json:"max_scaling_actions_per_hour,omitempty"`
+	ScalingRules                      []*ScalingRule         `json:"scaling_rules,omitempty"`
+	CompoundScalingRules              []*CompoundScalingRule `json:"compound_scaling_rules,omitempty"`
+	TargetTrackingRules               []*TargetTrackingRule  `json:"target_tracking_rules,omitempty"`
+	DerivedMetrics                    []*DerivedMetric       `json:"derived_metrics,omitempty"`
+	Schedules                         *ScalingSchedules      `json:"schedules,omitempty"`
 }
 
 func (s ScalingPolicy) String() string {
@@ -50,17 +68,259 @@ func (s ScalingPolicy) String() string {
 	return string(aJson)
 }
 
+// MetricTypes returns the distinct metric types the dynamic scaling rules of the policy depend on. Derived metrics are
+// replaced with the metric types of their variables.
+func (s *ScalingPolicy) MetricTypes() []string {
+	metricTypes := []string{}
+	seen := map[string]bool{}
+	addMetricType := func(metricType string) {
+		if !seen[metricType] {
+			seen[metricType] = true
+			metricTypes = append(metricTypes, metricType)
+		}
+	}
+	add := func(metricType string) {
+		derivedMetric := s.GetDerivedMetric(metricType)
+		if derivedMetric == nil {
+			addMetricType(metricType)
+			return
+		}
+		for _, variable := range derivedMetric.Variables() {
+			if variable != MetricNameInstances {
+				variableMetricType, _ := ParseAggregatedMetricType(variable)
+				addMetricType(variableMetricType)
+			}
+		}
+	}
+	for _, rule := range s.ScalingRules {
+		add(rule.MetricType)
+	}
+	for _, rule := range s.CompoundScalingRules {
+		for _, condition := range rule.Conditions {
+			add(condition.MetricType)
+		}
+	}
+	for _, rule := range s.TargetTrackingRules {
+		add(rule.MetricType)
+	}
+	return metricTypes
+}
+
+// AggregatedMetricTypes returns the distinct aggregated metric types, see AggregatedMetricType, the dynamic scaling
+// rules of the policy are evaluated against. Derived metrics are replaced with the aggregated metric types of their
+// variables, see DerivedMetricVariableMetricType.
+func (s *ScalingPolicy) AggregatedMetricTypes() []string {
+	metricTypes := []string{}
+	seen := map[string]bool{}
+	addAggregatedMetricType := func(aggregatedMetricType string) {
+		if !seen[aggregatedMetricType] {
+			seen[aggregatedMetricType] = true
+			metricTypes = append(metricTypes, aggregatedMetricType)
+		}
+	}
+	add := func(metricType string, aggregation string) {
+		derivedMetric := s.GetDerivedMetric(metricType)
+		if derivedMetric == nil {
+			addAggregatedMetricType(AggregatedMetricType(metricType, aggregation))
+			return
+		}
+		for _, variable := range derivedMetric.Variables() {
+			addAggregatedMetricType(DerivedMetricVariableMetricType(variable))
+		}
+	}
+	for _, rule := range s.ScalingRules {
+		add(rule.MetricType, rule.Aggregation)
+	}
+	for _, rule := range s.CompoundScalingRules {
+		for _, condition := range rule.Conditions {
+			add(condition.MetricType, condition.Aggregation)
+		}
+	}
+	for _, rule := range s.TargetTrackingRules {
+		add(rule.MetricType, rule.Aggregation)
+	}
+	return metricTypes
+}
+
+// GetDerivedMetric returns the derived metric with the given name, or nil if the policy defines none. It is safe to
+// call on a nil policy.
+func (s *ScalingPolicy) GetDerivedMetric(name string) *DerivedMetric {
+	if s == nil {
+		return nil
+	}
+	for _, derivedMetric := range s.DerivedMetrics {
+		if derivedMetric.Name == name {
+			return derivedMetric
+		}
+	}
+	return nil
+}
+
+// GetProcessType returns the process type a rule with the given process type scales. Rules without a process type
+// scale the process type of the policy, which defaults to the web process. It is safe to call on a nil policy.
+func (s *ScalingPolicy) GetProcessType(ruleProcessType string) string {
+	if ruleProcessType != "" {
+		return ruleProcessType
+	}
+	if s != nil && s.ProcessType != "" {
+		return s.ProcessType
+	}
+	return DefaultProcessType
+}
+
+// IsShadow reports whether the policy only simulates scaling decisions instead of applying them. It is safe to call
+// on a nil policy.
+func (s *ScalingPolicy) IsShadow() bool {
+	return s != nil && s.Mode == PolicyModeShadow
+}
+
+// ScaleInStabilizationWindow returns how far back scale-ins look for higher recommendations. It is safe to call on a
+// nil policy.
+func (s *ScalingPolicy) ScaleInStabilizationWindow() time.Duration {
+	if s == nil {
+		return 0
+	}
+	return time.Duration(s.ScaleInStabilizationWindowSeconds) * time.Second
+}
+
+// LimitScalingStep keeps a dynamic scaling action from adding more than MaxScaleOutStep or removing more than
+// MaxScaleInStep instances at once. The returned message is empty if the new instances are not changed. It is safe to
+// call on a nil policy.
+func (s *ScalingPolicy) LimitScalingStep(currentInstances int, newInstances int) (int, string) {
+	if s == nil {
+		return newInstances, ""
+	}
+	return LimitScalingStep(currentInstances, newInstances, s.MaxScaleOutStep, s.MaxScaleInStep)
+}
+
+// ScalingActionsPerHourLimit returns how many dynamic scaling actions may be taken within an hour, 0 means unlimited.
+// It is safe to call on a nil policy.
+func (s *ScalingPolicy) ScalingActionsPerHourLimit() int {
+	if s == nil {
+		return 0
+	}
+	return s.MaxScalingActionsPerHour
+}
+
+// MetricLookBack returns how far back the evaluation of the policy needs the metrics of the app, twice the longest
+// breach duration of its rules as the Evaluator looks back that far. It is safe to call on a nil policy.
+func (s *ScalingPolicy) MetricLookBack(defaultBreachDurationSecs int) time.Duration {
+	longestBreachDurationSecs := defaultBreachDurationSecs
+	if s != nil {
+		durations := []int{}
+		for _, rule := range s.ScalingRules {
+			durations = append(durations, rule.BreachDurationSeconds)
+		}
+		for _, rule := range s.CompoundScalingRules {
+			for _, condition := range rule.Conditions {
+				durations = append(durations, condition.BreachDurationSeconds)
+			}
+		}
+		for _, rule := range s.TargetTrackingRules {
+			durations = append(durations, rule.BreachDurationSeconds)
+		}
+		for _, duration := range durations {
+			longestBreachDurationSecs = max(longestBreachDurationSecs, duration)
+		}
+	}
+	return 2 * time.Duration(longestBreachDurationSecs) * time.Second
+}
+
 var _ fmt.Stringer = &ScalingPolicy{}
 
+const (
+	AggregationAvg    = "avg"
+	AggregationMax    = "max"
+	AggregationMin    = "min"
+	AggregationSum    = "sum"
+	AggregationMedian = "median"
+	AggregationP95    = "p95"
+)
+
+const aggregationSeparator = ":"
+
+// AggregatedMetricType returns the metric type under which the values of the given metric type, aggregated across
+// the app instances with the given aggregation, are stored. Averages keep the plain metric type, so that they stay
+// compatible with the metrics aggregated before aggregations could be chosen, other aggregations are appended to it,
+// e.g. "responsetime:p95".
+func AggregatedMetricType(metricType string, aggregation string) string {
+	if aggregation == "" || aggregation == AggregationAvg {
+		return metricType
+	}
+	return metricType + aggregationSeparator + aggregation
+}
+
+// ParseAggregatedMetricType splits an aggregated metric type into the metric type and the aggregation.
+func ParseAggregatedMetricType(aggregatedMetricType string) (metricType string, aggregation string) {
+	metricType, aggregation, found := strings.Cut(aggregatedMetricType, aggregationSeparator)
+	if !found {
+		return aggregatedMetricType, AggregationAvg
+	}
+	return metricType, aggregation
+}
+
 type ScalingRule struct {
-	MetricType            string `json:"metric_type"`
-	BreachDurationSeconds int    `json:"breach_duration_secs,omitempty"`
-	Threshold             int64  `json:"threshold"`
-	Operator              string `json:"operator"`
-	CoolDownSeconds       int    `json:"cool_down_secs,omitempty"`
-	Adjustment            string `json:"adjustment"`
+	MetricType            string  `json:"metric_type"`
+	Aggregation           string  `json:"aggregation,omitempty"`
+	BreachDurationSeconds int     `json:"breach_duration_secs,omitempty"`
+	Threshold             float64 `json:"threshold"`
+	Operator              string  `json:"operator"`
+	CoolDownSeconds       int     `json:"cool_down_secs,omitempty"`
+	Adjustment            string  `json:"adjustment"`
+	ProcessType           string  `json:"process_type,omitempty"`
+	MissingData           string  `json:"missing_data,omitempty"`
+}
+
+// Treatments of the aggregated metrics without value, i.e. of the evaluation periods in which no instance reported the
+// metric. Rules without treatment are not breached by missing data.
+const (
+	MissingDataIgnore       = "ignore"
+	MissingDataBreaching    = "breaching"
+	MissingDataNotBreaching = "not_breaching"
+	MissingDataLastKnown    = "last_known"
+)
+
+const (
+	CombinatorAnd = "and"
+	CombinatorOr  = "or"
+)
+
+type CompoundScalingRule struct {
+	Combinator      string              `json:"combinator"`
+	Conditions      []*ScalingCondition `json:"conditions"`
+	CoolDownSeconds int                 `json:"cool_down_secs,omitempty"`
+	Adjustment      string              `json:"adjustment"`
+	ProcessType     string              `json:"process_type,omitempty"`
+}
+
+type ScalingCondition struct {
+	MetricType            string  `json:"metric_type"`
+	Aggregation           string  `json:"aggregation,omitempty"`
+	BreachDurationSeconds int     `json:"breach_duration_secs,omitempty"`
+	Threshold             float64 `json:"threshold"`
+	Operator              string  `json:"operator"`
+	MissingData           string  `json:"missing_data,omitempty"`
 }
 
+// TargetTrackingRule adjusts the number of instances so that the average metric value per instance stays at the
+// target value. The metric only counts as off target beyond the tolerance, a fraction of the target value. The
+// dampening factors limit which fraction of the computed change is applied in a single step.
+type TargetTrackingRule struct {
+	MetricType            string  `json:"metric_type"`
+	Aggregation           string  `json:"aggregation,omitempty"`
+	TargetValue           float64 `json:"target_value"`
+	Tolerance             float64 `json:"tolerance,omitempty"`
+	BreachDurationSeconds int     `json:"breach_duration_secs,omitempty"`
+	CoolDownSeconds       int     `json:"cool_down_secs,omitempty"`
+	ScaleOutDampening     float64 `json:"scale_out_dampening,omitempty"`
+	ScaleInDampening      float64 `json:"scale_in_dampening,omitempty"`
+	ProcessType           string  `json:"process_type,omitempty"`
+	MissingData           string  `json:"missing_data,omitempty"`
+}
+
+// DefaultTargetTrackingTolerance is the tolerance of target tracking rules which do not set one.
+const DefaultTargetTrackingTolerance = 0.1
+
 type ScalingSchedules struct {
 	Timezone              string                  `json:"timezone"`
 	RecurringSchedules    []*RecurringSchedule    `json:"recurring_schedule,omitempty"`
@@ -115,20 +375,92 @@ func (r *ScalingRule) CoolDown(defaultCoolDownSecs int) time.Duration {
 }
 
 type Trigger struct {
-	AppId                 string `json:"app_id"`
-	MetricType            string `json:"metric_type"`
-	MetricUnit            string `json:"metric_unit"`
-	BreachDurationSeconds int    `json:"breach_duration_secs"`
-	Threshold             int64  `json:"threshold"`
-	Operator              string `json:"operator"`
-	CoolDownSeconds       int    `json:"cool_down_secs"`
-	Adjustment            string `json:"adjustment"`
+	AppId                 string  `json:"app_id"`
+	MetricType            string  `json:"metric_type"`
+	MetricUnit            string  `json:"metric_unit"`
+	BreachDurationSeconds int     `json:"breach_duration_secs"`
+	Threshold             float64 `json:"threshold"`
+	Operator              string  `json:"operator"`
+	CoolDownSeconds       int     `json:"cool_down_secs"`
+	Adjustment            string  `json:"adjustment"`
+	ProcessType           string  `json:"process_type"`
+	Shadow                bool    `json:"shadow,omitempty"`
+	MissingData           string  `json:"missing_data,omitempty"`
+	// MissingDataPoints is the number of metrics without value within the breach duration of the last evaluation.
+	MissingDataPoints int `json:"missing_data_points,omitempty"`
+
+	Combinator string              `json:"combinator,omitempty"`
+	Conditions []*TriggerCondition `json:"conditions,omitempty"`
+
+	TargetValue       float64 `json:"target_value,omitempty"`
+	Tolerance         float64 `json:"tolerance,omitempty"`
+	MetricValue       float64 `json:"metric_value,omitempty"`
+	ScaleOutDampening float64 `json:"scale_out_dampening,omitempty"`
+	ScaleInDampening  float64 `json:"scale_in_dampening,omitempty"`
+
+	ScaleInStabilizationWindowSeconds int `json:"scale_in_stabilization_window_secs,omitempty"`
+	// CoolingDown marks a trigger of a process type in cooldown, it is not evaluated but the evaluator still records
+	// a scale-in stabilization recommendation for its process type.
+	CoolingDown bool `json:"-"`
+	// CorrelationId is generated by the evaluator for every trigger alarm and sent in the CorrelationIdHeader, the
+	// scaling engine stores it in the scaling history.
+	CorrelationId string `json:"-"`
+}
+
+type TriggerCondition struct {
+	MetricType            string  `json:"metric_type"`
+	MetricUnit            string  `json:"metric_unit"`
+	BreachDurationSeconds int     `json:"breach_duration_secs"`
+	Threshold             float64 `json:"threshold"`
+	Operator              string  `json:"operator"`
+	MissingData           string  `json:"missing_data,omitempty"`
+	MissingDataPoints     int     `json:"missing_data_points,omitempty"`
 }
 
 func (t Trigger) BreachDuration() time.Duration {
 	return time.Duration(t.BreachDurationSeconds) * time.Second
 }
 
+// GetProcessType returns the process type the trigger scales, triggers without a process type scale the web process.
+func (t Trigger) GetProcessType() string {
+	if t.ProcessType == "" {
+		return DefaultProcessType
+	}
+	return t.ProcessType
+}
+
+// IsCompound reports whether the trigger was built from a compound scaling rule.
+func (t Trigger) IsCompound() bool {
+	return len(t.Conditions) > 0
+}
+
+// IsTargetTracking reports whether the trigger was built from a target tracking rule.
+func (t Trigger) IsTargetTracking() bool {
+	return t.TargetValue > 0
+}
+
+// GetTolerance returns the fraction of the target value a target tracking trigger tolerates before the metric counts
+// as off target.
+func (t Trigger) GetTolerance() float64 {
+	if t.Tolerance <= 0 || t.Tolerance >= 1 {
+		return DefaultTargetTrackingTolerance
+	}
+	return t.Tolerance
+}
+
+// ScalingDirection returns the direction the trigger scales in. It is empty for target tracking triggers, which
+// scale in either direction depending on the metric value.
+func (t Trigger) ScalingDirection() string {
+	switch {
+	case t.IsTargetTracking():
+		return ""
+	case strings.HasPrefix(t.Adjustment, "-"):
+		return ScalingDirectionIn
+	default:
+		return ScalingDirectionOut
+	}
+}
+
 func (t Trigger) CoolDown(defaultCoolDownSecs int) time.Duration {
 	if t.CoolDownSeconds <= 0 {
 		return time.Duration(defaultCoolDownSecs) * time.Second
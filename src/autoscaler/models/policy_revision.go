@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// PolicyRevision is a version of the scaling policy of an app. A revision is kept every time a policy is saved for
+// the app, so that earlier policies can be inspected and restored.
+type PolicyRevision struct {
+	AppId      string         `json:"app_id"`
+	Version    int            `json:"version"`
+	PolicyGuid string         `json:"policy_guid"`
+	Author     string         `json:"author"`
+	CreatedAt  int64          `json:"created_at"`
+	Policy     *ScalingPolicy `json:"policy,omitempty"`
+}
+
+// PolicyChange is a value which differs between two policies. Path is the location of the value in the policy JSON,
+// e.g. "scaling_rules[0].threshold". From or To is nil if the value only exists in one of the policies.
+type PolicyChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// DiffPolicies compares the JSON representation of two policies and returns the changed values ordered by path.
+func DiffPolicies(from *ScalingPolicy, to *ScalingPolicy) ([]*PolicyChange, error) {
+	fromValues, err := flattenPolicy(from)
+	if err != nil {
+		return nil, err
+	}
+	toValues, err := flattenPolicy(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*PolicyChange{}
+	for path, fromValue := range fromValues {
+		toValue, found := toValues[path]
+		if !found || !reflect.DeepEqual(fromValue, toValue) {
+			changes = append(changes, &PolicyChange{Path: path, From: fromValue, To: toValue})
+		}
+	}
+	for path, toValue := range toValues {
+		if _, found := fromValues[path]; !found {
+			changes = append(changes, &PolicyChange{Path: path, To: toValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func flattenPolicy(policy *ScalingPolicy) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if policy == nil {
+		return values, nil
+	}
+	policyJson, err := json.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy: %w", err)
+	}
+	var document interface{}
+	err = json.Unmarshal(policyJson, &document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal policy: %w", err)
+	}
+	flattenValue("", document, values)
+	return values, nil
+}
+
+func flattenValue(path string, value interface{}, values map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, values)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, values)
+		}
+	default:
+		values[path] = v
+	}
+}
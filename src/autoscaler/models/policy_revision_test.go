@@ -0,0 +1,79 @@
+package models_test
+
+import (
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiffPolicies", func() {
+	var (
+		from    *ScalingPolicy
+		to      *ScalingPolicy
+		changes []*PolicyChange
+		err     error
+	)
+
+	BeforeEach(func() {
+		from = &ScalingPolicy{
+			InstanceMin: 1,
+			InstanceMax: 5,
+			ScalingRules: []*ScalingRule{
+				{MetricType: "memoryused", Threshold: 30, Operator: ">", Adjustment: "+1"},
+			},
+		}
+		to = &ScalingPolicy{
+			InstanceMin: 1,
+			InstanceMax: 5,
+			ScalingRules: []*ScalingRule{
+				{MetricType: "memoryused", Threshold: 30, Operator: ">", Adjustment: "+1"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		changes, err = DiffPolicies(from, to)
+	})
+
+	Context("when the policies are equal", func() {
+		It("returns no changes", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(BeEmpty())
+		})
+	})
+
+	Context("when values have been changed, added and removed", func() {
+		BeforeEach(func() {
+			to.InstanceMax = 10
+			to.ScalingRules[0].Threshold = 40.5
+			to.ScalingRules = append(to.ScalingRules, &ScalingRule{MetricType: "cpu", Threshold: 80, Operator: ">", Adjustment: "+1"})
+			from.ProcessType = "worker"
+		})
+
+		It("returns the changes ordered by path", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(Equal([]*PolicyChange{
+				{Path: "instance_max_count", From: float64(5), To: float64(10)},
+				{Path: "process_type", From: "worker"},
+				{Path: "scaling_rules[0].threshold", From: float64(30), To: 40.5},
+				{Path: "scaling_rules[1].adjustment", To: "+1"},
+				{Path: "scaling_rules[1].metric_type", To: "cpu"},
+				{Path: "scaling_rules[1].operator", To: ">"},
+				{Path: "scaling_rules[1].threshold", To: float64(80)},
+			}))
+		})
+	})
+
+	Context("when there is no policy to compare with", func() {
+		BeforeEach(func() {
+			to = nil
+		})
+
+		It("returns every value as removed", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(HaveLen(6))
+			Expect(changes[0]).To(Equal(&PolicyChange{Path: "instance_max_count", From: float64(5)}))
+		})
+	})
+})
@@ -180,4 +180,111 @@ var _ = Describe("Policy", func() {
 			})
 		})
 	})
+
+	Context("MetricTypes", func() {
+		It("should return the distinct metric types of all dynamic scaling rules", func() {
+			scalingPolicy := &ScalingPolicy{
+				ScalingRules: []*ScalingRule{{MetricType: "cpu"}, {MetricType: "memoryused"}, {MetricType: "cpu"}},
+				CompoundScalingRules: []*CompoundScalingRule{{
+					Combinator: CombinatorAnd,
+					Conditions: []*ScalingCondition{{MetricType: "cpu"}, {MetricType: "responsetime"}},
+				}},
+				TargetTrackingRules: []*TargetTrackingRule{{MetricType: "throughput", TargetValue: 100}},
+			}
+			Expect(scalingPolicy.MetricTypes()).To(Equal([]string{"cpu", "memoryused", "responsetime", "throughput"}))
+		})
+
+		It("should replace derived metrics with the metric types of their variables", func() {
+			scalingPolicy := &ScalingPolicy{
+				ScalingRules: []*ScalingRule{{MetricType: "throughput_per_instance"}, {MetricType: "memoryused"}},
+				DerivedMetrics: []*DerivedMetric{
+					{Name: "throughput_per_instance", Expression: "throughput / instances + responsetime:p95 * 0"},
+				},
+			}
+			Expect(scalingPolicy.MetricTypes()).To(Equal([]string{"throughput", "responsetime", "memoryused"}))
+		})
+
+		It("should return no metric types for a schedule only policy", func() {
+			scalingPolicy := &ScalingPolicy{Schedules: &ScalingSchedules{Timezone: "UTC"}}
+			Expect(scalingPolicy.MetricTypes()).To(BeEmpty())
+		})
+	})
+
+	Context("AggregatedMetricTypes", func() {
+		It("should return the distinct aggregated metric types of all dynamic scaling rules", func() {
+			scalingPolicy := &ScalingPolicy{
+				ScalingRules: []*ScalingRule{{MetricType: "cpu"}, {MetricType: "cpu", Aggregation: AggregationMax}, {MetricType: "cpu", Aggregation: AggregationAvg}},
+				CompoundScalingRules: []*CompoundScalingRule{{
+					Combinator: CombinatorAnd,
+					Conditions: []*ScalingCondition{{MetricType: "cpu", Aggregation: AggregationMax}, {MetricType: "queue_depth", Aggregation: AggregationSum}},
+				}},
+				TargetTrackingRules: []*TargetTrackingRule{{MetricType: "responsetime", Aggregation: AggregationP95, TargetValue: 100}},
+			}
+			Expect(scalingPolicy.AggregatedMetricTypes()).To(Equal([]string{"cpu", "cpu:max", "queue_depth:sum", "responsetime:p95"}))
+		})
+
+		It("should replace derived metrics with the aggregated metric types of their variables", func() {
+			scalingPolicy := &ScalingPolicy{
+				ScalingRules: []*ScalingRule{{MetricType: "queue_per_instance"}, {MetricType: "cpu"}},
+				DerivedMetrics: []*DerivedMetric{
+					{Name: "queue_per_instance", Expression: "queue_depth:sum / instances"},
+				},
+			}
+			Expect(scalingPolicy.AggregatedMetricTypes()).To(Equal([]string{"queue_depth:sum", "cpu:count", "cpu"}))
+		})
+	})
+
+	Context("GetDerivedMetric", func() {
+		It("should return the derived metric with the name", func() {
+			derivedMetric := &DerivedMetric{Name: "queue_per_instance", Expression: "queue_depth / instances"}
+			scalingPolicy := &ScalingPolicy{DerivedMetrics: []*DerivedMetric{derivedMetric}}
+			Expect(scalingPolicy.GetDerivedMetric("queue_per_instance")).To(BeIdenticalTo(derivedMetric))
+			Expect(scalingPolicy.GetDerivedMetric("queue_depth")).To(BeNil())
+		})
+
+		It("should return nil for a nil policy", func() {
+			var scalingPolicy *ScalingPolicy
+			Expect(scalingPolicy.GetDerivedMetric("queue_per_instance")).To(BeNil())
+		})
+	})
+
+	Context("MetricLookBack", func() {
+		It("should return twice the longest breach duration of all dynamic scaling rules", func() {
+			scalingPolicy := &ScalingPolicy{
+				ScalingRules:         []*ScalingRule{{MetricType: "cpu", BreachDurationSeconds: 300}, {MetricType: "memoryused"}},
+				CompoundScalingRules: []*CompoundScalingRule{{Conditions: []*ScalingCondition{{MetricType: "cpu", BreachDurationSeconds: 600}}}},
+				TargetTrackingRules:  []*TargetTrackingRule{{MetricType: "throughput", BreachDurationSeconds: 400}},
+			}
+			Expect(scalingPolicy.MetricLookBack(120)).To(Equal(20 * time.Minute))
+		})
+
+		It("should return twice the default breach duration if it is the longest", func() {
+			scalingPolicy := &ScalingPolicy{ScalingRules: []*ScalingRule{{MetricType: "cpu", BreachDurationSeconds: 60}}}
+			Expect(scalingPolicy.MetricLookBack(120)).To(Equal(4 * time.Minute))
+
+			var nilPolicy *ScalingPolicy
+			Expect(nilPolicy.MetricLookBack(120)).To(Equal(4 * time.Minute))
+		})
+	})
+
+	Context("AggregatedMetricType", func() {
+		It("should keep the metric type for averages", func() {
+			Expect(AggregatedMetricType("cpu", "")).To(Equal("cpu"))
+			Expect(AggregatedMetricType("cpu", AggregationAvg)).To(Equal("cpu"))
+		})
+
+		It("should append other aggregations to the metric type", func() {
+			Expect(AggregatedMetricType("cpu", AggregationMedian)).To(Equal("cpu:median"))
+		})
+
+		It("should be reversed by ParseAggregatedMetricType", func() {
+			metricType, aggregation := ParseAggregatedMetricType("cpu:median")
+			Expect(metricType).To(Equal("cpu"))
+			Expect(aggregation).To(Equal(AggregationMedian))
+
+			metricType, aggregation = ParseAggregatedMetricType("cpu")
+			Expect(metricType).To(Equal("cpu"))
+			Expect(aggregation).To(Equal(AggregationAvg))
+		})
+	})
 })
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCoolDownSecs is the cooldown the scalingengine applies after a scaling if the trigger has no cool_down_secs
+// and autoscaler.scalingengine.defaultCoolDownSecs is not changed.
+const DefaultCoolDownSecs = 300
+
+// CorrelationIdHeader carries the correlation id of a trigger alarm from the evaluator to the scaling engine.
+const CorrelationIdHeader = "X-Autoscaler-Correlation-Id"
+
+// ComputeInstances returns the number of instances a breached trigger asks for, before it is limited by the
+// instance min and max counts.
+func ComputeInstances(currentInstances int, trigger *Trigger) (int, error) {
+	if trigger.IsTargetTracking() {
+		return computeTargetTrackingInstances(currentInstances, trigger), nil
+	}
+	return ComputeAdjustedInstances(currentInstances, trigger.Adjustment)
+}
+
+// LimitInstances keeps the number of instances within [instanceMin, instanceMax]. The returned message describes the
+// limit which has been applied, it is empty if the instances are within the range.
+func LimitInstances(instances int, instanceMin int, instanceMax int) (int, string) {
+	if instances < instanceMin {
+		return instanceMin, fmt.Sprintf("limited by min instances %d", instanceMin)
+	}
+	if instances > instanceMax {
+		return instanceMax, fmt.Sprintf("limited by max instances %d", instanceMax)
+	}
+	return instances, ""
+}
+
+// LimitScalingStep keeps the change from the current to the new number of instances within maxScaleOutStep and
+// maxScaleInStep instances, a limit of 0 does not limit the change. The returned message describes the limit which has
+// been applied, it is empty if the change is within the limits.
+func LimitScalingStep(currentInstances int, newInstances int, maxScaleOutStep int, maxScaleInStep int) (int, string) {
+	if maxScaleOutStep > 0 && newInstances-currentInstances > maxScaleOutStep {
+		return currentInstances + maxScaleOutStep, fmt.Sprintf("limited by max scale-out step %d", maxScaleOutStep)
+	}
+	if maxScaleInStep > 0 && currentInstances-newInstances > maxScaleInStep {
+		return currentInstances - maxScaleInStep, fmt.Sprintf("limited by max scale-in step %d", maxScaleInStep)
+	}
+	return newInstances, ""
+}
+
+// ScalingDirection returns the direction of a change from the current to the new number of instances.
+func ScalingDirection(currentInstances int, newInstances int) string {
+	if newInstances < currentInstances {
+		return ScalingDirectionIn
+	}
+	return ScalingDirectionOut
+}
+
+// StabilizeInstances keeps a scale-in from going below the highest recommendation for the process type within the
+// scale-in stabilization window, but never above the current number of instances. The returned message is empty if
+// the new instances are not changed.
+func StabilizeInstances(currentInstances int, newInstances int, processType string, recommendations []*ScalingRecommendation) (int, string) {
+	if newInstances >= currentInstances {
+		return newInstances, ""
+	}
+	stabilizedInstances := newInstances
+	for _, recommendation := range recommendations {
+		if recommendation.ProcessType == processType && recommendation.Instances > stabilizedInstances {
+			stabilizedInstances = recommendation.Instances
+		}
+	}
+	if stabilizedInstances == newInstances {
+		return newInstances, ""
+	}
+	stabilizedInstances = min(stabilizedInstances, currentInstances)
+	return stabilizedInstances, fmt.Sprintf("limited by scale-in stabilization window to %d", stabilizedInstances)
+}
+
+// ComputeAdjustedInstances applies a step ("+1", "-2") or percentage ("+50%") adjustment to the current instances.
+func ComputeAdjustedInstances(currentInstances int, adjustment string) (int, error) {
+	var newInstances int
+	if strings.HasSuffix(adjustment, "%") {
+		percentage, err := strconv.ParseFloat(strings.TrimSuffix(adjustment, "%"), 32)
+		if err != nil {
+			return -1, err
+		}
+		newInstances = int(float64(currentInstances)*(1+percentage/100) + 0.5)
+
+		if newInstances == currentInstances {
+			if percentage > 0 {
+				newInstances = currentInstances + 1
+			} else if percentage < 0 {
+				newInstances = currentInstances - 1
+			}
+		}
+	} else {
+		step, err := strconv.ParseInt(adjustment, 10, 32)
+		if err != nil {
+			return -1, err
+		}
+		newInstances = int(step) + currentInstances
+	}
+
+	return newInstances, nil
+}
+
+// computeTargetTrackingInstances derives the number of instances needed to bring the average metric value back to
+// the target value. Only the dampened fraction of the difference is applied, but at least one instance.
+func computeTargetTrackingInstances(currentInstances int, trigger *Trigger) int {
+	desiredInstances := int(math.Ceil(float64(currentInstances) * trigger.MetricValue / trigger.TargetValue))
+	delta := desiredInstances - currentInstances
+	if delta > 0 {
+		delta = int(math.Ceil(float64(delta) * dampeningOrDefault(trigger.ScaleOutDampening)))
+	} else if delta < 0 {
+		delta = -int(math.Ceil(float64(-delta) * dampeningOrDefault(trigger.ScaleInDampening)))
+	}
+	return currentInstances + delta
+}
+
+func dampeningOrDefault(dampening float64) float64 {
+	if dampening <= 0 || dampening > 1 {
+		return 1
+	}
+	return dampening
+}
+
+// TriggerRule describes the rule of a trigger the way it is defined in the policy, e.g. `cpu > 80`. Compound triggers
+// only describe the conditions which contributed to the breach.
+func TriggerRule(trigger *Trigger) string {
+	if trigger.IsTargetTracking() {
+		return fmt.Sprintf("%s target %s", trigger.MetricType, strconv.FormatFloat(trigger.TargetValue, 'f', -1, 64))
+	}
+	if trigger.IsCompound() {
+		conditions := make([]string, 0, len(trigger.Conditions))
+		for _, condition := range trigger.Conditions {
+			conditions = append(conditions, fmt.Sprintf("%s %s %s", condition.MetricType, condition.Operator,
+				strconv.FormatFloat(condition.Threshold, 'f', -1, 64)))
+		}
+		return strings.Join(conditions, " "+trigger.Combinator+" ")
+	}
+	return fmt.Sprintf("%s %s %s", trigger.MetricType, trigger.Operator, strconv.FormatFloat(trigger.Threshold, 'f', -1, 64))
+}
+
+// DynamicScalingReason describes why a breached trigger caused a scaling, as shown in the scaling history.
+func DynamicScalingReason(trigger *Trigger) string {
+	if trigger.IsTargetTracking() {
+		return fmt.Sprintf("target tracking %s at %s%s because average was %s%s for %d seconds",
+			trigger.MetricType,
+			strconv.FormatFloat(trigger.TargetValue, 'f', -1, 64),
+			trigger.MetricUnit,
+			strconv.FormatFloat(math.Round(trigger.MetricValue*100)/100, 'f', -1, 64),
+			trigger.MetricUnit,
+			trigger.BreachDurationSeconds)
+	}
+	if trigger.IsCompound() {
+		conditions := make([]string, 0, len(trigger.Conditions))
+		for _, condition := range trigger.Conditions {
+			conditions = append(conditions, fmt.Sprintf("%s %s %s%s for %d seconds",
+				condition.MetricType,
+				condition.Operator,
+				strconv.FormatFloat(condition.Threshold, 'f', -1, 64),
+				condition.MetricUnit,
+				condition.BreachDurationSeconds))
+		}
+		return fmt.Sprintf("%s instance(s) because %s",
+			trigger.Adjustment,
+			strings.Join(conditions, " "+trigger.Combinator+" "))
+	}
+	return fmt.Sprintf("%s instance(s) because %s %s %s%s for %d seconds",
+		trigger.Adjustment,
+		trigger.MetricType,
+		trigger.Operator,
+		strconv.FormatFloat(trigger.Threshold, 'f', -1, 64),
+		trigger.MetricUnit,
+		trigger.BreachDurationSeconds)
+}
@@ -0,0 +1,123 @@
+package models_test
+
+import (
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scaling", func() {
+	Describe("ComputeInstances", func() {
+		DescribeTable("computes the instances of a breached trigger",
+			func(trigger *Trigger, expected int) {
+				instances, err := ComputeInstances(4, trigger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(instances).To(Equal(expected))
+			},
+			Entry("step adjustment", &Trigger{Adjustment: "+2"}, 6),
+			Entry("percentage adjustment", &Trigger{Adjustment: "-50%"}, 2),
+			Entry("small percentage adjustment", &Trigger{Adjustment: "+1%"}, 5),
+			Entry("target tracking", &Trigger{TargetValue: 50, MetricValue: 75}, 6),
+			Entry("dampened target tracking", &Trigger{TargetValue: 50, MetricValue: 25, ScaleInDampening: 0.5}, 3),
+		)
+
+		It("fails on an invalid adjustment", func() {
+			_, err := ComputeInstances(4, &Trigger{Adjustment: "+a"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("LimitInstances", func() {
+		DescribeTable("keeps the instances within the limits",
+			func(instances int, expected int, message string) {
+				limited, limitMessage := LimitInstances(instances, 2, 5)
+				Expect(limited).To(Equal(expected))
+				Expect(limitMessage).To(Equal(message))
+			},
+			Entry("below min", 1, 2, "limited by min instances 2"),
+			Entry("above max", 6, 5, "limited by max instances 5"),
+			Entry("within the limits", 3, 3, ""),
+		)
+	})
+
+	Describe("LimitScalingStep", func() {
+		DescribeTable("keeps the change within the step limits",
+			func(newInstances int, maxScaleOutStep int, maxScaleInStep int, expected int, message string) {
+				limited, limitMessage := LimitScalingStep(5, newInstances, maxScaleOutStep, maxScaleInStep)
+				Expect(limited).To(Equal(expected))
+				Expect(limitMessage).To(Equal(message))
+			},
+			Entry("scale-out above the step", 50, 3, 0, 8, "limited by max scale-out step 3"),
+			Entry("scale-out within the step", 7, 3, 0, 7, ""),
+			Entry("scale-in above the step", 1, 0, 2, 3, "limited by max scale-in step 2"),
+			Entry("scale-in within the step", 4, 0, 2, 4, ""),
+			Entry("unlimited", 50, 0, 0, 50, ""),
+		)
+
+		It("does not limit the steps of a nil policy", func() {
+			var policy *ScalingPolicy
+			Expect(policy.LimitScalingStep(5, 50)).To(Equal(50))
+			Expect(policy.ScalingActionsPerHourLimit()).To(BeZero())
+		})
+	})
+
+	Describe("ScalingDirection", func() {
+		It("returns the direction of the change", func() {
+			Expect(ScalingDirection(3, 2)).To(Equal(ScalingDirectionIn))
+			Expect(ScalingDirection(3, 4)).To(Equal(ScalingDirectionOut))
+		})
+	})
+
+	Describe("StabilizeInstances", func() {
+		var recommendations []*ScalingRecommendation
+
+		BeforeEach(func() {
+			recommendations = []*ScalingRecommendation{
+				{ProcessType: "web", Instances: 4},
+				{ProcessType: "worker", Instances: 6},
+			}
+		})
+
+		It("does not change a scale-out", func() {
+			Expect(StabilizeInstances(3, 5, "web", recommendations)).To(Equal(5))
+		})
+
+		It("keeps a scale-in at the highest recommendation of the process type", func() {
+			instances, message := StabilizeInstances(5, 2, "web", recommendations)
+			Expect(instances).To(Equal(4))
+			Expect(message).To(Equal("limited by scale-in stabilization window to 4"))
+		})
+
+		It("never stabilizes above the current instances", func() {
+			instances, _ := StabilizeInstances(5, 2, "worker", recommendations)
+			Expect(instances).To(Equal(5))
+		})
+	})
+
+	Describe("DynamicScalingReason", func() {
+		It("describes a threshold trigger", func() {
+			Expect(DynamicScalingReason(&Trigger{
+				MetricType: "memoryused", MetricUnit: "MB", BreachDurationSeconds: 120,
+				Operator: ">", Threshold: 80, Adjustment: "+1",
+			})).To(Equal("+1 instance(s) because memoryused > 80MB for 120 seconds"))
+		})
+	})
+
+	Describe("TriggerRule", func() {
+		It("describes a threshold trigger", func() {
+			Expect(TriggerRule(&Trigger{MetricType: "memoryused", Operator: ">=", Threshold: 80.5})).To(Equal("memoryused >= 80.5"))
+		})
+
+		It("describes a target tracking trigger", func() {
+			Expect(TriggerRule(&Trigger{MetricType: "cpu", TargetValue: 50, MetricValue: 75})).To(Equal("cpu target 50"))
+		})
+
+		It("describes the conditions of a compound trigger", func() {
+			Expect(TriggerRule(&Trigger{Combinator: CombinatorOr, Conditions: []*TriggerCondition{
+				{MetricType: "cpu", Operator: ">", Threshold: 80},
+				{MetricType: "memoryused", Operator: ">", Threshold: 500},
+			}})).To(Equal("cpu > 80 or memoryused > 500"))
+		})
+	})
+})
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	WebhookScopeApp             = "app"
+	WebhookScopeServiceInstance = "service_instance"
+)
+
+const WebhookEventScaling = "scaling"
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+const (
+	WebhookSignatureHeader = "X-Autoscaler-Signature"
+	WebhookDeliveryHeader  = "X-Autoscaler-Delivery"
+)
+
+// WebhookSubscription is a URL which is notified of every scaling history entry of an app. A subscription of a service
+// instance is stored once without an app and notified of the scaling history entries of every app bound to it.
+type WebhookSubscription struct {
+	Guid              string `json:"guid"`
+	AppId             string `json:"app_id,omitempty"`
+	ServiceInstanceId string `json:"service_instance_id,omitempty"`
+	Url               string `json:"url"`
+	Secret            string `json:"secret,omitempty"`
+	CreatedAt         int64  `json:"created_at"`
+}
+
+func (s *WebhookSubscription) Scope() string {
+	if s.ServiceInstanceId != "" {
+		return WebhookScopeServiceInstance
+	}
+	return WebhookScopeApp
+}
+
+// WebhookDelivery is a scaling event waiting in the outbox of the scaling engine to be sent to a subscription, or the
+// outcome of sending it.
+type WebhookDelivery struct {
+	Guid             string `json:"guid"`
+	SubscriptionGuid string `json:"subscription_guid"`
+	AppId            string `json:"app_id"`
+	Payload          string `json:"-"`
+	Status           string `json:"status"`
+	Attempts         int    `json:"attempts"`
+	NextAttemptAt    int64  `json:"next_attempt_at"`
+	LastError        string `json:"last_error,omitempty"`
+	CreatedAt        int64  `json:"created_at"`
+	UpdatedAt        int64  `json:"updated_at"`
+}
+
+// WebhookEvent is the body posted to a webhook subscription.
+type WebhookEvent struct {
+	DeliveryGuid     string             `json:"delivery_guid"`
+	SubscriptionGuid string             `json:"subscription_guid"`
+	Event            string             `json:"event"`
+	History          *AppScalingHistory `json:"scaling_history"`
+}
+
+// SignWebhookPayload returns the value of the signature header of a webhook payload, the hex encoded HMAC-SHA256 of
+// the payload keyed with the secret of the subscription.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
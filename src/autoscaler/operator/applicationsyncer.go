@@ -5,6 +5,7 @@ import (
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager/v3"
 )
 
@@ -17,13 +18,15 @@ var _ Operator = &ApplicationSynchronizer{}
 type ApplicationSynchronizer struct {
 	cfClient cf.ContextClient
 	policyDb db.PolicyDB
+	clock    clock.Clock
 	logger   lager.Logger
 }
 
-func NewApplicationSynchronizer(cfClient cf.ContextClient, policyDb db.PolicyDB, logger lager.Logger) *ApplicationSynchronizer {
+func NewApplicationSynchronizer(cfClient cf.ContextClient, policyDb db.PolicyDB, clock clock.Clock, logger lager.Logger) *ApplicationSynchronizer {
 	return &ApplicationSynchronizer{
 		policyDb: policyDb,
 		cfClient: cfClient,
+		clock:    clock,
 		logger:   logger.Session("application-synchronizer"),
 	}
 }
@@ -33,6 +36,12 @@ func (as ApplicationSynchronizer) Operate(ctx context.Context) {
 	logger.Info("starting")
 	defer logger.Info("completed")
 
+	// expired suspensions no longer stop scaling, so they can go
+	err := as.policyDb.DeleteExpiredAppSuspensions(ctx, as.clock.Now().UnixNano())
+	if err != nil {
+		as.logger.Error("failed-to-prune-expired-app-suspensions", err)
+	}
+
 	// Get all the application details from policyDB
 	appIds, err := as.policyDb.GetAppIds(ctx)
 	if err != nil {
@@ -52,6 +61,16 @@ func (as ApplicationSynchronizer) Operate(ctx context.Context) {
 					//TODO make this a continue and write a test.
 					return
 				}
+				err = as.policyDb.DeletePolicyRevisions(ctx, appID)
+				if err != nil {
+					as.logger.Error("failed-to-prune-policy-revisions-of-non-existent-application", err)
+					return
+				}
+				err = as.policyDb.DeleteWebhookSubscriptions(ctx, appID)
+				if err != nil {
+					as.logger.Error("failed-to-prune-webhook-subscriptions-of-non-existent-application", err)
+					return
+				}
 				as.logger.Info("successfully-pruned-non-existent-application", lager.Data{"appid": appID})
 			}
 		}
@@ -2,10 +2,13 @@ package operator_test
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/operator"
+	"code.cloudfoundry.org/clock/fakeclock"
 
 	"code.cloudfoundry.org/lager/v3/lagertest"
 
@@ -18,13 +21,15 @@ var _ = Describe("AppSynchronizer", func() {
 		appSynchronizer *operator.ApplicationSynchronizer
 		cfc             *fakes.FakeContextClient
 		policyDB        *fakes.FakePolicyDB
+		fclock          *fakeclock.FakeClock
 	)
 
 	BeforeEach(func() {
 		logger := lagertest.NewTestLogger("application-synchoronizer-test")
 		cfc = &fakes.FakeContextClient{}
 		policyDB = &fakes.FakePolicyDB{}
-		appSynchronizer = operator.NewApplicationSynchronizer(cfc, policyDB, logger)
+		fclock = fakeclock.NewFakeClock(time.Now())
+		appSynchronizer = operator.NewApplicationSynchronizer(cfc, policyDB, fclock, logger)
 	})
 
 	Describe("Sync", func() {
@@ -57,6 +62,23 @@ var _ = Describe("AppSynchronizer", func() {
 				Eventually(policyDB.GetAppIdsCallCount).Should(Equal(1))
 				Eventually(cfc.GetAppCallCount).Should(Equal(1))
 				Eventually(policyDB.DeletePolicyCallCount).Should(Equal(1))
+				Eventually(policyDB.DeleteWebhookSubscriptionsCallCount).Should(Equal(1))
+			})
+		})
+
+		It("should prune the expired app suspensions", func() {
+			Expect(policyDB.DeleteExpiredAppSuspensionsCallCount()).To(Equal(1))
+			_, before := policyDB.DeleteExpiredAppSuspensionsArgsForCall(0)
+			Expect(before).To(Equal(fclock.Now().UnixNano()))
+		})
+
+		Context("when pruning the expired app suspensions fails", func() {
+			BeforeEach(func() {
+				policyDB.DeleteExpiredAppSuspensionsReturns(errors.New("an error"))
+				cfc.GetAppReturns(nil, cf.CfResourceNotFound)
+			})
+			It("should still delete non-existent application records", func() {
+				Eventually(policyDB.DeletePolicyCallCount).Should(Equal(1))
 			})
 		})
 	})
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager/v3"
 )
@@ -35,6 +36,18 @@ func (sdp ScalingEngineDbPruner) Operate(ctx context.Context) {
 	err := sdp.scalingEngineDb.PruneScalingHistories(ctx, timestamp)
 	if err != nil {
 		sdp.logger.Error("failed-prune-scaling-histories", err)
+	}
+
+	// pending deliveries are kept until they are delivered or have failed
+	err = sdp.scalingEngineDb.PruneWebhookDeliveries(ctx, timestamp)
+	if err != nil {
+		sdp.logger.Error("failed-prune-webhook-deliveries", err)
+	}
+
+	// recommendations are only needed within the longest scale-in stabilization window
+	err = sdp.scalingEngineDb.PruneScalingRecommendations(ctx, sdp.clock.Now().Add(-models.MaxScaleInStabilizationWindow).UnixNano())
+	if err != nil {
+		sdp.logger.Error("failed-prune-scaling-recommendations", err)
 		return
 	}
 }
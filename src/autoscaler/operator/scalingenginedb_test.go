@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/operator"
 
 	"code.cloudfoundry.org/clock/fakeclock"
@@ -56,6 +57,50 @@ var _ = Describe("ScalingEngineDbPruner", func() {
 				Eventually(scalingEngineDB.PruneScalingHistoriesCallCount).Should(Equal(1))
 				Eventually(buffer).Should(gbytes.Say("test error"))
 			})
+
+			It("still prunes the scaling recommendations", func() {
+				Eventually(scalingEngineDB.PruneScalingRecommendationsCallCount).Should(Equal(1))
+			})
+		})
+
+		Context("when pruning records from webhook_delivery table", func() {
+			It("prunes as per given cutoff days", func() {
+				Eventually(scalingEngineDB.PruneWebhookDeliveriesCallCount).Should(Equal(1))
+				_, cutoffTime := scalingEngineDB.PruneWebhookDeliveriesArgsForCall(0)
+				Expect(cutoffTime).To(Equal(fclock.Now().Add(-cutoffDuration).UnixNano()))
+			})
+		})
+
+		Context("when pruning records from webhook_delivery table fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.PruneWebhookDeliveriesReturns(errors.New("test error"))
+			})
+
+			It("should error", func() {
+				Eventually(buffer).Should(gbytes.Say("failed-prune-webhook-deliveries"))
+			})
+
+			It("still prunes the scaling recommendations", func() {
+				Eventually(scalingEngineDB.PruneScalingRecommendationsCallCount).Should(Equal(1))
+			})
+		})
+
+		Context("when pruning records from scalingrecommendation table", func() {
+			It("prunes the recommendations older than the longest stabilization window", func() {
+				Eventually(scalingEngineDB.PruneScalingRecommendationsCallCount).Should(Equal(1))
+				_, cutoffTime := scalingEngineDB.PruneScalingRecommendationsArgsForCall(0)
+				Expect(cutoffTime).To(Equal(fclock.Now().Add(-models.MaxScaleInStabilizationWindow).UnixNano()))
+			})
+		})
+
+		Context("when pruning records from scalingrecommendation table fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.PruneScalingRecommendationsReturns(errors.New("test error"))
+			})
+
+			It("should error", func() {
+				Eventually(buffer).Should(gbytes.Say("failed-prune-scaling-recommendations"))
+			})
 		})
 	})
 })
@@ -13,12 +13,27 @@ const (
 	AggregatedMetricHistoriesPath         = "/v1/apps/{appid}/aggregated_metric_histories/{metrictype}"
 	GetAggregatedMetricHistoriesRouteName = "GetAggregatedMetricHistories"
 
+	BacktestPath      = "/v1/apps/{appid}/backtest"
+	BacktestRouteName = "Backtest"
+
+	EvaluationStatePath         = "/v1/apps/{appid}/evaluation_state"
+	GetEvaluationStateRouteName = "GetEvaluationState"
+
 	ScalePath      = "/v1/apps/{appid}/scale"
 	ScaleRouteName = "Scale"
 
+	ManualScalePath      = "/v1/apps/{appid}/manual_scale"
+	ManualScaleRouteName = "ManualScale"
+
+	RecommendPath      = "/v1/apps/{appid}/recommendation"
+	RecommendRouteName = "Recommend"
+
 	ScalingHistoriesPath         = "/v1/apps/{guid}/scaling_histories"
 	GetScalingHistoriesRouteName = "GetScalingHistories"
 
+	ScalingHistoriesExportPath      = "/v1/apps/{appid}/scaling_histories/export"
+	ExportScalingHistoriesRouteName = "ExportScalingHistories"
+
 	ActiveSchedulePath            = "/v1/apps/{appid}/active_schedules/{scheduleid}"
 	SetActiveScheduleRouteName    = "SetActiveSchedule"
 	DeleteActiveScheduleRouteName = "DeleteActiveSchedule"
@@ -26,6 +41,12 @@ const (
 	ActiveSchedulesPath         = "/v1/apps/{appid}/active_schedules"
 	GetActiveSchedulesRouteName = "GetActiveSchedules"
 
+	ScalingStatePath         = "/v1/apps/{appid}/scaling_state"
+	GetScalingStateRouteName = "GetScalingState"
+
+	WebhookDeliveriesPath         = "/v1/apps/{appid}/webhook_deliveries"
+	GetWebhookDeliveriesRouteName = "GetWebhookDeliveries"
+
 	SyncActiveSchedulesPath      = "/v1/syncSchedules"
 	SyncActiveSchedulesRouteName = "SyncActiveSchedules"
 
@@ -42,16 +63,50 @@ const (
 	PublicApiScalingHistoryPath      = "/{appId}/scaling_histories"
 	PublicApiScalingHistoryRouteName = "GetPublicApiScalingHistories"
 
+	PublicApiScalingHistoryExportPath        = "/{appId}/scaling_histories/export"
+	PublicApiExportScalingHistoriesRouteName = "ExportPublicApiScalingHistories"
+
 	PublicApiMetricsHistoryPath = "/{appId}/metric_histories/{metricType}"
 
 	PublicApiAggregatedMetricsHistoryPath      = "/{appId}/aggregated_metric_histories/{metricType}"
 	PublicApiAggregatedMetricsHistoryRouteName = "GetPublicApiAggregatedMetricsHistories"
 
+	PublicApiBacktestPath      = "/v1/apps/{appId}/backtest"
+	PublicApiBacktestRouteName = "PublicApiBacktest"
+
+	PublicApiScalingStatePath      = "/{appId}/scaling_state"
+	PublicApiScalingStateRouteName = "GetPublicApiScalingState"
+
+	PublicApiManualScalePath      = "/v1/apps/{appId}/scale"
+	PublicApiManualScaleRouteName = "PublicApiManualScale"
+
+	PublicApiWebhooksPath                  = "/v1/apps/{appId}/webhooks"
+	PublicApiGetWebhooksRouteName          = "GetWebhooks"
+	PublicApiCreateWebhookRouteName        = "CreateWebhook"
+	PublicApiWebhookPath                   = "/{webhookId}"
+	PublicApiDeleteWebhookRouteName        = "DeleteWebhook"
+	PublicApiWebhookDeliveriesPath         = "/deliveries"
+	PublicApiGetWebhookDeliveriesRouteName = "GetPublicApiWebhookDeliveries"
+
 	PublicApiPolicyPath            = "/v1/apps/{appId:.+}/policy"
 	PublicApiGetPolicyRouteName    = "GetPolicy"
 	PublicApiAttachPolicyRouteName = "AttachPolicy"
 	PublicApiDetachPolicyRouteName = "DetachPolicy"
 
+	PublicApiPolicyRevisionsPath             = "/v1/apps/{appId:.+}/policy/revisions"
+	PublicApiGetPolicyRevisionsRouteName     = "GetPolicyRevisions"
+	PublicApiPolicyRevisionPath              = "/{version:[0-9]+}"
+	PublicApiGetPolicyRevisionRouteName      = "GetPolicyRevision"
+	PublicApiPolicyRevisionDiffPath          = "/{version:[0-9]+}/diff"
+	PublicApiDiffPolicyRevisionRouteName     = "DiffPolicyRevision"
+	PublicApiPolicyRevisionRollbackPath      = "/{version:[0-9]+}/rollback"
+	PublicApiRollbackPolicyRevisionRouteName = "RollbackPolicyRevision"
+
+	PublicApiSuspensionPath         = "/v1/apps/{appId:.+}/suspension"
+	PublicApiGetSuspensionRouteName = "GetSuspension"
+	PublicApiSuspendAppRouteName    = "SuspendApp"
+	PublicApiResumeAppRouteName     = "ResumeApp"
+
 	PublicApiCredentialPath            = "/v1/apps/{appId:.+}/credential" // #nosec G101
 	PublicApiCreateCredentialRouteName = "CreateCredential"               // #nosec G101
 	PublicApiDeleteCredentialRouteName = "DeleteCredential"               // #nosec G101
@@ -64,43 +119,60 @@ const (
 )
 
 type AutoScalerRoute struct {
-	schedulerRoutes        *mux.Router
-	metricsCollectorRoutes *mux.Router
-	eventGeneratorRoutes   *mux.Router
-	scalingEngineRoutes    *mux.Router
-	metricServerRoutes     *mux.Router
-	metricsForwarderRoutes *mux.Router
-	apiOpenRoutes          *mux.Router
-	apiRoutes              *mux.Router
-	apiPolicyRoutes        *mux.Router
-	apiCredentialRoutes    *mux.Router
+	schedulerRoutes         *mux.Router
+	metricsCollectorRoutes  *mux.Router
+	eventGeneratorRoutes    *mux.Router
+	scalingEngineRoutes     *mux.Router
+	metricServerRoutes      *mux.Router
+	metricsForwarderRoutes  *mux.Router
+	apiOpenRoutes           *mux.Router
+	apiRoutes               *mux.Router
+	apiPolicyRoutes         *mux.Router
+	apiPolicyRevisionRoutes *mux.Router
+	apiSuspensionRoutes     *mux.Router
+	apiBacktestRoutes       *mux.Router
+	apiManualScaleRoutes    *mux.Router
+	apiWebhookRoutes        *mux.Router
+	apiCredentialRoutes     *mux.Router
 }
 
 var autoScalerRouteInstance = newRouters()
 
 func newRouters() *AutoScalerRoute {
 	instance := &AutoScalerRoute{
-		schedulerRoutes:        mux.NewRouter(),
-		metricsCollectorRoutes: mux.NewRouter(),
-		eventGeneratorRoutes:   mux.NewRouter(),
-		scalingEngineRoutes:    mux.NewRouter(),
-		metricServerRoutes:     mux.NewRouter(),
-		metricsForwarderRoutes: mux.NewRouter(),
-		apiOpenRoutes:          mux.NewRouter(),
-		apiRoutes:              mux.NewRouter(),
-		apiPolicyRoutes:        mux.NewRouter(),
-		apiCredentialRoutes:    mux.NewRouter(),
+		schedulerRoutes:         mux.NewRouter(),
+		metricsCollectorRoutes:  mux.NewRouter(),
+		eventGeneratorRoutes:    mux.NewRouter(),
+		scalingEngineRoutes:     mux.NewRouter(),
+		metricServerRoutes:      mux.NewRouter(),
+		metricsForwarderRoutes:  mux.NewRouter(),
+		apiOpenRoutes:           mux.NewRouter(),
+		apiRoutes:               mux.NewRouter(),
+		apiPolicyRoutes:         mux.NewRouter(),
+		apiPolicyRevisionRoutes: mux.NewRouter(),
+		apiSuspensionRoutes:     mux.NewRouter(),
+		apiBacktestRoutes:       mux.NewRouter(),
+		apiManualScaleRoutes:    mux.NewRouter(),
+		apiWebhookRoutes:        mux.NewRouter(),
+		apiCredentialRoutes:     mux.NewRouter(),
 	}
 
 	instance.metricsCollectorRoutes.Path(MetricHistoriesPath).Methods(http.MethodGet).Name(GetMetricHistoriesRouteName)
 
 	instance.eventGeneratorRoutes.Path(AggregatedMetricHistoriesPath).Methods(http.MethodGet).Name(GetAggregatedMetricHistoriesRouteName)
+	instance.eventGeneratorRoutes.Path(BacktestPath).Methods(http.MethodPost).Name(BacktestRouteName)
+	instance.eventGeneratorRoutes.Path(EvaluationStatePath).Methods(http.MethodGet).Name(GetEvaluationStateRouteName)
 
 	instance.scalingEngineRoutes.Path(ScalePath).Methods(http.MethodPost).Name(ScaleRouteName)
+	instance.scalingEngineRoutes.Path(ManualScalePath).Methods(http.MethodPost).Name(ManualScaleRouteName)
+	instance.scalingEngineRoutes.Path(RecommendPath).Methods(http.MethodPost).Name(RecommendRouteName)
 	instance.scalingEngineRoutes.Path(ScalingHistoriesPath).Methods(http.MethodGet).Name(GetScalingHistoriesRouteName)
+	instance.scalingEngineRoutes.Path(ScalingHistoriesExportPath).Methods(http.MethodGet).Name(ExportScalingHistoriesRouteName)
 	instance.scalingEngineRoutes.Path(ActiveSchedulePath).Methods(http.MethodPut).Name(SetActiveScheduleRouteName)
 	instance.scalingEngineRoutes.Path(ActiveSchedulePath).Methods(http.MethodDelete).Name(DeleteActiveScheduleRouteName)
 	instance.scalingEngineRoutes.Path(ActiveSchedulesPath).Methods(http.MethodGet).Name(GetActiveSchedulesRouteName)
+	instance.scalingEngineRoutes.Path(ScalingStatePath).Methods(http.MethodGet).Name(GetScalingStateRouteName)
+	instance.scalingEngineRoutes.Path(WebhookDeliveriesPath).Methods(http.MethodGet).Name(GetWebhookDeliveriesRouteName)
 	instance.scalingEngineRoutes.Path(SyncActiveSchedulesPath).Methods(http.MethodPut).Name(SyncActiveSchedulesRouteName)
 
 	instance.metricsForwarderRoutes.Path(CustomMetricsPath).Methods(http.MethodPost).Name(PostCustomMetricsRouteName)
@@ -114,6 +186,8 @@ func newRouters() *AutoScalerRoute {
 
 	instance.apiRoutes = instance.apiOpenRoutes.PathPrefix("/v1/apps").Subrouter()
 	instance.apiRoutes.Path(PublicApiScalingHistoryPath).Methods(http.MethodGet).Name(PublicApiScalingHistoryRouteName)
+	instance.apiRoutes.Path(PublicApiScalingHistoryExportPath).Methods(http.MethodGet).Name(PublicApiExportScalingHistoriesRouteName)
+	instance.apiRoutes.Path(PublicApiScalingStatePath).Methods(http.MethodGet).Name(PublicApiScalingStateRouteName)
 	instance.apiRoutes.Path(PublicApiAggregatedMetricsHistoryPath).Methods(http.MethodGet).Name(PublicApiAggregatedMetricsHistoryRouteName)
 
 	instance.apiPolicyRoutes = instance.apiOpenRoutes.Path(PublicApiPolicyPath).Subrouter()
@@ -121,6 +195,29 @@ func newRouters() *AutoScalerRoute {
 	instance.apiPolicyRoutes.Path("").Methods(http.MethodPut).Name(PublicApiAttachPolicyRouteName)
 	instance.apiPolicyRoutes.Path("").Methods(http.MethodDelete).Name(PublicApiDetachPolicyRouteName)
 
+	instance.apiPolicyRevisionRoutes = instance.apiOpenRoutes.PathPrefix(PublicApiPolicyRevisionsPath).Subrouter()
+	instance.apiPolicyRevisionRoutes.Path("").Methods(http.MethodGet).Name(PublicApiGetPolicyRevisionsRouteName)
+	instance.apiPolicyRevisionRoutes.Path(PublicApiPolicyRevisionPath).Methods(http.MethodGet).Name(PublicApiGetPolicyRevisionRouteName)
+	instance.apiPolicyRevisionRoutes.Path(PublicApiPolicyRevisionDiffPath).Methods(http.MethodGet).Name(PublicApiDiffPolicyRevisionRouteName)
+	instance.apiPolicyRevisionRoutes.Path(PublicApiPolicyRevisionRollbackPath).Methods(http.MethodPost).Name(PublicApiRollbackPolicyRevisionRouteName)
+
+	instance.apiSuspensionRoutes = instance.apiOpenRoutes.Path(PublicApiSuspensionPath).Subrouter()
+	instance.apiSuspensionRoutes.Path("").Methods(http.MethodGet).Name(PublicApiGetSuspensionRouteName)
+	instance.apiSuspensionRoutes.Path("").Methods(http.MethodPut).Name(PublicApiSuspendAppRouteName)
+	instance.apiSuspensionRoutes.Path("").Methods(http.MethodDelete).Name(PublicApiResumeAppRouteName)
+
+	instance.apiBacktestRoutes = instance.apiOpenRoutes.Path(PublicApiBacktestPath).Subrouter()
+	instance.apiBacktestRoutes.Path("").Methods(http.MethodPost).Name(PublicApiBacktestRouteName)
+
+	instance.apiManualScaleRoutes = instance.apiOpenRoutes.Path(PublicApiManualScalePath).Subrouter()
+	instance.apiManualScaleRoutes.Path("").Methods(http.MethodPost).Name(PublicApiManualScaleRouteName)
+
+	instance.apiWebhookRoutes = instance.apiOpenRoutes.PathPrefix(PublicApiWebhooksPath).Subrouter()
+	instance.apiWebhookRoutes.Path("").Methods(http.MethodGet).Name(PublicApiGetWebhooksRouteName)
+	instance.apiWebhookRoutes.Path("").Methods(http.MethodPost).Name(PublicApiCreateWebhookRouteName)
+	instance.apiWebhookRoutes.Path(PublicApiWebhookDeliveriesPath).Methods(http.MethodGet).Name(PublicApiGetWebhookDeliveriesRouteName)
+	instance.apiWebhookRoutes.Path(PublicApiWebhookPath).Methods(http.MethodDelete).Name(PublicApiDeleteWebhookRouteName)
+
 	instance.apiCredentialRoutes = instance.apiOpenRoutes.Path(PublicApiCredentialPath).Subrouter()
 	instance.apiCredentialRoutes.Path("").Methods(http.MethodPut).Name(PublicApiCreateCredentialRouteName)
 	instance.apiCredentialRoutes.Path("").Methods(http.MethodDelete).Name(PublicApiDeleteCredentialRouteName)
@@ -162,6 +259,21 @@ func ApiRoutes() *mux.Router {
 func ApiPolicyRoutes() *mux.Router {
 	return autoScalerRouteInstance.apiPolicyRoutes
 }
+func ApiPolicyRevisionRoutes() *mux.Router {
+	return autoScalerRouteInstance.apiPolicyRevisionRoutes
+}
+func ApiSuspensionRoutes() *mux.Router {
+	return autoScalerRouteInstance.apiSuspensionRoutes
+}
+func ApiBacktestRoutes() *mux.Router {
+	return autoScalerRouteInstance.apiBacktestRoutes
+}
+func ApiManualScaleRoutes() *mux.Router {
+	return autoScalerRouteInstance.apiManualScaleRoutes
+}
+func ApiWebhookRoutes() *mux.Router {
+	return autoScalerRouteInstance.apiWebhookRoutes
+}
 func ApiCredentialRoutes() *mux.Router {
 	return autoScalerRouteInstance.apiCredentialRoutes
 }
@@ -113,6 +113,261 @@ var _ = Describe("Routes", func() {
 			})
 		})
 
+		Context("PublicApiBacktestRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiBacktestRoutes().Get(routes.PublicApiBacktestRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/backtest"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiBacktestRoutes().Get(routes.PublicApiBacktestRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiExportScalingHistoriesRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiRoutes().Get(routes.PublicApiExportScalingHistoriesRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_histories/export"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiRoutes().Get(routes.PublicApiExportScalingHistoriesRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiScalingStateRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiRoutes().Get(routes.PublicApiScalingStateRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_state"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiRoutes().Get(routes.PublicApiScalingStateRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiGetSuspensionRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiSuspensionRoutes().Get(routes.PublicApiGetSuspensionRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/suspension"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiSuspensionRoutes().Get(routes.PublicApiGetSuspensionRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiSuspendAppRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiSuspensionRoutes().Get(routes.PublicApiSuspendAppRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/suspension"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiSuspensionRoutes().Get(routes.PublicApiSuspendAppRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiResumeAppRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiSuspensionRoutes().Get(routes.PublicApiResumeAppRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/suspension"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiSuspensionRoutes().Get(routes.PublicApiResumeAppRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiManualScaleRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiManualScaleRoutes().Get(routes.PublicApiManualScaleRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scale"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiManualScaleRoutes().Get(routes.PublicApiManualScaleRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiGetWebhooksRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiWebhookRoutes().Get(routes.PublicApiGetWebhooksRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/webhooks"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiWebhookRoutes().Get(routes.PublicApiGetWebhooksRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiCreateWebhookRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiWebhookRoutes().Get(routes.PublicApiCreateWebhookRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/webhooks"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiWebhookRoutes().Get(routes.PublicApiCreateWebhookRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiDeleteWebhookRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiWebhookRoutes().Get(routes.PublicApiDeleteWebhookRouteName).URLPath("appId", testAppId, "webhookId", "a-webhook-id")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/webhooks/a-webhook-id"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiWebhookRoutes().Get(routes.PublicApiDeleteWebhookRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiGetWebhookDeliveriesRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiWebhookRoutes().Get(routes.PublicApiGetWebhookDeliveriesRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/webhooks/deliveries"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiWebhookRoutes().Get(routes.PublicApiGetWebhookDeliveriesRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiGetPolicyRevisionsRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiGetPolicyRevisionsRouteName).URLPath("appId", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy/revisions"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiGetPolicyRevisionsRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiGetPolicyRevisionRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiGetPolicyRevisionRouteName).URLPath("appId", testAppId, "version", "3")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy/revisions/3"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiGetPolicyRevisionRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiDiffPolicyRevisionRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiDiffPolicyRevisionRouteName).URLPath("appId", testAppId, "version", "3")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy/revisions/3/diff"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiDiffPolicyRevisionRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("PublicApiRollbackPolicyRevisionRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiRollbackPolicyRevisionRouteName).URLPath("appId", testAppId, "version", "3")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/policy/revisions/3/rollback"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ApiPolicyRevisionRoutes().Get(routes.PublicApiRollbackPolicyRevisionRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
 		Context("PublicApiGetPolicyRouteName", func() {
 
 			Context("when provide correct route variable", func() {
@@ -219,6 +474,40 @@ var _ = Describe("Routes", func() {
 			})
 		})
 
+		Context("BacktestRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.EventGeneratorRoutes().Get(routes.BacktestRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/backtest"))
+				})
+			})
+
+			Context("when provide wrong route variable", func() {
+				It("should return error", func() {
+					_, err := routes.EventGeneratorRoutes().Get(routes.BacktestRouteName).URLPath("wrongVariable", testAppId)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("GetEvaluationStateRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.EventGeneratorRoutes().Get(routes.GetEvaluationStateRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/evaluation_state"))
+				})
+			})
+
+			Context("when provide wrong route variable", func() {
+				It("should return error", func() {
+					_, err := routes.EventGeneratorRoutes().Get(routes.GetEvaluationStateRouteName).URLPath("wrongVariable", testAppId)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
 	})
 
 	Describe("ScalingEngineRoutes", func() {
@@ -351,6 +640,74 @@ var _ = Describe("Routes", func() {
 				})
 			})
 		})
+
+		Context("ManualScaleRoute", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ScalingEngineRoutes().Get(routes.ManualScaleRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/manual_scale"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ScalingEngineRoutes().Get(routes.ManualScaleRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("GetScalingStateRoute", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ScalingEngineRoutes().Get(routes.GetScalingStateRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_state"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ScalingEngineRoutes().Get(routes.GetScalingStateRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("ExportScalingHistoriesRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ScalingEngineRoutes().Get(routes.ExportScalingHistoriesRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/scaling_histories/export"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ScalingEngineRoutes().Get(routes.ExportScalingHistoriesRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("GetWebhookDeliveriesRouteName", func() {
+			Context("when provide correct route variable", func() {
+				It("should return the correct path", func() {
+					path, err := routes.ScalingEngineRoutes().Get(routes.GetWebhookDeliveriesRouteName).URLPath("appid", testAppId)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(path.Path).To(Equal("/v1/apps/" + testAppId + "/webhook_deliveries"))
+				})
+			})
+
+			Context("when provide not enough route variable", func() {
+				It("should return error", func() {
+					_, err := routes.ScalingEngineRoutes().Get(routes.GetWebhookDeliveriesRouteName).URLPath()
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
 	})
 
 	Describe("MetricServerRoutes", func() {
@@ -0,0 +1,149 @@
+package scalingengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	ErrAppLeaseTimeout = errors.New("timed out waiting for the scaling lease of the app")
+	ErrAppLeaseLost    = errors.New("lost the scaling lease of the app")
+)
+
+// AppLeaseMetrics describes the contention on the scaling leases of the apps.
+type AppLeaseMetrics struct {
+	Contentions  prometheus.Counter
+	WaitDuration prometheus.Histogram
+	Timeouts     prometheus.Counter
+}
+
+func NewAppLeaseMetrics() *AppLeaseMetrics {
+	return &AppLeaseMetrics{
+		Contentions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "autoscaler",
+			Subsystem: "scalingengine",
+			Name:      "app_lease_contentions_total",
+			Help:      "the number of attempts to take the scaling lease of an app held by another scaling engine instance",
+		}),
+		WaitDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "autoscaler",
+			Subsystem: "scalingengine",
+			Name:      "app_lease_wait_seconds",
+			Help:      "the time waited for the scaling lease of an app",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30},
+		}),
+		Timeouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "autoscaler",
+			Subsystem: "scalingengine",
+			Name:      "app_lease_timeouts_total",
+			Help:      "the number of scaling operations failed because the scaling lease of the app was not released in time",
+		}),
+	}
+}
+
+func (m *AppLeaseMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.Contentions, m.WaitDuration, m.Timeouts}
+}
+
+// AppLease serialises the scaling operations of an app across all scaling engine instances with a lease in the
+// scaling engine database. The lease is renewed while it is held, and expires after the ttl if its owner dies. Every
+// acquisition owns the lease under its own token, so that the operations of the same instance are serialised as well.
+type AppLease struct {
+	logger          lager.Logger
+	clock           clock.Clock
+	scalingEngineDB db.ScalingEngineDB
+	owner           string
+	ttl             time.Duration
+	retryInterval   time.Duration
+	waitTimeout     time.Duration
+	metrics         *AppLeaseMetrics
+	acquisitions    atomic.Uint64
+}
+
+func NewAppLease(logger lager.Logger, clock clock.Clock, scalingEngineDB db.ScalingEngineDB, owner string, ttl time.Duration, retryInterval time.Duration, waitTimeout time.Duration, metrics *AppLeaseMetrics) *AppLease {
+	return &AppLease{
+		logger:          logger.Session("app-lease"),
+		clock:           clock,
+		scalingEngineDB: scalingEngineDB,
+		owner:           owner,
+		ttl:             ttl,
+		retryInterval:   retryInterval,
+		waitTimeout:     waitTimeout,
+		metrics:         metrics,
+	}
+}
+
+// Acquire waits until it holds the lease of the app and returns the context of the operation holding it and the
+// function releasing it. The context is cancelled with ErrAppLeaseLost if the lease cannot be renewed, as another
+// instance may take it over once it expired. Acquire returns ErrAppLeaseTimeout if another operation holds the lease
+// for longer than the wait timeout.
+func (l *AppLease) Acquire(appId string) (context.Context, func(), error) {
+	owner := fmt.Sprintf("%s/%d", l.owner, l.acquisitions.Add(1))
+	logger := l.logger.WithData(lager.Data{"appId": appId, "owner": owner})
+	start := l.clock.Now()
+	for {
+		now := l.clock.Now()
+		acquired, err := l.scalingEngineDB.AcquireAppLease(appId, owner, now.UnixNano(), now.Add(l.ttl).UnixNano())
+		if err != nil {
+			logger.Error("failed-to-acquire-app-lease", err)
+			return nil, nil, err
+		}
+		if acquired {
+			l.metrics.WaitDuration.Observe(now.Sub(start).Seconds())
+			ctx, release := l.hold(logger, appId, owner)
+			return ctx, release, nil
+		}
+
+		l.metrics.Contentions.Inc()
+		if now.Sub(start) >= l.waitTimeout {
+			l.metrics.Timeouts.Inc()
+			logger.Info("app-lease-timeout", lager.Data{"waitTimeout": l.waitTimeout})
+			return nil, nil, ErrAppLeaseTimeout
+		}
+		l.clock.Sleep(l.retryInterval)
+	}
+}
+
+func (l *AppLease) hold(logger lager.Logger, appId string, owner string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	doneChan := make(chan bool)
+	stoppedChan := make(chan bool)
+	go func() {
+		defer close(stoppedChan)
+		ticker := l.clock.NewTicker(l.ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-doneChan:
+				return
+			case <-ticker.C():
+				now := l.clock.Now()
+				renewed, err := l.scalingEngineDB.AcquireAppLease(appId, owner, now.UnixNano(), now.Add(l.ttl).UnixNano())
+				if err != nil || !renewed {
+					cancel(ErrAppLeaseLost)
+					logger.Error("failed-to-renew-app-lease", err, lager.Data{"renewed": renewed})
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() {
+		close(doneChan)
+		<-stoppedChan
+		cancel(nil)
+		err := l.scalingEngineDB.ReleaseAppLease(appId, owner)
+		if err != nil {
+			logger.Error("failed-to-release-app-lease", err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package scalingengine_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("AppLease", func() {
+	const (
+		ttl           = 30 * time.Second
+		retryInterval = time.Second
+		waitTimeout   = 3 * time.Second
+	)
+
+	var (
+		scalingEngineDB *fakes.FakeScalingEngineDB
+		fclock          *fakeclock.FakeClock
+		metrics         *AppLeaseMetrics
+		appLease        *AppLease
+		ctx             context.Context
+		release         func()
+		err             error
+		done            chan bool
+	)
+
+	acquire := func() {
+		done = make(chan bool)
+		go func() {
+			defer GinkgoRecover()
+			ctx, release, err = appLease.Acquire("an-app-id")
+			close(done)
+		}()
+	}
+
+	BeforeEach(func() {
+		scalingEngineDB = &fakes.FakeScalingEngineDB{}
+		fclock = fakeclock.NewFakeClock(time.Now())
+		metrics = NewAppLeaseMetrics()
+		appLease = NewAppLease(lagertest.NewTestLogger("app-lease-test"), fclock, scalingEngineDB, "an-owner", ttl, retryInterval, waitTimeout, metrics)
+	})
+
+	Context("when the lease is free", func() {
+		BeforeEach(func() {
+			scalingEngineDB.AcquireAppLeaseReturns(true, nil)
+			acquire()
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("holds the lease until it is released", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scalingEngineDB.AcquireAppLeaseCallCount()).To(Equal(1))
+			Expect(testutil.ToFloat64(metrics.Contentions)).To(BeZero())
+
+			fclock.WaitForWatcherAndIncrement(ttl / 2)
+			Eventually(scalingEngineDB.AcquireAppLeaseCallCount).Should(Equal(2))
+			appId, owner, now, expireAt := scalingEngineDB.AcquireAppLeaseArgsForCall(1)
+			Expect(appId).To(Equal("an-app-id"))
+			Expect(owner).To(Equal("an-owner/1"))
+			Expect(expireAt).To(Equal(time.Unix(0, now).Add(ttl).UnixNano()))
+			Expect(ctx.Err()).NotTo(HaveOccurred())
+
+			release()
+			Expect(scalingEngineDB.ReleaseAppLeaseCallCount()).To(Equal(1))
+			_, owner = scalingEngineDB.ReleaseAppLeaseArgsForCall(0)
+			Expect(owner).To(Equal("an-owner/1"))
+			Expect(ctx.Err()).To(MatchError(context.Canceled))
+			fclock.Increment(ttl)
+			Consistently(scalingEngineDB.AcquireAppLeaseCallCount).Should(Equal(2))
+		})
+
+		It("takes the lease with a new owner token for every acquisition", func() {
+			release()
+			acquire()
+			Eventually(done).Should(BeClosed())
+			defer release()
+			_, owner, _, _ := scalingEngineDB.AcquireAppLeaseArgsForCall(1)
+			Expect(owner).To(Equal("an-owner/2"))
+		})
+	})
+
+	Context("when the lease cannot be renewed", func() {
+		BeforeEach(func() {
+			scalingEngineDB.AcquireAppLeaseReturnsOnCall(0, true, nil)
+			scalingEngineDB.AcquireAppLeaseReturnsOnCall(1, false, nil)
+			acquire()
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("cancels the context of the operation holding it", func() {
+			fclock.WaitForWatcherAndIncrement(ttl / 2)
+			Eventually(ctx.Done()).Should(BeClosed())
+			Expect(context.Cause(ctx)).To(MatchError(ErrAppLeaseLost))
+
+			fclock.Increment(ttl)
+			Consistently(scalingEngineDB.AcquireAppLeaseCallCount).Should(Equal(2))
+			release()
+			Expect(scalingEngineDB.ReleaseAppLeaseCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when another instance holds the lease", func() {
+		BeforeEach(func() {
+			scalingEngineDB.AcquireAppLeaseReturnsOnCall(0, false, nil)
+			scalingEngineDB.AcquireAppLeaseReturnsOnCall(1, false, nil)
+			scalingEngineDB.AcquireAppLeaseReturnsOnCall(2, true, nil)
+			acquire()
+		})
+
+		AfterEach(func() {
+			if release != nil {
+				release()
+			}
+		})
+
+		It("waits until the lease is released", func() {
+			fclock.WaitForWatcherAndIncrement(retryInterval)
+			fclock.WaitForWatcherAndIncrement(retryInterval)
+			Eventually(done).Should(BeClosed())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scalingEngineDB.AcquireAppLeaseCallCount()).To(Equal(3))
+			Expect(testutil.ToFloat64(metrics.Contentions)).To(Equal(2.0))
+		})
+	})
+
+	Context("when another instance holds the lease for longer than the wait timeout", func() {
+		BeforeEach(func() {
+			scalingEngineDB.AcquireAppLeaseReturns(false, nil)
+			acquire()
+		})
+
+		It("times out", func() {
+			for i := 0; i < 3; i++ {
+				fclock.WaitForWatcherAndIncrement(retryInterval)
+			}
+			Eventually(done).Should(BeClosed())
+			Expect(err).To(MatchError(ErrAppLeaseTimeout))
+			Expect(ctx).To(BeNil())
+			Expect(release).To(BeNil())
+			Expect(testutil.ToFloat64(metrics.Timeouts)).To(Equal(1.0))
+		})
+	})
+
+	Context("when the lease cannot be taken", func() {
+		BeforeEach(func() {
+			scalingEngineDB.AcquireAppLeaseReturns(false, errors.New("an error"))
+			acquire()
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(MatchError("an error"))
+			Expect(release).To(BeNil())
+		})
+	})
+})
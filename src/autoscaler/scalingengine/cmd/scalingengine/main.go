@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db/sqldb"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/healthendpoint"
@@ -14,6 +15,7 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/config"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/schedule"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/server"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/webhook"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -82,19 +84,48 @@ func main() {
 	defer func() { _ = schedulerDB.Close() }()
 
 	httpStatusCollector := healthendpoint.NewHTTPStatusCollector("autoscaler", "scalingengine")
+	quotaStarvedApps := prometheus.NewGauge(scalingengine.QuotaStarvedAppsGaugeOpts)
+	appLeaseMetrics := scalingengine.NewAppLeaseMetrics()
 	promRegistry := prometheus.NewRegistry()
-	healthendpoint.RegisterCollectors(promRegistry, []prometheus.Collector{
+	healthendpoint.RegisterCollectors(promRegistry, append([]prometheus.Collector{
 		healthendpoint.NewDatabaseStatusCollector("autoscaler", "scalingengine", "policyDB", policyDb),
 		healthendpoint.NewDatabaseStatusCollector("autoscaler", "scalingengine", "scalingengineDB", scalingEngineDB),
 		healthendpoint.NewDatabaseStatusCollector("autoscaler", "scalingengine", "schedulerDB", schedulerDB),
 		httpStatusCollector,
-	}, true, logger.Session("scalingengine-prometheus"))
+		quotaStarvedApps,
+	}, appLeaseMetrics.Collectors()...), true, logger.Session("scalingengine-prometheus"))
 
 	otel.SetTracerProvider(sdktrace.NewTracerProvider())
 	otel.SetTextMapPropagator(propagation.TraceContext{})
 
-	scalingEngine := scalingengine.NewScalingEngine(logger, cfClient, policyDb, scalingEngineDB, eClock, conf.DefaultCoolDownSecs, conf.LockSize)
+	appLog, err := applog.NewEmitter(logger, conf.AppLog, "scalingengine")
+	if err != nil {
+		logger.Error("failed to create app log emitter", err)
+		os.Exit(1)
+	}
+
+	var appLease *scalingengine.AppLease
+	if conf.AppLease.Enabled {
+		owner, err := helpers.GenerateGUID(logger)
+		if err != nil {
+			logger.Error("failed-to-generate-guid", err)
+			os.Exit(1)
+		}
+		appLease = scalingengine.NewAppLease(logger, eClock, scalingEngineDB, owner, conf.AppLease.TTL, conf.AppLease.RetryInterval, conf.AppLease.WaitTimeout, appLeaseMetrics)
+	}
+
+	var readinessCheck *scalingengine.ReadinessCheck
+	if conf.ReadinessCheck.Enabled {
+		readinessCheck = scalingengine.NewReadinessCheck(logger, eClock, cfClient.GetCtxClient(), scalingEngineDB, conf.ReadinessCheck.Timeout, conf.ReadinessCheck.PollInterval)
+	}
+
+	scalingEngine := scalingengine.NewScalingEngine(logger, cfClient, policyDb, scalingEngineDB, appLog, eClock, conf.DefaultCoolDownSecs, conf.LockSize, quotaStarvedApps, appLease, readinessCheck)
 	synchronizer := schedule.NewActiveScheduleSychronizer(logger, schedulerDB, scalingEngineDB, scalingEngine)
+	webhookDispatcher, err := webhook.NewDispatcher(logger, policyDb, scalingEngineDB, conf.Webhook, eClock)
+	if err != nil {
+		logger.Error("failed to create webhook dispatcher", err)
+		os.Exit(1)
+	}
 
 	httpServer, err := server.NewServer(logger.Session("http-server"), conf, scalingEngineDB, scalingEngine, synchronizer, httpStatusCollector)
 	if err != nil {
@@ -108,10 +139,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	members := grouper.Members{
+	members := grouper.Members{}
+	if readinessCheck != nil {
+		// stopped after the http server, so that no scaling starts a check once the checks are cancelled
+		members = append(members, grouper.Member{Name: "readiness_check", Runner: readinessCheck})
+	}
+	members = append(members, grouper.Members{
 		{"http_server", httpServer},
 		{"health_server", healthServer},
-	}
+		{"webhook_dispatcher", webhookDispatcher},
+	}...)
 
 	monitor := ifrit.Invoke(sigmon.New(grouper.NewOrdered(os.Interrupt, members)))
 	logger.Info("started")
@@ -61,6 +61,7 @@ var _ = SynchronizedBeforeSuite(
 			GetApp(models.AppStatusStarted, http.StatusOK, "test_space_guid").
 			GetAppProcesses(2).
 			ScaleAppWebProcess().
+			SpaceQuotas().
 			OauthToken("test-token")
 
 		conf.CF = cf.Config{
@@ -8,6 +8,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
@@ -35,6 +36,30 @@ var defaultLoggingConfig = helpers.LoggingConfig{
 	Level: "info",
 }
 
+var defaultWebhookConfig = WebhookConfig{
+	DispatchInterval: 10 * time.Second,
+	BatchSize:        100,
+	MaxAttempts:      8,
+	RetryBaseDelay:   10 * time.Second,
+	RetryMaxDelay:    time.Hour,
+	Timeout:          10 * time.Second,
+}
+
+var defaultAppLeaseConfig = AppLeaseConfig{
+	TTL:           30 * time.Second,
+	RetryInterval: 200 * time.Millisecond,
+	WaitTimeout:   10 * time.Second,
+}
+
+var defaultReadinessCheckConfig = ReadinessCheckConfig{
+	Timeout:      2 * time.Minute,
+	PollInterval: 5 * time.Second,
+}
+
+var defaultAppLogConfig = applog.Config{
+	MetronAddress: applog.DefaultMetronAddress,
+}
+
 type DBConfig struct {
 	PolicyDB        db.DatabaseConfig `yaml:"policy_db"`
 	ScalingEngineDB db.DatabaseConfig `yaml:"scalingengine_db"`
@@ -45,6 +70,32 @@ type SynchronizerConfig struct {
 	ActiveScheduleSyncInterval time.Duration `yaml:"active_schedule_sync_interval"`
 }
 
+type WebhookConfig struct {
+	DispatchInterval time.Duration `yaml:"dispatch_interval"`
+	BatchSize        int           `yaml:"batch_size"`
+	MaxAttempts      int           `yaml:"max_attempts"`
+	RetryBaseDelay   time.Duration `yaml:"retry_base_delay"`
+	RetryMaxDelay    time.Duration `yaml:"retry_max_delay"`
+	Timeout          time.Duration `yaml:"timeout"`
+	AllowedNetworks  []string      `yaml:"allowed_networks"`
+}
+
+// AppLeaseConfig configures the database lease which serialises the scaling operations of an app across scaling engine
+// instances.
+type AppLeaseConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	TTL           time.Duration `yaml:"ttl"`
+	RetryInterval time.Duration `yaml:"retry_interval"`
+	WaitTimeout   time.Duration `yaml:"wait_timeout"`
+}
+
+// ReadinessCheckConfig configures the check which waits for the instances of a scaled process to be running.
+type ReadinessCheckConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	Timeout      time.Duration `yaml:"timeout"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+}
+
 type Config struct {
 	CF                  cf.Config             `yaml:"cf"`
 	Logging             helpers.LoggingConfig `yaml:"logging"`
@@ -54,6 +105,10 @@ type Config struct {
 	DefaultCoolDownSecs int                   `yaml:"defaultCoolDownSecs"`
 	LockSize            int                   `yaml:"lockSize"`
 	HttpClientTimeout   time.Duration         `yaml:"http_client_timeout"`
+	Webhook             WebhookConfig         `yaml:"webhook"`
+	AppLease            AppLeaseConfig        `yaml:"app_lease"`
+	ReadinessCheck      ReadinessCheckConfig  `yaml:"readiness_check"`
+	AppLog              applog.Config         `yaml:"app_log"`
 }
 
 func LoadConfig(reader io.Reader) (*Config, error) {
@@ -63,6 +118,10 @@ func LoadConfig(reader io.Reader) (*Config, error) {
 		Server:            defaultServerConfig,
 		Health:            defaultHealthConfig,
 		HttpClientTimeout: DefaultHttpClientTimeout,
+		Webhook:           defaultWebhookConfig,
+		AppLease:          defaultAppLeaseConfig,
+		ReadinessCheck:    defaultReadinessCheckConfig,
+		AppLog:            defaultAppLogConfig,
 	}
 
 	dec := yaml.NewDecoder(reader)
@@ -108,6 +167,62 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("Configuration error: http_client_timeout is less-equal than 0")
 	}
 
+	if c.Webhook.DispatchInterval <= time.Duration(0) {
+		return fmt.Errorf("Configuration error: webhook.dispatch_interval is less-equal than 0")
+	}
+
+	if c.Webhook.BatchSize <= 0 {
+		return fmt.Errorf("Configuration error: webhook.batch_size is less-equal than 0")
+	}
+
+	if c.Webhook.MaxAttempts <= 0 {
+		return fmt.Errorf("Configuration error: webhook.max_attempts is less-equal than 0")
+	}
+
+	if c.Webhook.RetryBaseDelay <= time.Duration(0) {
+		return fmt.Errorf("Configuration error: webhook.retry_base_delay is less-equal than 0")
+	}
+
+	if c.Webhook.RetryMaxDelay < c.Webhook.RetryBaseDelay {
+		return fmt.Errorf("Configuration error: webhook.retry_max_delay is less than webhook.retry_base_delay")
+	}
+
+	if c.Webhook.Timeout <= time.Duration(0) {
+		return fmt.Errorf("Configuration error: webhook.timeout is less-equal than 0")
+	}
+
+	if _, err := helpers.NewAddressGuard(c.Webhook.AllowedNetworks); err != nil {
+		return fmt.Errorf("Configuration error: webhook.allowed_networks: %w", err)
+	}
+
+	if c.AppLease.Enabled {
+		if c.AppLease.TTL <= time.Duration(0) {
+			return fmt.Errorf("Configuration error: app_lease.ttl is less-equal than 0")
+		}
+
+		if c.AppLease.RetryInterval <= time.Duration(0) {
+			return fmt.Errorf("Configuration error: app_lease.retry_interval is less-equal than 0")
+		}
+
+		if c.AppLease.WaitTimeout <= time.Duration(0) {
+			return fmt.Errorf("Configuration error: app_lease.wait_timeout is less-equal than 0")
+		}
+	}
+
+	if c.ReadinessCheck.Enabled {
+		if c.ReadinessCheck.Timeout <= time.Duration(0) {
+			return fmt.Errorf("Configuration error: readiness_check.timeout is less-equal than 0")
+		}
+
+		if c.ReadinessCheck.PollInterval <= time.Duration(0) {
+			return fmt.Errorf("Configuration error: readiness_check.poll_interval is less-equal than 0")
+		}
+	}
+
+	if err := c.AppLog.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Health.Validate(); err != nil {
 		return err
 	}
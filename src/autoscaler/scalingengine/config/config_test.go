@@ -1,7 +1,9 @@
 package config_test
 
 import (
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/config"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/testhelpers"
 
@@ -85,6 +87,39 @@ var _ = Describe("Config", func() {
 				Expect(conf.LockSize).To(Equal(32))
 
 				Expect(conf.HttpClientTimeout).To(Equal(10 * time.Second))
+
+				Expect(conf.Webhook).To(Equal(WebhookConfig{
+					DispatchInterval: 5 * time.Second,
+					BatchSize:        50,
+					MaxAttempts:      4,
+					RetryBaseDelay:   30 * time.Second,
+					RetryMaxDelay:    30 * time.Minute,
+					Timeout:          3 * time.Second,
+					AllowedNetworks:  []string{"10.0.0.0/8"},
+				}))
+
+				Expect(conf.AppLease).To(Equal(AppLeaseConfig{
+					Enabled:       true,
+					TTL:           time.Minute,
+					RetryInterval: 500 * time.Millisecond,
+					WaitTimeout:   20 * time.Second,
+				}))
+
+				Expect(conf.ReadinessCheck).To(Equal(ReadinessCheckConfig{
+					Enabled:      true,
+					Timeout:      3 * time.Minute,
+					PollInterval: 10 * time.Second,
+				}))
+
+				Expect(conf.AppLog).To(Equal(applog.Config{
+					Enabled:       true,
+					MetronAddress: "127.0.0.1:3459",
+					TLS: models.TLSCerts{
+						KeyFile:    "/var/vcap/jobs/scalingengine/config/certs/metron_client/client.key",
+						CertFile:   "/var/vcap/jobs/scalingengine/config/certs/metron_client/client.crt",
+						CACertFile: "/var/vcap/jobs/scalingengine/config/certs/metron_client/ca.crt",
+					},
+				}))
 			})
 		})
 
@@ -123,6 +158,33 @@ var _ = Describe("Config", func() {
 					}))
 
 				Expect(conf.HttpClientTimeout).To(Equal(5 * time.Second))
+
+				Expect(conf.Webhook).To(Equal(WebhookConfig{
+					DispatchInterval: 10 * time.Second,
+					BatchSize:        100,
+					MaxAttempts:      8,
+					RetryBaseDelay:   10 * time.Second,
+					RetryMaxDelay:    time.Hour,
+					Timeout:          10 * time.Second,
+				}))
+
+				Expect(conf.AppLease).To(Equal(AppLeaseConfig{
+					Enabled:       false,
+					TTL:           30 * time.Second,
+					RetryInterval: 200 * time.Millisecond,
+					WaitTimeout:   10 * time.Second,
+				}))
+
+				Expect(conf.ReadinessCheck).To(Equal(ReadinessCheckConfig{
+					Enabled:      false,
+					Timeout:      2 * time.Minute,
+					PollInterval: 5 * time.Second,
+				}))
+
+				Expect(conf.AppLog).To(Equal(applog.Config{
+					Enabled:       false,
+					MetronAddress: "127.0.0.1:3458",
+				}))
 			})
 		})
 
@@ -201,6 +263,14 @@ health:
 			conf.DefaultCoolDownSecs = 300
 			conf.LockSize = 32
 			conf.HttpClientTimeout = 10 * time.Second
+			conf.Webhook = WebhookConfig{
+				DispatchInterval: 10 * time.Second,
+				BatchSize:        100,
+				MaxAttempts:      8,
+				RetryBaseDelay:   10 * time.Second,
+				RetryMaxDelay:    time.Hour,
+				Timeout:          10 * time.Second,
+			}
 		})
 
 		JustBeforeEach(func() {
@@ -291,6 +361,152 @@ health:
 				Expect(err).To(MatchError("Configuration error: http_client_timeout is less-equal than 0"))
 			})
 		})
+
+		Context("when webhook.dispatch_interval is <= 0", func() {
+			BeforeEach(func() {
+				conf.Webhook.DispatchInterval = 0
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: webhook.dispatch_interval is less-equal than 0"))
+			})
+		})
+
+		Context("when webhook.batch_size is <= 0", func() {
+			BeforeEach(func() {
+				conf.Webhook.BatchSize = 0
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: webhook.batch_size is less-equal than 0"))
+			})
+		})
+
+		Context("when webhook.max_attempts is <= 0", func() {
+			BeforeEach(func() {
+				conf.Webhook.MaxAttempts = 0
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: webhook.max_attempts is less-equal than 0"))
+			})
+		})
+
+		Context("when webhook.retry_base_delay is <= 0", func() {
+			BeforeEach(func() {
+				conf.Webhook.RetryBaseDelay = 0
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: webhook.retry_base_delay is less-equal than 0"))
+			})
+		})
+
+		Context("when webhook.retry_max_delay is less than webhook.retry_base_delay", func() {
+			BeforeEach(func() {
+				conf.Webhook.RetryMaxDelay = 5 * time.Second
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: webhook.retry_max_delay is less than webhook.retry_base_delay"))
+			})
+		})
+
+		Context("when webhook.timeout is <= 0", func() {
+			BeforeEach(func() {
+				conf.Webhook.Timeout = 0
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: webhook.timeout is less-equal than 0"))
+			})
+		})
+
+		Context("when webhook.allowed_networks contains an invalid network", func() {
+			BeforeEach(func() {
+				conf.Webhook.AllowedNetworks = []string{"10.0.0.1"}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError(ContainSubstring("Configuration error: webhook.allowed_networks: invalid allowed network \"10.0.0.1\"")))
+			})
+		})
+
+		Context("when the app lease is enabled", func() {
+			BeforeEach(func() {
+				conf.AppLease = AppLeaseConfig{
+					Enabled:       true,
+					TTL:           30 * time.Second,
+					RetryInterval: 200 * time.Millisecond,
+					WaitTimeout:   10 * time.Second,
+				}
+			})
+
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when app_lease.ttl is <= 0", func() {
+				BeforeEach(func() {
+					conf.AppLease.TTL = 0
+				})
+				It("should error", func() {
+					Expect(err).To(MatchError("Configuration error: app_lease.ttl is less-equal than 0"))
+				})
+			})
+
+			Context("when app_lease.retry_interval is <= 0", func() {
+				BeforeEach(func() {
+					conf.AppLease.RetryInterval = 0
+				})
+				It("should error", func() {
+					Expect(err).To(MatchError("Configuration error: app_lease.retry_interval is less-equal than 0"))
+				})
+			})
+
+			Context("when app_lease.wait_timeout is <= 0", func() {
+				BeforeEach(func() {
+					conf.AppLease.WaitTimeout = 0
+				})
+				It("should error", func() {
+					Expect(err).To(MatchError("Configuration error: app_lease.wait_timeout is less-equal than 0"))
+				})
+			})
+		})
+
+		Context("when the readiness check is enabled", func() {
+			BeforeEach(func() {
+				conf.ReadinessCheck = ReadinessCheckConfig{
+					Enabled:      true,
+					Timeout:      2 * time.Minute,
+					PollInterval: 5 * time.Second,
+				}
+			})
+
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when readiness_check.timeout is <= 0", func() {
+				BeforeEach(func() {
+					conf.ReadinessCheck.Timeout = 0
+				})
+				It("should error", func() {
+					Expect(err).To(MatchError("Configuration error: readiness_check.timeout is less-equal than 0"))
+				})
+			})
+
+			Context("when readiness_check.poll_interval is <= 0", func() {
+				BeforeEach(func() {
+					conf.ReadinessCheck.PollInterval = 0
+				})
+				It("should error", func() {
+					Expect(err).To(MatchError("Configuration error: readiness_check.poll_interval is less-equal than 0"))
+				})
+			})
+		})
+
+		Context("when the app log is enabled and app_log.metron_address is empty", func() {
+			BeforeEach(func() {
+				conf.AppLog = applog.Config{Enabled: true}
+			})
+			It("should error", func() {
+				Expect(err).To(MatchError("Configuration error: app_log.metron_address is empty"))
+			})
+		})
 	})
 
 })
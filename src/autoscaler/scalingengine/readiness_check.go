@@ -0,0 +1,151 @@
+package scalingengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// ReadinessCheck verifies the outcome of a scaling. It polls the instance states of the scaled process until all
+// instances are running or the timeout runs out, records the scaling as failed if instances did not get ready, and
+// restarts the cooldown from the time the instances got ready. The checks run in the background until the scaling
+// engine stops.
+type ReadinessCheck struct {
+	logger          lager.Logger
+	clock           clock.Clock
+	cfClient        cf.ContextClient
+	scalingEngineDB db.ScalingEngineDB
+	timeout         time.Duration
+	pollInterval    time.Duration
+	ctx             context.Context
+	cancel          context.CancelFunc
+	checks          sync.WaitGroup
+}
+
+func NewReadinessCheck(logger lager.Logger, clock clock.Clock, cfClient cf.ContextClient, scalingEngineDB db.ScalingEngineDB, timeout time.Duration, pollInterval time.Duration) *ReadinessCheck {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReadinessCheck{
+		logger:          logger.Session("readiness-check"),
+		clock:           clock,
+		cfClient:        cfClient,
+		scalingEngineDB: scalingEngineDB,
+		timeout:         timeout,
+		pollInterval:    pollInterval,
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+}
+
+// Run waits for the signal to stop and then cancels the running checks and waits for them to return.
+func (c *ReadinessCheck) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+	c.logger.Info("started")
+
+	<-signals
+	c.cancel()
+	c.checks.Wait()
+	c.logger.Info("stopped")
+	return nil
+}
+
+// Start checks a saved scaling history in the background, so that the caller does not wait for the instances.
+func (c *ReadinessCheck) Start(history models.AppScalingHistory, coolDownExpiredAt int64, coolDown time.Duration, directions ...string) {
+	c.checks.Add(1)
+	go func() {
+		defer c.checks.Done()
+		c.Check(c.ctx, history, coolDownExpiredAt, coolDown, directions...)
+	}()
+}
+
+// Check waits for the instances of a saved scaling history and then updates its outcome and extends the cooldown the
+// scaling started in the given directions, unless a later scaling restarted it. It gives up without any update once
+// the context is cancelled.
+func (c *ReadinessCheck) Check(ctx context.Context, history models.AppScalingHistory, coolDownExpiredAt int64, coolDown time.Duration, directions ...string) {
+	logger := c.logger.WithData(lager.Data{"appId": history.AppId, "processType": history.ProcessType, "instances": history.NewInstances})
+
+	readyAt, notReady, unverified := c.waitForInstances(ctx, logger, history.AppId, history.ProcessType, history.NewInstances)
+	if ctx.Err() != nil {
+		logger.Info("readiness-check-cancelled")
+		return
+	}
+	if notReady != "" || unverified != "" {
+		if notReady != "" {
+			history.Status = models.ScalingStatusFailed
+			history.Error = notReady
+		} else if history.Message != "" {
+			history.Message = history.Message + "; " + unverified
+		} else {
+			history.Message = unverified
+		}
+		err := c.scalingEngineDB.UpdateScalingHistoryOutcome(&history)
+		if err != nil {
+			logger.Error("failed-to-update-scaling-history", err)
+		}
+	}
+
+	expireAt := readyAt.Add(coolDown).UnixNano()
+	for _, direction := range directions {
+		err := c.scalingEngineDB.ExtendScalingCooldownExpireTime(history.AppId, history.ProcessType, direction, coolDownExpiredAt, expireAt)
+		if err != nil {
+			logger.Error("failed-to-extend-scaling-cool-down-expire-time", err, lager.Data{"direction": direction})
+		}
+	}
+}
+
+// waitForInstances returns the time the instances got ready, or the check gave up, and describes the instances which
+// did not get ready or, if their states could not be retrieved, why the check failed. Both are empty if the instances
+// got ready.
+func (c *ReadinessCheck) waitForInstances(ctx context.Context, logger lager.Logger, appId string, processType string, instances int) (time.Time, string, string) {
+	deadline := c.clock.Now().Add(c.timeout)
+	var states map[string]int
+	var err error
+	for {
+		now := c.clock.Now()
+		var stats cf.ProcessStats
+		stats, err = c.cfClient.GetAppProcessStats(ctx, cf.Guid(appId), processType)
+		if err != nil {
+			logger.Error("failed-to-get-process-stats", err)
+		} else {
+			states = stats.CountStates(instances)
+			if states[cf.InstanceStateRunning] >= instances {
+				logger.Info("instances-ready")
+				return now, "", ""
+			}
+		}
+		if !now.Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return now, "", ""
+		case <-c.clock.After(c.pollInterval):
+		}
+	}
+
+	now := c.clock.Now()
+	if states == nil {
+		logger.Info("instances-not-verified", lager.Data{"timeout": c.timeout})
+		return now, "", "scaled but failed to get the instance states: " + err.Error()
+	}
+
+	logger.Info("instances-not-ready", lager.Data{"timeout": c.timeout, "states": states})
+	var problems []string
+	crashed := states[cf.InstanceStateCrashed]
+	if crashed > 0 {
+		problems = append(problems, fmt.Sprintf("%d instances crashed", crashed))
+	}
+	if notRunning := instances - states[cf.InstanceStateRunning] - crashed; notRunning > 0 {
+		problems = append(problems, fmt.Sprintf("%d instances not running after %s", notRunning, c.timeout))
+	}
+	return now, "scaled but " + strings.Join(problems, " and "), ""
+}
@@ -0,0 +1,194 @@
+package scalingengine_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("ReadinessCheck", func() {
+	const (
+		timeout      = 30 * time.Second
+		pollInterval = 10 * time.Second
+		coolDown     = 5 * time.Minute
+	)
+
+	var (
+		cfc             *fakes.FakeContextClient
+		scalingEngineDB *fakes.FakeScalingEngineDB
+		fclock          *fakeclock.FakeClock
+		start           time.Time
+		readinessCheck  *ReadinessCheck
+		ctx             context.Context
+		cancel          context.CancelFunc
+		done            chan bool
+	)
+
+	statsOf := func(states ...string) cf.ProcessStats {
+		stats := cf.ProcessStats{}
+		for i, state := range states {
+			stats = append(stats, cf.InstanceStats{Type: "web", Index: i, State: state})
+		}
+		return stats
+	}
+
+	BeforeEach(func() {
+		cfc = &fakes.FakeContextClient{}
+		scalingEngineDB = &fakes.FakeScalingEngineDB{}
+		start = time.Now()
+		fclock = fakeclock.NewFakeClock(start)
+		readinessCheck = NewReadinessCheck(lagertest.NewTestLogger("readiness-check-test"), fclock, cfc, scalingEngineDB, timeout, pollInterval)
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	JustBeforeEach(func() {
+		done = make(chan bool)
+		go func() {
+			defer GinkgoRecover()
+			readinessCheck.Check(ctx, models.AppScalingHistory{
+				AppId:        "an-app-id",
+				ProcessType:  "web",
+				Timestamp:    start.UnixNano(),
+				OldInstances: 1,
+				NewInstances: 2,
+				Status:       models.ScalingStatusSucceeded,
+				Message:      "limited by max instances 2",
+			}, start.Add(coolDown).UnixNano(), coolDown, models.ScalingDirectionOut)
+			close(done)
+		}()
+	})
+
+	Context("when the instances are running", func() {
+		BeforeEach(func() {
+			cfc.GetAppProcessStatsReturns(statsOf(cf.InstanceStateRunning, cf.InstanceStateRunning, cf.InstanceStateDown), nil)
+		})
+
+		It("starts the cooldown right away", func() {
+			Eventually(done).Should(BeClosed())
+			_, appId, processType := cfc.GetAppProcessStatsArgsForCall(0)
+			Expect(appId).To(Equal(cf.Guid("an-app-id")))
+			Expect(processType).To(Equal("web"))
+			Expect(scalingEngineDB.UpdateScalingHistoryOutcomeCallCount()).To(BeZero())
+
+			Expect(scalingEngineDB.ExtendScalingCooldownExpireTimeCallCount()).To(Equal(1))
+			appId2, processType, direction, expireAt, newExpireAt := scalingEngineDB.ExtendScalingCooldownExpireTimeArgsForCall(0)
+			Expect(appId2).To(Equal("an-app-id"))
+			Expect(processType).To(Equal("web"))
+			Expect(direction).To(Equal(models.ScalingDirectionOut))
+			Expect(expireAt).To(Equal(start.Add(coolDown).UnixNano()))
+			Expect(newExpireAt).To(Equal(start.Add(coolDown).UnixNano()))
+		})
+	})
+
+	Context("when the new instances get ready", func() {
+		BeforeEach(func() {
+			cfc.GetAppProcessStatsReturnsOnCall(0, statsOf(cf.InstanceStateRunning, cf.InstanceStateStarting), nil)
+			cfc.GetAppProcessStatsReturnsOnCall(1, nil, errors.New("an error"))
+			cfc.GetAppProcessStatsReturnsOnCall(2, statsOf(cf.InstanceStateRunning, cf.InstanceStateRunning), nil)
+		})
+
+		It("starts the cooldown once they are running", func() {
+			fclock.WaitForWatcherAndIncrement(pollInterval)
+			fclock.WaitForWatcherAndIncrement(pollInterval)
+			Eventually(done).Should(BeClosed())
+			Expect(cfc.GetAppProcessStatsCallCount()).To(Equal(3))
+			Expect(scalingEngineDB.UpdateScalingHistoryOutcomeCallCount()).To(BeZero())
+
+			_, _, _, expireAt, newExpireAt := scalingEngineDB.ExtendScalingCooldownExpireTimeArgsForCall(0)
+			Expect(expireAt).To(Equal(start.Add(coolDown).UnixNano()))
+			Expect(newExpireAt).To(Equal(start.Add(2 * pollInterval).Add(coolDown).UnixNano()))
+		})
+	})
+
+	Context("when the new instances do not get ready in time", func() {
+		BeforeEach(func() {
+			cfc.GetAppProcessStatsReturns(statsOf(cf.InstanceStateCrashed, cf.InstanceStateStarting), nil)
+		})
+
+		It("records the scaling as failed with the instances which are not running", func() {
+			for i := 0; i < 3; i++ {
+				fclock.WaitForWatcherAndIncrement(pollInterval)
+			}
+			Eventually(done).Should(BeClosed())
+			Expect(cfc.GetAppProcessStatsCallCount()).To(Equal(4))
+
+			Expect(scalingEngineDB.UpdateScalingHistoryOutcomeCallCount()).To(Equal(1))
+			history := scalingEngineDB.UpdateScalingHistoryOutcomeArgsForCall(0)
+			Expect(history.AppId).To(Equal("an-app-id"))
+			Expect(history.Timestamp).To(Equal(start.UnixNano()))
+			Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+			Expect(history.Message).To(Equal("limited by max instances 2"))
+			Expect(history.Error).To(Equal("scaled but 1 instances crashed and 1 instances not running after 30s"))
+
+			_, _, _, _, newExpireAt := scalingEngineDB.ExtendScalingCooldownExpireTimeArgsForCall(0)
+			Expect(newExpireAt).To(Equal(start.Add(timeout).Add(coolDown).UnixNano()))
+		})
+	})
+
+	Context("when the instance states cannot be retrieved", func() {
+		BeforeEach(func() {
+			cfc.GetAppProcessStatsReturns(nil, errors.New("an error"))
+		})
+
+		It("records the failed check in the scaling history", func() {
+			for i := 0; i < 3; i++ {
+				fclock.WaitForWatcherAndIncrement(pollInterval)
+			}
+			Eventually(done).Should(BeClosed())
+
+			history := scalingEngineDB.UpdateScalingHistoryOutcomeArgsForCall(0)
+			Expect(history.Status).To(Equal(models.ScalingStatusSucceeded))
+			Expect(history.Message).To(Equal("limited by max instances 2; scaled but failed to get the instance states: an error"))
+			Expect(history.Error).To(BeEmpty())
+			Expect(scalingEngineDB.ExtendScalingCooldownExpireTimeCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the check is cancelled", func() {
+		BeforeEach(func() {
+			cfc.GetAppProcessStatsReturns(statsOf(cf.InstanceStateRunning, cf.InstanceStateStarting), nil)
+		})
+
+		It("gives up without updating the scaling history or the cooldown", func() {
+			Eventually(cfc.GetAppProcessStatsCallCount).Should(Equal(1))
+			cancel()
+			Eventually(done).Should(BeClosed())
+			Expect(scalingEngineDB.UpdateScalingHistoryOutcomeCallCount()).To(BeZero())
+			Expect(scalingEngineDB.ExtendScalingCooldownExpireTimeCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("Run", func() {
+		var process ifrit.Process
+
+		BeforeEach(func() {
+			cfc.GetAppProcessStatsReturns(statsOf(cf.InstanceStateStarting, cf.InstanceStateStarting), nil)
+			process = ifrit.Invoke(readinessCheck)
+			readinessCheck.Start(models.AppScalingHistory{AppId: "another-app-id", ProcessType: "web", NewInstances: 2}, 0, coolDown, models.ScalingDirectionOut)
+		})
+
+		It("cancels the running checks and waits for them when it is stopped", func() {
+			Eventually(cfc.GetAppProcessStatsCallCount).Should(Equal(2))
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(scalingEngineDB.ExtendScalingCooldownExpireTimeCallCount()).To(BeZero())
+			Expect(scalingEngineDB.UpdateScalingHistoryOutcomeCallCount()).To(BeZero())
+		})
+	})
+})
@@ -2,21 +2,37 @@ package scalingengine
 
 import (
 	"context"
+	"encoding/json"
 
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/applog"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 
 	"fmt"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager/v3"
+	uuid "github.com/nu7hatch/gouuid"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// QuotaStarvedAppsGaugeOpts describes the gauge of the apps whose last scale-out has been limited by the org or space
+// quota.
+var QuotaStarvedAppsGaugeOpts = prometheus.GaugeOpts{
+	Namespace: "autoscaler",
+	Subsystem: "scalingengine",
+	Name:      "quota_starved_apps",
+	Help:      "the number of apps whose last scale-out has been limited by the org or space quota",
+}
+
 type ScalingEngine interface {
 	Scale(appId string, trigger *models.Trigger) (*models.AppScalingResult, error)
+	ScaleManually(appId string, request *models.ManualScalingRequest) (*models.AppScalingResult, error)
+	Recommend(appId string, processType string) error
 	ComputeNewInstances(currentInstances int, adjustment string) (int, error)
 	SetActiveSchedule(appId string, schedule *models.ActiveSchedule) error
 	RemoveActiveSchedule(appId string, scheduleId string) error
@@ -27,9 +43,16 @@ type scalingEngine struct {
 	cfClient            cf.CFClient
 	policyDB            db.PolicyDB
 	scalingEngineDB     db.ScalingEngineDB
+	appLog              applog.Emitter
 	appLock             *StripedLock
+	appLease            *AppLease
+	readinessCheck      *ReadinessCheck
 	clock               clock.Clock
 	defaultCoolDownSecs int
+
+	quotaStarvedApps      prometheus.Gauge
+	quotaStarvedAppIds    map[string]bool
+	quotaStarvedAppIdsMux sync.Mutex
 }
 
 type ActiveScheduleNotFoundError struct {
@@ -41,39 +64,94 @@ func (ase *ActiveScheduleNotFoundError) Error() string {
 	return "active schedule not found"
 }
 
-func NewScalingEngine(logger lager.Logger, cfClient cf.CFClient, policyDB db.PolicyDB, scalingEngineDB db.ScalingEngineDB, clock clock.Clock, defaultCoolDownSecs int, lockSize int) ScalingEngine {
+func NewScalingEngine(logger lager.Logger, cfClient cf.CFClient, policyDB db.PolicyDB, scalingEngineDB db.ScalingEngineDB, appLog applog.Emitter, clock clock.Clock, defaultCoolDownSecs int, lockSize int, quotaStarvedApps prometheus.Gauge, appLease *AppLease, readinessCheck *ReadinessCheck) ScalingEngine {
 	return &scalingEngine{
 		logger:              logger.Session("scalingEngine"),
 		cfClient:            cfClient,
 		policyDB:            policyDB,
 		scalingEngineDB:     scalingEngineDB,
+		appLog:              appLog,
 		appLock:             NewStripedLock(lockSize),
+		appLease:            appLease,
+		readinessCheck:      readinessCheck,
 		clock:               clock,
 		defaultCoolDownSecs: defaultCoolDownSecs,
+		quotaStarvedApps:    quotaStarvedApps,
+		quotaStarvedAppIds:  map[string]bool{},
 	}
 }
 
-func (s *scalingEngine) Scale(appId string, trigger *models.Trigger) (*models.AppScalingResult, error) {
-	logger := s.logger.WithData(lager.Data{"appId": appId})
+// lockApp serialises the scaling operations of an app within the process and, with an app lease, across all scaling
+// engine instances. The lease is taken first, so that waiting for it does not block the other apps of the striped lock.
+// The returned context is cancelled when the lease is lost.
+func (s *scalingEngine) lockApp(appId string) (context.Context, func(), error) {
+	if s.appLease == nil {
+		s.appLock.GetLock(appId).Lock()
+		return context.Background(), s.appLock.GetLock(appId).Unlock, nil
+	}
 
+	ctx, release, err := s.appLease.Acquire(appId)
+	if err != nil {
+		return nil, nil, err
+	}
 	s.appLock.GetLock(appId).Lock()
-	defer s.appLock.GetLock(appId).Unlock()
+	return ctx, func() {
+		s.appLock.GetLock(appId).Unlock()
+		release()
+	}, nil
+}
 
-	now := s.clock.Now()
-	history := &models.AppScalingHistory{
-		AppId:        appId,
-		Timestamp:    now.UnixNano(),
-		ScalingType:  models.ScalingTypeDynamic,
-		OldInstances: -1,
-		NewInstances: -1,
-		Reason:       getDynamicScalingReason(trigger),
+// scaleAppProcess sets the instances of a process of an app within the context of the operation holding the app
+// lock. It fails once the app lease has been lost, as another scaling engine instance may be scaling the app.
+func (s *scalingEngine) scaleAppProcess(ctx context.Context, appId string, processType string, instances int) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return s.cfClient.GetCtxClient().ScaleAppProcess(ctx, cf.Guid(appId), processType, instances)
+}
+
+func (s *scalingEngine) Scale(appId string, trigger *models.Trigger) (*models.AppScalingResult, error) {
+	processType := trigger.GetProcessType()
+	logger := s.logger.WithData(lager.Data{"appId": appId, "processType": processType})
+
+	ctx, unlock, err := s.lockApp(appId)
+	if err != nil {
+		logger.Error("failed-to-lock-app", err)
+		return nil, err
 	}
+	defer unlock()
 
+	now := s.clock.Now()
+	history := &models.AppScalingHistory{
+		AppId:         appId,
+		ProcessType:   processType,
+		Timestamp:     now.UnixNano(),
+		ScalingType:   models.ScalingTypeDynamic,
+		OldInstances:  -1,
+		NewInstances:  -1,
+		Reason:        models.DynamicScalingReason(trigger),
+		Simulated:     trigger.Shadow,
+		Rule:          models.TriggerRule(trigger),
+		CorrelationId: trigger.CorrelationId,
+	}
+	if !trigger.IsCompound() {
+		metricValue := trigger.MetricValue
+		history.MetricValue = &metricValue
+	}
+
+	var checkReadiness func(history models.AppScalingHistory)
+	skipHistory := false
 	defer func() {
-		err := s.scalingEngineDB.SaveScalingHistory(history)
+		if skipHistory {
+			return
+		}
+		err := s.saveScalingHistory(history)
 		if err != nil {
 			s.logger.Error("Scale failed to save history", err)
 		}
+		if checkReadiness != nil {
+			checkReadiness(*history)
+		}
 	}()
 
 	result := &models.AppScalingResult{
@@ -82,7 +160,7 @@ func (s *scalingEngine) Scale(appId string, trigger *models.Trigger) (*models.Ap
 		CooldownExpiredAt: 0,
 	}
 
-	appAndProcesses, err := s.cfClient.GetAppAndProcesses(cf.Guid(appId))
+	appAndProcesses, err := s.cfClient.GetAppAndProcesses(cf.Guid(appId), processType)
 	if err != nil {
 		logger.Error("failed-to-get-app-info", err)
 		history.Status = models.ScalingStatusFailed
@@ -101,30 +179,23 @@ func (s *scalingEngine) Scale(appId string, trigger *models.Trigger) (*models.Ap
 		return result, nil
 	}
 
-	ok, expiredAt, err := s.scalingEngineDB.CanScaleApp(appId)
+	suspended, err := s.checkSuspension(appId, history, logger)
 	if err != nil {
-		logger.Error("failed-to-check-cooldown", err)
-		history.Status = models.ScalingStatusFailed
-		history.Error = "failed to check app cooldown setting"
 		return nil, err
 	}
-	result.CooldownExpiredAt = expiredAt
-	if !ok {
-		logger.Info("scaling ignored: App in cooldown")
-		history.Status = models.ScalingStatusIgnored
-		history.NewInstances = instances
-		history.Message = "app in cooldown period"
+	if suspended {
 		result.Status = history.Status
 		return result, nil
 	}
 
-	newInstances, err := s.ComputeNewInstances(instances, trigger.Adjustment)
+	newInstances, err := models.ComputeInstances(instances, trigger)
 	if err != nil {
 		logger.Error("failed-to-compute-new-instance", err, lager.Data{"instances": instances, "adjustment": trigger.Adjustment})
 		history.Status = models.ScalingStatusFailed
 		history.Error = "failed to compute new app instances"
 		return nil, err
 	}
+	computedInstances := newInstances
 
 	schedule, err := s.scalingEngineDB.GetActiveSchedule(appId)
 	if err != nil {
@@ -136,36 +207,87 @@ func (s *scalingEngine) Scale(appId string, trigger *models.Trigger) (*models.Ap
 
 	var instanceMin, instanceMax int
 
+	policy, err := s.policyDB.GetAppPolicy(context.TODO(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-policy", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get scaling policy"
+		return nil, err
+	}
+
 	if schedule != nil {
 		instanceMin = schedule.InstanceMin
 		instanceMax = schedule.InstanceMax
 	} else {
-		policy, err := s.policyDB.GetAppPolicy(context.TODO(), appId)
-		if err != nil {
-			logger.Error("failed-to-get-app-policy", err)
-			history.Status = models.ScalingStatusFailed
-			history.Error = "failed to get scaling policy"
-			return nil, err
-		}
 		if policy == nil {
 			logger.Info("check-get-app-policy", lager.Data{"message": "ignore scaling since app does not have scaling policy"})
 			history.Status = models.ScalingStatusIgnored
 			history.NewInstances = instances
 			history.Message = "app does not have policy set"
 			result.Status = history.Status
-			result.CooldownExpiredAt = 0
 			return result, nil
 		}
 		instanceMin = policy.InstanceMin
 		instanceMax = policy.InstanceMax
 	}
 
-	if newInstances < instanceMin {
-		newInstances = instanceMin
-		history.Message = fmt.Sprintf("limited by min instances %d", instanceMin)
-	} else if newInstances > instanceMax {
-		newInstances = instanceMax
-		history.Message = fmt.Sprintf("limited by max instances %d", instanceMax)
+	newInstances, history.Message = models.LimitInstances(newInstances, instanceMin, instanceMax)
+	direction := models.ScalingDirection(instances, newInstances)
+	result.Direction = direction
+
+	// the recommendation is recorded before the cooldown is checked, so that every evaluation counts for the window
+	var recommendations []*models.ScalingRecommendation
+	window := policy.ScaleInStabilizationWindow()
+	if window > 0 {
+		recommendations, err = s.scalingEngineDB.RetrieveScalingRecommendations(appId, now.Add(-window).UnixNano())
+		if err != nil {
+			logger.Error("failed-to-retrieve-scaling-recommendations", err)
+			history.Status = models.ScalingStatusFailed
+			history.Error = "failed to retrieve scaling recommendations"
+			return nil, err
+		}
+		if !trigger.Shadow {
+			err = s.scalingEngineDB.SaveScalingRecommendation(&models.ScalingRecommendation{AppId: appId, ProcessType: processType, Timestamp: now.UnixNano(), Instances: newInstances})
+			if err != nil {
+				logger.Error("failed-to-save-scaling-recommendation", err, lager.Data{"newInstances": newInstances})
+			}
+		}
+	}
+
+	ok, expiredAt, err := s.scalingEngineDB.CanScaleApp(appId, processType, direction)
+	if err != nil {
+		logger.Error("failed-to-check-cooldown", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to check app cooldown setting"
+		return nil, err
+	}
+	result.CooldownExpiredAt = expiredAt
+	if !ok {
+		logger.Info("scaling ignored: App in cooldown", lager.Data{"direction": direction})
+		history.Status = models.ScalingStatusIgnored
+		history.NewInstances = instances
+		history.Message = fmt.Sprintf("app in scale-%s cooldown period", direction)
+		result.Status = history.Status
+		return result, nil
+	}
+
+	if window > 0 {
+		if stabilizedInstances, message := models.StabilizeInstances(instances, newInstances, processType, recommendations); message != "" {
+			newInstances, history.Message = stabilizedInstances, message
+		}
+	}
+	if limitedInstances, message := policy.LimitScalingStep(instances, newInstances); message != "" {
+		newInstances, history.Message = limitedInstances, message
+	}
+	if newInstances > instances {
+		if fittingInstances, message := s.fitQuotas(appAndProcesses, instances, newInstances, logger); message != "" {
+			newInstances, history.Message = fittingInstances, message
+			s.setQuotaStarved(appId, !trigger.Shadow)
+		} else {
+			s.setQuotaStarved(appId, false)
+		}
+	} else if newInstances < instances {
+		s.setQuotaStarved(appId, false)
 	}
 	history.NewInstances = newInstances
 
@@ -175,62 +297,266 @@ func (s *scalingEngine) Scale(appId string, trigger *models.Trigger) (*models.Ap
 		result.Status = history.Status
 		result.Adjustment = 0
 		result.CooldownExpiredAt = 0
+		// a target tracking trigger slightly off target asks for the current instances, which is not worth recording
+		skipHistory = trigger.IsTargetTracking() && computedInstances == instances
 		return result, nil
 	}
 
-	err = s.cfClient.ScaleAppWebProcess(cf.Guid(appId), newInstances)
-	if err != nil {
-		logger.Error("failed-to-set-app-instances", err, lager.Data{"newInstances": newInstances})
-		history.Status = models.ScalingStatusFailed
-		history.Error = "failed to set app instances: " + err.Error()
-		return nil, err
+	if limit := policy.ScalingActionsPerHourLimit(); limit > 0 {
+		filter := db.ScalingHistoryFilter{
+			Statuses:     []models.ScalingStatus{models.ScalingStatusSucceeded},
+			ScalingTypes: []models.ScalingType{models.ScalingTypeDynamic},
+			Simulated:    &trigger.Shadow,
+		}
+		actions, err := s.scalingEngineDB.CountScalingHistories(context.TODO(), appId, now.Add(-time.Hour).UnixNano(), now.UnixNano(), filter)
+		if err != nil {
+			logger.Error("failed-to-count-scaling-actions", err)
+			history.Status = models.ScalingStatusFailed
+			history.Error = "failed to count scaling actions"
+			return nil, err
+		}
+		if actions >= limit {
+			logger.Info("scaling ignored: max scaling actions per hour reached", lager.Data{"actions": actions, "limit": limit})
+			history.Status = models.ScalingStatusIgnored
+			history.NewInstances = instances
+			history.Message = fmt.Sprintf("limited by max scaling actions per hour %d", limit)
+			result.Status = history.Status
+			result.Adjustment = 0
+			result.CooldownExpiredAt = 0
+			return result, nil
+		}
+	}
+
+	if trigger.Shadow {
+		logger.Info("shadow-mode", lager.Data{"message": "skip setting app instances since policy is in shadow mode", "newInstances": newInstances})
+	} else {
+		err = s.scaleAppProcess(ctx, appId, processType, newInstances)
+		if err != nil {
+			logger.Error("failed-to-set-app-instances", err, lager.Data{"newInstances": newInstances})
+			history.Status = models.ScalingStatusFailed
+			history.Error = "failed to set app instances: " + err.Error()
+			return nil, err
+		}
 	}
 
 	history.Status = models.ScalingStatusSucceeded
 	result.Status = history.Status
 	result.Adjustment = newInstances - instances
 	result.CooldownExpiredAt = now.Add(trigger.CoolDown(s.defaultCoolDownSecs)).UnixNano()
-	err = s.scalingEngineDB.UpdateScalingCooldownExpireTime(appId, result.CooldownExpiredAt)
+	if trigger.Shadow {
+		// the simulated cooldown is only kept by the eventgenerator, so that activating the policy starts without it
+		return result, nil
+	}
+	err = s.scalingEngineDB.UpdateScalingCooldownExpireTime(appId, processType, direction, result.CooldownExpiredAt)
 	if err != nil {
 		logger.Error("failed-to-update-scaling-cool-down-expire-time", err, lager.Data{"newInstances": newInstances})
 	}
+	if s.readinessCheck != nil {
+		// the cooldown is extended once the instances are ready, later scalings are then answered as in cooldown
+		checkReadiness = func(history models.AppScalingHistory) {
+			s.readinessCheck.Start(history, result.CooldownExpiredAt, trigger.CoolDown(s.defaultCoolDownSecs), direction)
+		}
+	}
 	return result, nil
 }
 
-func (s *scalingEngine) ComputeNewInstances(currentInstances int, adjustment string) (int, error) {
-	var newInstances int
-	if strings.HasSuffix(adjustment, "%") {
-		percentage, err := strconv.ParseFloat(strings.TrimSuffix(adjustment, "%"), 32)
+// ScaleManually scales a process of an app to the requested number of instances. Like dynamic scaling it respects
+// the cooldown of the scaling direction and the instance limits of the active schedule or policy, unless the request
+// overrides the limits. A successful manual scaling starts the cooldown of both directions, so that dynamic scaling
+// does not revert it right away. A suspension of autoscaling does not apply, as it only stops the scalings which
+// are not requested by a user.
+func (s *scalingEngine) ScaleManually(appId string, request *models.ManualScalingRequest) (*models.AppScalingResult, error) {
+	logger := s.logger.WithData(lager.Data{"appId": appId, "request": request})
+
+	ctx, unlock, err := s.lockApp(appId)
+	if err != nil {
+		logger.Error("failed-to-lock-app", err)
+		return nil, err
+	}
+	defer unlock()
+
+	now := s.clock.Now()
+	history := &models.AppScalingHistory{
+		AppId:        appId,
+		ProcessType:  request.ProcessType,
+		Timestamp:    now.UnixNano(),
+		ScalingType:  models.ScalingTypeManual,
+		OldInstances: -1,
+		NewInstances: -1,
+		Reason:       getManualScalingReason(request),
+	}
+	var checkReadiness func(history models.AppScalingHistory)
+	defer func() {
+		err := s.saveScalingHistory(history)
 		if err != nil {
-			s.logger.Error("failed-to-parse-percentage", err, lager.Data{"adjustment": adjustment})
-			return -1, err
+			s.logger.Error("ScaleManually failed to save history", err)
 		}
-		newInstances = int(float64(currentInstances)*(1+percentage/100) + 0.5)
+		if checkReadiness != nil {
+			checkReadiness(*history)
+		}
+	}()
 
-		if newInstances == currentInstances {
-			if percentage > 0 {
-				newInstances = currentInstances + 1
-			} else if percentage < 0 {
-				newInstances = currentInstances - 1
-			}
+	result := &models.AppScalingResult{
+		AppId:             appId,
+		Adjustment:        0,
+		CooldownExpiredAt: 0,
+	}
+
+	policy, err := s.policyDB.GetAppPolicy(context.TODO(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-policy", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get scaling policy"
+		return nil, err
+	}
+	processType := policy.GetProcessType(request.ProcessType)
+	history.ProcessType = processType
+
+	appAndProcesses, err := s.cfClient.GetAppAndProcesses(cf.Guid(appId), processType)
+	if err != nil {
+		logger.Error("failed-to-get-app-info", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get app info: " + err.Error()
+		return nil, err
+	}
+	instances := appAndProcesses.Processes.GetInstances()
+	history.OldInstances = instances
+	history.NewInstances = instances
+
+	if strings.ToUpper(appAndProcesses.App.State) != models.AppStatusStarted {
+		logger.Info("check-app-state", lager.Data{"message": "ignore scaling since app is not started"})
+		history.Status = models.ScalingStatusIgnored
+		history.Message = "app is not started"
+		result.Status = history.Status
+		return result, nil
+	}
+
+	newInstances := request.Instances
+	direction := models.ScalingDirection(instances, newInstances)
+	result.Direction = direction
+
+	ok, expiredAt, err := s.scalingEngineDB.CanScaleApp(appId, processType, direction)
+	if err != nil {
+		logger.Error("failed-to-check-cooldown", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to check app cooldown setting"
+		return nil, err
+	}
+	if !ok {
+		logger.Info("scaling ignored: App in cooldown", lager.Data{"direction": direction})
+		history.Status = models.ScalingStatusIgnored
+		history.Message = fmt.Sprintf("app in scale-%s cooldown period", direction)
+		result.Status = history.Status
+		result.CooldownExpiredAt = expiredAt
+		return result, nil
+	}
+
+	if !request.OverrideLimits {
+		schedule, err := s.scalingEngineDB.GetActiveSchedule(appId)
+		if err != nil {
+			logger.Error("failed-to-get-active-schedule", err)
+			history.Status = models.ScalingStatusFailed
+			history.Error = "failed to get active schedule"
+			return nil, err
 		}
-	} else {
-		step, err := strconv.ParseInt(adjustment, 10, 32)
+		if schedule != nil {
+			newInstances, history.Message = models.LimitInstances(newInstances, schedule.InstanceMin, schedule.InstanceMax)
+		} else if policy != nil {
+			newInstances, history.Message = models.LimitInstances(newInstances, policy.InstanceMin, policy.InstanceMax)
+		}
+	}
+	history.NewInstances = newInstances
+
+	if newInstances == instances {
+		logger.Info("scaling ignored: correct amount of instances")
+		history.Status = models.ScalingStatusIgnored
+		result.Status = history.Status
+		return result, nil
+	}
+
+	err = s.scaleAppProcess(ctx, appId, processType, newInstances)
+	if err != nil {
+		logger.Error("failed-to-set-app-instances", err, lager.Data{"newInstances": newInstances})
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to set app instances: " + err.Error()
+		return nil, err
+	}
+
+	history.Status = models.ScalingStatusSucceeded
+	result.Status = history.Status
+	result.Adjustment = newInstances - instances
+	result.CooldownExpiredAt = now.Add(time.Duration(s.defaultCoolDownSecs) * time.Second).UnixNano()
+	for _, cooldownDirection := range []string{models.ScalingDirectionOut, models.ScalingDirectionIn} {
+		err = s.scalingEngineDB.UpdateScalingCooldownExpireTime(appId, processType, cooldownDirection, result.CooldownExpiredAt)
 		if err != nil {
-			s.logger.Error("failed-to-parse-step-adjustment", err, lager.Data{"adjustment": adjustment})
-			return -1, err
+			// the app has been scaled already, so the scaling succeeded even though dynamic scaling may revert it
+			logger.Error("failed-to-update-scaling-cool-down-expire-time", err, lager.Data{"newInstances": newInstances, "direction": cooldownDirection})
+			history.Message = "failed to start the scaling cooldown"
+			break
+		}
+	}
+	if s.readinessCheck != nil {
+		checkReadiness = func(history models.AppScalingHistory) {
+			s.readinessCheck.Start(history, result.CooldownExpiredAt, time.Duration(s.defaultCoolDownSecs)*time.Second, models.ScalingDirectionOut, models.ScalingDirectionIn)
 		}
-		newInstances = int(step) + currentInstances
+	}
+	return result, nil
+}
+
+// Recommend records the current number of instances of a process as scale-in stabilization recommendation. The
+// eventgenerator calls it for the evaluations which do not send a breached trigger, so that a scale-in considers every
+// evaluation within the window and not only the ones which led to a scaling.
+func (s *scalingEngine) Recommend(appId string, processType string) error {
+	logger := s.logger.WithData(lager.Data{"appId": appId, "processType": processType})
+
+	_, unlock, err := s.lockApp(appId)
+	if err != nil {
+		logger.Error("failed-to-lock-app", err)
+		return err
+	}
+	defer unlock()
+
+	policy, err := s.policyDB.GetAppPolicy(context.TODO(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-policy", err)
+		return err
+	}
+	if policy.IsShadow() || policy.ScaleInStabilizationWindow() == 0 {
+		return nil
+	}
+
+	processes, err := s.cfClient.GetAppProcesses(cf.Guid(appId), processType)
+	if err != nil {
+		logger.Error("failed-to-get-app-info", err)
+		return err
 	}
 
+	err = s.scalingEngineDB.SaveScalingRecommendation(&models.ScalingRecommendation{AppId: appId, ProcessType: processType, Timestamp: s.clock.Now().UnixNano(), Instances: processes.GetInstances()})
+	if err != nil {
+		logger.Error("failed-to-save-scaling-recommendation", err)
+		return err
+	}
+	return nil
+}
+
+func (s *scalingEngine) ComputeNewInstances(currentInstances int, adjustment string) (int, error) {
+	newInstances, err := models.ComputeAdjustedInstances(currentInstances, adjustment)
+	if err != nil {
+		s.logger.Error("failed-to-parse-adjustment", err, lager.Data{"adjustment": adjustment})
+		return -1, err
+	}
 	return newInstances, nil
 }
 
 func (s *scalingEngine) SetActiveSchedule(appId string, schedule *models.ActiveSchedule) error {
 	logger := s.logger.WithData(lager.Data{"appId": appId, "schedule": schedule})
 
-	s.appLock.GetLock(appId).Lock()
-	defer s.appLock.GetLock(appId).Unlock()
+	ctx, unlock, err := s.lockApp(appId)
+	if err != nil {
+		logger.Error("failed-to-lock-app", err)
+		return err
+	}
+	defer unlock()
 
 	currentSchedule, err := s.scalingEngineDB.GetActiveSchedule(appId)
 	if err != nil {
@@ -262,13 +588,24 @@ func (s *scalingEngine) SetActiveSchedule(appId string, schedule *models.ActiveS
 		Reason:       getScheduledScalingReason(schedule),
 	}
 	defer func() {
-		err := s.scalingEngineDB.SaveScalingHistory(history)
+		err := s.saveScalingHistory(history)
 		if err != nil {
 			s.logger.Error("SetActiveSchedule failed to save history", err)
 		}
 	}()
 
-	processes, err := s.cfClient.GetAppProcesses(cf.Guid(appId), cf.ProcessTypeWeb)
+	policy, err := s.policyDB.GetAppPolicy(context.TODO(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-policy", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get scaling policy"
+		return err
+	}
+	processType := policy.GetProcessType("")
+	history.ProcessType = processType
+	history.Simulated = policy.IsShadow()
+
+	processes, err := s.cfClient.GetAppProcesses(cf.Guid(appId), processType)
 	if err != nil {
 		logger.Error("failed-to-get-app-info", err)
 		history.Status = models.ScalingStatusFailed
@@ -278,20 +615,17 @@ func (s *scalingEngine) SetActiveSchedule(appId string, schedule *models.ActiveS
 	instances := processes.GetInstances()
 	history.OldInstances = instances
 
+	if suspended, err := s.checkSuspension(appId, history, logger); err != nil || suspended {
+		return err
+	}
+
 	instanceMin := schedule.InstanceMinInitial
 	if schedule.InstanceMin > instanceMin {
 		instanceMin = schedule.InstanceMin
 	}
 
-	newInstances := instances
-	if newInstances < instanceMin {
-		newInstances = instanceMin
-		history.Message = fmt.Sprintf("limited by min instances %d", instanceMin)
-	} else if newInstances > schedule.InstanceMax {
-		newInstances = schedule.InstanceMax
-		history.Message = fmt.Sprintf("limited by max instances %d", schedule.InstanceMax)
-	}
-
+	newInstances, message := models.LimitInstances(instances, instanceMin, schedule.InstanceMax)
+	history.Message = message
 	history.NewInstances = newInstances
 
 	if newInstances == instances {
@@ -299,7 +633,13 @@ func (s *scalingEngine) SetActiveSchedule(appId string, schedule *models.ActiveS
 		return nil
 	}
 
-	err = s.cfClient.ScaleAppWebProcess(cf.Guid(appId), newInstances)
+	if history.Simulated {
+		logger.Info("shadow-mode", lager.Data{"message": "skip setting app instances since policy is in shadow mode", "newInstances": newInstances})
+		history.Status = models.ScalingStatusSucceeded
+		return nil
+	}
+
+	err = s.scaleAppProcess(ctx, appId, processType, newInstances)
 	if err != nil {
 		logger.Error("failed-to-set-app-instances", err)
 		history.Status = models.ScalingStatusFailed
@@ -313,8 +653,12 @@ func (s *scalingEngine) SetActiveSchedule(appId string, schedule *models.ActiveS
 func (s *scalingEngine) RemoveActiveSchedule(appId string, scheduleId string) error {
 	logger := s.logger.WithData(lager.Data{"appId": appId, "scheduleId": scheduleId})
 
-	s.appLock.GetLock(appId).Lock()
-	defer s.appLock.GetLock(appId).Unlock()
+	ctx, unlock, err := s.lockApp(appId)
+	if err != nil {
+		logger.Error("failed-to-lock-app", err)
+		return err
+	}
+	defer unlock()
 
 	currentSchedule, err := s.scalingEngineDB.GetActiveSchedule(appId)
 	if err != nil {
@@ -343,13 +687,24 @@ func (s *scalingEngine) RemoveActiveSchedule(appId string, scheduleId string) er
 		Reason:       "schedule ends",
 	}
 	defer func() {
-		err := s.scalingEngineDB.SaveScalingHistory(history)
+		err := s.saveScalingHistory(history)
 		if err != nil {
 			s.logger.Error("RemoveActiveSchedule failed to save history", err)
 		}
 	}()
 
-	processes, err := s.cfClient.GetAppProcesses(cf.Guid(appId), cf.ProcessTypeWeb)
+	policy, err := s.policyDB.GetAppPolicy(context.TODO(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-policy", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get app policy"
+		return err
+	}
+	processType := policy.GetProcessType("")
+	history.ProcessType = processType
+	history.Simulated = policy.IsShadow()
+
+	processes, err := s.cfClient.GetAppProcesses(cf.Guid(appId), processType)
 	if err != nil {
 		if cf.IsNotFound(err) {
 			s.logger.Info(fmt.Sprintf("app(%s) missing ignoring scale request", appId))
@@ -365,11 +720,7 @@ func (s *scalingEngine) RemoveActiveSchedule(appId string, scheduleId string) er
 
 	history.OldInstances = instances
 
-	policy, err := s.policyDB.GetAppPolicy(context.TODO(), appId)
-	if err != nil {
-		logger.Error("failed-to-get-app-policy", err)
-		history.Status = models.ScalingStatusFailed
-		history.Error = "failed to get app policy"
+	if suspended, err := s.checkSuspension(appId, history, logger); err != nil || suspended {
 		return err
 	}
 
@@ -379,15 +730,8 @@ func (s *scalingEngine) RemoveActiveSchedule(appId string, scheduleId string) er
 		return nil
 	}
 
-	newInstances := instances
-	if newInstances < policy.InstanceMin {
-		newInstances = policy.InstanceMin
-		history.Message = fmt.Sprintf("limited by min instances %d", policy.InstanceMin)
-	} else if newInstances > policy.InstanceMax {
-		newInstances = policy.InstanceMax
-		history.Message = fmt.Sprintf("limited by max instances %d", policy.InstanceMax)
-	}
-
+	newInstances, message := models.LimitInstances(instances, policy.InstanceMin, policy.InstanceMax)
+	history.Message = message
 	history.NewInstances = newInstances
 
 	if newInstances == instances {
@@ -396,7 +740,13 @@ func (s *scalingEngine) RemoveActiveSchedule(appId string, scheduleId string) er
 		return nil
 	}
 
-	err = s.cfClient.ScaleAppWebProcess(cf.Guid(appId), newInstances)
+	if history.Simulated {
+		logger.Info("shadow-mode", lager.Data{"message": "skip setting app instances since policy is in shadow mode", "newInstances": newInstances})
+		history.Status = models.ScalingStatusSucceeded
+		return nil
+	}
+
+	err = s.scaleAppProcess(ctx, appId, processType, newInstances)
 	if err != nil {
 		logger.Error("failed-to-set-app-instances", err)
 		history.Status = models.ScalingStatusFailed
@@ -407,17 +757,151 @@ func (s *scalingEngine) RemoveActiveSchedule(appId string, scheduleId string) er
 	return nil
 }
 
-func getDynamicScalingReason(trigger *models.Trigger) string {
-	return fmt.Sprintf("%s instance(s) because %s %s %d%s for %d seconds",
-		trigger.Adjustment,
-		trigger.MetricType,
-		trigger.Operator,
-		trigger.Threshold,
-		trigger.MetricUnit,
-		trigger.BreachDurationSeconds)
+// fitQuotas limits a scale-out to the instances which fit into the org and space quotas of the app, as CF rejects
+// scaling beyond them. The returned message names the quota which has limited the instances, it is empty if they all
+// fit. Failing to retrieve the quotas is only logged, as CF still enforces them.
+func (s *scalingEngine) fitQuotas(appAndProcesses *cf.AppAndProcesses, instances int, newInstances int, logger lager.Logger) (int, string) {
+	space := appAndProcesses.App.Relationships.Space
+	if space == nil {
+		return newInstances, ""
+	}
+	quotaUsage, err := s.cfClient.GetSpaceQuotaUsage(space.Data.Guid)
+	if err != nil {
+		logger.Error("failed-to-get-quota-usage", err, lager.Data{"spaceId": space.Data.Guid})
+		return newInstances, ""
+	}
+	fittingInstances, message := quotaUsage.FitInstances(instances, newInstances, appAndProcesses.Processes.GetMemoryInMb())
+	if message != "" {
+		logger.Info("scale-out limited by quota", lager.Data{"newInstances": newInstances, "fittingInstances": fittingInstances, "message": message})
+	}
+	return fittingInstances, message
+}
+
+// setQuotaStarved records whether the last scale-out of the app has been limited by a quota.
+func (s *scalingEngine) setQuotaStarved(appId string, starved bool) {
+	s.quotaStarvedAppIdsMux.Lock()
+	defer s.quotaStarvedAppIdsMux.Unlock()
+	if starved {
+		s.quotaStarvedAppIds[appId] = true
+	} else {
+		delete(s.quotaStarvedAppIds, appId)
+	}
+	s.quotaStarvedApps.Set(float64(len(s.quotaStarvedAppIds)))
+}
+
+// checkSuspension reports whether the autoscaling of the app is suspended and records the action in the history as
+// ignored if so.
+func (s *scalingEngine) checkSuspension(appId string, history *models.AppScalingHistory, logger lager.Logger) (bool, error) {
+	suspension, err := s.policyDB.GetAppSuspension(context.TODO(), appId)
+	if err != nil {
+		logger.Error("failed-to-get-app-suspension", err)
+		history.Status = models.ScalingStatusFailed
+		history.Error = "failed to get app suspension"
+		return false, err
+	}
+	if !suspension.IsActive(s.clock.Now()) {
+		return false, nil
+	}
+	logger.Info("check-app-suspension", lager.Data{"message": "ignore scaling since autoscaling is suspended", "suspension": suspension})
+	history.Status = models.ScalingStatusIgnored
+	history.NewInstances = history.OldInstances
+	history.Message = "autoscaling suspended"
+	return true, nil
+}
+
+// saveScalingHistory writes a scaling history entry into the log stream of the app, saves it and queues it in the outbox
+// of the webhook subscriptions of the app. Failing to queue the deliveries is only logged, as the scaling action has
+// already been taken.
+func (s *scalingEngine) saveScalingHistory(history *models.AppScalingHistory) error {
+	s.emitAppLog(history)
+
+	err := s.scalingEngineDB.SaveScalingHistory(history)
+	if err != nil {
+		return err
+	}
+	s.queueWebhookDeliveries(history)
+	return nil
+}
+
+func (s *scalingEngine) emitAppLog(history *models.AppScalingHistory) {
+	processType := history.ProcessType
+	if processType == "" {
+		processType = models.DefaultProcessType
+	}
+
+	switch {
+	case history.Status == models.ScalingStatusFailed:
+		s.appLog.Error(history.AppId, fmt.Sprintf("Failed to scale process %s: %s (%s)", processType, history.Error, history.Reason))
+	case history.Status == models.ScalingStatusIgnored:
+		s.appLog.Info(history.AppId, fmt.Sprintf("Skipped scaling process %s: %s (%s)", processType, history.Message, history.Reason))
+	case history.Simulated:
+		s.appLog.Info(history.AppId, fmt.Sprintf("Would scale process %s from %d to %d instances in shadow mode: %s",
+			processType, history.OldInstances, history.NewInstances, history.Reason))
+	default:
+		s.appLog.Info(history.AppId, fmt.Sprintf("Scaled process %s from %d to %d instances: %s",
+			processType, history.OldInstances, history.NewInstances, history.Reason))
+	}
+}
+
+func (s *scalingEngine) queueWebhookDeliveries(history *models.AppScalingHistory) {
+	logger := s.logger.Session("queue-webhook-deliveries", lager.Data{"appId": history.AppId})
+	subscriptions, err := s.policyDB.RetrieveWebhookSubscriptions(context.TODO(), history.AppId)
+	if err != nil {
+		logger.Error("failed-to-retrieve-webhook-subscriptions", err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	now := s.clock.Now().UnixNano()
+	deliveries := []*models.WebhookDelivery{}
+	for _, subscription := range subscriptions {
+		deliveryGuid, err := uuid.NewV4()
+		if err != nil {
+			logger.Error("failed-to-generate-delivery-guid", err)
+			return
+		}
+		payload, err := json.Marshal(&models.WebhookEvent{
+			DeliveryGuid:     deliveryGuid.String(),
+			SubscriptionGuid: subscription.Guid,
+			Event:            models.WebhookEventScaling,
+			History:          history,
+		})
+		if err != nil {
+			logger.Error("failed-to-marshal-webhook-event", err)
+			return
+		}
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			Guid:             deliveryGuid.String(),
+			SubscriptionGuid: subscription.Guid,
+			AppId:            history.AppId,
+			Payload:          string(payload),
+			Status:           models.WebhookDeliveryStatusPending,
+			NextAttemptAt:    now,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		})
+	}
+
+	err = s.scalingEngineDB.SaveWebhookDeliveries(deliveries)
+	if err != nil {
+		logger.Error("failed-to-save-webhook-deliveries", err)
+	}
 }
 
 func getScheduledScalingReason(schedule *models.ActiveSchedule) string {
 	return fmt.Sprintf("schedule starts with instance min %d, instance max %d and instance min initial %d",
 		schedule.InstanceMin, schedule.InstanceMax, schedule.InstanceMinInitial)
 }
+
+func getManualScalingReason(request *models.ManualScalingRequest) string {
+	reason := fmt.Sprintf("manual scaling to %d instance(s)", request.Instances)
+	if request.Requester != "" {
+		reason += " by " + request.Requester
+	}
+	if request.OverrideLimits {
+		reason += " overriding the instance limits"
+	}
+	return reason
+}
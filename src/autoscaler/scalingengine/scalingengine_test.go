@@ -6,7 +6,9 @@ import (
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine"
 
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -15,33 +17,43 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 var _ = Describe("ScalingEngine", func() {
 	var (
-		scalingEngine   ScalingEngine
-		activeSchedule  *models.ActiveSchedule
-		cfc             *fakes.FakeCFClient
-		policyDB        *fakes.FakePolicyDB
-		scalingEngineDB *fakes.FakeScalingEngineDB
-		clock           *fakeclock.FakeClock
+		scalingEngine    ScalingEngine
+		activeSchedule   *models.ActiveSchedule
+		cfc              *fakes.FakeCFClient
+		cfCtxClient      *fakes.FakeContextClient
+		policyDB         *fakes.FakePolicyDB
+		scalingEngineDB  *fakes.FakeScalingEngineDB
+		appLog           *fakes.FakeAppLogEmitter
+		clock            *fakeclock.FakeClock
+		quotaStarvedApps prometheus.Gauge
 
 		scalingResult *models.AppScalingResult
 		appState      string
 		trigger       *models.Trigger
 		buffer        *gbytes.Buffer
 		err           error
+		metricValue   = 95.0
 	)
 
 	BeforeEach(func() {
 		cfc = &fakes.FakeCFClient{}
+		cfCtxClient = &fakes.FakeContextClient{}
+		cfc.GetCtxClientReturns(cfCtxClient)
 		policyDB = &fakes.FakePolicyDB{}
 		scalingEngineDB = &fakes.FakeScalingEngineDB{}
+		appLog = &fakes.FakeAppLogEmitter{}
 
 		logger := lagertest.NewTestLogger("schedule-test")
 		buffer = logger.Buffer()
 		clock = fakeclock.NewFakeClock(time.Now())
-		scalingEngine = NewScalingEngine(logger, cfc, policyDB, scalingEngineDB, clock, 300, 32)
+		quotaStarvedApps = prometheus.NewGauge(QuotaStarvedAppsGaugeOpts)
+		scalingEngine = NewScalingEngine(logger, cfc, policyDB, scalingEngineDB, appLog, clock, 300, 32, quotaStarvedApps, nil, nil)
 		appState = models.AppStatusStarted
 		activeSchedule = &models.ActiveSchedule{
 			ScheduleId:         "a-schedule-id",
@@ -65,6 +77,8 @@ var _ = Describe("ScalingEngine", func() {
 				Threshold:             80,
 				Operator:              ">",
 				Adjustment:            "+1",
+				MetricValue:           metricValue,
+				CorrelationId:         "a-correlation-id",
 			}
 		})
 
@@ -82,83 +96,793 @@ var _ = Describe("ScalingEngine", func() {
 
 			It("sets the new app instance number and stores the succeeded scaling history", func() {
 				Expect(err).NotTo(HaveOccurred())
-				guid, num := cfc.ScaleAppWebProcessArgsForCall(0)
+				_, guid, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
 				Expect(guid.String()).To(Equal("an-app-id"))
 				Expect(num).To(Equal(3))
 
-				id, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				_, _, direction := scalingEngineDB.CanScaleAppArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
+
+				id, _, direction, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
 				Expect(id).To(Equal("an-app-id"))
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
 				Expect(expiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusSucceeded,
-					OldInstances: 2,
-					NewInstances: 3,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusSucceeded,
+					OldInstances:  2,
+					NewInstances:  3,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
 				}))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
 				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
 				Expect(scalingResult.Adjustment).To(Equal(1))
+				Expect(scalingResult.Direction).To(Equal(models.ScalingDirectionOut))
 				Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
 			})
-		})
 
-		Context("When app is not started", func() {
-			BeforeEach(func() {
-				setAppAndProcesses(2, "test-state")
+			It("writes the scaling into the log stream of the app", func() {
+				Expect(appLog.InfoCallCount()).To(Equal(1))
+				appId, message := appLog.InfoArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(message).To(Equal("Scaled process web from 2 to 3 instances: +1 instance(s) because test-metric-type > 80test-unit for 100 seconds"))
+			})
+
+			It("does not queue webhook deliveries", func() {
+				Expect(policyDB.RetrieveWebhookSubscriptionsCallCount()).To(Equal(1))
+				Expect(scalingEngineDB.SaveWebhookDeliveriesCallCount()).To(BeZero())
+			})
+
+			Context("and the app has webhook subscriptions", func() {
+				BeforeEach(func() {
+					policyDB.RetrieveWebhookSubscriptionsReturns([]*models.WebhookSubscription{
+						{Guid: "subscription-1", AppId: "an-app-id", Url: "https://example.com/1"},
+						{Guid: "subscription-2", AppId: "an-app-id", Url: "https://example.com/2"},
+					}, nil)
+				})
+
+				It("queues a delivery of the scaling history for every subscription", func() {
+					_, appId := policyDB.RetrieveWebhookSubscriptionsArgsForCall(0)
+					Expect(appId).To(Equal("an-app-id"))
+
+					deliveries := scalingEngineDB.SaveWebhookDeliveriesArgsForCall(0)
+					Expect(deliveries).To(HaveLen(2))
+					for i, delivery := range deliveries {
+						Expect(delivery.SubscriptionGuid).To(Equal(fmt.Sprintf("subscription-%d", i+1)))
+						Expect(delivery.AppId).To(Equal("an-app-id"))
+						Expect(delivery.Status).To(Equal(models.WebhookDeliveryStatusPending))
+						Expect(delivery.NextAttemptAt).To(Equal(clock.Now().UnixNano()))
+
+						event := &models.WebhookEvent{}
+						Expect(json.Unmarshal([]byte(delivery.Payload), event)).To(Succeed())
+						Expect(event.DeliveryGuid).To(Equal(delivery.Guid))
+						Expect(event.SubscriptionGuid).To(Equal(delivery.SubscriptionGuid))
+						Expect(event.Event).To(Equal(models.WebhookEventScaling))
+						Expect(event.History).To(Equal(scalingEngineDB.SaveScalingHistoryArgsForCall(0)))
+					}
+				})
+			})
+
+			Context("and retrieving the webhook subscriptions fails", func() {
+				BeforeEach(func() {
+					policyDB.RetrieveWebhookSubscriptionsReturns(nil, errors.New("test error"))
+				})
+
+				It("still succeeds", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingEngineDB.SaveWebhookDeliveriesCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Context("when the trigger threshold is a decimal", func() {
+			BeforeEach(func() {
+				trigger.Threshold = 0.75
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("stores the decimal threshold in the scaling history reason", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Reason).To(Equal("+1 instance(s) because test-metric-type > 0.75test-unit for 100 seconds"))
+			})
+		})
+
+		Context("when the trigger is a compound trigger", func() {
+			BeforeEach(func() {
+				trigger = &models.Trigger{
+					CoolDownSeconds: 30,
+					Adjustment:      "+1",
+					Combinator:      models.CombinatorAnd,
+					Conditions: []*models.TriggerCondition{
+						{MetricType: "cpu", MetricUnit: "%", BreachDurationSeconds: 120, Threshold: 70, Operator: ">"},
+						{MetricType: "responsetime", MetricUnit: "ms", BreachDurationSeconds: 60, Threshold: 300, Operator: ">"},
+					},
+				}
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("stores every contributing condition in the scaling history reason", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Reason).To(Equal("+1 instance(s) because cpu > 70% for 120 seconds and responsetime > 300ms for 60 seconds"))
+			})
+		})
+
+		Context("when the trigger is a target tracking trigger", func() {
+			BeforeEach(func() {
+				trigger = &models.Trigger{
+					MetricType:            "cpu",
+					MetricUnit:            "%",
+					BreachDurationSeconds: 120,
+					CoolDownSeconds:       30,
+					TargetValue:           60,
+					MetricValue:           90,
+				}
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 10}, nil)
+			})
+
+			Context("when the metric is above the target", func() {
+				BeforeEach(func() {
+					setAppAndProcesses(4, appState)
+				})
+
+				It("scales out proportionally to the utilisation and stores the reason", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(6))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Reason).To(Equal("target tracking cpu at 60% because average was 90% for 120 seconds"))
+					Expect(scalingResult.Adjustment).To(Equal(2))
+				})
+			})
+
+			Context("when the metric is below the target", func() {
+				BeforeEach(func() {
+					trigger.MetricValue = 15
+					setAppAndProcesses(8, appState)
+				})
+
+				It("scales in proportionally to the utilisation", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(2))
+				})
+			})
+
+			Context("when scale-out dampening is set", func() {
+				BeforeEach(func() {
+					trigger.ScaleOutDampening = 0.5
+					trigger.ScaleInDampening = 0.1
+					trigger.MetricValue = 120
+					setAppAndProcesses(4, appState)
+				})
+
+				It("applies only the dampened fraction of the change", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(6))
+				})
+			})
+
+			Context("when scale-in dampening is set", func() {
+				BeforeEach(func() {
+					trigger.ScaleOutDampening = 0.1
+					trigger.ScaleInDampening = 0.5
+					trigger.MetricValue = 15
+					setAppAndProcesses(8, appState)
+				})
+
+				It("applies only the dampened fraction of the change", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(5))
+				})
+			})
+
+			Context("when the computed instances are the current instances", func() {
+				BeforeEach(func() {
+					trigger.MetricValue = 50
+					setAppAndProcesses(4, appState)
+				})
+
+				It("ignores the trigger without recording it", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+					Expect(scalingEngineDB.SaveScalingHistoryCallCount()).To(BeZero())
+					Expect(appLog.InfoCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when the computed instances are limited to the current instances", func() {
+				BeforeEach(func() {
+					setAppAndProcesses(4, appState)
+					policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 4}, nil)
+				})
+
+				It("records the ignored scaling", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(scalingEngineDB.SaveScalingHistoryCallCount()).To(Equal(1))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("limited by max instances 4"))
+				})
+			})
+
+			Context("when the computed instances exceed the max instances in the active schedule", func() {
+				BeforeEach(func() {
+					trigger.MetricValue = 300
+					setAppAndProcesses(4, appState)
+					scalingEngineDB.GetActiveScheduleReturns(&models.ActiveSchedule{ScheduleId: "111111", InstanceMin: 2, InstanceMax: 8}, nil)
+				})
+
+				It("is limited by the active schedule", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(8))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("limited by max instances 8"))
+				})
+			})
+		})
+
+		Context("when the trigger scales a worker process", func() {
+			BeforeEach(func() {
+				trigger.ProcessType = "worker"
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("checks the cooldown of and scales the worker process only", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, processTypes := cfc.GetAppAndProcessesArgsForCall(0)
+				Expect(processTypes).To(Equal([]string{"worker"}))
+
+				_, processType, _ := scalingEngineDB.CanScaleAppArgsForCall(0)
+				Expect(processType).To(Equal("worker"))
+
+				_, _, processType, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(processType).To(Equal("worker"))
+				Expect(num).To(Equal(3))
+
+				_, processType, _, _ = scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				Expect(processType).To(Equal("worker"))
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).ProcessType).To(Equal("worker"))
+			})
+		})
+
+		Context("when the trigger belongs to a policy in shadow mode", func() {
+			BeforeEach(func() {
+				trigger.Shadow = true
+				setAppAndProcesses(5, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 5, Mode: models.PolicyModeShadow}, nil)
+			})
+
+			It("does not scale the app and stores the simulated scaling history", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				Expect(scalingEngineDB.CanScaleAppCallCount()).To(Equal(1))
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusIgnored,
+					OldInstances:  5,
+					NewInstances:  5,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "limited by max instances 5",
+					Simulated:     true,
+				}))
+			})
+
+			Context("when the new instances are within the limits", func() {
+				BeforeEach(func() {
+					setAppAndProcesses(2, appState)
+				})
+
+				It("records the scaling as succeeded and returns the simulated cooldown without scaling the app", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Status).To(Equal(models.ScalingStatusSucceeded))
+					Expect(history.NewInstances).To(Equal(3))
+					Expect(history.Simulated).To(BeTrue())
+
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
+					Expect(scalingResult.Adjustment).To(Equal(1))
+					Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
+				})
+
+				It("does not store the cooldown shared with active policies", func() {
+					Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
+				})
+
+				It("writes the simulated scaling into the log stream of the app", func() {
+					_, message := appLog.InfoArgsForCall(0)
+					Expect(message).To(Equal("Would scale process web from 2 to 3 instances in shadow mode: +1 instance(s) because test-metric-type > 80test-unit for 100 seconds"))
+				})
+			})
+
+			Context("when the policy has a scale-in stabilization window", func() {
+				BeforeEach(func() {
+					policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 5, Mode: models.PolicyModeShadow, ScaleInStabilizationWindowSeconds: 300}, nil)
+				})
+
+				It("does not store the scaling recommendation", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingEngineDB.RetrieveScalingRecommendationsCallCount()).To(Equal(1))
+					Expect(scalingEngineDB.SaveScalingRecommendationCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Context("When app is not started", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, "test-state")
+			})
+			It("ignore the scaling and store the ignored scaling history", func() {
+				Eventually(buffer).Should(gbytes.Say("check-app-state"))
+				Eventually(buffer).Should(gbytes.Say("ignore scaling since app is not started"))
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusIgnored,
+					OldInstances:  2,
+					NewInstances:  2,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "app is not started",
+				}))
+
+				Expect(scalingResult.AppId).To(Equal("an-app-id"))
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+				Expect(scalingResult.Adjustment).To(Equal(0))
+				Expect(scalingResult.CooldownExpiredAt).To(Equal(int64(0)))
+			})
+		})
+
+		Context("when autoscaling of the app is suspended", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				policyDB.GetAppSuspensionReturns(&models.AppSuspension{AppId: "an-app-id", SuspendedAt: clock.Now().Add(-time.Minute).UnixNano()}, nil)
+			})
+
+			It("ignores the scaling and stores the ignored scaling history", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				Expect(scalingEngineDB.CanScaleAppCallCount()).To(BeZero())
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusIgnored,
+					OldInstances:  2,
+					NewInstances:  2,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "autoscaling suspended",
+				}))
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+			})
+
+			Context("when the suspension expired", func() {
+				BeforeEach(func() {
+					scalingEngineDB.CanScaleAppReturns(true, 0, nil)
+					policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+					policyDB.GetAppSuspensionReturns(&models.AppSuspension{AppId: "an-app-id", SuspendedAt: clock.Now().Add(-time.Hour).UnixNano(), ExpireAt: clock.Now().Add(-time.Minute).UnixNano()}, nil)
+				})
+
+				It("scales the app", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(3))
+				})
+			})
+
+			Context("when getting the suspension fails", func() {
+				BeforeEach(func() {
+					policyDB.GetAppSuspensionReturns(nil, errors.New("test error"))
+				})
+
+				It("should error and store the failed scaling history", func() {
+					Expect(err).To(HaveOccurred())
+					Eventually(buffer).Should(gbytes.Say("failed-to-get-app-suspension"))
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+					Expect(history.Error).To(Equal("failed to get app suspension"))
+					Expect(scalingResult).To(BeNil())
+				})
+			})
+		})
+
+		Context("when app is in cooldown period", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(false, clock.Now().Add(30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("ignores the scaling", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusIgnored,
+					OldInstances:  2,
+					NewInstances:  2,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "app in scale-out cooldown period",
+				}))
+
+				Expect(scalingResult.AppId).To(Equal("an-app-id"))
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+				Expect(scalingResult.Adjustment).To(Equal(0))
+				Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
+
+			})
+
+			It("writes the skipped scaling into the log stream of the app", func() {
+				Expect(appLog.InfoCallCount()).To(Equal(1))
+				appId, message := appLog.InfoArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(message).To(Equal("Skipped scaling process web: app in scale-out cooldown period (+1 instance(s) because test-metric-type > 80test-unit for 100 seconds)"))
+			})
+
+			Context("when the policy has a scale-in stabilization window", func() {
+				BeforeEach(func() {
+					policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6, ScaleInStabilizationWindowSeconds: 300}, nil)
+				})
+
+				It("still saves the recommendation", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingEngineDB.SaveScalingRecommendationArgsForCall(0).Instances).To(Equal(3))
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				})
+			})
+		})
+
+		Context("when the instances are above the max instances and the trigger scales out", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(8, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("checks the cooldown of the direction after limiting the instances", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, _, direction := scalingEngineDB.CanScaleAppArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionIn))
+				_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(num).To(Equal(6))
+				Expect(scalingResult.Direction).To(Equal(models.ScalingDirectionIn))
+			})
+		})
+
+		Context("when the trigger scales in", func() {
+			BeforeEach(func() {
+				trigger.Operator = "<"
+				trigger.Threshold = 20
+				trigger.Adjustment = "-1"
+				setAppAndProcesses(3, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+			})
+
+			It("checks and updates the scale-in cooldown", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, _, direction := scalingEngineDB.CanScaleAppArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionIn))
+
+				_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(num).To(Equal(2))
+
+				_, _, direction, _ = scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionIn))
+				Expect(scalingResult.Direction).To(Equal(models.ScalingDirectionIn))
+				Expect(scalingEngineDB.SaveScalingRecommendationCallCount()).To(BeZero())
+			})
+
+			Context("when the policy has a scale-in stabilization window", func() {
+				BeforeEach(func() {
+					policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6, ScaleInStabilizationWindowSeconds: 300}, nil)
+				})
+
+				Context("when a higher number of instances was recommended within the window", func() {
+					BeforeEach(func() {
+						scalingEngineDB.RetrieveScalingRecommendationsReturns([]*models.ScalingRecommendation{
+							{AppId: "an-app-id", ProcessType: "web", Timestamp: clock.Now().Add(-200 * time.Second).UnixNano(), Instances: 3},
+							{AppId: "an-app-id", ProcessType: "worker", Timestamp: clock.Now().Add(-100 * time.Second).UnixNano(), Instances: 5},
+						}, nil)
+					})
+
+					It("does not scale in below the highest recommendation and saves the new recommendation", func() {
+						Expect(err).NotTo(HaveOccurred())
+						appId, since := scalingEngineDB.RetrieveScalingRecommendationsArgsForCall(0)
+						Expect(appId).To(Equal("an-app-id"))
+						Expect(since).To(Equal(clock.Now().Add(-300 * time.Second).UnixNano()))
+
+						Expect(scalingEngineDB.SaveScalingRecommendationArgsForCall(0)).To(Equal(&models.ScalingRecommendation{
+							AppId:       "an-app-id",
+							ProcessType: "web",
+							Timestamp:   clock.Now().UnixNano(),
+							Instances:   2,
+						}))
+
+						Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+						history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+						Expect(history.Status).To(Equal(models.ScalingStatusIgnored))
+						Expect(history.NewInstances).To(Equal(3))
+						Expect(history.Message).To(Equal("limited by scale-in stabilization window to 3"))
+						Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when no higher number of instances was recommended within the window", func() {
+					It("scales in", func() {
+						Expect(err).NotTo(HaveOccurred())
+						_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+						Expect(num).To(Equal(2))
+						Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(BeEmpty())
+					})
+				})
+
+				Context("when retrieving the recommendations fails", func() {
+					BeforeEach(func() {
+						scalingEngineDB.RetrieveScalingRecommendationsReturns(nil, errors.New("test error"))
+					})
+
+					It("should error and store the failed scaling history", func() {
+						Expect(err).To(HaveOccurred())
+						Eventually(buffer).Should(gbytes.Say("failed-to-retrieve-scaling-recommendations"))
+						history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+						Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+						Expect(history.Error).To(Equal("failed to retrieve scaling recommendations"))
+						Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+					})
+				})
+			})
+		})
+
+		Context("when the policy limits the scaling step", func() {
+			BeforeEach(func() {
+				trigger.Adjustment = "+1000%"
+				setAppAndProcesses(5, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 50, MaxScaleOutStep: 3, MaxScaleInStep: 1}, nil)
+			})
+
+			It("scales out by the max scale-out step and notes the limit in the history", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(num).To(Equal(8))
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusSucceeded))
+				Expect(history.NewInstances).To(Equal(8))
+				Expect(history.Message).To(Equal("limited by max scale-out step 3"))
+				Expect(scalingResult.Adjustment).To(Equal(3))
+			})
+
+			Context("when the trigger scales in", func() {
+				BeforeEach(func() {
+					trigger.Operator = "<"
+					trigger.Adjustment = "-50%"
+				})
+
+				It("scales in by the max scale-in step", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(4))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("limited by max scale-in step 1"))
+				})
+			})
+		})
+
+		Context("when the app is in a space with quotas", func() {
+			var quotaUsage *cf.SpaceQuotaUsage
+
+			intPtr := func(i int) *int { return &i }
+
+			BeforeEach(func() {
+				trigger.Adjustment = "+4"
+				cfc.GetAppAndProcessesReturns(&cf.AppAndProcesses{
+					Processes: cf.Processes{{Instances: 2, MemoryInMb: 256}},
+					App:       &cf.App{State: appState, Relationships: cf.Relationships{Space: &cf.Space{Data: cf.SpaceData{Guid: "a-space-id"}}}},
+				}, nil)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 10}, nil)
+				quotaUsage = &cf.SpaceQuotaUsage{
+					Org: &cf.QuotaUsage{
+						Quota: cf.Quota{Apps: cf.QuotaApps{TotalMemoryInMb: intPtr(2048)}},
+						Usage: cf.UsageSummary{StartedInstances: 4, MemoryInMb: 1280},
+					},
+				}
+				cfc.GetSpaceQuotaUsageReturns(quotaUsage, nil)
+			})
+
+			It("limits the scale-out to the instances fitting into the org quota", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfc.GetSpaceQuotaUsageArgsForCall(0)).To(Equal(cf.SpaceId("a-space-id")))
+				_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(num).To(Equal(5))
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusSucceeded))
+				Expect(history.NewInstances).To(Equal(5))
+				Expect(history.Message).To(Equal("limited by org quota"))
+				Expect(testutil.ToFloat64(quotaStarvedApps)).To(Equal(1.0))
+			})
+
+			Context("when the space quota is lower", func() {
+				BeforeEach(func() {
+					quotaUsage.Space = &cf.QuotaUsage{
+						Quota: cf.Quota{Apps: cf.QuotaApps{TotalInstances: intPtr(3)}},
+						Usage: cf.UsageSummary{StartedInstances: 2, MemoryInMb: 512},
+					}
+				})
+
+				It("limits the scale-out to the instances fitting into the space quota", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(3))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("limited by space quota"))
+				})
+			})
+
+			Context("when no instance fits into the quota", func() {
+				BeforeEach(func() {
+					quotaUsage.Org.Usage.MemoryInMb = 2000
+				})
+
+				It("ignores the scaling and notes the quota in the history", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(history.Message).To(Equal("limited by org quota"))
+					Expect(testutil.ToFloat64(quotaStarvedApps)).To(Equal(1.0))
+				})
+			})
+
+			Context("when all new instances fit into the quota", func() {
+				BeforeEach(func() {
+					quotaUsage.Org.Quota.Apps.TotalMemoryInMb = nil
+				})
+
+				It("scales out without limit", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(6))
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(BeEmpty())
+					Expect(testutil.ToFloat64(quotaStarvedApps)).To(BeZero())
+				})
+			})
+
+			Context("when the scaling is shadowed", func() {
+				BeforeEach(func() {
+					trigger.Shadow = true
+				})
+
+				It("notes the quota but does not count the app as quota-starved", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Message).To(Equal("limited by org quota"))
+					Expect(testutil.ToFloat64(quotaStarvedApps)).To(BeZero())
+				})
+			})
+
+			Context("when getting the quotas fails", func() {
+				BeforeEach(func() {
+					cfc.GetSpaceQuotaUsageReturns(nil, errors.New("test error"))
+				})
+
+				It("scales out without limit", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Eventually(buffer).Should(gbytes.Say("failed-to-get-quota-usage"))
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(6))
+				})
 			})
-			It("ignore the scaling and store the ignored scaling history", func() {
-				Eventually(buffer).Should(gbytes.Say("check-app-state"))
-				Eventually(buffer).Should(gbytes.Say("ignore scaling since app is not started"))
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusIgnored,
-					OldInstances: 2,
-					NewInstances: 2,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "app is not started",
-				}))
+			Context("when the trigger scales in", func() {
+				BeforeEach(func() {
+					trigger.Operator = "<"
+					trigger.Adjustment = "-1"
+				})
 
-				Expect(scalingResult.AppId).To(Equal("an-app-id"))
-				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
-				Expect(scalingResult.Adjustment).To(Equal(0))
-				Expect(scalingResult.CooldownExpiredAt).To(Equal(int64(0)))
+				It("does not check the quotas", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfc.GetSpaceQuotaUsageCallCount()).To(BeZero())
+				})
 			})
 		})
 
-		Context("when app is in cooldown period", func() {
+		Context("when the policy limits the scaling actions per hour", func() {
 			BeforeEach(func() {
 				setAppAndProcesses(2, appState)
-				scalingEngineDB.CanScaleAppReturns(false, clock.Now().Add(30*time.Second).UnixNano(), nil)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6, MaxScalingActionsPerHour: 4}, nil)
+				scalingEngineDB.CountScalingHistoriesReturns(3, nil)
 			})
 
-			It("ignores the scaling", func() {
+			It("counts the succeeded dynamic scaling actions within the last hour", func() {
 				Expect(err).NotTo(HaveOccurred())
-				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+				_, appId, start, end, filter := scalingEngineDB.CountScalingHistoriesArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(start).To(Equal(clock.Now().Add(-time.Hour).UnixNano()))
+				Expect(end).To(Equal(clock.Now().UnixNano()))
+				Expect(filter.Statuses).To(Equal([]models.ScalingStatus{models.ScalingStatusSucceeded}))
+				Expect(filter.ScalingTypes).To(Equal([]models.ScalingType{models.ScalingTypeDynamic}))
+				Expect(filter.Simulated).To(HaveValue(BeFalse()))
+			})
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusIgnored,
-					OldInstances: 2,
-					NewInstances: 2,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "app in cooldown period",
-				}))
+			It("scales while the limit is not reached", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(Equal(1))
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
+			})
 
-				Expect(scalingResult.AppId).To(Equal("an-app-id"))
-				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
-				Expect(scalingResult.Adjustment).To(Equal(0))
-				Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
+			Context("when the limit is reached", func() {
+				BeforeEach(func() {
+					scalingEngineDB.CountScalingHistoriesReturns(4, nil)
+				})
+
+				It("ignores the scaling and notes the limit in the history", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(history.NewInstances).To(Equal(2))
+					Expect(history.Message).To(Equal("limited by max scaling actions per hour 4"))
+					Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+					Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when counting the scaling actions fails", func() {
+				BeforeEach(func() {
+					scalingEngineDB.CountScalingHistoriesReturns(0, errors.New("test error"))
+				})
 
+				It("should error and store the failed scaling history", func() {
+					Expect(err).To(HaveOccurred())
+					Eventually(buffer).Should(gbytes.Say("failed-to-count-scaling-actions"))
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+					Expect(history.Error).To(Equal("failed to count scaling actions"))
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				})
 			})
 		})
 
@@ -173,17 +897,21 @@ var _ = Describe("ScalingEngine", func() {
 
 			It("does not update the app and stores the ignored scaling history", func() {
 				Expect(err).NotTo(HaveOccurred())
-				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusIgnored,
-					OldInstances: 6,
-					NewInstances: 6,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "limited by max instances 6",
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusIgnored,
+					OldInstances:  6,
+					NewInstances:  6,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "limited by max instances 6",
 				}))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
@@ -204,23 +932,27 @@ var _ = Describe("ScalingEngine", func() {
 			It("updates the app instance with  max instances and stores the succeeded scaling history", func() {
 				Expect(err).NotTo(HaveOccurred())
 
-				guid, num := cfc.ScaleAppWebProcessArgsForCall(0)
+				_, guid, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
 				Expect(guid.String()).To(Equal("an-app-id"))
 				Expect(num).To(Equal(6))
 
-				id, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				id, _, _, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
 				Expect(id).To(Equal("an-app-id"))
 				Expect(expiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusSucceeded,
-					OldInstances: 5,
-					NewInstances: 6,
-					Reason:       "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "limited by max instances 6",
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusSucceeded,
+					OldInstances:  5,
+					NewInstances:  6,
+					Reason:        "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "limited by max instances 6",
 				}))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
@@ -241,18 +973,22 @@ var _ = Describe("ScalingEngine", func() {
 			It("updates the app instance with  max instances and stores the ignored scaling history", func() {
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
 				Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusIgnored,
-					OldInstances: 6,
-					NewInstances: 6,
-					Reason:       "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "limited by max instances 6",
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusIgnored,
+					OldInstances:  6,
+					NewInstances:  6,
+					Reason:        "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "limited by max instances 6",
 				}))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
@@ -274,19 +1010,23 @@ var _ = Describe("ScalingEngine", func() {
 			It("updates the app instance with  min instances and stores the succeeded scaling history", func() {
 				Expect(err).NotTo(HaveOccurred())
 
-				guid, num := cfc.ScaleAppWebProcessArgsForCall(0)
+				_, guid, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
 				Expect(guid.String()).To(Equal("an-app-id"))
 				Expect(num).To(Equal(2))
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusSucceeded,
-					OldInstances: 3,
-					NewInstances: 2,
-					Reason:       "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "limited by min instances 2",
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusSucceeded,
+					OldInstances:  3,
+					NewInstances:  2,
+					Reason:        "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "limited by min instances 2",
 				}))
 
 				Expect(scalingResult.AppId).To(Equal("an-app-id"))
@@ -314,21 +1054,25 @@ var _ = Describe("ScalingEngine", func() {
 
 				It("updates the app instance with  max instances and stores the succeeded scaling history", func() {
 					Expect(err).NotTo(HaveOccurred())
-					Expect(policyDB.GetAppPolicyCallCount()).To(BeZero())
+					Expect(policyDB.GetAppPolicyCallCount()).To(Equal(1))
 
-					id, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					_, id, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
 					Expect(id.String()).To(Equal("an-app-id"))
 					Expect(num).To(Equal(7))
 
 					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-						AppId:        "an-app-id",
-						Timestamp:    clock.Now().UnixNano(),
-						ScalingType:  models.ScalingTypeDynamic,
-						Status:       models.ScalingStatusSucceeded,
-						OldInstances: 6,
-						NewInstances: 7,
-						Reason:       "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-						Message:      "limited by max instances 7",
+						AppId:         "an-app-id",
+						ProcessType:   "web",
+						Timestamp:     clock.Now().UnixNano(),
+						ScalingType:   models.ScalingTypeDynamic,
+						Status:        models.ScalingStatusSucceeded,
+						OldInstances:  6,
+						NewInstances:  7,
+						Reason:        "+2 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Rule:          "test-metric-type > 80",
+						MetricValue:   &metricValue,
+						CorrelationId: "a-correlation-id",
+						Message:       "limited by max instances 7",
 					}))
 
 					Expect(scalingResult.AppId).To(Equal("an-app-id"))
@@ -347,21 +1091,25 @@ var _ = Describe("ScalingEngine", func() {
 
 				It("updates the app instance with min instances and stores the succeeded scaling history", func() {
 					Expect(err).NotTo(HaveOccurred())
-					Expect(policyDB.GetAppPolicyCallCount()).To(BeZero())
+					Expect(policyDB.GetAppPolicyCallCount()).To(Equal(1))
 
-					id, num := cfc.ScaleAppWebProcessArgsForCall(0)
+					_, id, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
 					Expect(id.String()).To(Equal("an-app-id"))
 					Expect(num).To(Equal(3))
 
 					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-						AppId:        "an-app-id",
-						Timestamp:    clock.Now().UnixNano(),
-						ScalingType:  models.ScalingTypeDynamic,
-						Status:       models.ScalingStatusSucceeded,
-						OldInstances: 5,
-						NewInstances: 3,
-						Reason:       "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
-						Message:      "limited by min instances 3",
+						AppId:         "an-app-id",
+						ProcessType:   "web",
+						Timestamp:     clock.Now().UnixNano(),
+						ScalingType:   models.ScalingTypeDynamic,
+						Status:        models.ScalingStatusSucceeded,
+						OldInstances:  5,
+						NewInstances:  3,
+						Reason:        "-60% instance(s) because test-metric-type > 80test-unit for 100 seconds",
+						Rule:          "test-metric-type > 80",
+						MetricValue:   &metricValue,
+						CorrelationId: "a-correlation-id",
+						Message:       "limited by min instances 3",
 					}))
 
 					Expect(scalingResult.AppId).To(Equal("an-app-id"))
@@ -383,185 +1131,516 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("test error"))
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusFailed,
-					OldInstances: -1,
-					NewInstances: -1,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Error:        "failed to get app info: test error",
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusFailed,
+					OldInstances:  -1,
+					NewInstances:  -1,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Error:         "failed to get app info: test error",
 				}))
 
 				Expect(scalingResult).To(BeNil())
 
 			})
+
+			It("writes the failure into the error log stream of the app", func() {
+				Expect(appLog.InfoCallCount()).To(BeZero())
+				Expect(appLog.ErrorCallCount()).To(Equal(1))
+				appId, message := appLog.ErrorArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(message).To(Equal("Failed to scale process web: failed to get app info: test error (+1 instance(s) because test-metric-type > 80test-unit for 100 seconds)"))
+			})
 		})
 
 		Context("When checking cooldown fails", func() {
 			BeforeEach(func() {
 				setAppAndProcesses(2, appState)
 				scalingEngineDB.CanScaleAppReturns(false, 0, errors.New("test error"))
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
 			})
 			It("should error and store the failed scaling history", func() {
 				Expect(err).To(HaveOccurred())
 				Eventually(buffer).Should(gbytes.Say("failed-to-check-cooldown"))
 				Eventually(buffer).Should(gbytes.Say("test error"))
 
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusFailed,
+					OldInstances:  2,
+					NewInstances:  -1,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Error:         "failed to check app cooldown setting",
+				}))
+
+				Expect(scalingResult).To(BeNil())
+			})
+		})
+
+		Context("when computing new app instances fails", func() {
+			BeforeEach(func() {
+				trigger.Adjustment = "+a"
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+
+			})
+
+			It("should error and store failed scaling history", func() {
+				Expect(err).To(HaveOccurred())
+				Eventually(buffer).Should(gbytes.Say("failed-to-compute-new-instance"))
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusFailed,
+					OldInstances:  2,
+					NewInstances:  -1,
+					Reason:        "+a instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Error:         "failed to compute new app instances",
+				}))
+
+				Expect(scalingResult).To(BeNil())
+
+			})
+		})
+
+		Context("when getting active schedule fails", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				scalingEngineDB.GetActiveScheduleReturns(nil, errors.New("test error"))
+			})
+
+			It("should error and store the failed scaling history", func() {
+				Expect(err).To(HaveOccurred())
+				Eventually(buffer).Should(gbytes.Say("failed-to-get-active-schedule"))
+				Eventually(buffer).Should(gbytes.Say("test error"))
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusFailed,
+					OldInstances:  2,
+					NewInstances:  -1,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Error:         "failed to get active schedule",
+				}))
+
+				Expect(scalingResult).To(BeNil())
+
+			})
+		})
+
+		Context("when getting policy fails", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(nil, errors.New("test error"))
+			})
+
+			It("should error and store the failed scaling history", func() {
+				Expect(err).To(HaveOccurred())
+				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-policy"))
+				Eventually(buffer).Should(gbytes.Say("test error"))
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusFailed,
+					OldInstances:  2,
+					NewInstances:  -1,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Error:         "failed to get scaling policy",
+				}))
+
+				Expect(scalingResult).To(BeNil())
+
+			})
+		})
+
+		Context("when app does not have policy set", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(nil, nil)
+			})
+
+			It("does not update the app and stores the ignored scaling history", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusIgnored,
+					OldInstances:  2,
+					NewInstances:  2,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Message:       "app does not have policy set",
+				}))
+
+				Expect(scalingResult.AppId).To(Equal("an-app-id"))
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+				Expect(scalingResult.Adjustment).To(Equal(0))
+				Expect(scalingResult.CooldownExpiredAt).To(Equal(int64(0)))
+			})
+		})
+
+		Context("when set new instances fails", func() {
+			BeforeEach(func() {
+				setAppAndProcesses(2, appState)
+				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+				cfCtxClient.ScaleAppProcessReturns(errors.New("test error"))
+			})
+
+			It("should error and store failed scaling history", func() {
+				Expect(err).To(HaveOccurred())
+				Eventually(buffer).Should(gbytes.Say("failed-to-set-app-instances"))
+				Eventually(buffer).Should(gbytes.Say("test error"))
+
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:         "an-app-id",
+					ProcessType:   "web",
+					Timestamp:     clock.Now().UnixNano(),
+					ScalingType:   models.ScalingTypeDynamic,
+					Status:        models.ScalingStatusFailed,
+					OldInstances:  2,
+					NewInstances:  3,
+					Reason:        "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
+					Rule:          "test-metric-type > 80",
+					MetricValue:   &metricValue,
+					CorrelationId: "a-correlation-id",
+					Error:         "failed to set app instances: test error",
+				}))
+
+				Expect(scalingResult).To(BeNil())
+
+			})
+		})
+	})
+
+	Describe("ScaleManually", func() {
+		var request *models.ManualScalingRequest
+
+		BeforeEach(func() {
+			request = &models.ManualScalingRequest{Instances: 4}
+			setAppAndProcesses(2, appState)
+			scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+			policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+		})
+
+		JustBeforeEach(func() {
+			scalingResult, err = scalingEngine.ScaleManually("an-app-id", request)
+		})
+
+		Context("when scaling succeeds", func() {
+			It("sets the requested app instance number and starts the cooldown of both directions", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, guid, processType, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(guid.String()).To(Equal("an-app-id"))
+				Expect(processType).To(Equal("web"))
+				Expect(num).To(Equal(4))
+
+				_, _, direction := scalingEngineDB.CanScaleAppArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
+
+				Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(Equal(2))
+				_, _, direction, expiredAt := scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
+				Expect(expiredAt).To(Equal(clock.Now().Add(300 * time.Second).UnixNano()))
+				_, _, direction, _ = scalingEngineDB.UpdateScalingCooldownExpireTimeArgsForCall(1)
+				Expect(direction).To(Equal(models.ScalingDirectionIn))
+
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusFailed,
+					ScalingType:  models.ScalingTypeManual,
+					Status:       models.ScalingStatusSucceeded,
 					OldInstances: 2,
-					NewInstances: -1,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Error:        "failed to check app cooldown setting",
+					NewInstances: 4,
+					Reason:       "manual scaling to 4 instance(s)",
 				}))
 
-				Expect(scalingResult).To(BeNil())
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
+				Expect(scalingResult.Adjustment).To(Equal(2))
+				Expect(scalingResult.Direction).To(Equal(models.ScalingDirectionOut))
+				Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(300 * time.Second).UnixNano()))
+			})
+		})
+
+		Context("when the request has a requester", func() {
+			BeforeEach(func() {
+				request.Requester = "a-user"
+			})
+
+			It("includes the requester in the reason", func() {
+				Expect(err).NotTo(HaveOccurred())
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Reason).To(Equal("manual scaling to 4 instance(s) by a-user"))
+			})
+		})
+
+		Context("when autoscaling of the app is suspended", func() {
+			BeforeEach(func() {
+				policyDB.GetAppSuspensionReturns(&models.AppSuspension{AppId: "an-app-id", SuspendedAt: clock.Now().Add(-time.Minute).UnixNano()}, nil)
+			})
+
+			It("scales the app regardless", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policyDB.GetAppSuspensionCallCount()).To(BeZero())
+				_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(num).To(Equal(4))
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
+			})
+		})
+
+		Context("when the request scales a worker process", func() {
+			BeforeEach(func() {
+				request.ProcessType = "worker"
+			})
+
+			It("scales the requested process type", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, processTypes := cfc.GetAppAndProcessesArgsForCall(0)
+				Expect(processTypes).To(Equal([]string{"worker"}))
+				_, _, processType, _ := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(processType).To(Equal("worker"))
+			})
+		})
+
+		Context("when the requested instances exceed the max instances of the policy", func() {
+			BeforeEach(func() {
+				request.Instances = 8
+			})
+
+			It("limits the instances", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(num).To(Equal(6))
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.NewInstances).To(Equal(6))
+				Expect(history.Message).To(Equal("limited by max instances 6"))
+			})
+
+			Context("when the request overrides the limits", func() {
+				BeforeEach(func() {
+					request.OverrideLimits = true
+				})
+
+				It("sets the requested instances", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(scalingEngineDB.GetActiveScheduleCallCount()).To(BeZero())
+					_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+					Expect(num).To(Equal(8))
+					history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+					Expect(history.Reason).To(Equal("manual scaling to 8 instance(s) overriding the instance limits"))
+					Expect(history.Message).To(BeEmpty())
+				})
 			})
 		})
 
-		Context("when computing new app instances fails", func() {
+		Context("when there is active schedule", func() {
 			BeforeEach(func() {
-				trigger.Adjustment = "+a"
-				setAppAndProcesses(2, appState)
-				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+				request.Instances = 1
+				scalingEngineDB.GetActiveScheduleReturns(&models.ActiveSchedule{ScheduleId: "111111", InstanceMin: 2, InstanceMax: 7}, nil)
+			})
 
+			It("limits the instances by the active schedule", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusIgnored))
+				Expect(history.NewInstances).To(Equal(2))
+				Expect(history.Message).To(Equal("limited by min instances 2"))
 			})
+		})
 
-			It("should error and store failed scaling history", func() {
-				Expect(err).To(HaveOccurred())
-				Eventually(buffer).Should(gbytes.Say("failed-to-compute-new-instance"))
+		Context("when app is in cooldown period", func() {
+			BeforeEach(func() {
+				scalingEngineDB.CanScaleAppReturns(false, clock.Now().Add(30*time.Second).UnixNano(), nil)
+			})
 
+			It("ignores the scaling", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusFailed,
+					ScalingType:  models.ScalingTypeManual,
+					Status:       models.ScalingStatusIgnored,
 					OldInstances: 2,
-					NewInstances: -1,
-					Reason:       "+a instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Error:        "failed to compute new app instances",
+					NewInstances: 2,
+					Reason:       "manual scaling to 4 instance(s)",
+					Message:      "app in scale-out cooldown period",
 				}))
-
-				Expect(scalingResult).To(BeNil())
-
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
+				Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
 			})
 		})
 
-		Context("when getting active schedule fails", func() {
+		Context("when app is not started", func() {
 			BeforeEach(func() {
-				setAppAndProcesses(2, appState)
-				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
-				scalingEngineDB.GetActiveScheduleReturns(nil, errors.New("test error"))
+				setAppAndProcesses(2, models.AppStatusStopped)
 			})
 
-			It("should error and store the failed scaling history", func() {
-				Expect(err).To(HaveOccurred())
-				Eventually(buffer).Should(gbytes.Say("failed-to-get-active-schedule"))
-				Eventually(buffer).Should(gbytes.Say("test error"))
-
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusFailed,
-					OldInstances: 2,
-					NewInstances: -1,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Error:        "failed to get active schedule",
-				}))
+			It("ignores the scaling", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusIgnored))
+				Expect(history.Message).To(Equal("app is not started"))
+			})
+		})
 
-				Expect(scalingResult).To(BeNil())
+		Context("when app does not have policy set", func() {
+			BeforeEach(func() {
+				request.Instances = 8
+				policyDB.GetAppPolicyReturns(nil, nil)
+			})
 
+			It("scales without limits", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, _, _, num := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(num).To(Equal(8))
 			})
 		})
 
 		Context("when getting policy fails", func() {
 			BeforeEach(func() {
-				setAppAndProcesses(2, appState)
-				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
-				policyDB.GetAppPolicyReturns(nil, errors.New("test error"))
+				policyDB.GetAppPolicyReturns(nil, errors.New("an error"))
 			})
 
-			It("should error and store the failed scaling history", func() {
+			It("stores the failed scaling history", func() {
 				Expect(err).To(HaveOccurred())
-				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-policy"))
-				Eventually(buffer).Should(gbytes.Say("test error"))
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+				Expect(history.Error).To(Equal("failed to get scaling policy"))
+				Expect(scalingResult).To(BeNil())
+			})
+		})
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusFailed,
-					OldInstances: 2,
-					NewInstances: -1,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Error:        "failed to get scaling policy",
-				}))
+		Context("when set new instances fails", func() {
+			BeforeEach(func() {
+				cfCtxClient.ScaleAppProcessReturns(errors.New("an error"))
+			})
 
+			It("stores the failed scaling history", func() {
+				Expect(err).To(HaveOccurred())
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+				Expect(history.Error).To(Equal("failed to set app instances: an error"))
+				Expect(scalingEngineDB.UpdateScalingCooldownExpireTimeCallCount()).To(BeZero())
 				Expect(scalingResult).To(BeNil())
-
 			})
 		})
 
-		Context("when app does not have policy set", func() {
+		Context("when starting the cooldown fails", func() {
 			BeforeEach(func() {
-				setAppAndProcesses(2, appState)
-				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
-				policyDB.GetAppPolicyReturns(nil, nil)
+				scalingEngineDB.UpdateScalingCooldownExpireTimeReturns(errors.New("an error"))
 			})
 
-			It("does not update the app and stores the ignored scaling history", func() {
+			It("returns the successful scaling and notes the failure in the scaling history", func() {
 				Expect(err).NotTo(HaveOccurred())
-				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+				Expect(scalingResult.Status).To(Equal(models.ScalingStatusSucceeded))
+				Expect(scalingResult.Adjustment).To(Equal(2))
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(Equal(1))
+				Eventually(buffer).Should(gbytes.Say("failed-to-update-scaling-cool-down-expire-time"))
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusSucceeded))
+				Expect(history.Message).To(Equal("failed to start the scaling cooldown"))
+			})
+		})
+	})
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusIgnored,
-					OldInstances: 2,
-					NewInstances: 2,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Message:      "app does not have policy set",
-				}))
+	Describe("Recommend", func() {
+		JustBeforeEach(func() {
+			err = scalingEngine.Recommend("an-app-id", "worker")
+		})
 
-				Expect(scalingResult.AppId).To(Equal("an-app-id"))
-				Expect(scalingResult.Status).To(Equal(models.ScalingStatusIgnored))
-				Expect(scalingResult.Adjustment).To(Equal(0))
-				Expect(scalingResult.CooldownExpiredAt).To(Equal(int64(0)))
-			})
+		BeforeEach(func() {
+			cfc.GetAppProcessesReturns(cf.Processes{{Instances: 4}}, nil)
+			policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6, ScaleInStabilizationWindowSeconds: 300}, nil)
 		})
 
-		Context("when set new instances fails", func() {
+		It("saves the current instances as recommendation", func() {
+			Expect(err).NotTo(HaveOccurred())
+			appId, processType := cfc.GetAppProcessesArgsForCall(0)
+			Expect(appId).To(Equal(cf.Guid("an-app-id")))
+			Expect(processType).To(Equal([]string{"worker"}))
+			Expect(scalingEngineDB.SaveScalingRecommendationArgsForCall(0)).To(Equal(&models.ScalingRecommendation{
+				AppId:       "an-app-id",
+				ProcessType: "worker",
+				Timestamp:   clock.Now().UnixNano(),
+				Instances:   4,
+			}))
+		})
+
+		Context("when the policy has no scale-in stabilization window", func() {
 			BeforeEach(func() {
-				setAppAndProcesses(2, appState)
-				scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
 				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
-				cfc.ScaleAppWebProcessReturns(errors.New("test error"))
 			})
 
-			It("should error and store failed scaling history", func() {
-				Expect(err).To(HaveOccurred())
-				Eventually(buffer).Should(gbytes.Say("failed-to-set-app-instances"))
-				Eventually(buffer).Should(gbytes.Say("test error"))
+			It("does not save a recommendation", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfc.GetAppProcessesCallCount()).To(BeZero())
+				Expect(scalingEngineDB.SaveScalingRecommendationCallCount()).To(BeZero())
+			})
+		})
 
-				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
-					AppId:        "an-app-id",
-					Timestamp:    clock.Now().UnixNano(),
-					ScalingType:  models.ScalingTypeDynamic,
-					Status:       models.ScalingStatusFailed,
-					OldInstances: 2,
-					NewInstances: 3,
-					Reason:       "+1 instance(s) because test-metric-type > 80test-unit for 100 seconds",
-					Error:        "failed to set app instances: test error",
-				}))
+		Context("when the policy is in shadow mode", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6, ScaleInStabilizationWindowSeconds: 300, Mode: models.PolicyModeShadow}, nil)
+			})
 
-				Expect(scalingResult).To(BeNil())
+			It("does not save a recommendation", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.SaveScalingRecommendationCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when saving the recommendation fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.SaveScalingRecommendationReturns(errors.New("test error"))
+			})
 
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Eventually(buffer).Should(gbytes.Say("failed-to-save-scaling-recommendation"))
 			})
 		})
 	})
@@ -657,11 +1736,12 @@ var _ = Describe("ScalingEngine", func() {
 			It("sets the app instances to be InstanceMax", func() {
 				Expect(err).NotTo(HaveOccurred())
 
-				appid, instances := cfc.ScaleAppWebProcessArgsForCall(0)
+				_, appid, _, instances := cfCtxClient.ScaleAppProcessArgsForCall(0)
 				Expect(appid.String()).To(Equal("an-app-id"))
 				Expect(instances).To(Equal(10))
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusSucceeded,
@@ -674,6 +1754,47 @@ var _ = Describe("ScalingEngine", func() {
 			})
 		})
 
+		Context("when autoscaling of the app is suspended", func() {
+			BeforeEach(func() {
+				cfc.GetAppProcessesReturns(cf.Processes{{Instances: 12}}, nil)
+				policyDB.GetAppSuspensionReturns(&models.AppSuspension{AppId: "an-app-id", SuspendedAt: clock.Now().UnixNano()}, nil)
+			})
+
+			It("saves the active schedule but ignores the scaling", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.SetActiveScheduleCallCount()).To(Equal(1))
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:        "an-app-id",
+					ProcessType:  "web",
+					Timestamp:    clock.Now().UnixNano(),
+					ScalingType:  models.ScalingTypeSchedule,
+					Status:       models.ScalingStatusIgnored,
+					OldInstances: 12,
+					NewInstances: 12,
+					Reason:       "schedule starts with instance min 2, instance max 10 and instance min initial 5",
+					Message:      "autoscaling suspended",
+				}))
+			})
+		})
+
+		Context("when the policy is in shadow mode", func() {
+			BeforeEach(func() {
+				cfc.GetAppProcessesReturns(cf.Processes{{Instances: 12}}, nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6, Mode: models.PolicyModeShadow}, nil)
+			})
+
+			It("does not scale the app and stores the simulated scaling history", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusSucceeded))
+				Expect(history.NewInstances).To(Equal(10))
+				Expect(history.Simulated).To(BeTrue())
+			})
+		})
+
 		Context("when initial min instance is zero (not set)", func() {
 			BeforeEach(func() {
 				activeSchedule.InstanceMinInitial = 0
@@ -687,12 +1808,13 @@ var _ = Describe("ScalingEngine", func() {
 				It("sets the app instances to be InstanceMin", func() {
 					Expect(err).NotTo(HaveOccurred())
 
-					appid, instances := cfc.ScaleAppWebProcessArgsForCall(0)
+					_, appid, _, instances := cfCtxClient.ScaleAppProcessArgsForCall(0)
 					Expect(appid.String()).To(Equal("an-app-id"))
 					Expect(instances).To(Equal(2))
 
 					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 						AppId:        "an-app-id",
+						ProcessType:  "web",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusSucceeded,
@@ -711,10 +1833,11 @@ var _ = Describe("ScalingEngine", func() {
 				})
 				It("does not change the instance number", func() {
 					Expect(err).NotTo(HaveOccurred())
-					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
 
 					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 						AppId:        "an-app-id",
+						ProcessType:  "web",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusIgnored,
@@ -735,12 +1858,13 @@ var _ = Describe("ScalingEngine", func() {
 				It("sets the app instances to be InstanceMinInitial", func() {
 					Expect(err).NotTo(HaveOccurred())
 
-					appid, instances := cfc.ScaleAppWebProcessArgsForCall(0)
+					_, appid, _, instances := cfCtxClient.ScaleAppProcessArgsForCall(0)
 					Expect(appid.String()).To(Equal("an-app-id"))
 					Expect(instances).To(Equal(5))
 
 					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 						AppId:        "an-app-id",
+						ProcessType:  "web",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusSucceeded,
@@ -759,10 +1883,11 @@ var _ = Describe("ScalingEngine", func() {
 				})
 				It("does not change the instance number", func() {
 					Expect(err).NotTo(HaveOccurred())
-					Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+					Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
 
 					Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 						AppId:        "an-app-id",
+						ProcessType:  "web",
 						Timestamp:    clock.Now().UnixNano(),
 						ScalingType:  models.ScalingTypeSchedule,
 						Status:       models.ScalingStatusIgnored,
@@ -806,6 +1931,35 @@ var _ = Describe("ScalingEngine", func() {
 
 		})
 
+		Context("when the policy scales a worker process", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 5, ProcessType: "worker"}, nil)
+				cfc.GetAppProcessesReturns(cf.Processes{{Instances: 12}}, nil)
+			})
+
+			It("scales the worker process", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, processTypes := cfc.GetAppProcessesArgsForCall(0)
+				Expect(processTypes).To(Equal([]string{"worker"}))
+				_, _, processType, instances := cfCtxClient.ScaleAppProcessArgsForCall(0)
+				Expect(processType).To(Equal("worker"))
+				Expect(instances).To(Equal(10))
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).ProcessType).To(Equal("worker"))
+			})
+		})
+
+		Context("when getting app policy fails", func() {
+			BeforeEach(func() {
+				policyDB.GetAppPolicyReturns(nil, errors.New("an error"))
+			})
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-policy"))
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0).Error).To(Equal("failed to get scaling policy"))
+			})
+		})
+
 		Context("when getting current active schedule fails", func() {
 			BeforeEach(func() {
 				scalingEngineDB.GetActiveScheduleReturns(nil, errors.New("an error"))
@@ -840,6 +1994,7 @@ var _ = Describe("ScalingEngine", func() {
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusFailed,
@@ -854,7 +2009,7 @@ var _ = Describe("ScalingEngine", func() {
 
 		Context("when setting app instances fails", func() {
 			BeforeEach(func() {
-				cfc.ScaleAppWebProcessReturns(errors.New("an error"))
+				cfCtxClient.ScaleAppProcessReturns(errors.New("an error"))
 			})
 
 			It("should error", func() {
@@ -864,6 +2019,7 @@ var _ = Describe("ScalingEngine", func() {
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusFailed,
@@ -894,9 +2050,10 @@ var _ = Describe("ScalingEngine", func() {
 			})
 
 			It("does not change the instance number", func() {
-				Expect(cfc.ScaleAppWebProcessCallCount()).To(Equal(0))
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(Equal(0))
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusIgnored,
@@ -907,6 +2064,31 @@ var _ = Describe("ScalingEngine", func() {
 			})
 		})
 
+		Context("when autoscaling of the app is suspended", func() {
+			BeforeEach(func() {
+				scalingEngineDB.GetActiveScheduleReturns(&models.ActiveSchedule{ScheduleId: "a-schedule-id"}, nil)
+				cfc.GetAppProcessesReturns(cf.Processes{{Instances: 1}}, nil)
+				policyDB.GetAppSuspensionReturns(&models.AppSuspension{AppId: "an-app-id", SuspendedAt: clock.Now().UnixNano()}, nil)
+			})
+
+			It("removes the active schedule but ignores the scaling", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.RemoveActiveScheduleCallCount()).To(Equal(1))
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
+					AppId:        "an-app-id",
+					ProcessType:  "web",
+					Timestamp:    clock.Now().UnixNano(),
+					ScalingType:  models.ScalingTypeSchedule,
+					Status:       models.ScalingStatusIgnored,
+					OldInstances: 1,
+					NewInstances: 1,
+					Reason:       "schedule ends",
+					Message:      "autoscaling suspended",
+				}))
+			})
+		})
+
 		Context("when app instance number is below the default InstanceMin in the policy", func() {
 			BeforeEach(func() {
 				scalingEngineDB.GetActiveScheduleReturns(&models.ActiveSchedule{ScheduleId: "a-schedule-id"}, nil)
@@ -914,11 +2096,12 @@ var _ = Describe("ScalingEngine", func() {
 			})
 
 			It("changes the instance number to InstanceMin", func() {
-				appId, instances := cfc.ScaleAppWebProcessArgsForCall(0)
+				_, appId, _, instances := cfCtxClient.ScaleAppProcessArgsForCall(0)
 				Expect(appId.String()).To(Equal("an-app-id"))
 				Expect(instances).To(Equal(3))
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusSucceeded,
@@ -938,12 +2121,13 @@ var _ = Describe("ScalingEngine", func() {
 			})
 
 			It("changes the instance number to instance-max-count", func() {
-				appId, instances := cfc.ScaleAppWebProcessArgsForCall(0)
+				_, appId, _, instances := cfCtxClient.ScaleAppProcessArgsForCall(0)
 				Expect(appId.String()).To(Equal("an-app-id"))
 				Expect(instances).To(Equal(6))
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusSucceeded,
@@ -1011,6 +2195,7 @@ var _ = Describe("ScalingEngine", func() {
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusFailed,
@@ -1035,12 +2220,13 @@ var _ = Describe("ScalingEngine", func() {
 				Eventually(buffer).Should(gbytes.Say("failed-to-get-app-policy"))
 				Eventually(buffer).Should(gbytes.Say("an error"))
 
+				Expect(cfc.GetAppProcessesCallCount()).To(BeZero())
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusFailed,
-					OldInstances: 2,
+					OldInstances: -1,
 					NewInstances: -1,
 					Reason:       "schedule ends",
 					Error:        "failed to get app policy",
@@ -1058,7 +2244,7 @@ var _ = Describe("ScalingEngine", func() {
 
 			It("should not have any error", func() {
 				Expect(err).NotTo(HaveOccurred())
-				Expect(cfc.ScaleAppWebProcessCallCount()).To(BeZero())
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
 				Expect(scalingEngineDB.RemoveActiveScheduleCallCount()).To(Equal(1))
 			})
 		})
@@ -1067,7 +2253,7 @@ var _ = Describe("ScalingEngine", func() {
 			BeforeEach(func() {
 				scalingEngineDB.GetActiveScheduleReturns(&models.ActiveSchedule{ScheduleId: "a-schedule-id"}, nil)
 				cfc.GetAppProcessesReturns(cf.Processes{{Instances: 2}}, nil)
-				cfc.ScaleAppWebProcessReturns(errors.New("an error"))
+				cfCtxClient.ScaleAppProcessReturns(errors.New("an error"))
 			})
 
 			It("should error", func() {
@@ -1077,6 +2263,7 @@ var _ = Describe("ScalingEngine", func() {
 
 				Expect(scalingEngineDB.SaveScalingHistoryArgsForCall(0)).To(Equal(&models.AppScalingHistory{
 					AppId:        "an-app-id",
+					ProcessType:  "web",
 					Timestamp:    clock.Now().UnixNano(),
 					ScalingType:  models.ScalingTypeSchedule,
 					Status:       models.ScalingStatusFailed,
@@ -1091,4 +2278,156 @@ var _ = Describe("ScalingEngine", func() {
 		})
 
 	})
+
+	Describe("with an app lease", func() {
+		var appLeaseMetrics *AppLeaseMetrics
+
+		BeforeEach(func() {
+			appLeaseMetrics = NewAppLeaseMetrics()
+			logger := lagertest.NewTestLogger("schedule-test")
+			buffer = logger.Buffer()
+			appLease := NewAppLease(logger, clock, scalingEngineDB, "an-owner", 30*time.Second, time.Second, 0, appLeaseMetrics)
+			scalingEngine = NewScalingEngine(logger, cfc, policyDB, scalingEngineDB, appLog, clock, 300, 32, quotaStarvedApps, appLease, nil)
+		})
+
+		Context("when the lease is free", func() {
+			BeforeEach(func() {
+				scalingEngineDB.AcquireAppLeaseReturns(true, nil)
+				err = scalingEngine.RemoveActiveSchedule("an-app-id", "a-schedule-id")
+			})
+
+			It("takes the lease around the scaling operation", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingEngineDB.AcquireAppLeaseCallCount()).To(Equal(1))
+				appId, owner, now, expireAt := scalingEngineDB.AcquireAppLeaseArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(owner).To(Equal("an-owner/1"))
+				Expect(now).To(Equal(clock.Now().UnixNano()))
+				Expect(expireAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
+				Expect(scalingEngineDB.GetActiveScheduleCallCount()).To(Equal(1))
+
+				Expect(scalingEngineDB.ReleaseAppLeaseCallCount()).To(Equal(1))
+				appId, owner = scalingEngineDB.ReleaseAppLeaseArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(owner).To(Equal("an-owner/1"))
+			})
+		})
+
+		Context("when the lease is lost before the app is scaled", func() {
+			BeforeEach(func() {
+				scalingEngineDB.AcquireAppLeaseReturnsOnCall(0, true, nil)
+				scalingEngineDB.AcquireAppLeaseReturnsOnCall(1, false, nil)
+				scalingEngineDB.CanScaleAppReturns(true, 0, nil)
+				policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+				cfc.GetAppAndProcessesStub = func(cf.Guid, ...string) (*cf.AppAndProcesses, error) {
+					clock.WaitForWatcherAndIncrement(15 * time.Second)
+					Eventually(buffer).Should(gbytes.Say("failed-to-renew-app-lease"))
+					return &cf.AppAndProcesses{Processes: cf.Processes{{Instances: 2}}, App: &cf.App{State: appState}}, nil
+				}
+				scalingResult, err = scalingEngine.ScaleManually("an-app-id", &models.ManualScalingRequest{Instances: 4})
+			})
+
+			It("fails the scaling without scaling the app", func() {
+				Expect(err).To(MatchError(ErrAppLeaseLost))
+				Expect(cfCtxClient.ScaleAppProcessCallCount()).To(BeZero())
+				history := scalingEngineDB.SaveScalingHistoryArgsForCall(0)
+				Expect(history.Status).To(Equal(models.ScalingStatusFailed))
+				Expect(history.Error).To(Equal("failed to set app instances: " + ErrAppLeaseLost.Error()))
+			})
+		})
+
+		Context("when another scaling engine holds the lease", func() {
+			BeforeEach(func() {
+				scalingEngineDB.AcquireAppLeaseReturns(false, nil)
+				_, err = scalingEngine.Scale("an-app-id", &models.Trigger{MetricType: "test-metric-type", Adjustment: "+1"})
+			})
+
+			It("fails the scaling operation", func() {
+				Expect(err).To(MatchError(ErrAppLeaseTimeout))
+				Expect(cfc.GetAppAndProcessesCallCount()).To(BeZero())
+				Expect(scalingEngineDB.SaveScalingHistoryCallCount()).To(BeZero())
+				Expect(scalingEngineDB.ReleaseAppLeaseCallCount()).To(BeZero())
+				Expect(testutil.ToFloat64(appLeaseMetrics.Timeouts)).To(Equal(1.0))
+				Expect(buffer).To(gbytes.Say("failed-to-lock-app"))
+			})
+		})
+	})
+
+	Describe("with a readiness check", func() {
+		BeforeEach(func() {
+			logger := lagertest.NewTestLogger("schedule-test")
+			readinessCheck := NewReadinessCheck(logger, clock, cfCtxClient, scalingEngineDB, 30*time.Second, 10*time.Second)
+			scalingEngine = NewScalingEngine(logger, cfc, policyDB, scalingEngineDB, appLog, clock, 300, 32, quotaStarvedApps, nil, readinessCheck)
+
+			setAppAndProcesses(2, appState)
+			scalingEngineDB.CanScaleAppReturns(true, clock.Now().Add(0-30*time.Second).UnixNano(), nil)
+			policyDB.GetAppPolicyReturns(&models.ScalingPolicy{InstanceMin: 1, InstanceMax: 6}, nil)
+			cfCtxClient.GetAppProcessStatsReturns(cf.ProcessStats{
+				{Type: "web", Index: 0, State: cf.InstanceStateRunning},
+				{Type: "web", Index: 1, State: cf.InstanceStateRunning},
+				{Type: "web", Index: 2, State: cf.InstanceStateRunning},
+			}, nil)
+		})
+
+		Context("when scaling succeeds", func() {
+			BeforeEach(func() {
+				trigger = &models.Trigger{MetricType: "test-metric-type", CoolDownSeconds: 30, Adjustment: "+1"}
+				scalingResult, err = scalingEngine.Scale("an-app-id", trigger)
+			})
+
+			It("checks the new instances after saving the scaling history", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scalingResult.CooldownExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
+				Expect(scalingEngineDB.SaveScalingHistoryCallCount()).To(Equal(1))
+
+				Eventually(scalingEngineDB.ExtendScalingCooldownExpireTimeCallCount).Should(Equal(1))
+				_, appId, processType := cfCtxClient.GetAppProcessStatsArgsForCall(0)
+				Expect(appId).To(Equal(cf.Guid("an-app-id")))
+				Expect(processType).To(Equal("web"))
+				_, _, direction, expiredAt, newExpiredAt := scalingEngineDB.ExtendScalingCooldownExpireTimeArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
+				Expect(expiredAt).To(Equal(scalingResult.CooldownExpiredAt))
+				Expect(newExpiredAt).To(Equal(clock.Now().Add(30 * time.Second).UnixNano()))
+			})
+		})
+
+		Context("when scaling manually succeeds", func() {
+			BeforeEach(func() {
+				_, err = scalingEngine.ScaleManually("an-app-id", &models.ManualScalingRequest{Instances: 3})
+			})
+
+			It("restarts the cooldown of both directions once the instances are ready", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(scalingEngineDB.ExtendScalingCooldownExpireTimeCallCount).Should(Equal(2))
+				_, _, direction, _, _ := scalingEngineDB.ExtendScalingCooldownExpireTimeArgsForCall(0)
+				Expect(direction).To(Equal(models.ScalingDirectionOut))
+				_, _, direction, _, _ = scalingEngineDB.ExtendScalingCooldownExpireTimeArgsForCall(1)
+				Expect(direction).To(Equal(models.ScalingDirectionIn))
+			})
+		})
+
+		Context("when the policy is in shadow mode", func() {
+			BeforeEach(func() {
+				trigger = &models.Trigger{MetricType: "test-metric-type", Adjustment: "+1", Shadow: true}
+				_, err = scalingEngine.Scale("an-app-id", trigger)
+			})
+
+			It("does not check the instances", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Consistently(cfCtxClient.GetAppProcessStatsCallCount).Should(BeZero())
+			})
+		})
+
+		Context("when scaling fails", func() {
+			BeforeEach(func() {
+				cfCtxClient.ScaleAppProcessReturns(errors.New("an error"))
+				_, err = scalingEngine.Scale("an-app-id", &models.Trigger{MetricType: "test-metric-type", Adjustment: "+1"})
+			})
+
+			It("does not check the instances", func() {
+				Expect(err).To(HaveOccurred())
+				Consistently(cfCtxClient.GetAppProcessStatsCallCount).Should(BeZero())
+			})
+		})
+	})
 })
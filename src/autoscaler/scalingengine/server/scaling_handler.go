@@ -1,6 +1,7 @@
 package server
 
 import (
+	"encoding/csv"
 	"errors"
 	"fmt"
 
@@ -13,8 +14,23 @@ import (
 
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 500
+
+	scalingHistoriesExportPageSize = 500
+	maxScalingHistoryReasonLength  = 255
+)
+
+var scalingHistoriesExportHeader = []string{
+	"app_id", "process_type", "timestamp", "scaling_type", "status", "old_instances", "new_instances",
+	"reason", "message", "error", "simulated", "rule", "metric_value", "correlation_id",
+}
+
 type ScalingHandler struct {
 	logger          lager.Logger
 	scalingEngineDB db.ScalingEngineDB
@@ -42,8 +58,9 @@ func (h *ScalingHandler) Scale(w http.ResponseWriter, r *http.Request, vars map[
 			Message: "Incorrect trigger in request body"})
 		return
 	}
+	trigger.CorrelationId = r.Header.Get(models.CorrelationIdHeader)
 
-	logger.Debug("handling", lager.Data{"trigger": trigger})
+	logger.Debug("handling", lager.Data{"trigger": trigger, "correlationId": trigger.CorrelationId})
 
 	result, err := h.scalingEngine.Scale(appId, trigger)
 
@@ -72,6 +89,60 @@ func (h *ScalingHandler) Scale(w http.ResponseWriter, r *http.Request, vars map[
 	handlers.WriteJSONResponse(w, http.StatusOK, result)
 }
 
+func (h *ScalingHandler) ManualScale(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+	logger := h.logger.Session("manual-scale", lager.Data{"appId": appId})
+
+	request := &models.ManualScalingRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil || request.Instances < 1 || !models.IsValidProcessType(request.ProcessType) {
+		logger.Error("failed-to-decode", err)
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: "Incorrect manual scaling request in request body"})
+		return
+	}
+
+	logger.Info("handling", lager.Data{"request": request})
+
+	result, err := h.scalingEngine.ScaleManually(appId, request)
+	if err != nil {
+		logger.Error("failed-to-scale-manually", err, lager.Data{"request": request})
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-server-error",
+			Message: "Error taking manual scaling action"})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, result)
+}
+
+func (h *ScalingHandler) Recommend(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+	logger := h.logger.Session("recommend", lager.Data{"appId": appId})
+
+	trigger := &models.Trigger{}
+	err := json.NewDecoder(r.Body).Decode(trigger)
+	if err != nil {
+		logger.Error("failed-to-decode", err)
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: "Incorrect trigger in request body"})
+		return
+	}
+
+	err = h.scalingEngine.Recommend(appId, trigger.GetProcessType())
+	if err != nil {
+		logger.Error("failed-to-recommend", err, lager.Data{"trigger": trigger})
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-server-error",
+			Message: "Error recording scaling recommendation"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *ScalingHandler) StartActiveSchedule(w http.ResponseWriter, r *http.Request, vars map[string]string) {
 	appId := vars["appid"]
 	scheduleId := vars["scheduleid"]
@@ -161,3 +232,248 @@ func (h *ScalingHandler) GetActiveSchedule(w http.ResponseWriter, r *http.Reques
 		logger.Error("failed-to-write-body", err)
 	}
 }
+
+// GetScalingState returns the cooldowns of the app and the scaling recommendations within the longest scale-in
+// stabilization window.
+func (h *ScalingHandler) GetScalingState(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("get-scaling-state", lager.Data{"appid": appId})
+	logger.Info("handle-scaling-state-get")
+
+	cooldowns, err := h.scalingEngineDB.RetrieveScalingCooldowns(appId)
+	if err != nil {
+		logger.Error("failed-to-retrieve-scaling-cooldowns", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting scaling cooldowns from database"})
+		return
+	}
+
+	since := time.Now().Add(-models.MaxScaleInStabilizationWindow).UnixNano()
+	recommendations, err := h.scalingEngineDB.RetrieveScalingRecommendations(appId, since)
+	if err != nil {
+		logger.Error("failed-to-retrieve-scaling-recommendations", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting scaling recommendations from database"})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, models.AppScalingState{
+		Cooldowns:       cooldowns,
+		Recommendations: recommendations,
+	})
+}
+
+// GetWebhookDeliveries returns the most recent webhook deliveries of the app, optionally filtered by their status.
+func (h *ScalingHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("get-webhook-deliveries", lager.Data{"appid": appId})
+	logger.Info("handle-webhook-deliveries-get")
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.WebhookDeliveryStatusPending, models.WebhookDeliveryStatusDelivered, models.WebhookDeliveryStatusFailed:
+	default:
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: "status must be one of pending, delivered or failed"})
+		return
+	}
+
+	limit := defaultWebhookDeliveriesLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxWebhookDeliveriesLimit {
+			handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+				Code:    "Bad-Request",
+				Message: fmt.Sprintf("limit must be an integer between 1 and %d", maxWebhookDeliveriesLimit)})
+			return
+		}
+	}
+
+	deliveries, err := h.scalingEngineDB.RetrieveWebhookDeliveries(r.Context(), appId, status, limit)
+	if err != nil {
+		logger.Error("failed-to-retrieve-webhook-deliveries", err)
+		handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "Internal-Server-Error",
+			Message: "Error getting webhook deliveries from database"})
+		return
+	}
+
+	handlers.WriteJSONResponse(w, http.StatusOK, deliveries)
+}
+
+// ExportScalingHistories streams all scaling histories of the app within the requested time range as CSV or as
+// newline delimited JSON. The histories are read page by page, so that long ranges don't have to fit into memory.
+func (h *ScalingHandler) ExportScalingHistories(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+	appId := vars["appid"]
+
+	logger := h.logger.Session("export-scaling-histories", lager.Data{"appid": appId, "query": r.URL.RawQuery})
+	logger.Info("handle-scaling-histories-export")
+
+	query := r.URL.Query()
+	badRequest := func(message string) {
+		handlers.WriteJSONResponse(w, http.StatusBadRequest, models.ErrorResponse{
+			Code:    "Bad-Request",
+			Message: message})
+	}
+
+	format := query.Get("format")
+	switch format {
+	case "":
+		format = "csv"
+	case "csv", "ndjson":
+	default:
+		badRequest("format must be one of csv or ndjson")
+		return
+	}
+
+	start, end := int64(0), int64(-1)
+	var err error
+	if startParam := query.Get("start-time"); startParam != "" {
+		start, err = strconv.ParseInt(startParam, 10, 64)
+		if err != nil {
+			badRequest("start-time must be an integer")
+			return
+		}
+	}
+	if endParam := query.Get("end-time"); endParam != "" {
+		end, err = strconv.ParseInt(endParam, 10, 64)
+		if err != nil {
+			badRequest("end-time must be an integer")
+			return
+		}
+	}
+	// the end is resolved once, so that histories saved during the export don't shift the pages
+	if end < 0 {
+		end = time.Now().UnixNano()
+	}
+
+	order := db.ASC
+	switch query.Get("order-direction") {
+	case "", "asc":
+	case "desc":
+		order = db.DESC
+	default:
+		badRequest("order-direction must be one of asc or desc")
+		return
+	}
+
+	filter := db.ScalingHistoryFilter{Reason: query.Get("reason")}
+	if len(filter.Reason) > maxScalingHistoryReasonLength {
+		badRequest(fmt.Sprintf("reason must not be longer than %d characters", maxScalingHistoryReasonLength))
+		return
+	}
+	for _, status := range query["status"] {
+		value, ok := historyStatuses[status]
+		if !ok {
+			badRequest("status must be one of succeeded, failed or ignored")
+			return
+		}
+		filter.Statuses = append(filter.Statuses, value)
+	}
+	for _, scalingType := range query["scaling-type"] {
+		value, ok := historyScalingTypes[scalingType]
+		if !ok {
+			badRequest("scaling-type must be one of dynamic, scheduled or manual")
+			return
+		}
+		filter.ScalingTypes = append(filter.ScalingTypes, value)
+	}
+
+	var write func(history *models.AppScalingHistory) error
+	var csvWriter *csv.Writer
+	// the response is only started once the first page was read, because afterwards the status code can't be changed
+	// anymore. Later errors are logged and end the export early.
+	begin := func() error {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="scaling-histories-%s.%s"`, appId, format))
+		if format == "ndjson" {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			encoder := json.NewEncoder(w)
+			write = func(history *models.AppScalingHistory) error {
+				return encoder.Encode(history)
+			}
+			return nil
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		csvWriter = csv.NewWriter(w)
+		write = func(history *models.AppScalingHistory) error {
+			return csvWriter.Write(scalingHistoryRecord(history))
+		}
+		return csvWriter.Write(scalingHistoriesExportHeader)
+	}
+	flush := func() error {
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	for page := 1; ; page++ {
+		histories, err := h.scalingEngineDB.RetrieveScalingHistories(r.Context(), appId, start, end, order, filter, page, scalingHistoriesExportPageSize)
+		if err != nil {
+			logger.Error("failed-to-retrieve-scaling-histories", err, lager.Data{"page": page})
+			if page == 1 {
+				handlers.WriteJSONResponse(w, http.StatusInternalServerError, models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting scaling histories from database"})
+			}
+			return
+		}
+
+		if page == 1 {
+			if err := begin(); err != nil {
+				logger.Error("failed-to-write-header", err)
+				return
+			}
+		}
+		for _, history := range histories {
+			if err := write(history); err != nil {
+				logger.Error("failed-to-write-scaling-history", err)
+				return
+			}
+		}
+		if err := flush(); err != nil {
+			logger.Error("failed-to-flush", err)
+			return
+		}
+
+		if len(histories) < scalingHistoriesExportPageSize {
+			return
+		}
+	}
+}
+
+func scalingHistoryRecord(history *models.AppScalingHistory) []string {
+	metricValue := ""
+	if history.MetricValue != nil {
+		metricValue = strconv.FormatFloat(*history.MetricValue, 'f', -1, 64)
+	}
+	return []string{
+		history.AppId,
+		history.ProcessType,
+		strconv.FormatInt(history.Timestamp, 10),
+		strconv.Itoa(int(history.ScalingType)),
+		strconv.Itoa(int(history.Status)),
+		strconv.Itoa(history.OldInstances),
+		strconv.Itoa(history.NewInstances),
+		history.Reason,
+		history.Message,
+		history.Error,
+		strconv.FormatBool(history.Simulated),
+		history.Rule,
+		metricValue,
+		history.CorrelationId,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/cf"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
 	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
 	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/server"
@@ -16,6 +17,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 )
 
 const testUrlActiveSchedules = "http://localhost/v1/apps/an-app-id/active_schedules/a-schedule-id"
@@ -184,6 +186,140 @@ var _ = Describe("ScalingHandler", func() {
 		})
 	})
 
+	Describe("ManualScale", func() {
+		var request *models.ManualScalingRequest
+		JustBeforeEach(func() {
+			handler.ManualScale(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when scaling app succeeds", func() {
+			BeforeEach(func() {
+				scalingEngine.ScaleManuallyReturns(&models.AppScalingResult{
+					AppId:             "an-app-id",
+					Status:            models.ScalingStatusSucceeded,
+					Adjustment:        2,
+					CooldownExpiredAt: 10000,
+				}, nil)
+
+				request = &models.ManualScalingRequest{Instances: 4, OverrideLimits: true}
+				body, err = json.Marshal(request)
+				Expect(err).NotTo(HaveOccurred())
+
+				req, err = http.NewRequest("POST", "", bytes.NewReader(body))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns 200 with the scaling result", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+
+				Expect(scalingEngine.ScaleManuallyCallCount()).To(Equal(1))
+				appId, scaleRequest := scalingEngine.ScaleManuallyArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(scaleRequest).To(Equal(request))
+
+				props := &models.AppScalingResult{}
+				err = json.Unmarshal(resp.Body.Bytes(), props)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(props.Adjustment).To(Equal(2))
+				Expect(props.Status).To(Equal(models.ScalingStatusSucceeded))
+			})
+		})
+
+		Context("when request body is not valid", func() {
+			BeforeEach(func() {
+				req, err = http.NewRequest("POST", "", bytes.NewReader([]byte(`{"instances":0}`)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(scalingEngine.ScaleManuallyCallCount()).To(Equal(0))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Bad-Request",
+					Message: "Incorrect manual scaling request in request body",
+				}))
+			})
+		})
+
+		Context("when the process type is not valid", func() {
+			BeforeEach(func() {
+				req, err = http.NewRequest("POST", "", bytes.NewReader([]byte(`{"instances":2,"process_type":"web/../worker"}`)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(scalingEngine.ScaleManuallyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when scaling app fails", func() {
+			BeforeEach(func() {
+				scalingEngine.ScaleManuallyReturns(nil, errors.New("an error"))
+				req, err = http.NewRequest("POST", "", bytes.NewReader([]byte(`{"instances":2}`)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-server-error",
+					Message: "Error taking manual scaling action",
+				}))
+			})
+		})
+	})
+
+	Describe("Recommend", func() {
+		JustBeforeEach(func() {
+			handler.Recommend(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		BeforeEach(func() {
+			req, err = http.NewRequest("POST", "", bytes.NewReader([]byte(`{"app_id":"an-app-id","process_type":"worker"}`)))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when recording the recommendation succeeds", func() {
+			It("returns 200", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				appId, processType := scalingEngine.RecommendArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(processType).To(Equal("worker"))
+			})
+		})
+
+		Context("when request body is not valid", func() {
+			BeforeEach(func() {
+				req, err = http.NewRequest("POST", "", bytes.NewReader([]byte(`not-json`)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(scalingEngine.RecommendCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when recording the recommendation fails", func() {
+			BeforeEach(func() {
+				scalingEngine.RecommendReturns(errors.New("an error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
 	Describe("StartActiveSchedule", func() {
 		JustBeforeEach(func() {
 			req, err = http.NewRequest(http.MethodPut, testUrlActiveSchedules, bytes.NewReader(body))
@@ -343,4 +479,325 @@ var _ = Describe("ScalingHandler", func() {
 			})
 		})
 	})
+
+	Describe("GetScalingState", func() {
+		JustBeforeEach(func() {
+			handler.GetScalingState(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when query database succeeds", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingCooldownsReturns([]*models.ScalingCooldown{
+					{ProcessType: "web", Direction: models.ScalingDirectionIn, ExpireAt: 222222},
+				}, nil)
+				scalingEngineDB.RetrieveScalingRecommendationsReturns([]*models.ScalingRecommendation{
+					{AppId: "an-app-id", ProcessType: "web", Timestamp: 111111, Instances: 3},
+				}, nil)
+			})
+
+			It("returns 200 with the cooldowns and recommendations in message body", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+
+				Expect(scalingEngineDB.RetrieveScalingCooldownsArgsForCall(0)).To(Equal("an-app-id"))
+
+				state := &models.AppScalingState{}
+				err = json.Unmarshal(resp.Body.Bytes(), state)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(state).To(Equal(&models.AppScalingState{
+					Cooldowns:       []*models.ScalingCooldown{{ProcessType: "web", Direction: models.ScalingDirectionIn, ExpireAt: 222222}},
+					Recommendations: []*models.ScalingRecommendation{{AppId: "an-app-id", ProcessType: "web", Timestamp: 111111, Instances: 3}},
+				}))
+			})
+		})
+
+		Context("when retrieving the cooldowns fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingCooldownsReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting scaling cooldowns from database",
+				}))
+			})
+		})
+
+		Context("when retrieving the recommendations fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingRecommendationsReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting scaling recommendations from database",
+				}))
+			})
+		})
+	})
+
+	Describe("GetWebhookDeliveries", func() {
+		var query string
+
+		BeforeEach(func() {
+			query = ""
+		})
+
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "http://localhost/v1/apps/an-app-id/webhook_deliveries"+query, nil)
+			Expect(err).NotTo(HaveOccurred())
+			handler.GetWebhookDeliveries(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when query database succeeds", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveWebhookDeliveriesReturns([]*models.WebhookDelivery{
+					{Guid: "delivery-guid", SubscriptionGuid: "subscription-guid", AppId: "an-app-id", Status: models.WebhookDeliveryStatusFailed, Attempts: 8, LastError: "webhook responded with status code 500"},
+				}, nil)
+			})
+
+			It("returns 200 with the deliveries in message body", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+
+				_, appId, status, limit := scalingEngineDB.RetrieveWebhookDeliveriesArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(status).To(BeEmpty())
+				Expect(limit).To(Equal(50))
+
+				var deliveries []*models.WebhookDelivery
+				err = json.Unmarshal(resp.Body.Bytes(), &deliveries)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deliveries).To(Equal([]*models.WebhookDelivery{
+					{Guid: "delivery-guid", SubscriptionGuid: "subscription-guid", AppId: "an-app-id", Status: models.WebhookDeliveryStatusFailed, Attempts: 8, LastError: "webhook responded with status code 500"},
+				}))
+			})
+
+			Context("when status and limit are given", func() {
+				BeforeEach(func() {
+					query = "?status=failed&limit=10"
+				})
+
+				It("filters the deliveries", func() {
+					Expect(resp.Code).To(Equal(http.StatusOK))
+
+					_, _, status, limit := scalingEngineDB.RetrieveWebhookDeliveriesArgsForCall(0)
+					Expect(status).To(Equal(models.WebhookDeliveryStatusFailed))
+					Expect(limit).To(Equal(10))
+				})
+			})
+		})
+
+		Context("when status is invalid", func() {
+			BeforeEach(func() {
+				query = "?status=unknown"
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("status must be one of pending, delivered or failed"))
+				Expect(scalingEngineDB.RetrieveWebhookDeliveriesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when limit is out of range", func() {
+			BeforeEach(func() {
+				query = "?limit=501"
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("limit must be an integer between 1 and 500"))
+				Expect(scalingEngineDB.RetrieveWebhookDeliveriesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when retrieving the deliveries fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveWebhookDeliveriesReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting webhook deliveries from database",
+				}))
+			})
+		})
+	})
+
+	Describe("ExportScalingHistories", func() {
+		var (
+			query       string
+			metricValue float64
+			history     *models.AppScalingHistory
+		)
+
+		BeforeEach(func() {
+			query = ""
+			metricValue = 87.5
+			history = &models.AppScalingHistory{
+				AppId:         "an-app-id",
+				ProcessType:   "web",
+				Timestamp:     222,
+				ScalingType:   models.ScalingTypeDynamic,
+				Status:        models.ScalingStatusSucceeded,
+				OldInstances:  2,
+				NewInstances:  3,
+				Reason:        "+1 instance(s) because memoryused >= 80MB for 120 seconds",
+				Rule:          "memoryused >= 80",
+				MetricValue:   &metricValue,
+				CorrelationId: "a-correlation-id",
+			}
+			scalingEngineDB.RetrieveScalingHistoriesReturns([]*models.AppScalingHistory{history}, nil)
+		})
+
+		JustBeforeEach(func() {
+			req, err = http.NewRequest(http.MethodGet, "http://localhost/v1/apps/an-app-id/scaling_histories/export"+query, nil)
+			Expect(err).NotTo(HaveOccurred())
+			handler.ExportScalingHistories(resp, req, map[string]string{"appid": "an-app-id"})
+		})
+
+		Context("when no format is given", func() {
+			It("exports the histories in ascending order as CSV", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get("Content-Type")).To(Equal("text/csv"))
+				Expect(resp.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="scaling-histories-an-app-id.csv"`))
+				Expect(resp.Body.String()).To(Equal(
+					"app_id,process_type,timestamp,scaling_type,status,old_instances,new_instances,reason,message,error,simulated,rule,metric_value,correlation_id\n" +
+						"an-app-id,web,222,0,0,2,3,+1 instance(s) because memoryused >= 80MB for 120 seconds,,,false,memoryused >= 80,87.5,a-correlation-id\n"))
+
+				_, appId, start, end, order, filter, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+				Expect(appId).To(Equal("an-app-id"))
+				Expect(start).To(Equal(int64(0)))
+				Expect(end).To(BeNumerically(">", 0))
+				Expect(order).To(Equal(db.ASC))
+				Expect(filter).To(Equal(db.ScalingHistoryFilter{}))
+				Expect(page).To(Equal(1))
+				Expect(resultsPerPage).To(Equal(500))
+			})
+		})
+
+		Context("when exporting newline delimited JSON with filters", func() {
+			BeforeEach(func() {
+				query = "?format=ndjson&start-time=100&end-time=300&order-direction=desc&status=succeeded&status=failed&scaling-type=dynamic&reason=memoryused"
+			})
+
+			It("exports one JSON object per line", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(resp.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+
+				lines := strings.Split(strings.TrimSuffix(resp.Body.String(), "\n"), "\n")
+				Expect(lines).To(HaveLen(1))
+				exported := &models.AppScalingHistory{}
+				Expect(json.Unmarshal([]byte(lines[0]), exported)).To(Succeed())
+				Expect(exported).To(Equal(history))
+
+				_, _, start, end, order, filter, _, _ := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+				Expect(start).To(Equal(int64(100)))
+				Expect(end).To(Equal(int64(300)))
+				Expect(order).To(Equal(db.DESC))
+				Expect(filter).To(Equal(db.ScalingHistoryFilter{
+					Statuses:     []models.ScalingStatus{models.ScalingStatusSucceeded, models.ScalingStatusFailed},
+					ScalingTypes: []models.ScalingType{models.ScalingTypeDynamic},
+					Reason:       "memoryused",
+				}))
+			})
+		})
+
+		Context("when the histories span several pages", func() {
+			BeforeEach(func() {
+				query = "?format=ndjson"
+				fullPage := make([]*models.AppScalingHistory, 500)
+				for i := range fullPage {
+					fullPage[i] = history
+				}
+				scalingEngineDB.RetrieveScalingHistoriesReturnsOnCall(0, fullPage, nil)
+				scalingEngineDB.RetrieveScalingHistoriesReturnsOnCall(1, []*models.AppScalingHistory{history}, nil)
+			})
+
+			It("reads page by page until the last page", func() {
+				Expect(resp.Code).To(Equal(http.StatusOK))
+				Expect(strings.Count(resp.Body.String(), "\n")).To(Equal(501))
+
+				Expect(scalingEngineDB.RetrieveScalingHistoriesCallCount()).To(Equal(2))
+				_, _, _, firstEnd, _, _, _, _ := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+				_, _, _, secondEnd, _, _, page, _ := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(1)
+				Expect(page).To(Equal(2))
+				Expect(secondEnd).To(Equal(firstEnd))
+			})
+		})
+
+		Context("when the format is invalid", func() {
+			BeforeEach(func() {
+				query = "?format=xml"
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("format must be one of csv or ndjson"))
+				Expect(scalingEngineDB.RetrieveScalingHistoriesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the status is invalid", func() {
+			BeforeEach(func() {
+				query = "?status=unknown"
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("status must be one of succeeded, failed or ignored"))
+				Expect(scalingEngineDB.RetrieveScalingHistoriesCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the start time is invalid", func() {
+			BeforeEach(func() {
+				query = "?start-time=yesterday"
+			})
+
+			It("returns 400", func() {
+				Expect(resp.Code).To(Equal(http.StatusBadRequest))
+				Expect(resp.Body.String()).To(ContainSubstring("start-time must be an integer"))
+			})
+		})
+
+		Context("when retrieving the histories fails", func() {
+			BeforeEach(func() {
+				scalingEngineDB.RetrieveScalingHistoriesReturns(nil, errors.New("database error"))
+			})
+
+			It("returns 500", func() {
+				Expect(resp.Code).To(Equal(http.StatusInternalServerError))
+
+				errJson := &models.ErrorResponse{}
+				err = json.Unmarshal(resp.Body.Bytes(), errJson)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(errJson).To(Equal(&models.ErrorResponse{
+					Code:    "Internal-Server-Error",
+					Message: "Error getting scaling histories from database",
+				}))
+			})
+		})
+	})
 })
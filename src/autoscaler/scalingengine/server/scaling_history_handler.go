@@ -84,7 +84,6 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	if orderDirection == scalinghistory.V1AppsGUIDScalingHistoriesGetOrderDirectionAsc {
 		dbOrder = db.ASC
 	}
-	includeAll := false
 	page := params.Page.Or(1)
 	resultsPerPage := params.ResultsPerPage.Or(50)
 
@@ -94,11 +93,25 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	parameters.Add("order-direction", string(orderDirection))
 	parameters.Add("results-per-page", strconv.Itoa(resultsPerPage))
 
+	filter := db.ScalingHistoryFilter{}
+	for _, status := range params.Status {
+		filter.Statuses = append(filter.Statuses, historyStatuses[string(status)])
+		parameters.Add("status", string(status))
+	}
+	for _, scalingType := range params.ScalingType {
+		filter.ScalingTypes = append(filter.ScalingTypes, historyScalingTypes[string(scalingType)])
+		parameters.Add("scaling-type", string(scalingType))
+	}
+	if reason, ok := params.Reason.Get(); ok {
+		filter.Reason = reason
+		parameters.Add("reason", reason)
+	}
+
 	logger := h.logger.Session("get-scaling-histories", helpers.AddTraceID(ctx, lager.Data{"parameters": parameters, "app-guid": appId}))
 	logger.Info("start")
 	defer logger.Info("end")
 
-	count, err := h.scalingEngineDB.CountScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), includeAll)
+	count, err := h.scalingEngineDB.CountScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), filter)
 	if err != nil {
 		logger.Error("failed-to-count-histories", err)
 		return nil, errors.New("error counting scaling histories in database")
@@ -106,7 +119,7 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	totalPages := int(math.Ceil(float64(count) / float64(resultsPerPage)))
 	logger.Debug("count-results", lager.Data{"count": count, "totalPages": totalPages})
 
-	histories, err := h.scalingEngineDB.RetrieveScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), dbOrder, includeAll, page, resultsPerPage)
+	histories, err := h.scalingEngineDB.RetrieveScalingHistories(ctx, string(appId), int64(startTime), int64(endTime), dbOrder, filter, page, resultsPerPage)
 	if err != nil {
 		logger.Error("failed-to-retrieve-histories", err)
 		return nil, errors.New("error getting scaling histories from database")
@@ -117,6 +130,7 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	for i, item := range histories {
 		entry := scalinghistory.HistoryEntry{
 			AppID:        scalinghistory.NewOptGUID(scalinghistory.GUID(item.AppId)),
+			ProcessType:  scalinghistory.NewOptString(item.ProcessType),
 			Status:       scalinghistory.NewOptHistoryEntryStatus(scalinghistory.HistoryEntryStatus(item.Status)),
 			Timestamp:    scalinghistory.NewOptInt(int(item.Timestamp)),
 			ScalingType:  scalinghistory.NewOptHistoryEntryScalingType(scalinghistory.HistoryEntryScalingType(item.ScalingType)),
@@ -124,6 +138,16 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 			NewInstances: scalinghistory.NewOptInt64(int64(item.NewInstances)),
 			Reason:       scalinghistory.NewOptString(item.Reason),
 			Message:      scalinghistory.NewOptString(item.Message),
+			Simulated:    scalinghistory.NewOptBool(item.Simulated),
+		}
+		if item.Rule != "" {
+			entry.Rule = scalinghistory.NewOptString(item.Rule)
+		}
+		if item.MetricValue != nil {
+			entry.MetricValue = scalinghistory.NewOptFloat64(*item.MetricValue)
+		}
+		if item.CorrelationId != "" {
+			entry.CorrelationID = scalinghistory.NewOptString(item.CorrelationId)
 		}
 
 		switch item.Status {
@@ -166,6 +190,20 @@ func (h *ScalingHistoryHandler) V1AppsGUIDScalingHistoriesGet(ctx context.Contex
 	return result, nil
 }
 
+// historyStatuses and historyScalingTypes map the values of the status and scaling-type query parameters of the
+// scaling history API to the values stored in the database.
+var historyStatuses = map[string]models.ScalingStatus{
+	"succeeded": models.ScalingStatusSucceeded,
+	"failed":    models.ScalingStatusFailed,
+	"ignored":   models.ScalingStatusIgnored,
+}
+
+var historyScalingTypes = map[string]models.ScalingType{
+	"dynamic":   models.ScalingTypeDynamic,
+	"scheduled": models.ScalingTypeSchedule,
+	"manual":    models.ScalingTypeManual,
+}
+
 func getPageURL(appId scalinghistory.GUID, page int, parameters url.Values) (scalinghistory.OptURI, error) {
 	scalingHistoryURL, err := url.Parse(routes.ScalingHistoriesPath)
 	if err != nil {
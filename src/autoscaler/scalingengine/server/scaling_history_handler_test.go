@@ -26,16 +26,19 @@ var _ = Describe("ScalingHistoryHandler", func() {
 
 	history1 = &models.AppScalingHistory{
 		AppId:        "an-app-id",
+		ProcessType:  "web",
 		Timestamp:    222,
 		ScalingType:  models.ScalingTypeDynamic,
 		Status:       models.ScalingStatusSucceeded,
 		OldInstances: 2,
 		NewInstances: 4,
 		Reason:       "a reason",
+		Simulated:    true,
 	}
 
 	history2 = &models.AppScalingHistory{
 		AppId:        "an-app-id",
+		ProcessType:  "web",
 		Timestamp:    333,
 		ScalingType:  models.ScalingTypeSchedule,
 		Status:       models.ScalingStatusFailed,
@@ -48,6 +51,7 @@ var _ = Describe("ScalingHistoryHandler", func() {
 
 	history3 = &models.AppScalingHistory{
 		AppId:        "an-app-id",
+		ProcessType:  "worker",
 		Timestamp:    444,
 		ScalingType:  models.ScalingTypeDynamic,
 		Status:       models.ScalingStatusIgnored,
@@ -61,35 +65,41 @@ var _ = Describe("ScalingHistoryHandler", func() {
 
 		Status:       scalinghistory.OptHistoryEntryStatus{Value: 2, Set: true},
 		AppID:        scalinghistory.OptGUID{Value: "an-app-id", Set: true},
+		ProcessType:  scalinghistory.OptString{Value: "worker", Set: true},
 		Timestamp:    scalinghistory.OptInt{Value: 444, Set: true},
 		ScalingType:  scalinghistory.OptHistoryEntryScalingType{Value: 0, Set: true},
 		OldInstances: scalinghistory.OptInt64{Value: 2, Set: true},
 		NewInstances: scalinghistory.OptInt64{Value: 4, Set: true},
 		Reason:       scalinghistory.OptString{Value: "a reason", Set: true},
 		Message:      scalinghistory.OptString{Value: "a message", Set: true},
+		Simulated:    scalinghistory.OptBool{Value: false, Set: true},
 		OneOf:        scalinghistory.NewHistoryIgnoreEntryHistoryEntrySum(scalinghistory.HistoryIgnoreEntry{IgnoreReason: scalinghistory.NewOptString("a message")}),
 	}
 
 	history2Entry := scalinghistory.HistoryEntry{
 		Status:       scalinghistory.OptHistoryEntryStatus{Value: 1, Set: true},
 		AppID:        scalinghistory.OptGUID{Value: "an-app-id", Set: true},
+		ProcessType:  scalinghistory.OptString{Value: "web", Set: true},
 		Timestamp:    scalinghistory.OptInt{Value: 333, Set: true},
 		ScalingType:  scalinghistory.OptHistoryEntryScalingType{Value: 1, Set: true},
 		OldInstances: scalinghistory.OptInt64{Value: 2, Set: true},
 		NewInstances: scalinghistory.OptInt64{Value: 4, Set: true},
 		Reason:       scalinghistory.OptString{Value: "a reason", Set: true},
 		Message:      scalinghistory.OptString{Value: "a message", Set: true},
+		Simulated:    scalinghistory.OptBool{Value: false, Set: true},
 		OneOf:        scalinghistory.NewHistoryErrorEntryHistoryEntrySum(scalinghistory.HistoryErrorEntry{Error: scalinghistory.NewOptString("an error")}),
 	}
 	history3Entry := scalinghistory.HistoryEntry{
 		Status:       scalinghistory.OptHistoryEntryStatus{Value: 0, Set: true},
 		AppID:        scalinghistory.OptGUID{Value: "an-app-id", Set: true},
+		ProcessType:  scalinghistory.OptString{Value: "web", Set: true},
 		Timestamp:    scalinghistory.OptInt{Value: 222, Set: true},
 		ScalingType:  scalinghistory.OptHistoryEntryScalingType{Value: 0, Set: true},
 		OldInstances: scalinghistory.OptInt64{Value: 2, Set: true},
 		NewInstances: scalinghistory.OptInt64{Value: 4, Set: true},
 		Reason:       scalinghistory.OptString{Value: "a reason", Set: true},
 		Message:      scalinghistory.OptString{Value: "", Set: true},
+		Simulated:    scalinghistory.OptBool{Value: true, Set: true},
 		OneOf:        scalinghistory.NewHistorySuccessEntryHistoryEntrySum(scalinghistory.HistorySuccessEntry{}),
 	}
 	BeforeEach(func() {
@@ -119,13 +129,13 @@ var _ = Describe("ScalingHistoryHandler", func() {
 				})
 
 				It("retrieves scaling histories from database with the given start and end time and order ", func() {
-					ctx, appid, start, end, order, includeAll, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+					ctx, appid, start, end, order, filter, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
 					Expect(ctx).NotTo(BeNil())
 					Expect(appid).To(Equal("an-app-id"))
 					Expect(start).To(Equal(int64(123)))
 					Expect(end).To(Equal(int64(567)))
 					Expect(order).To(Equal(db.DESC))
-					Expect(includeAll).To(BeFalse())
+					Expect(filter).To(Equal(db.ScalingHistoryFilter{}))
 					Expect(page).To(Equal(1))
 					Expect(resultsPerPage).To(Equal(50))
 				})
@@ -150,6 +160,58 @@ var _ = Describe("ScalingHistoryHandler", func() {
 				})
 			})
 
+			Context("when filtering by status, scaling type and reason", func() {
+				BeforeEach(func() {
+					scalingHistoryParams.Status = []scalinghistory.V1AppsGUIDScalingHistoriesGetStatusItem{
+						scalinghistory.V1AppsGUIDScalingHistoriesGetStatusItemFailed,
+						scalinghistory.V1AppsGUIDScalingHistoriesGetStatusItemIgnored,
+					}
+					scalingHistoryParams.ScalingType = []scalinghistory.V1AppsGUIDScalingHistoriesGetScalingTypeItem{
+						scalinghistory.V1AppsGUIDScalingHistoriesGetScalingTypeItemManual,
+					}
+					scalingHistoryParams.Reason = scalinghistory.NewOptString("memoryused")
+					scalingHistoryParams.ResultsPerPage = scalinghistory.NewOptInt(1)
+
+					scalingEngineDB.CountScalingHistoriesReturns(2, nil)
+					scalingEngineDB.RetrieveScalingHistoriesReturns([]*models.AppScalingHistory{history2}, nil)
+				})
+
+				It("passes the filter to the database and keeps it in the page links", func() {
+					expectedFilter := db.ScalingHistoryFilter{
+						Statuses:     []models.ScalingStatus{models.ScalingStatusFailed, models.ScalingStatusIgnored},
+						ScalingTypes: []models.ScalingType{models.ScalingTypeManual},
+						Reason:       "memoryused",
+					}
+					_, _, _, _, countFilter := scalingEngineDB.CountScalingHistoriesArgsForCall(0)
+					Expect(countFilter).To(Equal(expectedFilter))
+					_, _, _, _, _, filter, _, _ := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+					Expect(filter).To(Equal(expectedFilter))
+
+					Expect(history.NextURL.IsSet()).To(BeTrue())
+					Expect(history.NextURL.Value.RawQuery).To(ContainSubstring("status=failed&status=ignored"))
+					Expect(history.NextURL.Value.RawQuery).To(ContainSubstring("scaling-type=manual"))
+					Expect(history.NextURL.Value.RawQuery).To(ContainSubstring("reason=memoryused"))
+				})
+			})
+
+			Context("when the histories were triggered by a rule", func() {
+				BeforeEach(func() {
+					metricValue := 87.5
+					dynamicHistory := *history1
+					dynamicHistory.Rule = "memoryused >= 80"
+					dynamicHistory.MetricValue = &metricValue
+					dynamicHistory.CorrelationId = "a-correlation-id"
+					scalingEngineDB.RetrieveScalingHistoriesReturns([]*models.AppScalingHistory{&dynamicHistory}, nil)
+				})
+
+				It("returns the rule, the metric value and the correlation id", func() {
+					Expect(history.Resources).To(HaveLen(1))
+					Expect(history.Resources[0].Rule).To(Equal(scalinghistory.NewOptString("memoryused >= 80")))
+					Expect(history.Resources[0].MetricValue).To(Equal(scalinghistory.NewOptFloat64(87.5)))
+					Expect(history.Resources[0].CorrelationID).To(Equal(scalinghistory.NewOptString("a-correlation-id")))
+				})
+			})
+
 			Context("when paginating", func() {
 				BeforeEach(func() {
 					scalingHistoryParams.StartTime = scalinghistory.NewOptInt(123)
@@ -199,13 +261,13 @@ var _ = Describe("ScalingHistoryHandler", func() {
 						Expect(history.TotalPages.Value).To(Equal(int64(3)))
 					})
 					By("forwarding the direction parameter to the DB", func() {
-						ctx, appid, start, end, order, includeAll, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
+						ctx, appid, start, end, order, filter, page, resultsPerPage := scalingEngineDB.RetrieveScalingHistoriesArgsForCall(0)
 						Expect(ctx).NotTo(BeNil())
 						Expect(appid).To(Equal("an-app-id"))
 						Expect(start).To(Equal(int64(123)))
 						Expect(end).To(Equal(int64(567)))
 						Expect(order).To(Equal(db.ASC))
-						Expect(includeAll).To(BeFalse())
+						Expect(filter).To(Equal(db.ScalingHistoryFilter{}))
 						Expect(page).To(Equal(2))
 						Expect(resultsPerPage).To(Equal(1))
 					})
@@ -35,16 +35,21 @@ func NewServer(logger lager.Logger, conf *config.Config, scalingEngineDB db.Scal
 
 	r.Use(httpStatusCollectMiddleware.Collect)
 	r.Get(routes.ScaleRouteName).Handler(VarsFunc(handler.Scale))
+	r.Get(routes.ManualScaleRouteName).Handler(VarsFunc(handler.ManualScale))
+	r.Get(routes.RecommendRouteName).Handler(VarsFunc(handler.Recommend))
 
 	scalingHistoryHandler, err := newScalingHistoryHandler(logger, scalingEngineDB)
 	if err != nil {
 		return nil, err
 	}
 	r.Get(routes.GetScalingHistoriesRouteName).Handler(scalingHistoryHandler)
+	r.Get(routes.ExportScalingHistoriesRouteName).Handler(VarsFunc(handler.ExportScalingHistories))
 
 	r.Get(routes.SetActiveScheduleRouteName).Handler(VarsFunc(handler.StartActiveSchedule))
 	r.Get(routes.DeleteActiveScheduleRouteName).Handler(VarsFunc(handler.RemoveActiveSchedule))
 	r.Get(routes.GetActiveSchedulesRouteName).Handler(VarsFunc(handler.GetActiveSchedule))
+	r.Get(routes.GetScalingStateRouteName).Handler(VarsFunc(handler.GetScalingState))
+	r.Get(routes.GetWebhookDeliveriesRouteName).Handler(VarsFunc(handler.GetWebhookDeliveries))
 
 	r.Get(routes.SyncActiveSchedulesRouteName).Handler(VarsFunc(syncHandler.Sync))
 
@@ -241,6 +241,42 @@ var _ = Describe("Server", func() {
 		})
 	})
 
+	Context("when getting scaling state", func() {
+		BeforeEach(func() {
+			uPath, err := route.Get(routes.GetScalingStateRouteName).URLPath("appid", "test-app-id")
+			Expect(err).NotTo(HaveOccurred())
+			urlPath = uPath.Path
+		})
+
+		JustBeforeEach(func() {
+			rsp, err = http.Get(serverUrl + urlPath)
+		})
+
+		It("should return 200", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+			rsp.Body.Close()
+		})
+	})
+
+	Context("when getting webhook deliveries", func() {
+		BeforeEach(func() {
+			uPath, err := route.Get(routes.GetWebhookDeliveriesRouteName).URLPath("appid", "test-app-id")
+			Expect(err).NotTo(HaveOccurred())
+			urlPath = uPath.Path
+		})
+
+		JustBeforeEach(func() {
+			rsp, err = http.Get(serverUrl + urlPath)
+		})
+
+		It("should return 200", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rsp.StatusCode).To(Equal(http.StatusOK))
+			rsp.Body.Close()
+		})
+	})
+
 	Context("when requesting sync shedule", func() {
 		JustBeforeEach(func() {
 			uPath, err := route.Get(routes.SyncActiveSchedulesRouteName).URLPath()
@@ -0,0 +1,178 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/db"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/helpers"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/config"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const (
+	maxLastErrorLength  = 1024
+	maxResponseBodySize = 64 * 1024
+)
+
+// Dispatcher sends the webhook deliveries queued by the scaling engine. Every delivery is claimed before it is sent,
+// so that several scaling engine instances can share the outbox. Failed deliveries are retried with exponential
+// backoff until the maximum number of attempts is reached. Webhooks are only sent to public addresses and the allowed
+// networks of the configuration.
+type Dispatcher struct {
+	logger          lager.Logger
+	policyDB        db.PolicyDB
+	scalingEngineDB db.ScalingEngineDB
+	conf            config.WebhookConfig
+	httpClient      *http.Client
+	clock           clock.Clock
+	dispatching     sync.Mutex
+}
+
+func NewDispatcher(logger lager.Logger, policyDB db.PolicyDB, scalingEngineDB db.ScalingEngineDB, conf config.WebhookConfig, clock clock.Clock) (*Dispatcher, error) {
+	guard, err := helpers.NewAddressGuard(conf.AllowedNetworks)
+	if err != nil {
+		return nil, err
+	}
+	// the guard checks every connection including redirects, a proxy would connect on behalf of the dispatcher instead
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: conf.Timeout, Control: guard.Control}).DialContext
+
+	return &Dispatcher{
+		logger:          logger.Session("webhook-dispatcher"),
+		policyDB:        policyDB,
+		scalingEngineDB: scalingEngineDB,
+		conf:            conf,
+		httpClient:      &http.Client{Timeout: conf.Timeout, Transport: transport},
+		clock:           clock,
+	}, nil
+}
+
+func (d *Dispatcher) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+	ticker := d.clock.NewTicker(d.conf.DispatchInterval)
+
+	d.logger.Info("started", lager.Data{"dispatch_interval": d.conf.DispatchInterval})
+
+	for {
+		go d.Dispatch()
+		select {
+		case <-signals:
+			ticker.Stop()
+			d.logger.Info("stopped")
+			return nil
+		case <-ticker.C():
+		}
+	}
+}
+
+// Dispatch sends the deliveries which are due. It returns right away if the previous dispatch is still running.
+func (d *Dispatcher) Dispatch() {
+	if !d.dispatching.TryLock() {
+		return
+	}
+	defer d.dispatching.Unlock()
+
+	deliveries, err := d.scalingEngineDB.RetrieveDueWebhookDeliveries(d.clock.Now().UnixNano(), d.conf.BatchSize)
+	if err != nil {
+		d.logger.Error("failed-to-retrieve-due-webhook-deliveries", err)
+		return
+	}
+	for _, delivery := range deliveries {
+		d.deliver(delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(delivery *models.WebhookDelivery) {
+	logger := d.logger.WithData(lager.Data{"appId": delivery.AppId, "delivery": delivery.Guid, "subscription": delivery.SubscriptionGuid})
+
+	// the lease covers the attempt, afterwards another instance may retry the delivery
+	claimed, err := d.scalingEngineDB.ClaimWebhookDelivery(delivery, d.clock.Now().Add(2*d.conf.Timeout).UnixNano())
+	if err != nil || !claimed {
+		return
+	}
+
+	subscription, err := d.policyDB.GetWebhookSubscription(context.Background(), delivery.AppId, delivery.SubscriptionGuid)
+	if err != nil {
+		logger.Error("failed-to-get-webhook-subscription", err)
+		return
+	}
+
+	now := d.clock.Now()
+	delivery.UpdatedAt = now.UnixNano()
+	if subscription == nil {
+		delivery.Status = models.WebhookDeliveryStatusFailed
+		delivery.LastError = "webhook subscription deleted"
+	} else if err = d.send(subscription, delivery); err != nil {
+		logger.Info("failed-to-send-webhook", lager.Data{"attempts": delivery.Attempts + 1, "error": err.Error()})
+		delivery.Attempts++
+		delivery.LastError = truncate(err.Error(), maxLastErrorLength)
+		if delivery.Attempts >= d.conf.MaxAttempts {
+			delivery.Status = models.WebhookDeliveryStatusFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts)).UnixNano()
+		}
+	} else {
+		delivery.Attempts++
+		delivery.Status = models.WebhookDeliveryStatusDelivered
+		delivery.LastError = ""
+	}
+
+	err = d.scalingEngineDB.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		logger.Error("failed-to-update-webhook-delivery", err)
+	}
+}
+
+func (d *Dispatcher) send(subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) error {
+	payload := []byte(delivery.Payload)
+	request, err := http.NewRequest(http.MethodPost, subscription.Url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(models.WebhookDeliveryHeader, delivery.Guid)
+	request.Header.Set(models.WebhookSignatureHeader, models.SignWebhookPayload(subscription.Secret, payload))
+
+	response, err := d.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBodySize))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status code %d", response.StatusCode)
+	}
+	return nil
+}
+
+// backoff doubles the delay before the next attempt with every failed attempt, up to the maximum delay.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.conf.RetryBaseDelay
+	for i := 1; i < attempts && delay < d.conf.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.conf.RetryMaxDelay {
+		return d.conf.RetryMaxDelay
+	}
+	return delay
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package webhook_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/fakes"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/models"
+	"code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/config"
+	. "code.cloudfoundry.org/app-autoscaler/src/autoscaler/scalingengine/webhook"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Dispatcher", func() {
+	const payload = `{"delivery_guid":"delivery-guid","event":"scaling"}`
+
+	var (
+		policyDB        *fakes.FakePolicyDB
+		scalingEngineDB *fakes.FakeScalingEngineDB
+		fclock          *fakeclock.FakeClock
+		server          *ghttp.Server
+		dispatcher      *Dispatcher
+		delivery        *models.WebhookDelivery
+		conf            config.WebhookConfig
+	)
+
+	BeforeEach(func() {
+		policyDB = &fakes.FakePolicyDB{}
+		scalingEngineDB = &fakes.FakeScalingEngineDB{}
+		fclock = fakeclock.NewFakeClock(time.Now())
+		server = ghttp.NewServer()
+		conf = config.WebhookConfig{
+			DispatchInterval: 10 * time.Second,
+			BatchSize:        100,
+			MaxAttempts:      3,
+			RetryBaseDelay:   10 * time.Second,
+			RetryMaxDelay:    15 * time.Second,
+			Timeout:          5 * time.Second,
+			AllowedNetworks:  []string{"127.0.0.0/8"},
+		}
+
+		delivery = &models.WebhookDelivery{
+			Guid:             "delivery-guid",
+			SubscriptionGuid: "subscription-guid",
+			AppId:            "an-app-id",
+			Payload:          payload,
+			Status:           models.WebhookDeliveryStatusPending,
+			NextAttemptAt:    fclock.Now().UnixNano(),
+		}
+		scalingEngineDB.RetrieveDueWebhookDeliveriesReturns([]*models.WebhookDelivery{delivery}, nil)
+		scalingEngineDB.ClaimWebhookDeliveryReturns(true, nil)
+		policyDB.GetWebhookSubscriptionReturns(&models.WebhookSubscription{
+			Guid:   "subscription-guid",
+			AppId:  "an-app-id",
+			Url:    server.URL() + "/hook",
+			Secret: "a-secret",
+		}, nil)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		dispatcher, err = NewDispatcher(lagertest.NewTestLogger("webhook-dispatcher-test"), policyDB, scalingEngineDB, conf, fclock)
+		Expect(err).NotTo(HaveOccurred())
+		dispatcher.Dispatch()
+	})
+
+	Context("when the webhook accepts the delivery", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest(http.MethodPost, "/hook"),
+				ghttp.VerifyContentType("application/json"),
+				ghttp.VerifyHeaderKV(models.WebhookDeliveryHeader, "delivery-guid"),
+				ghttp.VerifyHeaderKV(models.WebhookSignatureHeader, models.SignWebhookPayload("a-secret", []byte(payload))),
+				ghttp.VerifyBody([]byte(payload)),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			))
+		})
+
+		It("claims, sends and marks the delivery as delivered", func() {
+			now, limit := scalingEngineDB.RetrieveDueWebhookDeliveriesArgsForCall(0)
+			Expect(now).To(Equal(fclock.Now().UnixNano()))
+			Expect(limit).To(Equal(100))
+
+			claimed, leaseUntil := scalingEngineDB.ClaimWebhookDeliveryArgsForCall(0)
+			Expect(claimed).To(Equal(delivery))
+			Expect(leaseUntil).To(Equal(fclock.Now().Add(10 * time.Second).UnixNano()))
+
+			_, appId, guid := policyDB.GetWebhookSubscriptionArgsForCall(0)
+			Expect(appId).To(Equal("an-app-id"))
+			Expect(guid).To(Equal("subscription-guid"))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+			Expect(scalingEngineDB.UpdateWebhookDeliveryCallCount()).To(Equal(1))
+			updated := scalingEngineDB.UpdateWebhookDeliveryArgsForCall(0)
+			Expect(updated.Status).To(Equal(models.WebhookDeliveryStatusDelivered))
+			Expect(updated.Attempts).To(Equal(1))
+			Expect(updated.UpdatedAt).To(Equal(fclock.Now().UnixNano()))
+		})
+	})
+
+	Context("when the webhook rejects the delivery", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusInternalServerError, nil))
+		})
+
+		It("schedules a retry after the base delay", func() {
+			updated := scalingEngineDB.UpdateWebhookDeliveryArgsForCall(0)
+			Expect(updated.Status).To(Equal(models.WebhookDeliveryStatusPending))
+			Expect(updated.Attempts).To(Equal(1))
+			Expect(updated.LastError).To(Equal("webhook responded with status code 500"))
+			Expect(updated.NextAttemptAt).To(Equal(fclock.Now().Add(10 * time.Second).UnixNano()))
+		})
+
+		Context("and it has been attempted before", func() {
+			BeforeEach(func() {
+				delivery.Attempts = 1
+			})
+
+			It("doubles the delay up to the maximum delay", func() {
+				updated := scalingEngineDB.UpdateWebhookDeliveryArgsForCall(0)
+				Expect(updated.Status).To(Equal(models.WebhookDeliveryStatusPending))
+				Expect(updated.Attempts).To(Equal(2))
+				Expect(updated.NextAttemptAt).To(Equal(fclock.Now().Add(15 * time.Second).UnixNano()))
+			})
+		})
+
+		Context("and it was the last attempt", func() {
+			BeforeEach(func() {
+				delivery.Attempts = 2
+			})
+
+			It("marks the delivery as failed", func() {
+				updated := scalingEngineDB.UpdateWebhookDeliveryArgsForCall(0)
+				Expect(updated.Status).To(Equal(models.WebhookDeliveryStatusFailed))
+				Expect(updated.Attempts).To(Equal(3))
+				Expect(updated.LastError).To(Equal("webhook responded with status code 500"))
+			})
+		})
+	})
+
+	Context("when the webhook responds with a large body", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, strings.Repeat("a", 1024*1024)))
+		})
+
+		It("reads only the beginning of the body and marks the delivery as delivered", func() {
+			updated := scalingEngineDB.UpdateWebhookDeliveryArgsForCall(0)
+			Expect(updated.Status).To(Equal(models.WebhookDeliveryStatusDelivered))
+		})
+	})
+
+	Context("when the webhook is sent to an internal address", func() {
+		BeforeEach(func() {
+			conf.AllowedNetworks = nil
+		})
+
+		It("refuses to connect and schedules a retry", func() {
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+			updated := scalingEngineDB.UpdateWebhookDeliveryArgsForCall(0)
+			Expect(updated.Status).To(Equal(models.WebhookDeliveryStatusPending))
+			Expect(updated.LastError).To(ContainSubstring("connecting to 127.0.0.1 is not allowed"))
+		})
+	})
+
+	Context("when the delivery is claimed by another instance", func() {
+		BeforeEach(func() {
+			scalingEngineDB.ClaimWebhookDeliveryReturns(false, nil)
+		})
+
+		It("does not send the delivery", func() {
+			Expect(policyDB.GetWebhookSubscriptionCallCount()).To(BeZero())
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+			Expect(scalingEngineDB.UpdateWebhookDeliveryCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the subscription has been deleted", func() {
+		BeforeEach(func() {
+			policyDB.GetWebhookSubscriptionReturns(nil, nil)
+		})
+
+		It("marks the delivery as failed without sending it", func() {
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+			updated := scalingEngineDB.UpdateWebhookDeliveryArgsForCall(0)
+			Expect(updated.Status).To(Equal(models.WebhookDeliveryStatusFailed))
+			Expect(updated.LastError).To(Equal("webhook subscription deleted"))
+		})
+	})
+
+	Context("when getting the subscription fails", func() {
+		BeforeEach(func() {
+			policyDB.GetWebhookSubscriptionReturns(nil, errors.New("database error"))
+		})
+
+		It("leaves the delivery to be retried once the claim expires", func() {
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+			Expect(scalingEngineDB.UpdateWebhookDeliveryCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when retrieving the due deliveries fails", func() {
+		BeforeEach(func() {
+			scalingEngineDB.RetrieveDueWebhookDeliveriesReturns(nil, errors.New("database error"))
+		})
+
+		It("does not send anything", func() {
+			Expect(scalingEngineDB.ClaimWebhookDeliveryCallCount()).To(BeZero())
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+})